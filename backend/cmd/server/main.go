@@ -17,16 +17,35 @@ import (
 	"github.com/Schieck/packs-calculator/internal/adapter/config"
 	httpAdapter "github.com/Schieck/packs-calculator/internal/adapter/http"
 	"github.com/Schieck/packs-calculator/internal/adapter/repository"
+	"github.com/Schieck/packs-calculator/internal/adapter/repository/cache"
+	"github.com/Schieck/packs-calculator/internal/adapter/repository/memory"
+	"github.com/Schieck/packs-calculator/internal/adapter/repository/sqlite"
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
 
+	analyticsService "github.com/Schieck/packs-calculator/internal/service/analytics"
+	auditService "github.com/Schieck/packs-calculator/internal/service/audit"
 	authService "github.com/Schieck/packs-calculator/internal/service/auth"
+	calculationService "github.com/Schieck/packs-calculator/internal/service/calculation"
 	healthService "github.com/Schieck/packs-calculator/internal/service/health"
+	inventoryService "github.com/Schieck/packs-calculator/internal/service/inventory"
 	packCalculatorService "github.com/Schieck/packs-calculator/internal/service/pack_calculator"
 	packConfigurationService "github.com/Schieck/packs-calculator/internal/service/pack_configuration"
+	packTypeService "github.com/Schieck/packs-calculator/internal/service/pack_type"
+	warehouseService "github.com/Schieck/packs-calculator/internal/service/warehouse"
+	webhookService "github.com/Schieck/packs-calculator/internal/service/webhook"
 
+	analyticsUseCase "github.com/Schieck/packs-calculator/internal/usecase/analytics"
+	auditUseCase "github.com/Schieck/packs-calculator/internal/usecase/audit"
 	authUseCase "github.com/Schieck/packs-calculator/internal/usecase/auth"
+	cacheUseCase "github.com/Schieck/packs-calculator/internal/usecase/cache"
+	calculationUseCase "github.com/Schieck/packs-calculator/internal/usecase/calculation"
 	healthUseCase "github.com/Schieck/packs-calculator/internal/usecase/health"
+	inventoryUseCase "github.com/Schieck/packs-calculator/internal/usecase/inventory"
 	packCalculatorUseCase "github.com/Schieck/packs-calculator/internal/usecase/pack_calculator"
 	packConfigurationUseCase "github.com/Schieck/packs-calculator/internal/usecase/pack_configuration"
+	packTypeUseCase "github.com/Schieck/packs-calculator/internal/usecase/pack_type"
+	warehouseUseCase "github.com/Schieck/packs-calculator/internal/usecase/warehouse"
+	webhookUseCase "github.com/Schieck/packs-calculator/internal/usecase/webhook"
 
 	"github.com/Schieck/packs-calculator/pkg/db"
 	"github.com/Schieck/packs-calculator/pkg/middleware"
@@ -56,23 +75,84 @@ func main() {
 	// Configure Swagger host dynamically
 	configureSwaggerHost(cfg.Server.Port)
 
-	// Initialize database
-	database, err := db.NewConnection(cfg.Database.DSN)
-	if err != nil {
-		slog.Error("Failed to initialize database", "error", err)
+	// Initialize storage. Pack configurations, pack types and the audit log
+	// work on every backend; the features needing the other repositories are
+	// only available on Postgres and left out elsewhere.
+	var (
+		database         *db.DB
+		postgresDatabase *db.DB
+		packConfigRepo   entity.PackConfigurationRepository
+		packTypeRepo     entity.PackTypeRepository
+		auditRepo        entity.AuditRepository
+	)
+	switch cfg.Storage.Backend {
+	case config.StorageDatabase:
+		var err error
+		database, err = db.NewConnection(cfg.Database.DSN)
+		if err != nil {
+			slog.Error("Failed to initialize database", "error", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		if database.Driver == db.DriverSQLite {
+			slog.Warn("Using SQLite storage: inventory, warehouses, calculation history, analytics and webhooks are disabled")
+			packConfigRepo = sqlite.NewPackConfigurationRepository(database.DB, cfg.Database.QueryTimeout)
+			packTypeRepo = sqlite.NewPackTypeRepository(database.DB)
+			auditRepo = sqlite.NewAuditRepository(database.DB)
+			break
+		}
+		postgresDatabase = database
+		packConfigRepo = repository.NewPackConfigurationRepository(database.DB, cfg.Database.QueryTimeout)
+		packTypeRepo = repository.NewPackTypeRepository(database.DB)
+		auditRepo = repository.NewAuditRepository(database.DB)
+	case config.StorageMemory:
+		slog.Warn("Using in-memory storage: data is lost on restart, and inventory, warehouses, calculation history, analytics and webhooks are disabled")
+		memoryPackConfigRepo := memory.NewPackConfigurationRepository()
+		packConfigRepo = memoryPackConfigRepo
+		packTypeRepo = memory.NewPackTypeRepository(memoryPackConfigRepo)
+		auditRepo = memory.NewAuditRepository(memoryPackConfigRepo)
+	default:
+		slog.Error("Unknown STORAGE", "storage", cfg.Storage.Backend, "supported", []string{config.StorageDatabase, config.StorageMemory})
 		os.Exit(1)
 	}
-	defer database.Close()
 
-	// Initialize repositories
-	packConfigRepo := repository.NewPackConfigurationRepository(database.DB)
+	// Serve repeated configuration reads from memory. Writes through this
+	// process invalidate it directly, and on Postgres writes by any replica
+	// reach it as notifications. In-memory storage has nothing to save.
+	var configCache *cache.PackConfigurationRepository
+	if database != nil && cfg.Cache.TTL > 0 {
+		configCache = cache.NewPackConfigurationRepository(packConfigRepo, cfg.Cache.TTL, cfg.Cache.MaxEntries)
+		packConfigRepo = configCache
+
+		if postgresDatabase != nil {
+			stopListening, err := repository.ListenForPackConfigurationChanges(cfg.Database.DSN, configCache)
+			if err != nil {
+				slog.Error("Failed to listen for pack configuration changes", "error", err)
+				os.Exit(1)
+			}
+			defer stopListening()
+		}
+		slog.Info("Pack configuration cache enabled", "ttl", cfg.Cache.TTL, "max_entries", cfg.Cache.MaxEntries)
+	}
+
+	if err := authService.ValidateTenantSecrets(cfg.Auth.AuthSecret, cfg.Auth.TenantSecrets); err != nil {
+		slog.Error("Invalid TENANT_SECRETS", "error", err)
+		os.Exit(1)
+	}
 
 	// Initialize services
-	authSvc := authService.NewAuthServiceWithDefaults(cfg.Auth.JWTSecret, cfg.Auth.AuthSecret)
-	healthSvc := healthService.NewHealthService(database, "1.0.0")
+	authSvc := authService.NewAuthServiceWithDefaults(cfg.Auth.JWTSecret, cfg.Auth.AuthSecret, cfg.Auth.TenantSecrets)
+	var healthDatabase healthService.Pinger
+	if database != nil {
+		healthDatabase = database
+	}
+	healthSvc := healthService.NewHealthService(healthDatabase, "1.0.0")
 	packCalculatorSvc := packCalculatorService.NewPackCalculatorService()
 	packSizeProcessorSvc := packCalculatorService.NewPackSizeProcessorService()
-	packConfigSvc := packConfigurationService.NewPackConfigurationService(packConfigRepo)
+	packConfigSvc := packConfigurationService.NewPackConfigurationService(packConfigRepo, packTypeRepo, cfg.Packs.AllowDuplicatePackSizes)
+	packTypeSvc := packTypeService.NewPackTypeService(packTypeRepo)
+	auditSvc := auditService.NewAuditService(auditRepo)
 
 	// Initialize use cases
 	authenticateUseCase := authUseCase.NewAuthenticateUseCase(authSvc, logger)
@@ -81,26 +161,191 @@ func main() {
 	calculatePacksUseCase := packCalculatorUseCase.NewCalculatePacksUseCase(packCalculatorSvc, packSizeProcessorSvc, logger)
 
 	// Pack configuration use cases
-	getAllConfigurationsUseCase := packConfigurationUseCase.NewGetAllConfigurationsUseCase(packConfigSvc, logger)
+	listConfigurationsUseCase := packConfigurationUseCase.NewListConfigurationsUseCase(packConfigSvc, logger)
 	getConfigurationByIDUseCase := packConfigurationUseCase.NewGetConfigurationByIDUseCase(packConfigSvc, logger)
 	getDefaultConfigurationUseCase := packConfigurationUseCase.NewGetDefaultConfigurationUseCase(packConfigSvc, logger)
+	getEffectiveConfigurationUseCase := packConfigurationUseCase.NewGetEffectiveConfigurationUseCase(packConfigSvc, logger)
 	createConfigurationUseCase := packConfigurationUseCase.NewCreateConfigurationUseCase(packConfigSvc, logger)
+	upsertConfigurationUseCase := packConfigurationUseCase.NewUpsertConfigurationUseCase(packConfigSvc, logger)
 	updateConfigurationUseCase := packConfigurationUseCase.NewUpdateConfigurationUseCase(packConfigSvc, logger)
+	patchConfigurationUseCase := packConfigurationUseCase.NewPatchConfigurationUseCase(packConfigSvc, logger)
 	deleteConfigurationUseCase := packConfigurationUseCase.NewDeleteConfigurationUseCase(packConfigSvc, logger)
 	setDefaultConfigurationUseCase := packConfigurationUseCase.NewSetDefaultConfigurationUseCase(packConfigSvc, logger)
+	getConfigurationVersionsUseCase := packConfigurationUseCase.NewGetConfigurationVersionsUseCase(packConfigSvc, logger)
+	getConfigurationVersionUseCase := packConfigurationUseCase.NewGetConfigurationVersionUseCase(packConfigSvc, logger)
+	restoreConfigurationVersionUseCase := packConfigurationUseCase.NewRestoreConfigurationVersionUseCase(packConfigSvc, logger)
+	restoreConfigurationUseCase := packConfigurationUseCase.NewRestoreConfigurationUseCase(packConfigSvc, logger)
+	purgeConfigurationUseCase := packConfigurationUseCase.NewPurgeConfigurationUseCase(packConfigSvc, logger)
+	exportConfigurationsUseCase := packConfigurationUseCase.NewExportConfigurationsUseCase(packConfigSvc, logger)
+	importConfigurationsUseCase := packConfigurationUseCase.NewImportConfigurationsUseCase(packConfigSvc, logger)
+	findEquivalentUseCase := packConfigurationUseCase.NewFindEquivalentConfigurationsUseCase(packConfigSvc, logger)
+
+	// Pack type use cases
+	listPackTypesUseCase := packTypeUseCase.NewListPackTypesUseCase(packTypeSvc, logger)
+	getPackTypeByIDUseCase := packTypeUseCase.NewGetPackTypeByIDUseCase(packTypeSvc, logger)
+	createPackTypeUseCase := packTypeUseCase.NewCreatePackTypeUseCase(packTypeSvc, logger)
+	updatePackTypeUseCase := packTypeUseCase.NewUpdatePackTypeUseCase(packTypeSvc, logger)
+	deletePackTypeUseCase := packTypeUseCase.NewDeletePackTypeUseCase(packTypeSvc, logger)
+
+	// Audit use cases
+	listAuditEntriesUseCase := auditUseCase.NewListAuditEntriesUseCase(auditSvc, logger)
+
+	// Inventory, warehouses, calculation history and analytics need Postgres
+	var (
+		calculateWithStockUseCase *inventoryUseCase.CalculateWithStockUseCase
+		recordCalculationUseCase  *calculationUseCase.RecordCalculationUseCase
+		pruneCalculationsUseCase  *calculationUseCase.PruneCalculationsUseCase
+		calculationHistoryHandler *httpAdapter.CalculationHistoryHandler
+		analyticsHandler          *httpAdapter.AnalyticsHandler
+		inventoryHandler          *httpAdapter.InventoryHandler
+		warehouseHandler          *httpAdapter.WarehouseHandler
+		dispatchWebhooksUseCase   *webhookUseCase.DispatchWebhooksUseCase
+		webhookHandler            *httpAdapter.WebhookHandler
+	)
+	if postgresDatabase != nil {
+		inventoryRepo := repository.NewInventoryRepository(postgresDatabase.DB)
+		warehouseRepo := repository.NewWarehouseRepository(postgresDatabase.DB)
+		calculationRepo := repository.NewCalculationRepository(postgresDatabase.DB)
+		analyticsRepo := repository.NewAnalyticsRepository(postgresDatabase.DB)
+		webhookRepo := repository.NewWebhookRepository(postgresDatabase.DB)
+
+		inventorySvc := inventoryService.NewInventoryService(
+			inventoryRepo,
+			packCalculatorService.NewStockAwarePackCalculatorService(),
+			packSizeProcessorSvc,
+		)
+		warehouseSvc := warehouseService.NewWarehouseService(
+			warehouseRepo,
+			packConfigRepo,
+			inventoryRepo,
+			packCalculatorService.NewSourcingSolverService(),
+			packSizeProcessorSvc,
+			cfg.Sourcing.SplitPenalty,
+		)
+		calculationSvc := calculationService.NewCalculationService(calculationRepo)
+		analyticsSvc := analyticsService.NewAnalyticsService(analyticsRepo)
+		webhookSvc := webhookService.NewWebhookService(webhookRepo, cfg.Webhooks.AllowPrivate)
+		webhookDispatcher := webhookService.NewDispatcher(
+			webhookRepo,
+			webhookService.NewHTTPClient(cfg.Webhooks.Timeout, cfg.Webhooks.AllowPrivate),
+			webhookService.RetryPolicy{
+				MaxAttempts:    cfg.Webhooks.MaxAttempts,
+				InitialBackoff: cfg.Webhooks.RetryBackoff,
+				MaxBackoff:     cfg.Webhooks.MaxBackoff,
+			},
+		)
+
+		// Inventory use cases
+		getStockUseCase := inventoryUseCase.NewGetStockUseCase(inventorySvc, logger)
+		setStockUseCase := inventoryUseCase.NewSetStockUseCase(inventorySvc, logger)
+		calculateWithStockUseCase = inventoryUseCase.NewCalculateWithStockUseCase(inventorySvc, logger)
+		reserveOrderUseCase := inventoryUseCase.NewReserveOrderUseCase(inventorySvc, logger)
+		getReservationUseCase := inventoryUseCase.NewGetReservationUseCase(inventorySvc, logger)
+		cancelReservationUseCase := inventoryUseCase.NewCancelReservationUseCase(inventorySvc, logger)
+
+		// Warehouse use cases
+		getAllWarehousesUseCase := warehouseUseCase.NewGetAllWarehousesUseCase(warehouseSvc, logger)
+		getWarehouseByIDUseCase := warehouseUseCase.NewGetWarehouseByIDUseCase(warehouseSvc, logger)
+		createWarehouseUseCase := warehouseUseCase.NewCreateWarehouseUseCase(warehouseSvc, logger)
+		deleteWarehouseUseCase := warehouseUseCase.NewDeleteWarehouseUseCase(warehouseSvc, logger)
+		planSourcingUseCase := warehouseUseCase.NewPlanSourcingUseCase(warehouseSvc, logger)
+
+		// Calculation history use cases
+		recordCalculationUseCase = calculationUseCase.NewRecordCalculationUseCase(calculationSvc, logger)
+		getCalculationUseCase := calculationUseCase.NewGetCalculationUseCase(calculationSvc, logger)
+		listCalculationsUseCase := calculationUseCase.NewListCalculationsUseCase(calculationSvc, logger)
+		pruneCalculationsUseCase = calculationUseCase.NewPruneCalculationsUseCase(calculationSvc, logger)
+
+		// Analytics use cases
+		getSurplusTrendUseCase := analyticsUseCase.NewGetSurplusTrendUseCase(analyticsSvc, logger)
+		getExactMatchRatesUseCase := analyticsUseCase.NewGetExactMatchRatesUseCase(analyticsSvc, logger)
+		getPackSizeUsageUseCase := analyticsUseCase.NewGetPackSizeUsageUseCase(analyticsSvc, logger)
+		getPacksPerOrderUseCase := analyticsUseCase.NewGetPacksPerOrderUseCase(analyticsSvc, logger)
+
+		// Webhook use cases
+		listWebhooksUseCase := webhookUseCase.NewListWebhooksUseCase(webhookSvc, logger)
+		createWebhookUseCase := webhookUseCase.NewCreateWebhookUseCase(webhookSvc, logger)
+		deleteWebhookUseCase := webhookUseCase.NewDeleteWebhookUseCase(webhookSvc, logger)
+		listDeadLettersUseCase := webhookUseCase.NewListDeadLettersUseCase(webhookSvc, logger)
+		redeliverUseCase := webhookUseCase.NewRedeliverUseCase(webhookSvc, logger)
+		dispatchWebhooksUseCase = webhookUseCase.NewDispatchWebhooksUseCase(webhookDispatcher, logger)
+
+		calculationHistoryHandler = httpAdapter.NewCalculationHistoryHandler(getCalculationUseCase, listCalculationsUseCase, logger)
+		analyticsHandler = httpAdapter.NewAnalyticsHandler(
+			getSurplusTrendUseCase,
+			getExactMatchRatesUseCase,
+			getPackSizeUsageUseCase,
+			getPacksPerOrderUseCase,
+			logger,
+		)
+		inventoryHandler = httpAdapter.NewInventoryHandler(
+			getStockUseCase,
+			setStockUseCase,
+			calculateWithStockUseCase,
+			reserveOrderUseCase,
+			getReservationUseCase,
+			cancelReservationUseCase,
+			logger,
+		)
+		warehouseHandler = httpAdapter.NewWarehouseHandler(
+			getAllWarehousesUseCase,
+			getWarehouseByIDUseCase,
+			createWarehouseUseCase,
+			deleteWarehouseUseCase,
+			planSourcingUseCase,
+			logger,
+		)
+		webhookHandler = httpAdapter.NewWebhookHandler(
+			listWebhooksUseCase,
+			createWebhookUseCase,
+			deleteWebhookUseCase,
+			listDeadLettersUseCase,
+			redeliverUseCase,
+			logger,
+		)
+	}
 
 	// Initialize HTTP handlers
 	authHandler := httpAdapter.NewAuthHandler(authenticateUseCase, logger)
 	healthHandler := httpAdapter.NewHealthHandler(healthCheckUseCase, logger)
-	packCalculatorHandler := httpAdapter.NewCalculatorHandler(calculatePacksUseCase, logger)
+	packCalculatorHandler := httpAdapter.NewCalculatorHandler(
+		calculatePacksUseCase,
+		calculateWithStockUseCase,
+		getEffectiveConfigurationUseCase,
+		getConfigurationVersionUseCase,
+		recordCalculationUseCase,
+		logger,
+	)
+	auditHandler := httpAdapter.NewAuditHandler(listAuditEntriesUseCase, logger)
 	packConfigHandler := httpAdapter.NewPackConfigurationHandler(
-		getAllConfigurationsUseCase,
+		listConfigurationsUseCase,
 		getConfigurationByIDUseCase,
 		getDefaultConfigurationUseCase,
 		createConfigurationUseCase,
+		upsertConfigurationUseCase,
 		updateConfigurationUseCase,
+		patchConfigurationUseCase,
 		deleteConfigurationUseCase,
 		setDefaultConfigurationUseCase,
+		getConfigurationVersionsUseCase,
+		restoreConfigurationVersionUseCase,
+		restoreConfigurationUseCase,
+		purgeConfigurationUseCase,
+		exportConfigurationsUseCase,
+		importConfigurationsUseCase,
+		findEquivalentUseCase,
+		logger,
+	)
+	var cacheHandler *httpAdapter.CacheHandler
+	if configCache != nil {
+		cacheHandler = httpAdapter.NewCacheHandler(cacheUseCase.NewGetCacheStatsUseCase(configCache, logger), logger)
+	}
+	packTypeHandler := httpAdapter.NewPackTypeHandler(
+		listPackTypesUseCase,
+		getPackTypeByIDUseCase,
+		createPackTypeUseCase,
+		updatePackTypeUseCase,
+		deletePackTypeUseCase,
 		logger,
 	)
 
@@ -112,9 +357,11 @@ func main() {
 	router := gin.New()
 
 	// Middleware
+	router.Use(middleware.RequestID())
 	router.Use(middleware.Logger(logger))
 	router.Use(middleware.Recovery(logger))
 	router.Use(middleware.CORS())
+	router.Use(middleware.ErrorHandler())
 
 	// Swagger documentation
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -136,11 +383,62 @@ func main() {
 
 		protected.GET("/pack-configurations", packConfigHandler.GetAllConfigurations)
 		protected.GET("/pack-configurations/default", packConfigHandler.GetDefaultConfiguration)
+		protected.GET("/pack-configurations/export", packConfigHandler.ExportConfigurations)
+		protected.POST("/pack-configurations/import", packConfigHandler.ImportConfigurations)
+		protected.GET("/pack-configurations/equivalent", packConfigHandler.FindEquivalentConfigurations)
 		protected.GET("/pack-configurations/:id", packConfigHandler.GetConfigurationByID)
 		protected.POST("/pack-configurations", packConfigHandler.CreateConfiguration)
 		protected.PUT("/pack-configurations/:id", packConfigHandler.UpdateConfiguration)
+		protected.PATCH("/pack-configurations/:id", packConfigHandler.PatchConfiguration)
 		protected.DELETE("/pack-configurations/:id", packConfigHandler.DeleteConfiguration)
 		protected.PATCH("/pack-configurations/:id/default", packConfigHandler.SetDefaultConfiguration)
+		protected.GET("/pack-configurations/:id/versions", packConfigHandler.GetConfigurationVersions)
+		protected.POST("/pack-configurations/:id/versions/:version/restore", packConfigHandler.RestoreConfigurationVersion)
+		protected.POST("/pack-configurations/:id/restore", packConfigHandler.RestoreConfiguration)
+		protected.DELETE("/pack-configurations/:id/purge", middleware.RequireSubject(cfg.Auth.AdminSubjects), packConfigHandler.PurgeConfiguration)
+
+		protected.GET("/pack-types", packTypeHandler.ListPackTypes)
+		protected.GET("/pack-types/:id", packTypeHandler.GetPackTypeByID)
+		protected.POST("/pack-types", packTypeHandler.CreatePackType)
+		protected.PUT("/pack-types/:id", packTypeHandler.UpdatePackType)
+		protected.DELETE("/pack-types/:id", packTypeHandler.DeletePackType)
+
+		protected.GET("/audit", auditHandler.ListAuditEntries)
+
+		if cacheHandler != nil {
+			protected.GET("/cache/stats", cacheHandler.GetStats)
+		}
+
+		if postgresDatabase != nil {
+			protected.GET("/calculations", calculationHistoryHandler.ListCalculations)
+			protected.GET("/calculations/:id", calculationHistoryHandler.GetCalculation)
+
+			protected.GET("/analytics/surplus", analyticsHandler.GetSurplus)
+			protected.GET("/analytics/exact-match-rate", analyticsHandler.GetExactMatchRate)
+			protected.GET("/analytics/pack-sizes", analyticsHandler.GetPackSizes)
+			protected.GET("/analytics/packs-per-order", analyticsHandler.GetPacksPerOrder)
+
+			protected.GET("/inventory/locations/:location/stock", inventoryHandler.GetStock)
+			protected.PUT("/inventory/locations/:location/stock/:pack_size", inventoryHandler.SetStock)
+			protected.POST("/inventory/locations/:location/calculate", inventoryHandler.Calculate)
+			protected.POST("/inventory/locations/:location/reservations", inventoryHandler.Reserve)
+			protected.GET("/inventory/reservations/:id", inventoryHandler.GetReservation)
+			protected.POST("/inventory/reservations/:id/cancel", inventoryHandler.CancelReservation)
+
+			protected.GET("/warehouses", warehouseHandler.GetAllWarehouses)
+			protected.GET("/warehouses/:id", warehouseHandler.GetWarehouseByID)
+			protected.POST("/warehouses", warehouseHandler.CreateWarehouse)
+			protected.DELETE("/warehouses/:id", warehouseHandler.DeleteWarehouse)
+			protected.POST("/warehouses/calculate", warehouseHandler.Calculate)
+
+			webhooks := protected.Group("/webhooks", middleware.RequireSubject(cfg.Auth.AdminSubjects))
+			webhooks.GET("", webhookHandler.ListWebhooks)
+			webhooks.POST("", webhookHandler.CreateWebhook)
+			webhooks.DELETE("/:id", webhookHandler.DeleteWebhook)
+			webhooks.GET("/dead-letters", webhookHandler.ListDeadLetters)
+			webhooks.POST("/dead-letters/:id/redeliver", webhookHandler.Redeliver)
+		}
+
 	}
 
 	// Setup HTTP server
@@ -152,6 +450,18 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// Prune calculation history in the background
+	if pruneCalculationsUseCase != nil {
+		stopPruning := startCalculationPruning(pruneCalculationsUseCase, cfg.History.Retention, cfg.History.PruneInterval)
+		defer stopPruning()
+	}
+
+	// Deliver configuration change events to webhooks in the background
+	if dispatchWebhooksUseCase != nil {
+		stopDispatch := startWebhookDispatch(dispatchWebhooksUseCase, cfg.Webhooks.DispatchInterval)
+		defer stopDispatch()
+	}
+
 	// Start server in goroutine
 	go func() {
 		slog.Info("Server starting", "port", cfg.Server.Port)
@@ -207,3 +517,67 @@ func configureSwaggerHost(port string) {
 
 	slog.Info("Swagger configured", "host", docs.SwaggerInfo.Host, "schemes", docs.SwaggerInfo.Schemes)
 }
+
+// startCalculationPruning periodically deletes calculations older than the
+// retention period. The returned function stops the loop.
+func startCalculationPruning(pruneUseCase *calculationUseCase.PruneCalculationsUseCase, retention, interval time.Duration) func() {
+	if retention <= 0 || interval <= 0 {
+		slog.Info("Calculation history pruning disabled")
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			// Errors are logged by the use case; the next tick retries
+			_, _ = pruneUseCase.Execute(retention)
+
+			select {
+			case <-ticker.C:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	slog.Info("Calculation history pruning started", "retention", retention, "interval", interval)
+	return func() { close(done) }
+}
+
+// startWebhookDispatch periodically fans out outbox events and sends the
+// deliveries that are due. The returned function stops the loop, cancelling
+// deliveries in flight; they are retried once their lease runs out.
+func startWebhookDispatch(dispatchUseCase *webhookUseCase.DispatchWebhooksUseCase, interval time.Duration) func() {
+	if interval <= 0 {
+		slog.Info("Webhook dispatch disabled")
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			// Errors are logged by the use case; the next tick retries
+			_, _ = dispatchUseCase.Execute(ctx)
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	slog.Info("Webhook dispatch started", "interval", interval)
+	return func() {
+		cancel()
+		<-done
+	}
+}
@@ -0,0 +1,119 @@
+// Command webhook-receiver is a local endpoint for trying out webhooks
+// offline: it checks each delivery's signature, logs the event and, when
+// asked to, fails deliveries so that retries and dead letters can be watched.
+//
+//	go run ./cmd/webhook-receiver -secret <webhook secret> -fail 3
+//
+// then register http://localhost:9090/ as a webhook with the same secret.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Schieck/packs-calculator/pkg/webhook"
+)
+
+// maxBodySize bounds the deliveries the receiver reads
+const maxBodySize = 1 << 20
+
+func main() {
+	addr := flag.String("addr", ":9090", "address to listen on")
+	secret := flag.String("secret", os.Getenv("WEBHOOK_SECRET"), "webhook secret to verify signatures with (default $WEBHOOK_SECRET)")
+	fail := flag.Int("fail", 0, "answer the first n deliveries with 503 Service Unavailable")
+	tolerance := flag.Duration("tolerance", 5*time.Minute, "how far a delivery's timestamp may be from now; 0 accepts any")
+	flag.Parse()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	if *secret == "" {
+		logger.Error("A webhook secret is required: pass -secret or set WEBHOOK_SECRET")
+		os.Exit(2)
+	}
+
+	receiver := &receiver{secret: *secret, failuresLeft: *fail, tolerance: *tolerance, seen: map[int64]bool{}, logger: logger}
+	logger.Info("Webhook receiver listening", "addr", *addr, "fail", *fail)
+	if err := http.ListenAndServe(*addr, receiver); err != nil {
+		logger.Error("Webhook receiver stopped", "error", err)
+		os.Exit(1)
+	}
+}
+
+type receiver struct {
+	secret    string
+	tolerance time.Duration
+	logger    *slog.Logger
+
+	mu           sync.Mutex
+	failuresLeft int
+	// seen holds the IDs of the events received so far: delivery is at least
+	// once, so an event can arrive twice
+	seen map[int64]bool
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxBodySize))
+	if err != nil {
+		r.logger.Warn("Failed to read delivery", "error", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	delivery := req.Header.Get(webhook.DeliveryHeader)
+	err = webhook.Verify(r.secret, req.Header.Get(webhook.TimestampHeader), req.Header.Get(webhook.SignatureHeader), body, time.Now(), r.tolerance)
+	if err != nil {
+		r.logger.Warn("Rejected delivery", "delivery", delivery, "error", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var event struct {
+		ID         int64           `json:"id"`
+		Tenant     string          `json:"tenant"`
+		Type       string          `json:"type"`
+		Data       json.RawMessage `json:"data"`
+		OccurredAt time.Time       `json:"occurred_at"`
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		r.logger.Warn("Rejected malformed event", "delivery", delivery, "error", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	r.mu.Lock()
+	failing := r.failuresLeft > 0
+	if failing {
+		r.failuresLeft--
+	}
+	duplicate := !failing && r.seen[event.ID]
+	if !failing {
+		r.seen[event.ID] = true
+	}
+	r.mu.Unlock()
+
+	if failing {
+		r.logger.Info("Failing delivery on purpose", "delivery", delivery, "event", event.ID, "type", event.Type)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	r.logger.Info("Received event",
+		"delivery", delivery,
+		"event", event.ID,
+		"type", event.Type,
+		"tenant", event.Tenant,
+		"occurred_at", event.OccurredAt,
+		"duplicate", duplicate,
+		"data", string(event.Data))
+	w.WriteHeader(http.StatusNoContent)
+}
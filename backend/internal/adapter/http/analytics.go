@@ -0,0 +1,260 @@
+package http
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+	"github.com/Schieck/packs-calculator/internal/dto"
+	analyticsUseCase "github.com/Schieck/packs-calculator/internal/usecase/analytics"
+	"github.com/gin-gonic/gin"
+)
+
+type AnalyticsHandler struct {
+	getSurplusTrendUseCase    *analyticsUseCase.GetSurplusTrendUseCase
+	getExactMatchRatesUseCase *analyticsUseCase.GetExactMatchRatesUseCase
+	getPackSizeUsageUseCase   *analyticsUseCase.GetPackSizeUsageUseCase
+	getPacksPerOrderUseCase   *analyticsUseCase.GetPacksPerOrderUseCase
+	logger                    *slog.Logger
+}
+
+func NewAnalyticsHandler(
+	getSurplusTrendUseCase *analyticsUseCase.GetSurplusTrendUseCase,
+	getExactMatchRatesUseCase *analyticsUseCase.GetExactMatchRatesUseCase,
+	getPackSizeUsageUseCase *analyticsUseCase.GetPackSizeUsageUseCase,
+	getPacksPerOrderUseCase *analyticsUseCase.GetPacksPerOrderUseCase,
+	logger *slog.Logger,
+) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		getSurplusTrendUseCase:    getSurplusTrendUseCase,
+		getExactMatchRatesUseCase: getExactMatchRatesUseCase,
+		getPackSizeUsageUseCase:   getPackSizeUsageUseCase,
+		getPacksPerOrderUseCase:   getPacksPerOrderUseCase,
+		logger:                    logger,
+	}
+}
+
+// GetSurplus handles GET /analytics/surplus
+// @Summary Surplus Trend
+// @Description Total surplus shipped per time bucket
+// @Tags analytics
+// @Produce json
+// @Produce text/csv
+// @Param from query string false "Inclusive start (RFC3339 or YYYY-MM-DD, default 30 days before to)"
+// @Param to query string false "Exclusive end (RFC3339 or YYYY-MM-DD, default now)"
+// @Param bucket query string false "Time bucket: hour, day, week or month (default day)"
+// @Param configuration_id query int false "Only calculations made with this configuration"
+// @Param format query string false "Response format: json or csv (default json)"
+// @Success 200 {object} dto.SurplusTrendResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Security BearerAuth
+// @Router /analytics/surplus [get]
+func (h AnalyticsHandler) GetSurplus(c *gin.Context) {
+	filter, ok := h.bindAnalyticsFilter(c)
+	if !ok {
+		return
+	}
+
+	points, err := h.getSurplusTrendUseCase.Execute(filter)
+	if err != nil {
+		h.respondAnalyticsError(c, "Get surplus trend use case failed", err)
+		return
+	}
+
+	h.respondAnalytics(c, "surplus", dto.ToSurplusTrendResponse(filter.Bucket, points))
+}
+
+// GetExactMatchRate handles GET /analytics/exact-match-rate
+// @Summary Exact Match Rate
+// @Description Share of calculations with zero surplus per configuration and time bucket
+// @Tags analytics
+// @Produce json
+// @Produce text/csv
+// @Param from query string false "Inclusive start (RFC3339 or YYYY-MM-DD, default 30 days before to)"
+// @Param to query string false "Exclusive end (RFC3339 or YYYY-MM-DD, default now)"
+// @Param bucket query string false "Time bucket: hour, day, week or month (default day)"
+// @Param configuration_id query int false "Only calculations made with this configuration"
+// @Param format query string false "Response format: json or csv (default json)"
+// @Success 200 {object} dto.ExactMatchRatesResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Security BearerAuth
+// @Router /analytics/exact-match-rate [get]
+func (h AnalyticsHandler) GetExactMatchRate(c *gin.Context) {
+	filter, ok := h.bindAnalyticsFilter(c)
+	if !ok {
+		return
+	}
+
+	rates, err := h.getExactMatchRatesUseCase.Execute(filter)
+	if err != nil {
+		h.respondAnalyticsError(c, "Get exact match rates use case failed", err)
+		return
+	}
+
+	h.respondAnalytics(c, "exact-match-rate", dto.ToExactMatchRatesResponse(filter.Bucket, rates))
+}
+
+// GetPackSizes handles GET /analytics/pack-sizes
+// @Summary Most Used Pack Sizes
+// @Description Pack sizes ranked by the number of packs shipped in the range
+// @Tags analytics
+// @Produce json
+// @Produce text/csv
+// @Param from query string false "Inclusive start (RFC3339 or YYYY-MM-DD, default 30 days before to)"
+// @Param to query string false "Exclusive end (RFC3339 or YYYY-MM-DD, default now)"
+// @Param configuration_id query int false "Only calculations made with this configuration"
+// @Param limit query int false "Number of pack sizes (default 20, max 100)"
+// @Param format query string false "Response format: json or csv (default json)"
+// @Success 200 {object} dto.PackSizeUsageListResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Security BearerAuth
+// @Router /analytics/pack-sizes [get]
+func (h AnalyticsHandler) GetPackSizes(c *gin.Context) {
+	filter, ok := h.bindAnalyticsFilter(c)
+	if !ok {
+		return
+	}
+
+	usage, err := h.getPackSizeUsageUseCase.Execute(filter)
+	if err != nil {
+		h.respondAnalyticsError(c, "Get pack size usage use case failed", err)
+		return
+	}
+
+	h.respondAnalytics(c, "pack-sizes", dto.ToPackSizeUsageListResponse(usage))
+}
+
+// GetPacksPerOrder handles GET /analytics/packs-per-order
+// @Summary Packs per Order Percentiles
+// @Description p50 and p95 of packs per order per time bucket
+// @Tags analytics
+// @Produce json
+// @Produce text/csv
+// @Param from query string false "Inclusive start (RFC3339 or YYYY-MM-DD, default 30 days before to)"
+// @Param to query string false "Exclusive end (RFC3339 or YYYY-MM-DD, default now)"
+// @Param bucket query string false "Time bucket: hour, day, week or month (default day)"
+// @Param configuration_id query int false "Only calculations made with this configuration"
+// @Param format query string false "Response format: json or csv (default json)"
+// @Success 200 {object} dto.PacksPerOrderResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Security BearerAuth
+// @Router /analytics/packs-per-order [get]
+func (h AnalyticsHandler) GetPacksPerOrder(c *gin.Context) {
+	filter, ok := h.bindAnalyticsFilter(c)
+	if !ok {
+		return
+	}
+
+	points, err := h.getPacksPerOrderUseCase.Execute(filter)
+	if err != nil {
+		h.respondAnalyticsError(c, "Get packs per order use case failed", err)
+		return
+	}
+
+	h.respondAnalytics(c, "packs-per-order", dto.ToPacksPerOrderResponse(filter.Bucket, points))
+}
+
+// bindAnalyticsFilter parses the shared query parameters and writes a 400 on failure
+func (h AnalyticsHandler) bindAnalyticsFilter(c *gin.Context) (entity.AnalyticsFilter, bool) {
+	filter, err := parseAnalyticsFilter(c)
+	if err == nil {
+		err = validateAnalyticsFormat(c.Query("format"))
+	}
+	if err != nil {
+		h.logger.Warn("Invalid analytics query", "error", err)
+		respondInvalidParameter(c, "Invalid query parameters", err.Error())
+		return filter, false
+	}
+	return filter, true
+}
+
+func (h AnalyticsHandler) respondAnalyticsError(c *gin.Context, message string, err error) {
+	h.logger.Error(message, "error", err)
+	if errors.Is(err, errs.ErrInvalidAnalyticsFilter) {
+		respondInvalidParameter(c, "Invalid query parameters", err.Error())
+		return
+	}
+	respondError(c, err, "Failed to compute analytics")
+}
+
+// respondAnalytics writes the report as JSON, or as a CSV attachment when format=csv
+func (h AnalyticsHandler) respondAnalytics(c *gin.Context, name string, report dto.CSVExporter) {
+	if c.Query("format") != "csv" {
+		c.JSON(http.StatusOK, report)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, name))
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	if err := writer.WriteAll(report.CSVRows()); err != nil {
+		h.logger.Error("Failed to write CSV export", "report", name, "error", err)
+	}
+}
+
+func parseAnalyticsFilter(c *gin.Context) (entity.AnalyticsFilter, error) {
+	filter := entity.AnalyticsFilter{Tenant: requestTenant(c)}
+
+	bucket, err := entity.ParseTimeBucket(c.Query("bucket"))
+	if err != nil {
+		return filter, err
+	}
+	filter.Bucket = bucket
+
+	if filter.From, err = parseAnalyticsTime(c, "from"); err != nil {
+		return filter, err
+	}
+	if filter.To, err = parseAnalyticsTime(c, "to"); err != nil {
+		return filter, err
+	}
+
+	if value := c.Query("configuration_id"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil || id <= 0 {
+			return filter, fmt.Errorf("configuration_id must be a positive integer")
+		}
+		filter.ConfigurationID = &id
+	}
+
+	if filter.Limit, err = parseNonNegativeQuery(c, "limit"); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+// parseAnalyticsTime accepts RFC3339 timestamps or plain UTC dates
+func parseAnalyticsTime(c *gin.Context, key string) (time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%s must be an RFC3339 timestamp or a YYYY-MM-DD date", key)
+}
+
+func validateAnalyticsFormat(format string) error {
+	switch format {
+	case "", "json", "csv":
+		return nil
+	default:
+		return fmt.Errorf("format must be json or csv")
+	}
+}
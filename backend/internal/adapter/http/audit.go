@@ -0,0 +1,121 @@
+package http
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/dto"
+	auditService "github.com/Schieck/packs-calculator/internal/service/audit"
+	auditUseCase "github.com/Schieck/packs-calculator/internal/usecase/audit"
+	"github.com/Schieck/packs-calculator/pkg/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// anonymousActor is recorded when a change reaches a handler without an
+// authenticated subject
+const anonymousActor = "anonymous"
+
+type AuditHandler struct {
+	listAuditEntriesUseCase *auditUseCase.ListAuditEntriesUseCase
+	logger                  *slog.Logger
+}
+
+func NewAuditHandler(listAuditEntriesUseCase *auditUseCase.ListAuditEntriesUseCase, logger *slog.Logger) *AuditHandler {
+	return &AuditHandler{
+		listAuditEntriesUseCase: listAuditEntriesUseCase,
+		logger:                  logger,
+	}
+}
+
+// ListAuditEntries handles GET /audit
+// @Summary List Audit Log
+// @Description Retrieve the append-only audit trail of configuration changes, newest first
+// @Tags audit
+// @Accept json
+// @Produce json
+// @Param entity_type query string false "Filter by entity type, e.g. pack_configuration"
+// @Param entity_id query int false "Filter by entity ID"
+// @Param actor query string false "Filter by the subject that made the change"
+// @Param from query string false "Inclusive lower bound on change time (RFC3339)"
+// @Param to query string false "Exclusive upper bound on change time (RFC3339)"
+// @Param limit query int false "Page size (default 50, max 500)"
+// @Param offset query int false "Number of entries to skip"
+// @Success 200 {object} dto.AuditLogResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Security BearerAuth
+// @Router /audit [get]
+func (h AuditHandler) ListAuditEntries(c *gin.Context) {
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		h.logger.Warn("Invalid audit filter", "error", err)
+		respondInvalidParameter(c, "Invalid query parameters", err.Error())
+		return
+	}
+
+	filter = auditService.NormalizeFilter(filter)
+
+	entries, total, err := h.listAuditEntriesUseCase.Execute(filter)
+	if err != nil {
+		h.logger.Error("List audit entries use case failed", "error", err)
+		respondError(c, err, "Failed to retrieve audit log")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToAuditLogResponse(entries, total, filter))
+}
+
+func parseAuditFilter(c *gin.Context) (entity.AuditFilter, error) {
+	filter := entity.AuditFilter{
+		Tenant:     requestTenant(c),
+		EntityType: c.Query("entity_type"),
+		Actor:      c.Query("actor"),
+	}
+
+	if value := c.Query("entity_id"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil || id <= 0 {
+			return filter, fmt.Errorf("entity_id must be a positive integer")
+		}
+		filter.EntityID = &id
+	}
+
+	var err error
+	if filter.From, filter.To, err = parseTimeRangeQuery(c); err != nil {
+		return filter, err
+	}
+	if filter.Limit, err = parseNonNegativeQuery(c, "limit"); err != nil {
+		return filter, err
+	}
+	if filter.Offset, err = parseNonNegativeQuery(c, "offset"); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+// requestActor identifies who is making the current request for the audit trail
+func requestActor(c *gin.Context) entity.Actor {
+	subject, ok := middleware.GetSubject(c)
+	if !ok || subject == "" {
+		subject = anonymousActor
+	}
+	return entity.Actor{
+		Subject:   subject,
+		Tenant:    requestTenant(c),
+		RequestID: middleware.GetRequestID(c),
+	}
+}
+
+// requestTenant is the tenant the current request acts for, falling back to
+// the default tenant when no JWT set one
+func requestTenant(c *gin.Context) string {
+	tenant, ok := middleware.GetTenant(c)
+	if !ok || tenant == "" {
+		return entity.DefaultTenant
+	}
+	return tenant
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/Schieck/packs-calculator/internal/domain/errs"
 	"github.com/Schieck/packs-calculator/internal/dto"
 	authUseCase "github.com/Schieck/packs-calculator/internal/usecase/auth"
+	"github.com/Schieck/packs-calculator/pkg/middleware"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 )
@@ -23,7 +24,7 @@ func NewAuthHandler(authenticateUseCase *authUseCase.AuthenticateUseCase, logger
 	return &AuthHandler{
 		authenticateUseCase: authenticateUseCase,
 		logger:              logger,
-		validator:           validator.New(),
+		validator:           newValidator(),
 	}
 }
 
@@ -35,27 +36,22 @@ func NewAuthHandler(authenticateUseCase *authUseCase.AuthenticateUseCase, logger
 // @Produce json
 // @Param request body dto.AuthRequest true "Authentication secret"
 // @Success 200 {object} dto.AuthResponse
-// @Failure 400 {object} errs.ErrorResponse
-// @Failure 401 {object} errs.ErrorResponse
-// @Failure 500 {object} errs.ErrorResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 401 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
 // @Router /auth/token [post]
 func (h AuthHandler) Authenticate(c *gin.Context) {
 	var dtoReq dto.AuthRequest
 
 	if err := c.ShouldBindJSON(&dtoReq); err != nil {
 		h.logger.Warn("Invalid request body", "error", err)
-		c.JSON(http.StatusBadRequest, errs.ErrorResponse{
-			Error: "Invalid request body",
-		})
+		respondInvalidBody(c, err)
 		return
 	}
 
 	if err := h.validator.Struct(&dtoReq); err != nil {
 		h.logger.Warn("Request validation failed", "error", err)
-		c.JSON(http.StatusBadRequest, errs.ErrorResponse{
-			Error:   "Validation failed",
-			Details: errs.FormatValidationErrors(err),
-		})
+		respondInvalidBody(c, err)
 		return
 	}
 
@@ -68,22 +64,20 @@ func (h AuthHandler) Authenticate(c *gin.Context) {
 	entityResponse, err := h.authenticateUseCase.Execute(entityReq)
 	if err != nil {
 		if errors.Is(err, errs.ErrInvalidCredentials) {
-			c.JSON(http.StatusUnauthorized, errs.ErrorResponse{
-				Error: "Invalid credentials",
-			})
+			middleware.AbortWithProblem(c, middleware.NewProblem(c, http.StatusUnauthorized,
+				"invalid_credentials", "Invalid credentials", ""))
 			return
 		}
 
 		h.logger.Error("Authentication use case failed", "error", err)
-		c.JSON(http.StatusInternalServerError, errs.ErrorResponse{
-			Error: "Internal server error",
-		})
+		respondError(c, err, "Internal server error")
 		return
 	}
 
 	// Convert domain entity to DTO for response
 	dtoResponse := &dto.AuthResponse{
 		Token:     entityResponse.Token,
+		Tenant:    entityResponse.Tenant,
 		ExpiresAt: entityResponse.ExpiresAt.Unix(),
 	}
 
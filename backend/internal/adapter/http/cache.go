@@ -0,0 +1,46 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/Schieck/packs-calculator/internal/dto"
+	cacheUseCase "github.com/Schieck/packs-calculator/internal/usecase/cache"
+	"github.com/gin-gonic/gin"
+)
+
+type CacheHandler struct {
+	getCacheStatsUseCase *cacheUseCase.GetCacheStatsUseCase
+	logger               *slog.Logger
+}
+
+func NewCacheHandler(getCacheStatsUseCase *cacheUseCase.GetCacheStatsUseCase, logger *slog.Logger) *CacheHandler {
+	return &CacheHandler{
+		getCacheStatsUseCase: getCacheStatsUseCase,
+		logger:               logger,
+	}
+}
+
+// GetStats handles GET /cache/stats
+// @Summary Pack Configuration Cache Statistics
+// @Description Report hits, misses, evictions, expirations and invalidations of the pack configuration cache since the server started. Only served while the cache is enabled.
+// @Tags cache
+// @Produce json
+// @Success 200 {object} dto.CacheStatsResponse
+// @Security BearerAuth
+// @Router /cache/stats [get]
+func (h CacheHandler) GetStats(c *gin.Context) {
+	stats := h.getCacheStatsUseCase.Execute()
+
+	c.JSON(http.StatusOK, dto.CacheStatsResponse{
+		Hits:          stats.Hits,
+		Misses:        stats.Misses,
+		HitRatio:      stats.HitRatio(),
+		Evictions:     stats.Evictions,
+		Expirations:   stats.Expirations,
+		Invalidations: stats.Invalidations,
+		Entries:       stats.Entries,
+		MaxEntries:    stats.MaxEntries,
+		TTLSeconds:    stats.TTL.Seconds(),
+	})
+}
@@ -0,0 +1,162 @@
+package http
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/dto"
+	calculationService "github.com/Schieck/packs-calculator/internal/service/calculation"
+	calculationUseCase "github.com/Schieck/packs-calculator/internal/usecase/calculation"
+	"github.com/gin-gonic/gin"
+)
+
+type CalculationHistoryHandler struct {
+	getCalculationUseCase   *calculationUseCase.GetCalculationUseCase
+	listCalculationsUseCase *calculationUseCase.ListCalculationsUseCase
+	logger                  *slog.Logger
+}
+
+func NewCalculationHistoryHandler(
+	getCalculationUseCase *calculationUseCase.GetCalculationUseCase,
+	listCalculationsUseCase *calculationUseCase.ListCalculationsUseCase,
+	logger *slog.Logger,
+) *CalculationHistoryHandler {
+	return &CalculationHistoryHandler{
+		getCalculationUseCase:   getCalculationUseCase,
+		listCalculationsUseCase: listCalculationsUseCase,
+		logger:                  logger,
+	}
+}
+
+// ListCalculations handles GET /calculations
+// @Summary List Calculation History
+// @Description Retrieve persisted calculations, newest first, with optional filters
+// @Tags calculations
+// @Accept json
+// @Produce json
+// @Param subject query string false "Filter by the subject that ran the calculation"
+// @Param configuration_id query int false "Filter by pack configuration ID"
+// @Param from query string false "Inclusive lower bound on creation time (RFC3339)"
+// @Param to query string false "Exclusive upper bound on creation time (RFC3339)"
+// @Param limit query int false "Page size (default 50, max 500)"
+// @Param offset query int false "Number of records to skip"
+// @Success 200 {object} dto.CalculationHistoryResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Security BearerAuth
+// @Router /calculations [get]
+func (h CalculationHistoryHandler) ListCalculations(c *gin.Context) {
+	filter, err := parseCalculationFilter(c)
+	if err != nil {
+		h.logger.Warn("Invalid calculation filter", "error", err)
+		respondInvalidParameter(c, "Invalid query parameters", err.Error())
+		return
+	}
+
+	filter = calculationService.NormalizeFilter(filter)
+
+	records, total, err := h.listCalculationsUseCase.Execute(filter)
+	if err != nil {
+		h.logger.Error("List calculations use case failed", "error", err)
+		respondError(c, err, "Failed to retrieve calculations")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToCalculationHistoryResponse(records, total, filter))
+}
+
+// GetCalculation handles GET /calculations/:id
+// @Summary Get Calculation
+// @Description Retrieve a single persisted calculation by its ID
+// @Tags calculations
+// @Accept json
+// @Produce json
+// @Param id path int true "Calculation ID"
+// @Success 200 {object} dto.CalculationRecordResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 404 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Security BearerAuth
+// @Router /calculations/{id} [get]
+func (h CalculationHistoryHandler) GetCalculation(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil || id <= 0 {
+		h.logger.Warn("Invalid calculation ID", "id", idParam, "error", err)
+		respondInvalidParameter(c, "Invalid calculation ID", "id must be a positive integer")
+		return
+	}
+
+	record, err := h.getCalculationUseCase.Execute(requestTenant(c), id)
+	if err != nil {
+		h.logger.Error("Get calculation use case failed", "id", id, "error", err)
+		respondError(c, err, "Failed to retrieve calculation")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToCalculationRecordResponse(record))
+}
+
+func parseCalculationFilter(c *gin.Context) (entity.CalculationFilter, error) {
+	filter := entity.CalculationFilter{
+		Tenant:  requestTenant(c),
+		Subject: c.Query("subject"),
+	}
+
+	if value := c.Query("configuration_id"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil || id <= 0 {
+			return filter, fmt.Errorf("configuration_id must be a positive integer")
+		}
+		filter.ConfigurationID = &id
+	}
+
+	var err error
+	if filter.From, filter.To, err = parseTimeRangeQuery(c); err != nil {
+		return filter, err
+	}
+	if filter.Limit, err = parseNonNegativeQuery(c, "limit"); err != nil {
+		return filter, err
+	}
+	if filter.Offset, err = parseNonNegativeQuery(c, "offset"); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+func parseNonNegativeQuery(c *gin.Context, key string) (int, error) {
+	value := c.Query(key)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer", key)
+	}
+	return n, nil
+}
+
+// parseTimeRangeQuery reads optional RFC3339 from/to query parameters
+func parseTimeRangeQuery(c *gin.Context) (*time.Time, *time.Time, error) {
+	var bounds [2]*time.Time
+	for i, key := range []string{"from", "to"} {
+		if value := c.Query(key); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s must be an RFC3339 timestamp", key)
+			}
+			bounds[i] = &t
+		}
+	}
+
+	from, to := bounds[0], bounds[1]
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, nil, fmt.Errorf("from must be before to")
+	}
+	return from, to, nil
+}
@@ -4,9 +4,9 @@ import (
 	"log/slog"
 	"net/http"
 
-	"github.com/Schieck/packs-calculator/internal/domain/errs"
 	"github.com/Schieck/packs-calculator/internal/dto"
 	healthUseCase "github.com/Schieck/packs-calculator/internal/usecase/health"
+	"github.com/Schieck/packs-calculator/pkg/middleware"
 	"github.com/gin-gonic/gin"
 )
 
@@ -28,15 +28,14 @@ func NewHealthHandler(healthUseCase *healthUseCase.HealthUseCase, logger *slog.L
 // @Tags health
 // @Produce json
 // @Success 200 {object} dto.HealthResponse
-// @Failure 503 {object} errs.ErrorResponse
+// @Failure 503 {object} errs.Problem
 // @Router /health [get]
 func (h HealthHandler) Health(c *gin.Context) {
 	entityResponse, err := h.healthUseCase.Execute()
 	if err != nil {
 		h.logger.Error("Health check failed", "error", err)
-		c.JSON(http.StatusServiceUnavailable, errs.ErrorResponse{
-			Error: "Database connection failed",
-		})
+		middleware.AbortWithProblem(c, middleware.NewProblem(c, http.StatusServiceUnavailable,
+			"service_unavailable", "Database connection failed", ""))
 		return
 	}
 
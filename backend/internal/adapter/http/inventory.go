@@ -0,0 +1,264 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/Schieck/packs-calculator/internal/dto"
+	inventoryUseCase "github.com/Schieck/packs-calculator/internal/usecase/inventory"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type InventoryHandler struct {
+	getStockUseCase           *inventoryUseCase.GetStockUseCase
+	setStockUseCase           *inventoryUseCase.SetStockUseCase
+	calculateWithStockUseCase *inventoryUseCase.CalculateWithStockUseCase
+	reserveOrderUseCase       *inventoryUseCase.ReserveOrderUseCase
+	getReservationUseCase     *inventoryUseCase.GetReservationUseCase
+	cancelReservationUseCase  *inventoryUseCase.CancelReservationUseCase
+	logger                    *slog.Logger
+	validator                 *validator.Validate
+}
+
+func NewInventoryHandler(
+	getStockUseCase *inventoryUseCase.GetStockUseCase,
+	setStockUseCase *inventoryUseCase.SetStockUseCase,
+	calculateWithStockUseCase *inventoryUseCase.CalculateWithStockUseCase,
+	reserveOrderUseCase *inventoryUseCase.ReserveOrderUseCase,
+	getReservationUseCase *inventoryUseCase.GetReservationUseCase,
+	cancelReservationUseCase *inventoryUseCase.CancelReservationUseCase,
+	logger *slog.Logger,
+) *InventoryHandler {
+	return &InventoryHandler{
+		getStockUseCase:           getStockUseCase,
+		setStockUseCase:           setStockUseCase,
+		calculateWithStockUseCase: calculateWithStockUseCase,
+		reserveOrderUseCase:       reserveOrderUseCase,
+		getReservationUseCase:     getReservationUseCase,
+		cancelReservationUseCase:  cancelReservationUseCase,
+		logger:                    logger,
+		validator:                 newValidator(),
+	}
+}
+
+// GetStock handles GET /inventory/locations/:location/stock
+// @Summary Get Stock Levels
+// @Description Retrieve on-hand, reserved and available packs per pack size for one of the caller's tenant's locations
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Param location path string true "Stock location"
+// @Success 200 {object} dto.StockListResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Security BearerAuth
+// @Router /inventory/locations/{location}/stock [get]
+func (h InventoryHandler) GetStock(c *gin.Context) {
+	location := c.Param("location")
+
+	levels, err := h.getStockUseCase.Execute(c.Request.Context(), requestTenant(c), location)
+	if err != nil {
+		h.logger.Error("Get stock use case failed", "location", location, "error", err)
+		respondError(c, err, "Failed to retrieve stock levels")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToStockListResponse(location, levels))
+}
+
+// SetStock handles PUT /inventory/locations/:location/stock/:pack_size
+// @Summary Set Stock Level
+// @Description Set the on-hand pack count for a pack size at a location
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Param location path string true "Stock location"
+// @Param pack_size path int true "Pack size"
+// @Param request body dto.SetStockRequest true "On-hand pack count"
+// @Success 200 {object} dto.StockLevelResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 409 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Security BearerAuth
+// @Router /inventory/locations/{location}/stock/{pack_size} [put]
+func (h InventoryHandler) SetStock(c *gin.Context) {
+	location := c.Param("location")
+	packSizeParam := c.Param("pack_size")
+	packSize, err := strconv.Atoi(packSizeParam)
+	if err != nil {
+		h.logger.Warn("Invalid pack size", "pack_size", packSizeParam, "error", err)
+		respondInvalidParameter(c, "Invalid pack size", "pack_size must be an integer")
+		return
+	}
+
+	var dtoReq dto.SetStockRequest
+
+	if err := c.ShouldBindJSON(&dtoReq); err != nil {
+		h.logger.Warn("Invalid request body", "error", err)
+		respondInvalidBody(c, err)
+		return
+	}
+
+	if err := h.validator.Struct(&dtoReq); err != nil {
+		h.logger.Warn("Request validation failed", "error", err)
+		respondInvalidBody(c, err)
+		return
+	}
+
+	level, err := h.setStockUseCase.Execute(c.Request.Context(), requestTenant(c), location, packSize, dtoReq.OnHand)
+	if err != nil {
+		h.logger.Error("Set stock use case failed", "location", location, "pack_size", packSize, "error", err)
+		respondError(c, err, "Failed to set stock level")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToStockLevelResponse(level))
+}
+
+// Calculate handles POST /inventory/locations/:location/calculate
+// @Summary Calculate Packs From Stock
+// @Description Calculate the optimal pack allocation using only packs available at a location
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Param location path string true "Stock location"
+// @Param request body dto.StockCalculationRequest true "Calculation parameters"
+// @Success 200 {object} dto.CalculationResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 422 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Security BearerAuth
+// @Router /inventory/locations/{location}/calculate [post]
+func (h InventoryHandler) Calculate(c *gin.Context) {
+	location := c.Param("location")
+
+	var dtoReq dto.StockCalculationRequest
+	if !h.bindStockCalculationRequest(c, &dtoReq) {
+		return
+	}
+
+	result, err := h.calculateWithStockUseCase.Execute(c.Request.Context(), requestTenant(c), location, dtoReq.PackSizes, dtoReq.Items)
+	if err != nil {
+		h.logger.Error("Stock-aware calculation use case failed", "location", location, "error", err)
+		respondError(c, err, "Pack calculation failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToCalculationResponse(result))
+}
+
+// Reserve handles POST /inventory/locations/:location/reservations
+// @Summary Reserve Order
+// @Description Calculate an order against current stock and atomically reserve the packs it uses
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Param location path string true "Stock location"
+// @Param request body dto.StockCalculationRequest true "Order parameters"
+// @Success 201 {object} dto.ReservationResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 409 {object} errs.Problem
+// @Failure 422 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Security BearerAuth
+// @Router /inventory/locations/{location}/reservations [post]
+func (h InventoryHandler) Reserve(c *gin.Context) {
+	location := c.Param("location")
+
+	var dtoReq dto.StockCalculationRequest
+	if !h.bindStockCalculationRequest(c, &dtoReq) {
+		return
+	}
+
+	reservation, err := h.reserveOrderUseCase.Execute(c.Request.Context(), requestTenant(c), location, dtoReq.PackSizes, dtoReq.Items)
+	if err != nil {
+		h.logger.Error("Reserve order use case failed", "location", location, "error", err)
+		respondError(c, err, "Failed to reserve order")
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ToReservationResponse(reservation))
+}
+
+// GetReservation handles GET /inventory/reservations/:id
+// @Summary Get Reservation
+// @Description Retrieve a reservation and the packs it holds
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Param id path int true "Reservation ID"
+// @Success 200 {object} dto.ReservationResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 404 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Security BearerAuth
+// @Router /inventory/reservations/{id} [get]
+func (h InventoryHandler) GetReservation(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		h.logger.Warn("Invalid reservation ID", "id", idParam, "error", err)
+		respondInvalidParameter(c, "Invalid reservation ID", "id must be an integer")
+		return
+	}
+
+	reservation, err := h.getReservationUseCase.Execute(c.Request.Context(), requestTenant(c), id)
+	if err != nil {
+		h.logger.Error("Get reservation use case failed", "id", id, "error", err)
+		respondError(c, err, "Failed to retrieve reservation")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToReservationResponse(reservation))
+}
+
+// CancelReservation handles POST /inventory/reservations/:id/cancel
+// @Summary Cancel Reservation
+// @Description Cancel an active reservation and release its packs back to available stock
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Param id path int true "Reservation ID"
+// @Success 200 {object} dto.ReservationResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 404 {object} errs.Problem
+// @Failure 409 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Security BearerAuth
+// @Router /inventory/reservations/{id}/cancel [post]
+func (h InventoryHandler) CancelReservation(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		h.logger.Warn("Invalid reservation ID", "id", idParam, "error", err)
+		respondInvalidParameter(c, "Invalid reservation ID", "id must be an integer")
+		return
+	}
+
+	reservation, err := h.cancelReservationUseCase.Execute(c.Request.Context(), requestTenant(c), id)
+	if err != nil {
+		h.logger.Error("Cancel reservation use case failed", "id", id, "error", err)
+		respondError(c, err, "Failed to cancel reservation")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToReservationResponse(reservation))
+}
+
+func (h InventoryHandler) bindStockCalculationRequest(c *gin.Context, dtoReq *dto.StockCalculationRequest) bool {
+	if err := c.ShouldBindJSON(dtoReq); err != nil {
+		h.logger.Warn("Invalid request body", "error", err)
+		respondInvalidBody(c, err)
+		return false
+	}
+
+	if err := h.validator.Struct(dtoReq); err != nil {
+		h.logger.Warn("Request validation failed", "error", err)
+		respondInvalidBody(c, err)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,99 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+	"github.com/Schieck/packs-calculator/pkg/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// mergePatchContentType is the media type of RFC 7386 JSON Merge Patch bodies
+const mergePatchContentType = "application/merge-patch+json"
+
+// bindMergePatch decodes a JSON Merge Patch body into req, a pointer to a
+// struct whose json tags name the members that may be patched. Members req
+// does not know, and nulls (which would remove a member) for members not
+// tagged patch:"removable", are rejected.
+// It writes the error response and returns false when the patch is unusable.
+func bindMergePatch(c *gin.Context, req interface{}) bool {
+	if contentType := c.ContentType(); contentType != mergePatchContentType && contentType != gin.MIMEJSON {
+		middleware.AbortWithProblem(c, middleware.NewProblem(c, http.StatusUnsupportedMediaType, "unsupported_media_type",
+			"Unsupported media type", "Send the patch as "+mergePatchContentType+" or "+gin.MIMEJSON))
+		return false
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		respondInvalidBody(c, err)
+		return false
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		respondInvalidBody(c, io.EOF)
+		return false
+	}
+
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(body, &members); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			middleware.AbortWithProblem(c, middleware.NewProblem(c, http.StatusBadRequest, "invalid_request_body",
+				"Invalid request body", "A merge patch must be a JSON object"))
+			return false
+		}
+		respondInvalidBody(c, err)
+		return false
+	}
+
+	known := jsonMemberNames(reflect.TypeOf(req).Elem())
+	var fieldErrors []errs.FieldError
+	for _, name := range sortedKeys(members) {
+		removable, ok := known[name]
+		switch {
+		case !ok:
+			fieldErrors = append(fieldErrors, errs.FieldError{Field: name, Message: "is not a field that can be patched"})
+		case !removable && string(bytes.TrimSpace(members[name])) == "null":
+			fieldErrors = append(fieldErrors, errs.FieldError{Field: name, Message: "cannot be removed"})
+		}
+	}
+	if len(fieldErrors) > 0 {
+		respondInvalidFields(c, fieldErrors)
+		return false
+	}
+
+	if err := json.Unmarshal(body, req); err != nil {
+		respondInvalidBody(c, err)
+		return false
+	}
+	return true
+}
+
+// jsonMemberNames maps the JSON names of a struct's fields to whether a
+// patch may remove them
+func jsonMemberNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names[name] = field.Tag.Get("patch") == "removable"
+		}
+	}
+	return names
+}
+
+func sortedKeys(members map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(members))
+	for key := range members {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
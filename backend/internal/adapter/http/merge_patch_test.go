@@ -0,0 +1,149 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+	"github.com/Schieck/packs-calculator/internal/dto"
+)
+
+func TestBindMergePatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		errors      []errs.FieldError
+		check       func(t *testing.T, req dto.PatchPackConfigurationRequest)
+	}{
+		{
+			name:        "absent members stay nil",
+			contentType: "application/merge-patch+json",
+			body:        `{"add_pack_sizes": [750]}`,
+			status:      http.StatusOK,
+			check: func(t *testing.T, req dto.PatchPackConfigurationRequest) {
+				assert.Nil(t, req.Name)
+				assert.Nil(t, req.PackSizes)
+				assert.Nil(t, req.IsDefault)
+				assert.False(t, req.EffectiveFrom.Set)
+				assert.Equal(t, []int{750}, req.AddPackSizes)
+			},
+		},
+		{
+			name:        "plain JSON is accepted",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"name": "Renamed", "is_default": true}`,
+			status:      http.StatusOK,
+			check: func(t *testing.T, req dto.PatchPackConfigurationRequest) {
+				require.NotNil(t, req.Name)
+				assert.Equal(t, "Renamed", *req.Name)
+				require.NotNil(t, req.IsDefault)
+				assert.True(t, *req.IsDefault)
+			},
+		},
+		{
+			name:        "null and unknown members are rejected",
+			contentType: "application/merge-patch+json",
+			body:        `{"name": null, "colour": "red"}`,
+			status:      http.StatusBadRequest,
+			errors: []errs.FieldError{
+				{Field: "colour", Message: "is not a field that can be patched"},
+				{Field: "name", Message: "cannot be removed"},
+			},
+		},
+		{
+			name:        "removable members may be null",
+			contentType: "application/merge-patch+json",
+			body:        `{"effective_from": "2025-03-01T00:00:00Z", "effective_to": null}`,
+			status:      http.StatusOK,
+			check: func(t *testing.T, req dto.PatchPackConfigurationRequest) {
+				require.True(t, req.EffectiveFrom.Set)
+				require.NotNil(t, req.EffectiveFrom.Value)
+				assert.Equal(t, 2025, req.EffectiveFrom.Value.Year())
+				assert.True(t, req.EffectiveTo.Set)
+				assert.Nil(t, req.EffectiveTo.Value)
+			},
+		},
+		{
+			name:        "labels merge per key",
+			contentType: "application/merge-patch+json",
+			body:        `{"labels": {"region": "eu", "customer": null}, "metadata": null}`,
+			status:      http.StatusOK,
+			check: func(t *testing.T, req dto.PatchPackConfigurationRequest) {
+				patch := dto.ToPackConfigurationPatch(&req)
+				require.Contains(t, patch.Labels, "region")
+				assert.Equal(t, "eu", *patch.Labels["region"])
+				require.Contains(t, patch.Labels, "customer")
+				assert.Nil(t, patch.Labels["customer"])
+				assert.False(t, patch.ClearLabels)
+				assert.Equal(t, "null", string(patch.Metadata))
+			},
+		},
+		{
+			name:        "null labels clear them",
+			contentType: "application/merge-patch+json",
+			body:        `{"labels": null}`,
+			status:      http.StatusOK,
+			check: func(t *testing.T, req dto.PatchPackConfigurationRequest) {
+				assert.True(t, dto.ToPackConfigurationPatch(&req).ClearLabels)
+			},
+		},
+		{
+			name:        "patch must be an object",
+			contentType: "application/merge-patch+json",
+			body:        `[{"op": "add"}]`,
+			status:      http.StatusBadRequest,
+		},
+		{
+			name:        "wrong member type",
+			contentType: "application/merge-patch+json",
+			body:        `{"pack_sizes": "250"}`,
+			status:      http.StatusBadRequest,
+			errors:      []errs.FieldError{{Field: "pack_sizes", Message: "must be an array"}},
+		},
+		{
+			name:        "other media types are unsupported",
+			contentType: "application/json-patch+json",
+			body:        `[]`,
+			status:      http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req dto.PatchPackConfigurationRequest
+			router := gin.New()
+			router.PATCH("/patch", func(c *gin.Context) {
+				if bindMergePatch(c, &req) {
+					c.Status(http.StatusOK)
+				}
+			})
+
+			httpReq := httptest.NewRequest("PATCH", "/patch", strings.NewReader(tt.body))
+			httpReq.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httpReq)
+
+			require.Equal(t, tt.status, w.Code)
+			if tt.check != nil {
+				tt.check(t, req)
+			}
+			if tt.status != http.StatusOK {
+				var problem errs.Problem
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+				assert.Equal(t, tt.status, problem.Status)
+				assert.Equal(t, tt.errors, problem.Errors)
+			}
+		})
+	}
+}
@@ -3,75 +3,209 @@ package http
 import (
 	"log/slog"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
 	"github.com/Schieck/packs-calculator/internal/domain/errs"
 	"github.com/Schieck/packs-calculator/internal/dto"
+	calculationUseCase "github.com/Schieck/packs-calculator/internal/usecase/calculation"
+	inventoryUseCase "github.com/Schieck/packs-calculator/internal/usecase/inventory"
 	calculatorUseCase "github.com/Schieck/packs-calculator/internal/usecase/pack_calculator"
+	packConfigurationUseCase "github.com/Schieck/packs-calculator/internal/usecase/pack_configuration"
+	"github.com/Schieck/packs-calculator/pkg/middleware"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 )
 
 type CalculatorHandler struct {
-	calculatePacksUseCase *calculatorUseCase.CalculatePacksUseCase
-	logger                *slog.Logger
-	validator             *validator.Validate
+	calculatePacksUseCase     *calculatorUseCase.CalculatePacksUseCase
+	calculateWithStockUseCase *inventoryUseCase.CalculateWithStockUseCase
+	getEffectiveConfigUseCase *packConfigurationUseCase.GetEffectiveConfigurationUseCase
+	getConfigVersionUseCase   *packConfigurationUseCase.GetConfigurationVersionUseCase
+	recordCalculationUseCase  *calculationUseCase.RecordCalculationUseCase
+	logger                    *slog.Logger
+	validator                 *validator.Validate
 }
 
-func NewCalculatorHandler(calculatePacksUseCase *calculatorUseCase.CalculatePacksUseCase, logger *slog.Logger) *CalculatorHandler {
+func NewCalculatorHandler(
+	calculatePacksUseCase *calculatorUseCase.CalculatePacksUseCase,
+	calculateWithStockUseCase *inventoryUseCase.CalculateWithStockUseCase,
+	getEffectiveConfigUseCase *packConfigurationUseCase.GetEffectiveConfigurationUseCase,
+	getConfigVersionUseCase *packConfigurationUseCase.GetConfigurationVersionUseCase,
+	recordCalculationUseCase *calculationUseCase.RecordCalculationUseCase,
+	logger *slog.Logger,
+) *CalculatorHandler {
 	return &CalculatorHandler{
-		calculatePacksUseCase: calculatePacksUseCase,
-		logger:                logger,
-		validator:             validator.New(),
+		calculatePacksUseCase:     calculatePacksUseCase,
+		calculateWithStockUseCase: calculateWithStockUseCase,
+		getEffectiveConfigUseCase: getEffectiveConfigUseCase,
+		getConfigVersionUseCase:   getConfigVersionUseCase,
+		recordCalculationUseCase:  recordCalculationUseCase,
+		logger:                    logger,
+		validator:                 newValidator(),
 	}
 }
 
-// Calculate handles pack calculation requests
+// Calculate handles pack calculation requests. Stock is opt-in: it is only
+// checked when the request names a location, and there is no default
+// location, so without one the plan ignores stock levels entirely.
 // @Summary Calculate Optimal Packs
-// @Description Calculate the optimal pack allocation for a given order quantity and available pack sizes
+// @Description Calculate the optimal pack allocation for a given order quantity and available pack sizes.
+// @Description When a configuration_id is given, that configuration's pack sizes are used and pack_sizes must be left out;
+// @Description configuration_version pins an earlier version of it. Otherwise the configuration must be
+// @Description effective at as_of, which defaults to now.
+// @Description Stock is opt-in: only when a location is given are the packs limited to those in stock at that location.
+// @Description Without a location no stock is checked, as there is no default location.
+// @Description With persist=true the calculation is stored in the calculation history.
+// @Description Locations and persist=true need Postgres storage and fail with 501 on SQLite or STORAGE=memory.
+// @Description When the configuration references pack types, packs lists the allocation with each size's SKU code and label.
 // @Tags calculator
 // @Accept json
 // @Produce json
+// @Param persist query bool false "Store the calculation in the history"
+// @Param as_of query string false "RFC3339 timestamp or YYYY-MM-DD date the configuration must be effective at"
 // @Param request body dto.CalculationRequest true "Calculation parameters"
 // @Success 200 {object} dto.CalculationResponse
-// @Failure 400 {object} errs.ErrorResponse
-// @Failure 500 {object} errs.ErrorResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 404 {object} errs.Problem
+// @Failure 422 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Failure 501 {object} errs.Problem
+// @Failure 504 {object} errs.Problem
 // @Security BearerAuth
 // @Router /calculate [post]
 func (h CalculatorHandler) Calculate(c *gin.Context) {
+	persist, err := parseBoolQuery(c, "persist")
+	if err != nil {
+		h.logger.Warn("Invalid persist flag", "persist", c.Query("persist"), "error", err)
+		respondInvalidParameter(c, "Invalid persist flag", "persist must be true or false")
+		return
+	}
+
 	var dtoReq dto.CalculationRequest
 
 	if err := c.ShouldBindJSON(&dtoReq); err != nil {
 		h.logger.Warn("Invalid request body", "error", err)
-		c.JSON(http.StatusBadRequest, errs.ErrorResponse{
-			Error: "Invalid request body",
-		})
+		respondInvalidBody(c, err)
 		return
 	}
 
 	if err := h.validator.Struct(&dtoReq); err != nil {
 		h.logger.Warn("Request validation failed", "error", err)
-		c.JSON(http.StatusBadRequest, errs.ErrorResponse{
-			Error:   "Validation failed",
-			Details: errs.FormatValidationErrors(err),
-		})
+		respondInvalidBody(c, err)
 		return
 	}
 
-	result, err := h.calculatePacksUseCase.Execute(dtoReq.PackSizes, dtoReq.Items)
+	// Stock and history are only kept in a database
+	if dtoReq.Location != "" && h.calculateWithStockUseCase == nil {
+		respondError(c, errs.ErrStorageUnsupported.Withf("stock-aware calculations need Postgres storage"), "Pack calculation failed")
+		return
+	}
+	if persist && h.recordCalculationUseCase == nil {
+		respondError(c, errs.ErrStorageUnsupported.Withf("persisting calculations needs Postgres storage"), "Failed to persist calculation")
+		return
+	}
+
+	packSizes, configurationVersion, packTypes, ok := h.resolvePackSizes(c, &dtoReq)
+	if !ok {
+		return
+	}
+
+	var result *entity.CalculationResult
+	solver := entity.SolverUnboundedDP
+	start := time.Now()
+	if dtoReq.Location != "" {
+		solver = entity.SolverBoundedDP
+		result, err = h.calculateWithStockUseCase.Execute(c.Request.Context(), requestTenant(c), dtoReq.Location, packSizes, dtoReq.Items)
+	} else {
+		result, err = h.calculatePacksUseCase.Execute(packSizes, dtoReq.Items)
+	}
+	duration := time.Since(start)
 	if err != nil {
 		h.logger.Error("Pack calculation use case failed", "error", err)
-		c.JSON(http.StatusInternalServerError, errs.ErrorResponse{
-			Error: "Pack calculation failed",
-		})
+		respondError(c, err, "Pack calculation failed")
 		return
 	}
 
-	dtoResponse := &dto.CalculationResponse{
-		Allocation: result.Allocation.GetAllocation(),
-		TotalPacks: result.Allocation.TotalPacks(),
-		TotalItems: result.Allocation.TotalItems(),
-		Surplus:    result.Surplus,
+	response := dto.ToCalculationResponse(result)
+	response.Packs = dto.ToPackAllocations(response.Allocation, packTypes)
+	response.ConfigurationVersion = configurationVersion
+
+	if persist {
+		subject, _ := middleware.GetSubject(c)
+		record, err := entity.NewCalculationRecord(requestTenant(c), subject, dtoReq.Items, packSizes, result, solver, duration)
+		if err == nil {
+			record.ConfigurationID = dtoReq.ConfigurationID
+			record.ConfigurationVersion = configurationVersion
+			record.Location = dtoReq.Location
+			record, err = h.recordCalculationUseCase.Execute(record)
+		}
+		if err != nil {
+			h.logger.Error("Record calculation use case failed", "error", err)
+			respondError(c, err, "Failed to persist calculation")
+			return
+		}
+		response.CalculationID = &record.ID
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// resolvePackSizes picks the pack sizes for a request: a pinned configuration
+// version, the configuration's current version if it is effective at as_of,
+// or the raw sizes given, which a configuration excludes. Only the current
+// version comes with its pack types, since version snapshots keep pack sizes
+// alone. It reports the error and returns false when the sizes cannot be
+// resolved.
+func (h CalculatorHandler) resolvePackSizes(c *gin.Context, dtoReq *dto.CalculationRequest) ([]int, *int, []*entity.PackType, bool) {
+	asOf, err := parseAsOfQuery(c)
+	if err != nil {
+		h.logger.Warn("Invalid as_of", "as_of", c.Query("as_of"), "error", err)
+		respondInvalidParameter(c, "Invalid as_of", err.Error())
+		return nil, nil, nil, false
+	}
+
+	if dtoReq.ConfigurationID == nil {
+		return dtoReq.PackSizes, nil, nil, true
 	}
+	id := *dtoReq.ConfigurationID
+	if dtoReq.PackSizes != nil {
+		h.logger.Warn("pack_sizes given with a configuration", "id", id)
+		respondError(c, errs.ErrInvalidOrder.Withf("pack_sizes cannot be combined with configuration_id, whose pack sizes are used"), "Pack calculation failed")
+		return nil, nil, nil, false
+	}
+
+	if dtoReq.ConfigurationVersion != nil {
+		if c.Query("as_of") != "" {
+			h.logger.Warn("as_of given with a pinned configuration version", "id", id)
+			respondInvalidParameter(c, "Invalid as_of", "as_of cannot be combined with configuration_version")
+			return nil, nil, nil, false
+		}
 
-	c.JSON(http.StatusOK, dtoResponse)
+		snapshot, err := h.getConfigVersionUseCase.Execute(c.Request.Context(), requestTenant(c), id, *dtoReq.ConfigurationVersion)
+		if err != nil {
+			h.logger.Error("Get pack configuration version use case failed", "id", id, "version", *dtoReq.ConfigurationVersion, "error", err)
+			respondError(c, err, "Failed to retrieve pack configuration version")
+			return nil, nil, nil, false
+		}
+		return snapshot.GetRawPackSizes(), &snapshot.Version, nil, true
+	}
+
+	configuration, err := h.getEffectiveConfigUseCase.Execute(c.Request.Context(), requestTenant(c), id, asOf)
+	if err != nil {
+		h.logger.Error("Get effective pack configuration use case failed", "id", id, "as_of", asOf, "error", err)
+		respondError(c, err, "Failed to retrieve pack configuration")
+		return nil, nil, nil, false
+	}
+	return configuration.GetRawPackSizes(), &configuration.Version, configuration.PackTypes, true
+}
+
+// parseBoolQuery reads an optional boolean query parameter, defaulting to false
+func parseBoolQuery(c *gin.Context, key string) (bool, error) {
+	value := c.Query(key)
+	if value == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
 }
@@ -0,0 +1,50 @@
+package http
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+	"github.com/Schieck/packs-calculator/pkg/middleware"
+)
+
+func TestCalculateRejectsPackSizesWithConfiguration(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "current version", body: `{"items": 251, "configuration_id": 1, "pack_sizes": [250, 500]}`},
+		{name: "pinned version", body: `{"items": 251, "configuration_id": 1, "configuration_version": 2, "pack_sizes": [250]}`},
+	}
+
+	// The request is refused before any configuration is loaded
+	handler := NewCalculatorHandler(nil, nil, nil, nil, nil, slog.New(slog.DiscardHandler))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(middleware.ErrorHandler())
+			router.POST("/calculate", handler.Calculate)
+
+			httpReq := httptest.NewRequest("POST", "/calculate", strings.NewReader(tt.body))
+			httpReq.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httpReq)
+
+			require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+			var problem errs.Problem
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, "invalid_order", problem.Code)
+			assert.Contains(t, problem.Detail, "pack_sizes cannot be combined with configuration_id")
+		})
+	}
+}
@@ -1,73 +1,127 @@
 package http
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
 	"github.com/Schieck/packs-calculator/internal/domain/errs"
 	"github.com/Schieck/packs-calculator/internal/dto"
+	packConfigurationService "github.com/Schieck/packs-calculator/internal/service/pack_configuration"
 	packConfigurationUseCase "github.com/Schieck/packs-calculator/internal/usecase/pack_configuration"
+	"github.com/Schieck/packs-calculator/pkg/middleware"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 )
 
 type PackConfigurationHandler struct {
-	getAllConfigurationsUseCase    *packConfigurationUseCase.GetAllConfigurationsUseCase
+	listConfigurationsUseCase      *packConfigurationUseCase.ListConfigurationsUseCase
 	getConfigurationByIDUseCase    *packConfigurationUseCase.GetConfigurationByIDUseCase
 	getDefaultConfigurationUseCase *packConfigurationUseCase.GetDefaultConfigurationUseCase
 	createConfigurationUseCase     *packConfigurationUseCase.CreateConfigurationUseCase
+	upsertConfigurationUseCase     *packConfigurationUseCase.UpsertConfigurationUseCase
 	updateConfigurationUseCase     *packConfigurationUseCase.UpdateConfigurationUseCase
+	patchConfigurationUseCase      *packConfigurationUseCase.PatchConfigurationUseCase
 	deleteConfigurationUseCase     *packConfigurationUseCase.DeleteConfigurationUseCase
 	setDefaultConfigurationUseCase *packConfigurationUseCase.SetDefaultConfigurationUseCase
+	getVersionsUseCase             *packConfigurationUseCase.GetConfigurationVersionsUseCase
+	restoreVersionUseCase          *packConfigurationUseCase.RestoreConfigurationVersionUseCase
+	restoreConfigurationUseCase    *packConfigurationUseCase.RestoreConfigurationUseCase
+	purgeConfigurationUseCase      *packConfigurationUseCase.PurgeConfigurationUseCase
+	exportConfigurationsUseCase    *packConfigurationUseCase.ExportConfigurationsUseCase
+	importConfigurationsUseCase    *packConfigurationUseCase.ImportConfigurationsUseCase
+	findEquivalentUseCase          *packConfigurationUseCase.FindEquivalentConfigurationsUseCase
 	logger                         *slog.Logger
 	validator                      *validator.Validate
 }
 
 func NewPackConfigurationHandler(
-	getAllConfigurationsUseCase *packConfigurationUseCase.GetAllConfigurationsUseCase,
+	listConfigurationsUseCase *packConfigurationUseCase.ListConfigurationsUseCase,
 	getConfigurationByIDUseCase *packConfigurationUseCase.GetConfigurationByIDUseCase,
 	getDefaultConfigurationUseCase *packConfigurationUseCase.GetDefaultConfigurationUseCase,
 	createConfigurationUseCase *packConfigurationUseCase.CreateConfigurationUseCase,
+	upsertConfigurationUseCase *packConfigurationUseCase.UpsertConfigurationUseCase,
 	updateConfigurationUseCase *packConfigurationUseCase.UpdateConfigurationUseCase,
+	patchConfigurationUseCase *packConfigurationUseCase.PatchConfigurationUseCase,
 	deleteConfigurationUseCase *packConfigurationUseCase.DeleteConfigurationUseCase,
 	setDefaultConfigurationUseCase *packConfigurationUseCase.SetDefaultConfigurationUseCase,
+	getVersionsUseCase *packConfigurationUseCase.GetConfigurationVersionsUseCase,
+	restoreVersionUseCase *packConfigurationUseCase.RestoreConfigurationVersionUseCase,
+	restoreConfigurationUseCase *packConfigurationUseCase.RestoreConfigurationUseCase,
+	purgeConfigurationUseCase *packConfigurationUseCase.PurgeConfigurationUseCase,
+	exportConfigurationsUseCase *packConfigurationUseCase.ExportConfigurationsUseCase,
+	importConfigurationsUseCase *packConfigurationUseCase.ImportConfigurationsUseCase,
+	findEquivalentUseCase *packConfigurationUseCase.FindEquivalentConfigurationsUseCase,
 	logger *slog.Logger,
 ) *PackConfigurationHandler {
 	return &PackConfigurationHandler{
-		getAllConfigurationsUseCase:    getAllConfigurationsUseCase,
+		listConfigurationsUseCase:      listConfigurationsUseCase,
 		getConfigurationByIDUseCase:    getConfigurationByIDUseCase,
 		getDefaultConfigurationUseCase: getDefaultConfigurationUseCase,
 		createConfigurationUseCase:     createConfigurationUseCase,
+		upsertConfigurationUseCase:     upsertConfigurationUseCase,
 		updateConfigurationUseCase:     updateConfigurationUseCase,
+		patchConfigurationUseCase:      patchConfigurationUseCase,
 		deleteConfigurationUseCase:     deleteConfigurationUseCase,
 		setDefaultConfigurationUseCase: setDefaultConfigurationUseCase,
+		getVersionsUseCase:             getVersionsUseCase,
+		restoreVersionUseCase:          restoreVersionUseCase,
+		restoreConfigurationUseCase:    restoreConfigurationUseCase,
+		purgeConfigurationUseCase:      purgeConfigurationUseCase,
+		exportConfigurationsUseCase:    exportConfigurationsUseCase,
+		importConfigurationsUseCase:    importConfigurationsUseCase,
+		findEquivalentUseCase:          findEquivalentUseCase,
 		logger:                         logger,
-		validator:                      validator.New(),
+		validator:                      newValidator(),
 	}
 }
 
 // GetAllConfigurations handles GET /pack-configurations
-// @Summary Get All Pack Configurations
-// @Description Retrieve all active pack configurations
+// @Summary List Pack Configurations
+// @Description Retrieve one page of active pack configurations, or the soft-deleted ones with status=archived.
+// @Description Without a sort the default configuration comes first, then the newest.
 // @Tags pack-configurations
 // @Accept json
 // @Produce json
+// @Param status query string false "active (default) or archived"
+// @Param search query string false "Case-insensitive name prefix"
+// @Param pack_size query int false "Only configurations offering this pack size"
+// @Param label query []string false "Label selector key=value; repeat, or separate with commas, to require several" collectionFormat(multi)
+// @Param created_from query string false "Inclusive lower bound on creation time (RFC3339 or YYYY-MM-DD)"
+// @Param created_to query string false "Exclusive upper bound on creation time (RFC3339 or YYYY-MM-DD)"
+// @Param sort query string false "name, created_at or updated_at"
+// @Param order query string false "asc (default) or desc"
+// @Param limit query int false "Page size (default 50, max 500)"
+// @Param offset query int false "Number of configurations to skip"
 // @Success 200 {object} dto.PackConfigurationListResponse
-// @Failure 500 {object} errs.ErrorResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Failure 504 {object} errs.Problem
 // @Security BearerAuth
 // @Router /pack-configurations [get]
 func (h PackConfigurationHandler) GetAllConfigurations(c *gin.Context) {
-	configurations, err := h.getAllConfigurationsUseCase.Execute()
+	query, err := parsePackConfigurationQuery(c)
 	if err != nil {
-		h.logger.Error("Get all pack configurations use case failed", "error", err)
-		c.JSON(http.StatusInternalServerError, errs.ErrorResponse{
-			Error: "Failed to retrieve pack configurations",
-		})
+		h.logger.Warn("Invalid pack configuration query", "error", err)
+		respondInvalidParameter(c, "Invalid query parameters", err.Error())
 		return
 	}
 
-	response := dto.ToPackConfigurationListResponse(configurations)
+	query = packConfigurationService.NormalizeQuery(query)
+
+	configurations, total, err := h.listConfigurationsUseCase.Execute(c.Request.Context(), query)
+	if err != nil {
+		h.logger.Error("List pack configurations use case failed", "error", err)
+		respondError(c, err, "Failed to retrieve pack configurations")
+		return
+	}
+
+	response := dto.ToPackConfigurationListResponse(configurations, total, query)
 	c.JSON(http.StatusOK, response)
 }
 
@@ -79,117 +133,157 @@ func (h PackConfigurationHandler) GetAllConfigurations(c *gin.Context) {
 // @Produce json
 // @Param id path int true "Pack Configuration ID"
 // @Success 200 {object} dto.PackConfigurationResponse
-// @Failure 400 {object} errs.ErrorResponse
-// @Failure 404 {object} errs.ErrorResponse
-// @Failure 500 {object} errs.ErrorResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 404 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Failure 504 {object} errs.Problem
 // @Security BearerAuth
+// @Header 200 {string} ETag "Current version of the configuration"
 // @Router /pack-configurations/{id} [get]
 func (h PackConfigurationHandler) GetConfigurationByID(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := strconv.Atoi(idParam)
 	if err != nil {
 		h.logger.Warn("Invalid pack configuration ID", "id", idParam, "error", err)
-		c.JSON(http.StatusBadRequest, errs.ErrorResponse{
-			Error: "Invalid pack configuration ID",
-		})
+		respondInvalidParameter(c, "Invalid pack configuration ID", "id must be an integer")
 		return
 	}
 
-	configuration, err := h.getConfigurationByIDUseCase.Execute(id)
+	configuration, err := h.getConfigurationByIDUseCase.Execute(c.Request.Context(), requestTenant(c), id)
 	if err != nil {
 		h.logger.Error("Get pack configuration by ID use case failed", "id", id, "error", err)
-		c.JSON(http.StatusNotFound, errs.ErrorResponse{
-			Error: "Pack configuration not found",
-		})
+		respondError(c, err, "Failed to retrieve pack configuration")
 		return
 	}
 
+	setConfigurationETag(c, configuration)
 	response := dto.ToPackConfigurationResponse(configuration)
 	c.JSON(http.StatusOK, response)
 }
 
 // GetDefaultConfiguration handles GET /pack-configurations/default
 // @Summary Get Default Pack Configuration
-// @Description Retrieve the default pack configuration
+// @Description Retrieve the default pack configuration in effect at as_of, or now
 // @Tags pack-configurations
 // @Accept json
 // @Produce json
+// @Param as_of query string false "RFC3339 timestamp or YYYY-MM-DD date to resolve the default at"
 // @Success 200 {object} dto.PackConfigurationResponse
-// @Failure 404 {object} errs.ErrorResponse
-// @Failure 500 {object} errs.ErrorResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 404 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Failure 504 {object} errs.Problem
 // @Security BearerAuth
+// @Header 200 {string} ETag "Current version of the configuration"
 // @Router /pack-configurations/default [get]
 func (h PackConfigurationHandler) GetDefaultConfiguration(c *gin.Context) {
-	configuration, err := h.getDefaultConfigurationUseCase.Execute()
+	asOf, err := parseAsOfQuery(c)
+	if err != nil {
+		h.logger.Warn("Invalid as_of", "as_of", c.Query("as_of"), "error", err)
+		respondInvalidParameter(c, "Invalid as_of", err.Error())
+		return
+	}
+
+	configuration, err := h.getDefaultConfigurationUseCase.Execute(c.Request.Context(), requestTenant(c), asOf)
 	if err != nil {
 		h.logger.Error("Get default pack configuration use case failed", "error", err)
-		c.JSON(http.StatusNotFound, errs.ErrorResponse{
-			Error: "No default pack configuration found",
-		})
+		respondError(c, err, "Failed to retrieve default pack configuration")
 		return
 	}
 
+	setConfigurationETag(c, configuration)
 	response := dto.ToPackConfigurationResponse(configuration)
 	c.JSON(http.StatusOK, response)
 }
 
 // CreateConfiguration handles POST /pack-configurations
 // @Summary Create Pack Configuration
-// @Description Create a new pack configuration
+// @Description Create a new pack configuration. Names are unique among active configurations regardless of case;
+// @Description a taken name is a 409 carrying existing_id, unless upsert=true, which updates that configuration's pack sizes instead.
+// @Description Pack sizes are stored sorted without repeats; a set another active configuration uses is a 409 duplicate_pack_sizes.
+// @Description pack_types references pack types by code and adds their item counts to the pack sizes; unknown codes are a 422 unknown_pack_type.
 // @Tags pack-configurations
 // @Accept json
 // @Produce json
+// @Param upsert query bool false "Update the active configuration with this name instead of failing"
 // @Param request body dto.CreatePackConfigurationRequest true "Pack configuration data"
+// @Success 200 {object} dto.PackConfigurationResponse "Existing configuration updated by upsert"
 // @Success 201 {object} dto.PackConfigurationResponse
-// @Failure 400 {object} errs.ErrorResponse
-// @Failure 500 {object} errs.ErrorResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 409 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Failure 504 {object} errs.Problem
 // @Security BearerAuth
 // @Router /pack-configurations [post]
 func (h PackConfigurationHandler) CreateConfiguration(c *gin.Context) {
+	upsert, err := parseBoolQuery(c, "upsert")
+	if err != nil {
+		h.logger.Warn("Invalid upsert flag", "upsert", c.Query("upsert"), "error", err)
+		respondInvalidParameter(c, "Invalid upsert flag", "upsert must be true or false")
+		return
+	}
+
 	var dtoReq dto.CreatePackConfigurationRequest
 
 	if err := c.ShouldBindJSON(&dtoReq); err != nil {
 		h.logger.Warn("Invalid request body", "error", err)
-		c.JSON(http.StatusBadRequest, errs.ErrorResponse{
-			Error: "Invalid request body",
-		})
+		respondInvalidBody(c, err)
 		return
 	}
 
 	if err := h.validator.Struct(&dtoReq); err != nil {
 		h.logger.Warn("Request validation failed", "error", err)
-		c.JSON(http.StatusBadRequest, errs.ErrorResponse{
-			Error:   "Validation failed",
-			Details: errs.FormatValidationErrors(err),
-		})
+		respondInvalidBody(c, err)
+		return
+	}
+
+	if upsert {
+		configuration, created, err := h.upsertConfigurationUseCase.Execute(c.Request.Context(), requestActor(c), dtoReq.Name, dtoReq.PackSizes, dtoReq.PackTypes, dto.ToEffectivePeriod(dtoReq.EffectiveFrom, dtoReq.EffectiveTo), dto.ToPackConfigurationDetails(dtoReq.PackConfigurationDetailsRequest))
+		if err != nil {
+			h.logger.Error("Upsert pack configuration use case failed", "error", err)
+			respondError(c, err, "Failed to upsert pack configuration")
+			return
+		}
+
+		status := http.StatusOK
+		if created {
+			status = http.StatusCreated
+		}
+		setConfigurationETag(c, configuration)
+		c.JSON(status, dto.ToPackConfigurationResponse(configuration))
 		return
 	}
 
-	configuration, err := h.createConfigurationUseCase.Execute(dtoReq.Name, dtoReq.PackSizes)
+	configuration, err := h.createConfigurationUseCase.Execute(c.Request.Context(), requestActor(c), dtoReq.Name, dtoReq.PackSizes, dtoReq.PackTypes, dto.ToEffectivePeriod(dtoReq.EffectiveFrom, dtoReq.EffectiveTo), dto.ToPackConfigurationDetails(dtoReq.PackConfigurationDetailsRequest))
 	if err != nil {
 		h.logger.Error("Create pack configuration use case failed", "error", err)
-		c.JSON(http.StatusInternalServerError, errs.ErrorResponse{
-			Error: "Failed to create pack configuration",
-		})
+		respondError(c, err, "Failed to create pack configuration")
 		return
 	}
 
+	setConfigurationETag(c, configuration)
 	response := dto.ToPackConfigurationResponse(configuration)
 	c.JSON(http.StatusCreated, response)
 }
 
 // UpdateConfiguration handles PUT /pack-configurations/:id
 // @Summary Update Pack Configuration
-// @Description Update an existing pack configuration
+// @Description Update an existing pack configuration. If-Match must carry the ETag the client last read.
 // @Tags pack-configurations
 // @Accept json
 // @Produce json
 // @Param id path int true "Pack Configuration ID"
+// @Param If-Match header string true "ETag of the version being changed"
 // @Param request body dto.UpdatePackConfigurationRequest true "Updated pack configuration data"
 // @Success 200 {object} dto.PackConfigurationResponse
-// @Failure 400 {object} errs.ErrorResponse
-// @Failure 404 {object} errs.ErrorResponse
-// @Failure 500 {object} errs.ErrorResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 404 {object} errs.Problem
+// @Failure 409 {object} errs.Problem
+// @Failure 412 {object} errs.Problem
+// @Failure 428 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Failure 504 {object} errs.Problem
+// @Header 200 {string} ETag "New version of the configuration"
 // @Security BearerAuth
 // @Router /pack-configurations/{id} [put]
 func (h PackConfigurationHandler) UpdateConfiguration(c *gin.Context) {
@@ -197,9 +291,12 @@ func (h PackConfigurationHandler) UpdateConfiguration(c *gin.Context) {
 	id, err := strconv.Atoi(idParam)
 	if err != nil {
 		h.logger.Warn("Invalid pack configuration ID", "id", idParam, "error", err)
-		c.JSON(http.StatusBadRequest, errs.ErrorResponse{
-			Error: "Invalid pack configuration ID",
-		})
+		respondInvalidParameter(c, "Invalid pack configuration ID", "id must be an integer")
+		return
+	}
+
+	version, ok := h.bindIfMatch(c)
+	if !ok {
 		return
 	}
 
@@ -207,45 +304,109 @@ func (h PackConfigurationHandler) UpdateConfiguration(c *gin.Context) {
 
 	if err := c.ShouldBindJSON(&dtoReq); err != nil {
 		h.logger.Warn("Invalid request body", "error", err)
-		c.JSON(http.StatusBadRequest, errs.ErrorResponse{
-			Error: "Invalid request body",
-		})
+		respondInvalidBody(c, err)
 		return
 	}
 
 	if err := h.validator.Struct(&dtoReq); err != nil {
 		h.logger.Warn("Request validation failed", "error", err)
-		c.JSON(http.StatusBadRequest, errs.ErrorResponse{
-			Error:   "Validation failed",
-			Details: errs.FormatValidationErrors(err),
-		})
+		respondInvalidBody(c, err)
 		return
 	}
 
-	configuration, err := h.updateConfigurationUseCase.Execute(id, dtoReq.Name, dtoReq.PackSizes, dtoReq.IsDefault)
+	configuration, err := h.updateConfigurationUseCase.Execute(c.Request.Context(), requestActor(c), id, version, dtoReq.Name, dtoReq.PackSizes, dtoReq.PackTypes,
+		dto.ToEffectivePeriod(dtoReq.EffectiveFrom, dtoReq.EffectiveTo), dto.ToPackConfigurationDetails(dtoReq.PackConfigurationDetailsRequest), dtoReq.IsDefault)
 	if err != nil {
 		h.logger.Error("Update pack configuration use case failed", "id", id, "error", err)
-		c.JSON(http.StatusInternalServerError, errs.ErrorResponse{
-			Error: "Failed to update pack configuration",
-		})
+		h.respondConditionalWriteError(c, err, "Failed to update pack configuration")
 		return
 	}
 
+	setConfigurationETag(c, configuration)
+	response := dto.ToPackConfigurationResponse(configuration)
+	c.JSON(http.StatusOK, response)
+}
+
+// PatchConfiguration handles PATCH /pack-configurations/:id
+// @Summary Patch Pack Configuration
+// @Description Change some fields of a pack configuration with a JSON Merge Patch (RFC 7386).
+// @Description Absent members are left unchanged; add_pack_sizes and remove_pack_sizes edit the pack sizes in place.
+// @Description pack_types replaces the referenced pack types; a size a kept pack type offers cannot be removed.
+// @Description is_default=true moves the default flag here; the default cannot be unset directly.
+// @Description If-Match must carry the ETag the client last read.
+// @Tags pack-configurations
+// @Accept application/merge-patch+json
+// @Accept json
+// @Produce json
+// @Param id path int true "Pack Configuration ID"
+// @Param If-Match header string true "ETag of the version being changed"
+// @Param request body dto.PatchPackConfigurationRequest true "Fields to change"
+// @Success 200 {object} dto.PackConfigurationResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 404 {object} errs.Problem
+// @Failure 409 {object} errs.Problem
+// @Failure 412 {object} errs.Problem
+// @Failure 415 {object} errs.Problem
+// @Failure 422 {object} errs.Problem
+// @Failure 428 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Failure 504 {object} errs.Problem
+// @Header 200 {string} ETag "Version of the configuration after the patch"
+// @Security BearerAuth
+// @Router /pack-configurations/{id} [patch]
+func (h PackConfigurationHandler) PatchConfiguration(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		h.logger.Warn("Invalid pack configuration ID", "id", idParam, "error", err)
+		respondInvalidParameter(c, "Invalid pack configuration ID", "id must be an integer")
+		return
+	}
+
+	version, ok := h.bindIfMatch(c)
+	if !ok {
+		return
+	}
+
+	var dtoReq dto.PatchPackConfigurationRequest
+	if !bindMergePatch(c, &dtoReq) {
+		h.logger.Warn("Invalid merge patch", "id", id)
+		return
+	}
+
+	if err := h.validator.Struct(&dtoReq); err != nil {
+		h.logger.Warn("Request validation failed", "error", err)
+		respondInvalidBody(c, err)
+		return
+	}
+
+	configuration, err := h.patchConfigurationUseCase.Execute(c.Request.Context(), requestActor(c), id, version, dto.ToPackConfigurationPatch(&dtoReq))
+	if err != nil {
+		h.logger.Error("Patch pack configuration use case failed", "id", id, "error", err)
+		h.respondConditionalWriteError(c, err, "Failed to patch pack configuration")
+		return
+	}
+
+	setConfigurationETag(c, configuration)
 	response := dto.ToPackConfigurationResponse(configuration)
 	c.JSON(http.StatusOK, response)
 }
 
 // DeleteConfiguration handles DELETE /pack-configurations/:id
 // @Summary Delete Pack Configuration
-// @Description Delete a pack configuration (soft delete)
+// @Description Delete a pack configuration (soft delete). If-Match must carry the ETag the client last read.
 // @Tags pack-configurations
 // @Accept json
 // @Produce json
 // @Param id path int true "Pack Configuration ID"
+// @Param If-Match header string true "ETag of the version being changed"
 // @Success 204
-// @Failure 400 {object} errs.ErrorResponse
-// @Failure 404 {object} errs.ErrorResponse
-// @Failure 500 {object} errs.ErrorResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 404 {object} errs.Problem
+// @Failure 412 {object} errs.Problem
+// @Failure 428 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Failure 504 {object} errs.Problem
 // @Security BearerAuth
 // @Router /pack-configurations/{id} [delete]
 func (h PackConfigurationHandler) DeleteConfiguration(c *gin.Context) {
@@ -253,18 +414,19 @@ func (h PackConfigurationHandler) DeleteConfiguration(c *gin.Context) {
 	id, err := strconv.Atoi(idParam)
 	if err != nil {
 		h.logger.Warn("Invalid pack configuration ID", "id", idParam, "error", err)
-		c.JSON(http.StatusBadRequest, errs.ErrorResponse{
-			Error: "Invalid pack configuration ID",
-		})
+		respondInvalidParameter(c, "Invalid pack configuration ID", "id must be an integer")
 		return
 	}
 
-	err = h.deleteConfigurationUseCase.Execute(id)
+	version, ok := h.bindIfMatch(c)
+	if !ok {
+		return
+	}
+
+	err = h.deleteConfigurationUseCase.Execute(c.Request.Context(), requestActor(c), id, version)
 	if err != nil {
 		h.logger.Error("Delete pack configuration use case failed", "id", id, "error", err)
-		c.JSON(http.StatusInternalServerError, errs.ErrorResponse{
-			Error: "Failed to delete pack configuration",
-		})
+		h.respondConditionalWriteError(c, err, "Failed to delete pack configuration")
 		return
 	}
 
@@ -273,15 +435,19 @@ func (h PackConfigurationHandler) DeleteConfiguration(c *gin.Context) {
 
 // SetDefaultConfiguration handles PATCH /pack-configurations/:id/default
 // @Summary Set Default Pack Configuration
-// @Description Set a specific pack configuration as the default
+// @Description Set a specific pack configuration as the default. If-Match must carry the ETag the client last read.
 // @Tags pack-configurations
 // @Accept json
 // @Produce json
 // @Param id path int true "Pack Configuration ID"
+// @Param If-Match header string true "ETag of the version being changed"
 // @Success 200 {object} dto.PackConfigurationResponse
-// @Failure 400 {object} errs.ErrorResponse
-// @Failure 404 {object} errs.ErrorResponse
-// @Failure 500 {object} errs.ErrorResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 404 {object} errs.Problem
+// @Failure 412 {object} errs.Problem
+// @Failure 428 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Failure 504 {object} errs.Problem
 // @Security BearerAuth
 // @Router /pack-configurations/{id}/default [patch]
 func (h PackConfigurationHandler) SetDefaultConfiguration(c *gin.Context) {
@@ -289,30 +455,491 @@ func (h PackConfigurationHandler) SetDefaultConfiguration(c *gin.Context) {
 	id, err := strconv.Atoi(idParam)
 	if err != nil {
 		h.logger.Warn("Invalid pack configuration ID", "id", idParam, "error", err)
-		c.JSON(http.StatusBadRequest, errs.ErrorResponse{
-			Error: "Invalid pack configuration ID",
-		})
+		respondInvalidParameter(c, "Invalid pack configuration ID", "id must be an integer")
 		return
 	}
 
-	err = h.setDefaultConfigurationUseCase.Execute(id)
+	version, ok := h.bindIfMatch(c)
+	if !ok {
+		return
+	}
+
+	err = h.setDefaultConfigurationUseCase.Execute(c.Request.Context(), requestActor(c), id, version)
 	if err != nil {
 		h.logger.Error("Set default pack configuration use case failed", "id", id, "error", err)
-		c.JSON(http.StatusInternalServerError, errs.ErrorResponse{
-			Error: "Failed to set default pack configuration",
-		})
+		h.respondConditionalWriteError(c, err, "Failed to set default pack configuration")
 		return
 	}
 
-	configuration, err := h.getConfigurationByIDUseCase.Execute(id)
+	configuration, err := h.getConfigurationByIDUseCase.Execute(c.Request.Context(), requestTenant(c), id)
 	if err != nil {
 		h.logger.Error("Get pack configuration by ID use case failed after setting default", "id", id, "error", err)
-		c.JSON(http.StatusInternalServerError, errs.ErrorResponse{
-			Error: "Failed to retrieve updated pack configuration",
-		})
+		respondError(c, err, "Failed to retrieve updated pack configuration")
 		return
 	}
 
+	setConfigurationETag(c, configuration)
 	response := dto.ToPackConfigurationResponse(configuration)
 	c.JSON(http.StatusOK, response)
 }
+
+// GetConfigurationVersions handles GET /pack-configurations/:id/versions
+// @Summary Get Pack Configuration Versions
+// @Description Retrieve every version of a pack configuration, newest first. History stays available after deletion.
+// @Tags pack-configurations
+// @Accept json
+// @Produce json
+// @Param id path int true "Pack Configuration ID"
+// @Success 200 {object} dto.PackConfigurationVersionListResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 404 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Failure 504 {object} errs.Problem
+// @Security BearerAuth
+// @Router /pack-configurations/{id}/versions [get]
+func (h PackConfigurationHandler) GetConfigurationVersions(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		h.logger.Warn("Invalid pack configuration ID", "id", idParam, "error", err)
+		respondInvalidParameter(c, "Invalid pack configuration ID", "id must be an integer")
+		return
+	}
+
+	versions, err := h.getVersionsUseCase.Execute(c.Request.Context(), requestTenant(c), id)
+	if err != nil {
+		h.logger.Error("Get pack configuration versions use case failed", "id", id, "error", err)
+		respondError(c, err, "Failed to retrieve pack configuration versions")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToPackConfigurationVersionListResponse(versions))
+}
+
+// RestoreConfigurationVersion handles POST /pack-configurations/:id/versions/:version/restore
+// @Summary Restore Pack Configuration Version
+// @Description Roll a pack configuration back to an earlier version. The restored contents are saved as a new version.
+// @Tags pack-configurations
+// @Accept json
+// @Produce json
+// @Param id path int true "Pack Configuration ID"
+// @Param version path int true "Version to restore"
+// @Success 200 {object} dto.PackConfigurationResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 404 {object} errs.Problem
+// @Failure 409 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Failure 504 {object} errs.Problem
+// @Security BearerAuth
+// @Router /pack-configurations/{id}/versions/{version}/restore [post]
+func (h PackConfigurationHandler) RestoreConfigurationVersion(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		h.logger.Warn("Invalid pack configuration ID", "id", idParam, "error", err)
+		respondInvalidParameter(c, "Invalid pack configuration ID", "id must be an integer")
+		return
+	}
+
+	versionParam := c.Param("version")
+	version, err := strconv.Atoi(versionParam)
+	if err != nil || version <= 0 {
+		h.logger.Warn("Invalid pack configuration version", "version", versionParam, "error", err)
+		respondInvalidParameter(c, "Invalid pack configuration version", "version must be a positive integer")
+		return
+	}
+
+	configuration, err := h.restoreVersionUseCase.Execute(c.Request.Context(), requestActor(c), id, version)
+	if err != nil {
+		h.logger.Error("Restore pack configuration version use case failed", "id", id, "version", version, "error", err)
+		respondError(c, err, "Failed to restore pack configuration version")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToPackConfigurationResponse(configuration))
+}
+
+// RestoreConfiguration handles POST /pack-configurations/:id/restore
+// @Summary Restore Archived Pack Configuration
+// @Description Reactivate a soft-deleted pack configuration. Pass a name to restore it under a new name when an active configuration already uses the old one.
+// @Description The restored configuration becomes the default only if no active configuration is currently the default.
+// @Tags pack-configurations
+// @Accept json
+// @Produce json
+// @Param id path int true "Pack Configuration ID"
+// @Param request body dto.RestorePackConfigurationRequest false "Optional new name"
+// @Success 200 {object} dto.PackConfigurationResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 404 {object} errs.Problem
+// @Failure 409 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Failure 504 {object} errs.Problem
+// @Security BearerAuth
+// @Router /pack-configurations/{id}/restore [post]
+func (h PackConfigurationHandler) RestoreConfiguration(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		h.logger.Warn("Invalid pack configuration ID", "id", idParam, "error", err)
+		respondInvalidParameter(c, "Invalid pack configuration ID", "id must be an integer")
+		return
+	}
+
+	var dtoReq dto.RestorePackConfigurationRequest
+
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&dtoReq); err != nil {
+			h.logger.Warn("Invalid request body", "error", err)
+			respondInvalidBody(c, err)
+			return
+		}
+	}
+
+	if err := h.validator.Struct(&dtoReq); err != nil {
+		h.logger.Warn("Request validation failed", "error", err)
+		respondInvalidBody(c, err)
+		return
+	}
+
+	configuration, err := h.restoreConfigurationUseCase.Execute(c.Request.Context(), requestActor(c), id, dtoReq.Name)
+	if err != nil {
+		h.logger.Error("Restore pack configuration use case failed", "id", id, "error", err)
+		respondError(c, err, "Failed to restore pack configuration")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToPackConfigurationResponse(configuration))
+}
+
+// PurgeConfiguration handles DELETE /pack-configurations/:id/purge
+// @Summary Purge Pack Configuration
+// @Description Permanently remove an archived pack configuration and its version history. Admin only.
+// @Tags pack-configurations
+// @Accept json
+// @Produce json
+// @Param id path int true "Pack Configuration ID"
+// @Success 204
+// @Failure 400 {object} errs.Problem
+// @Failure 403 {object} errs.Problem
+// @Failure 404 {object} errs.Problem
+// @Failure 409 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Failure 504 {object} errs.Problem
+// @Security BearerAuth
+// @Router /pack-configurations/{id}/purge [delete]
+func (h PackConfigurationHandler) PurgeConfiguration(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		h.logger.Warn("Invalid pack configuration ID", "id", idParam, "error", err)
+		respondInvalidParameter(c, "Invalid pack configuration ID", "id must be an integer")
+		return
+	}
+
+	if err := h.purgeConfigurationUseCase.Execute(c.Request.Context(), requestActor(c), id); err != nil {
+		h.logger.Error("Purge pack configuration use case failed", "id", id, "error", err)
+		respondError(c, err, "Failed to purge pack configuration")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ExportConfigurations handles GET /pack-configurations/export
+// @Summary Export Pack Configurations
+// @Description Download every active pack configuration, ordered by name, in a file POST /pack-configurations/import accepts.
+// @Description CSV puts pack sizes in one cell separated by semicolons.
+// @Tags pack-configurations
+// @Produce json
+// @Produce text/csv
+// @Produce application/yaml
+// @Param format query string false "json (default), csv or yaml"
+// @Success 200 {object} dto.PackConfigurationDocument
+// @Failure 400 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Failure 504 {object} errs.Problem
+// @Security BearerAuth
+// @Router /pack-configurations/export [get]
+func (h PackConfigurationHandler) ExportConfigurations(c *gin.Context) {
+	format, err := parseTransferFormat(c.DefaultQuery("format", string(transferFormatJSON)))
+	if err != nil {
+		h.logger.Warn("Invalid export format", "format", c.Query("format"))
+		respondInvalidParameter(c, "Invalid query parameters", err.Error())
+		return
+	}
+
+	configurations, err := h.exportConfigurationsUseCase.Execute(c.Request.Context(), requestTenant(c))
+	if err != nil {
+		h.logger.Error("Export pack configurations use case failed", "error", err)
+		respondError(c, err, "Failed to export pack configurations")
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="pack-configurations.%s"`, format))
+	if err := writeConfigurationDocument(c, format, dto.ToPackConfigurationDocument(configurations)); err != nil {
+		h.logger.Error("Failed to write pack configuration export", "format", format, "error", err)
+	}
+}
+
+// ImportConfigurations handles POST /pack-configurations/import
+// @Summary Import Pack Configurations
+// @Description Create pack configurations from a file in the export format. With upsert=true a configuration with the same name is updated instead of rejected.
+// @Description Every row is validated first and problems are listed by row; nothing is written unless all rows are valid.
+// @Description dry_run=true reports what would happen without writing.
+// @Tags pack-configurations
+// @Accept json
+// @Accept text/csv
+// @Accept application/yaml
+// @Produce json
+// @Param format query string false "json, csv or yaml (default from Content-Type, else json)"
+// @Param upsert query bool false "Update configurations that already exist by name"
+// @Param dry_run query bool false "Validate and report without writing"
+// @Param request body dto.PackConfigurationDocument true "Configurations to import"
+// @Success 200 {object} dto.PackConfigurationImportResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 409 {object} errs.Problem
+// @Failure 413 {object} errs.Problem
+// @Failure 422 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Failure 504 {object} errs.Problem
+// @Security BearerAuth
+// @Router /pack-configurations/import [post]
+func (h PackConfigurationHandler) ImportConfigurations(c *gin.Context) {
+	format, options, err := parseImportQuery(c)
+	if err != nil {
+		h.logger.Warn("Invalid import query", "error", err)
+		respondInvalidParameter(c, "Invalid query parameters", err.Error())
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes)
+
+	document, fieldErrors, err := readConfigurationDocument(c.Request.Body, format)
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		h.logger.Warn("Import body too large", "limit", tooLarge.Limit)
+		middleware.AbortWithProblem(c, middleware.NewProblem(c, http.StatusRequestEntityTooLarge, "request_too_large",
+			"Import file is too large", fmt.Sprintf("Import files are limited to %d bytes", tooLarge.Limit)))
+		return
+	case err != nil:
+		h.logger.Warn("Invalid import body", "format", format, "error", err)
+		respondUnreadableDocument(c, format, err)
+		return
+	case len(fieldErrors) > 0:
+		h.logger.Warn("Invalid import body", "format", format, "errors", len(fieldErrors))
+		respondInvalidFields(c, fieldErrors)
+		return
+	}
+
+	results, err := h.importConfigurationsUseCase.Execute(c.Request.Context(), requestActor(c), document.ImportRows(), options)
+	if err != nil {
+		h.logger.Error("Import pack configurations use case failed", "error", err)
+		respondError(c, err, "Failed to import pack configurations")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToPackConfigurationImportResponse(results, options.DryRun))
+}
+
+// FindEquivalentConfigurations handles GET /pack-configurations/equivalent
+// @Summary Find Equivalent Pack Configurations
+// @Description List active configurations with the same pack sizes, then those that differ only by redundant sizes.
+// @Description A size is redundant when it is a sum of smaller sizes, e.g. 750 alongside 250 and 500; equivalent configurations ship the same items for every order.
+// @Tags pack-configurations
+// @Accept json
+// @Produce json
+// @Param pack_sizes query string true "Comma-separated pack sizes, e.g. 250,500,1000"
+// @Success 200 {object} dto.PackSizeEquivalenceResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Failure 504 {object} errs.Problem
+// @Security BearerAuth
+// @Router /pack-configurations/equivalent [get]
+func (h PackConfigurationHandler) FindEquivalentConfigurations(c *gin.Context) {
+	packSizes, err := parsePackSizesQuery(c, "pack_sizes")
+	if err != nil {
+		h.logger.Warn("Invalid pack sizes", "pack_sizes", c.Query("pack_sizes"), "error", err)
+		respondInvalidParameter(c, "Invalid pack sizes", err.Error())
+		return
+	}
+
+	equivalence, err := h.findEquivalentUseCase.Execute(c.Request.Context(), requestTenant(c), packSizes)
+	if err != nil {
+		h.logger.Error("Find equivalent pack configurations use case failed", "error", err)
+		respondError(c, err, "Failed to find equivalent pack configurations")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToPackSizeEquivalenceResponse(equivalence))
+}
+
+// parsePackSizesQuery reads a required comma-separated list of positive sizes
+func parsePackSizesQuery(c *gin.Context, key string) ([]int, error) {
+	value := strings.TrimSpace(c.Query(key))
+	if value == "" {
+		return nil, fmt.Errorf("%s is required", key)
+	}
+
+	var packSizes []int
+	for _, part := range strings.Split(value, ",") {
+		size, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || size <= 0 {
+			return nil, fmt.Errorf("%s must be positive integers separated by commas", key)
+		}
+		packSizes = append(packSizes, size)
+	}
+	return packSizes, nil
+}
+
+// parseAsOfQuery reads the moment to resolve configurations at, defaulting
+// to now
+func parseAsOfQuery(c *gin.Context) (time.Time, error) {
+	value := c.Query("as_of")
+	if value == "" {
+		return time.Now(), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("as_of must be an RFC3339 timestamp or a YYYY-MM-DD date")
+}
+
+func parsePackConfigurationQuery(c *gin.Context) (entity.PackConfigurationQuery, error) {
+	query := entity.PackConfigurationQuery{
+		Tenant: requestTenant(c),
+		Search: c.Query("search"),
+	}
+
+	switch status := c.DefaultQuery("status", "active"); status {
+	case "active":
+	case "archived":
+		query.Archived = true
+	default:
+		return query, fmt.Errorf("status must be active or archived")
+	}
+
+	if value := c.Query("pack_size"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size <= 0 {
+			return query, fmt.Errorf("pack_size must be a positive integer")
+		}
+		query.PackSize = &size
+	}
+
+	labels, err := entity.ParseLabelSelector(c.QueryArray("label"))
+	if err != nil {
+		return query, err
+	}
+	query.Labels = labels
+
+	createdFrom, err := parseAnalyticsTime(c, "created_from")
+	if err != nil {
+		return query, err
+	}
+	if !createdFrom.IsZero() {
+		query.CreatedFrom = &createdFrom
+	}
+	createdTo, err := parseAnalyticsTime(c, "created_to")
+	if err != nil {
+		return query, err
+	}
+	if !createdTo.IsZero() {
+		query.CreatedTo = &createdTo
+	}
+
+	if query.Sort, err = entity.ParsePackConfigurationSort(c.Query("sort")); err != nil {
+		return query, err
+	}
+	switch order := c.DefaultQuery("order", "asc"); order {
+	case "asc":
+	case "desc":
+		query.Descending = true
+	default:
+		return query, fmt.Errorf("order must be asc or desc")
+	}
+
+	if query.Limit, err = parseNonNegativeQuery(c, "limit"); err != nil {
+		return query, err
+	}
+	if query.Offset, err = parseNonNegativeQuery(c, "offset"); err != nil {
+		return query, err
+	}
+
+	return query, nil
+}
+
+var errMissingIfMatch = errors.New("If-Match header is required")
+
+// configurationETag is the strong entity tag of a configuration version
+func configurationETag(version int) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+func setConfigurationETag(c *gin.Context, configuration *entity.PackConfiguration) {
+	c.Header("ETag", configurationETag(configuration.Version))
+}
+
+// ifMatchVersion reads the configuration version a write is conditioned on.
+// "*" is refused: it would match whatever version is current and let the
+// write overwrite changes the client has not seen.
+func ifMatchVersion(c *gin.Context) (int, error) {
+	value := strings.TrimSpace(c.GetHeader("If-Match"))
+	if value == "" {
+		return 0, errMissingIfMatch
+	}
+	if value == "*" {
+		return 0, fmt.Errorf("If-Match must name the version last read; * is not accepted")
+	}
+
+	unquoted, err := strconv.Unquote(value)
+	if err != nil {
+		return 0, fmt.Errorf("If-Match must be a quoted ETag such as \"3\"")
+	}
+	version, err := strconv.Atoi(unquoted)
+	if err != nil || version <= 0 {
+		return 0, fmt.Errorf("If-Match does not name a pack configuration version")
+	}
+	return version, nil
+}
+
+// bindIfMatch writes a 428 or 400 and returns false when If-Match is missing or malformed
+func (h PackConfigurationHandler) bindIfMatch(c *gin.Context) (int, bool) {
+	version, err := ifMatchVersion(c)
+	if err == nil {
+		return version, true
+	}
+
+	h.logger.Warn("Invalid If-Match header", "if_match", c.GetHeader("If-Match"), "error", err)
+	if errors.Is(err, errMissingIfMatch) {
+		middleware.AbortWithProblem(c, middleware.NewProblem(c, http.StatusPreconditionRequired, "precondition_required",
+			"If-Match header is required", "Send the ETag returned when the pack configuration was read"))
+		return 0, false
+	}
+	respondInvalidParameter(c, "Invalid If-Match header", err.Error())
+	return 0, false
+}
+
+// respondConditionalWriteError answers a stale If-Match with 412 and the
+// current ETag so the client can reload; other errors go to the error middleware.
+func (h PackConfigurationHandler) respondConditionalWriteError(c *gin.Context, err error, fallback string) {
+	var conflict *errs.VersionConflictError
+	switch {
+	case errors.As(err, &conflict):
+		c.Header("ETag", configurationETag(conflict.CurrentVersion))
+		middleware.AbortWithProblem(c, middleware.NewProblem(c, http.StatusPreconditionFailed, errs.ErrConfigurationVersionConflict.Code,
+			"Pack configuration was modified by another request",
+			fmt.Sprintf("Current version is %d; reload it and retry", conflict.CurrentVersion)))
+	default:
+		respondError(c, err, fallback)
+	}
+}
+
+// respondError hands err to the error middleware, which maps domain errors to
+// their status and code; message is only shown for unexpected errors
+func respondError(c *gin.Context, err error, message string) {
+	_ = c.Error(err).SetMeta(message)
+}
@@ -0,0 +1,62 @@
+package http
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+)
+
+func TestBindIfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		ifMatch string
+		status  int
+		code    string
+		version int
+	}{
+		{name: "quoted version", ifMatch: `"3"`, status: http.StatusOK, version: 3},
+		{name: "missing", ifMatch: "", status: http.StatusPreconditionRequired, code: "precondition_required"},
+		{name: "any version", ifMatch: "*", status: http.StatusBadRequest, code: "invalid_parameter"},
+		{name: "unquoted", ifMatch: "3", status: http.StatusBadRequest, code: "invalid_parameter"},
+		{name: "not a version", ifMatch: `"0"`, status: http.StatusBadRequest, code: "invalid_parameter"},
+	}
+
+	handler := PackConfigurationHandler{logger: slog.New(slog.DiscardHandler)}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var version int
+			router := gin.New()
+			router.PUT("/write", func(c *gin.Context) {
+				var ok bool
+				if version, ok = handler.bindIfMatch(c); ok {
+					c.Status(http.StatusOK)
+				}
+			})
+
+			httpReq := httptest.NewRequest("PUT", "/write", nil)
+			if tt.ifMatch != "" {
+				httpReq.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httpReq)
+
+			require.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.version, version)
+			if tt.status != http.StatusOK {
+				var problem errs.Problem
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+				assert.Equal(t, tt.code, problem.Code)
+			}
+		})
+	}
+}
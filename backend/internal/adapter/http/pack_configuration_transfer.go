@@ -0,0 +1,143 @@
+package http
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+	"github.com/Schieck/packs-calculator/internal/dto"
+	"github.com/Schieck/packs-calculator/pkg/middleware"
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+// maxImportBytes caps the size of an import file
+const maxImportBytes = 10 << 20
+
+// transferFormat is a file format of the pack configuration export and import
+type transferFormat string
+
+const (
+	transferFormatJSON transferFormat = "json"
+	transferFormatCSV  transferFormat = "csv"
+	transferFormatYAML transferFormat = "yaml"
+)
+
+func parseTransferFormat(value string) (transferFormat, error) {
+	switch format := transferFormat(value); format {
+	case transferFormatJSON, transferFormatCSV, transferFormatYAML:
+		return format, nil
+	default:
+		return "", fmt.Errorf("format must be json, csv or yaml")
+	}
+}
+
+// parseImportQuery reads the import format, from ?format or else the
+// Content-Type, and the upsert and dry_run flags
+func parseImportQuery(c *gin.Context) (transferFormat, entity.ImportOptions, error) {
+	var options entity.ImportOptions
+	format, err := importFormat(c)
+	if err != nil {
+		return "", options, err
+	}
+	if options.Upsert, err = parseBoolQuery(c, "upsert"); err != nil {
+		return "", options, fmt.Errorf("upsert must be true or false")
+	}
+	if options.DryRun, err = parseBoolQuery(c, "dry_run"); err != nil {
+		return "", options, fmt.Errorf("dry_run must be true or false")
+	}
+	return format, options, nil
+}
+
+func importFormat(c *gin.Context) (transferFormat, error) {
+	if value := c.Query("format"); value != "" {
+		return parseTransferFormat(value)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	switch mediaType {
+	case "text/csv":
+		return transferFormatCSV, nil
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return transferFormatYAML, nil
+	default:
+		return transferFormatJSON, nil
+	}
+}
+
+func writeConfigurationDocument(c *gin.Context, format transferFormat, document *dto.PackConfigurationDocument) error {
+	switch format {
+	case transferFormatCSV:
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Status(http.StatusOK)
+		writer := csv.NewWriter(c.Writer)
+		return writer.WriteAll(document.CSVRows())
+	case transferFormatYAML:
+		data, err := yaml.Marshal(document)
+		if err != nil {
+			return err
+		}
+		c.Data(http.StatusOK, "application/yaml; charset=utf-8", data)
+		return nil
+	default:
+		c.JSON(http.StatusOK, document)
+		return nil
+	}
+}
+
+// readConfigurationDocument decodes an import file, rejecting members or
+// columns the export does not write. CSV cells that cannot be parsed are
+// returned as field errors rather than failing the whole file.
+func readConfigurationDocument(body io.Reader, format transferFormat) (*dto.PackConfigurationDocument, []errs.FieldError, error) {
+	if format == transferFormatCSV {
+		return dto.ParsePackConfigurationCSV(body)
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var document dto.PackConfigurationDocument
+	if format == transferFormatYAML {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&document)
+	} else {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&document)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return &document, nil, nil
+}
+
+// respondUnreadableDocument writes a 400 for an import file that could not be
+// decoded at all
+func respondUnreadableDocument(c *gin.Context, format transferFormat, err error) {
+	if format == transferFormatJSON {
+		respondInvalidBody(c, err)
+		return
+	}
+
+	var typeErr *yaml.TypeError
+	detail := err.Error()
+	switch {
+	case errors.Is(err, io.EOF):
+		detail = "The request body is empty"
+	case errors.As(err, &typeErr):
+		detail = typeErr.Errors[0]
+	}
+	middleware.AbortWithProblem(c, middleware.NewProblem(c, http.StatusBadRequest, "invalid_request_body",
+		"Invalid request body", fmt.Sprintf("Not a valid %s import: %s", strings.ToUpper(string(format)), detail)))
+}
@@ -0,0 +1,108 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+	"github.com/Schieck/packs-calculator/internal/dto"
+)
+
+func TestConfigurationDocumentRoundTrip(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	created := time.Date(2024, 1, 2, 3, 4, 5, 123456000, time.UTC)
+	updated := time.Date(2024, 6, 7, 8, 9, 10, 654321000, time.FixedZone("CEST", 2*60*60))
+	effectiveFrom := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	exported := dto.ToPackConfigurationDocument([]*entity.PackConfiguration{
+		{ID: 4, Name: "Main, Edge \"Case\"", PackSizes: []int{23, 31, 53}, IsDefault: true, CreatedAt: created, UpdatedAt: updated,
+			EffectivePeriod: entity.EffectivePeriod{EffectiveFrom: &effectiveFrom},
+			PackConfigurationDetails: entity.PackConfigurationDetails{
+				Description: "Edge cases; \"quoted\"",
+				Labels:      entity.Labels{"customer": "acme", "region": "eu"},
+				Metadata:    json.RawMessage(`{"erp": {"id": 42, "tags": ["a", "b"]}}`),
+			}},
+		{ID: 9, Name: "Standard", PackSizes: []int{250, 500}, CreatedAt: created, UpdatedAt: created, PackTypeIDs: []int{1, 2},
+			PackTypes: []*entity.PackType{{ID: 1, Code: "BOX-250", ItemCount: 250}, {ID: 2, Code: "BOX-500", ItemCount: 500}}},
+	})
+
+	for _, format := range []transferFormat{transferFormatJSON, transferFormatCSV, transferFormatYAML} {
+		t.Run(string(format), func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			require.NoError(t, writeConfigurationDocument(c, format, exported))
+
+			imported, fieldErrors, err := readConfigurationDocument(strings.NewReader(w.Body.String()), format)
+			require.NoError(t, err)
+			require.Empty(t, fieldErrors)
+			require.Len(t, imported.Configurations, 2)
+
+			for i, record := range imported.Configurations {
+				want := exported.Configurations[i]
+				assert.Equal(t, want.Name, record.Name)
+				assert.Equal(t, want.PackSizes, record.PackSizes)
+				assert.Equal(t, want.PackTypes, record.PackTypes)
+				assert.Equal(t, want.IsDefault, record.IsDefault)
+				require.NotNil(t, record.CreatedAt)
+				require.NotNil(t, record.UpdatedAt)
+				assert.True(t, want.CreatedAt.Equal(*record.CreatedAt), "created_at %s", record.CreatedAt)
+				assert.True(t, want.UpdatedAt.Equal(*record.UpdatedAt), "updated_at %s", record.UpdatedAt)
+				assert.Equal(t, want.EffectiveFrom == nil, record.EffectiveFrom == nil)
+				if want.EffectiveFrom != nil {
+					assert.True(t, want.EffectiveFrom.Equal(*record.EffectiveFrom), "effective_from %s", record.EffectiveFrom)
+				}
+				assert.Nil(t, record.EffectiveTo)
+				assert.Equal(t, want.Description, record.Description)
+				assert.Equal(t, want.Labels, record.Labels)
+				wantMetadata, _ := json.Marshal(want.Metadata)
+				gotMetadata, _ := json.Marshal(record.Metadata)
+				assert.JSONEq(t, string(wantMetadata), string(gotMetadata))
+			}
+		})
+	}
+}
+
+func TestReadConfigurationDocumentRejectsUnknownFields(t *testing.T) {
+	tests := []struct {
+		format transferFormat
+		body   string
+	}{
+		{transferFormatJSON, `{"configurations": [{"name": "A", "pack_sizes": [1], "colour": "red"}]}`},
+		{transferFormatYAML, "configurations:\n  - name: A\n    pack_sizes: [1]\n    colour: red\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			_, _, err := readConfigurationDocument(strings.NewReader(tt.body), tt.format)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestReadConfigurationDocumentCSVCellErrors(t *testing.T) {
+	body := "pack_sizes,name,is_default\n250;500,Standard,yes\n250;x,Broken,false\n"
+
+	document, fieldErrors, err := readConfigurationDocument(strings.NewReader(body), transferFormatCSV)
+	require.NoError(t, err)
+	assert.Equal(t, []errs.FieldError{
+		{Field: "configurations[0].is_default", Message: "must be true or false"},
+		{Field: "configurations[1].pack_sizes", Message: "must be integers separated by semicolons"},
+	}, fieldErrors)
+	require.Len(t, document.Configurations, 2)
+	assert.Equal(t, []int{250, 500}, document.Configurations[0].PackSizes)
+
+	_, fieldErrors, err = readConfigurationDocument(strings.NewReader("name,sizes\nA,1\n"), transferFormatCSV)
+	require.NoError(t, err)
+	assert.Equal(t, []errs.FieldError{
+		{Field: "sizes", Message: "is not a known column"},
+		{Field: "pack_sizes", Message: "column is required"},
+	}, fieldErrors)
+}
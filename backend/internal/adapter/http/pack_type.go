@@ -0,0 +1,224 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/Schieck/packs-calculator/internal/dto"
+	packTypeUseCase "github.com/Schieck/packs-calculator/internal/usecase/pack_type"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type PackTypeHandler struct {
+	listPackTypesUseCase   *packTypeUseCase.ListPackTypesUseCase
+	getPackTypeByIDUseCase *packTypeUseCase.GetPackTypeByIDUseCase
+	createPackTypeUseCase  *packTypeUseCase.CreatePackTypeUseCase
+	updatePackTypeUseCase  *packTypeUseCase.UpdatePackTypeUseCase
+	deletePackTypeUseCase  *packTypeUseCase.DeletePackTypeUseCase
+	logger                 *slog.Logger
+	validator              *validator.Validate
+}
+
+func NewPackTypeHandler(
+	listPackTypesUseCase *packTypeUseCase.ListPackTypesUseCase,
+	getPackTypeByIDUseCase *packTypeUseCase.GetPackTypeByIDUseCase,
+	createPackTypeUseCase *packTypeUseCase.CreatePackTypeUseCase,
+	updatePackTypeUseCase *packTypeUseCase.UpdatePackTypeUseCase,
+	deletePackTypeUseCase *packTypeUseCase.DeletePackTypeUseCase,
+	logger *slog.Logger,
+) *PackTypeHandler {
+	return &PackTypeHandler{
+		listPackTypesUseCase:   listPackTypesUseCase,
+		getPackTypeByIDUseCase: getPackTypeByIDUseCase,
+		createPackTypeUseCase:  createPackTypeUseCase,
+		updatePackTypeUseCase:  updatePackTypeUseCase,
+		deletePackTypeUseCase:  deletePackTypeUseCase,
+		logger:                 logger,
+		validator:              newValidator(),
+	}
+}
+
+// ListPackTypes handles GET /pack-types
+// @Summary List Pack Types
+// @Description Retrieve the pack SKUs of the tenant ordered by code. Deleted ones are only listed with include_inactive=true.
+// @Tags pack-types
+// @Accept json
+// @Produce json
+// @Param include_inactive query bool false "Also list deleted pack types"
+// @Success 200 {object} dto.PackTypeListResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Security BearerAuth
+// @Router /pack-types [get]
+func (h PackTypeHandler) ListPackTypes(c *gin.Context) {
+	includeInactive, err := parseBoolQuery(c, "include_inactive")
+	if err != nil {
+		h.logger.Warn("Invalid include_inactive flag", "include_inactive", c.Query("include_inactive"), "error", err)
+		respondInvalidParameter(c, "Invalid include_inactive flag", "include_inactive must be true or false")
+		return
+	}
+
+	packTypes, err := h.listPackTypesUseCase.Execute(c.Request.Context(), requestTenant(c), includeInactive)
+	if err != nil {
+		h.logger.Error("List pack types use case failed", "error", err)
+		respondError(c, err, "Failed to retrieve pack types")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToPackTypeListResponse(packTypes))
+}
+
+// GetPackTypeByID handles GET /pack-types/:id
+// @Summary Get Pack Type by ID
+// @Description Retrieve a specific pack type by its ID, including a deleted one
+// @Tags pack-types
+// @Accept json
+// @Produce json
+// @Param id path int true "Pack type ID"
+// @Success 200 {object} dto.PackTypeResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 404 {object} errs.Problem
+// @Security BearerAuth
+// @Router /pack-types/{id} [get]
+func (h PackTypeHandler) GetPackTypeByID(c *gin.Context) {
+	id, ok := h.packTypeID(c)
+	if !ok {
+		return
+	}
+
+	packType, err := h.getPackTypeByIDUseCase.Execute(c.Request.Context(), requestTenant(c), id)
+	if err != nil {
+		h.logger.Error("Get pack type by ID use case failed", "id", id, "error", err)
+		respondError(c, err, "Failed to retrieve pack type")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToPackTypeResponse(packType))
+}
+
+// CreatePackType handles POST /pack-types
+// @Summary Create Pack Type
+// @Description Create a pack SKU. Codes are unique within the tenant regardless of case, deleted pack types included.
+// @Tags pack-types
+// @Accept json
+// @Produce json
+// @Param request body dto.PackTypeRequest true "Pack type data"
+// @Success 201 {object} dto.PackTypeResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 409 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Security BearerAuth
+// @Router /pack-types [post]
+func (h PackTypeHandler) CreatePackType(c *gin.Context) {
+	var dtoReq dto.PackTypeRequest
+
+	if err := c.ShouldBindJSON(&dtoReq); err != nil {
+		h.logger.Warn("Invalid request body", "error", err)
+		respondInvalidBody(c, err)
+		return
+	}
+
+	if err := h.validator.Struct(&dtoReq); err != nil {
+		h.logger.Warn("Request validation failed", "error", err)
+		respondInvalidBody(c, err)
+		return
+	}
+
+	packType, err := h.createPackTypeUseCase.Execute(c.Request.Context(), requestTenant(c), dto.ToPackType(&dtoReq))
+	if err != nil {
+		h.logger.Error("Create pack type use case failed", "error", err)
+		respondError(c, err, "Failed to create pack type")
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ToPackTypeResponse(packType))
+}
+
+// UpdatePackType handles PUT /pack-types/:id
+// @Summary Update Pack Type
+// @Description Replace a pack type's code, label, item count, dimensions and cost.
+// @Description The item count cannot change while an active pack configuration references the pack type.
+// @Tags pack-types
+// @Accept json
+// @Produce json
+// @Param id path int true "Pack type ID"
+// @Param request body dto.PackTypeRequest true "Pack type data"
+// @Success 200 {object} dto.PackTypeResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 404 {object} errs.Problem
+// @Failure 409 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Security BearerAuth
+// @Router /pack-types/{id} [put]
+func (h PackTypeHandler) UpdatePackType(c *gin.Context) {
+	id, ok := h.packTypeID(c)
+	if !ok {
+		return
+	}
+
+	var dtoReq dto.PackTypeRequest
+
+	if err := c.ShouldBindJSON(&dtoReq); err != nil {
+		h.logger.Warn("Invalid request body", "error", err)
+		respondInvalidBody(c, err)
+		return
+	}
+
+	if err := h.validator.Struct(&dtoReq); err != nil {
+		h.logger.Warn("Request validation failed", "error", err)
+		respondInvalidBody(c, err)
+		return
+	}
+
+	packType, err := h.updatePackTypeUseCase.Execute(c.Request.Context(), requestTenant(c), id, dto.ToPackType(&dtoReq))
+	if err != nil {
+		h.logger.Error("Update pack type use case failed", "id", id, "error", err)
+		respondError(c, err, "Failed to update pack type")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToPackTypeResponse(packType))
+}
+
+// DeletePackType handles DELETE /pack-types/:id
+// @Summary Delete Pack Type
+// @Description Deactivate a pack type (soft delete). Pack types referenced by an active pack configuration cannot be deleted.
+// @Tags pack-types
+// @Accept json
+// @Produce json
+// @Param id path int true "Pack type ID"
+// @Success 204
+// @Failure 400 {object} errs.Problem
+// @Failure 404 {object} errs.Problem
+// @Failure 409 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Security BearerAuth
+// @Router /pack-types/{id} [delete]
+func (h PackTypeHandler) DeletePackType(c *gin.Context) {
+	id, ok := h.packTypeID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.deletePackTypeUseCase.Execute(c.Request.Context(), requestTenant(c), id); err != nil {
+		h.logger.Error("Delete pack type use case failed", "id", id, "error", err)
+		respondError(c, err, "Failed to delete pack type")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// packTypeID reads the id path parameter, reporting the error when it is not an integer
+func (h PackTypeHandler) packTypeID(c *gin.Context) (int, bool) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		h.logger.Warn("Invalid pack type ID", "id", idParam, "error", err)
+		respondInvalidParameter(c, "Invalid pack type ID", "id must be an integer")
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,198 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+	"github.com/Schieck/packs-calculator/pkg/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// newValidator reports fields by their JSON names, so validation errors
+// point at the request body rather than at Go structs
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return v
+}
+
+// respondInvalidBody writes a 400 problem for a request body that failed to
+// decode or validate, listing each invalid field by its JSON path
+func respondInvalidBody(c *gin.Context, err error) {
+	problem := middleware.NewProblem(c, http.StatusBadRequest, "invalid_request_body", "Invalid request body", "")
+
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &validationErrs):
+		fieldErrors := make([]errs.FieldError, len(validationErrs))
+		for i, fieldErr := range validationErrs {
+			fieldErrors[i] = errs.FieldError{
+				Field:   fieldPath(fieldErr),
+				Message: fieldMessage(fieldErr),
+			}
+		}
+		respondInvalidFields(c, fieldErrors)
+		return
+	case errors.As(err, &typeErr):
+		problem.Detail = "A field has the wrong type"
+		problem.Errors = []errs.FieldError{{
+			Field:   decoderFieldPath(typeErr.Field),
+			Message: "must be " + jsonTypeName(typeErr.Type),
+		}}
+	case errors.As(err, &syntaxErr):
+		problem.Detail = fmt.Sprintf("Malformed JSON at offset %d", syntaxErr.Offset)
+	case errors.Is(err, io.EOF):
+		problem.Detail = "The request body is empty"
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		problem.Detail = "The request body is truncated"
+	default:
+		problem.Detail = "The request body is not valid JSON"
+	}
+
+	middleware.AbortWithProblem(c, problem)
+}
+
+// respondInvalidFields writes a 400 validation problem listing fieldErrors
+func respondInvalidFields(c *gin.Context, fieldErrors []errs.FieldError) {
+	problem := middleware.NewProblem(c, http.StatusBadRequest, "validation_failed", "Validation failed",
+		fmt.Sprintf("The request body has %d invalid field(s)", len(fieldErrors)))
+	problem.Errors = fieldErrors
+	middleware.AbortWithProblem(c, problem)
+}
+
+// respondInvalidParameter writes a 400 problem for a malformed path, query
+// or header parameter; detail explains what was expected
+func respondInvalidParameter(c *gin.Context, title, detail string) {
+	middleware.AbortWithProblem(c, middleware.NewProblem(c, http.StatusBadRequest, "invalid_parameter", title, detail))
+}
+
+// fieldPath drops the root struct from the validator namespace, leaving
+// the JSON path, e.g. pack_sizes[2]
+func fieldPath(fieldErr validator.FieldError) string {
+	_, path, found := strings.Cut(fieldErr.Namespace(), ".")
+	if !found {
+		return fieldErr.Field()
+	}
+	return path
+}
+
+// decoderFieldPath writes the array indexes of a JSON decoder path, such as
+// pack_sizes.1, the way the validator does: pack_sizes[1]
+func decoderFieldPath(path string) string {
+	segments := strings.Split(path, ".")
+	var b strings.Builder
+	for i, segment := range segments {
+		if _, err := strconv.Atoi(segment); err == nil && i > 0 {
+			b.WriteString("[" + segment + "]")
+			continue
+		}
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(segment)
+	}
+	return b.String()
+}
+
+func fieldMessage(fieldErr validator.FieldError) string {
+	param := fieldErr.Param()
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "required_without_all":
+		return "is required unless one of " + jsonFieldNames(param) + " is given"
+	case "excluded_without":
+		return "is only allowed together with " + jsonFieldNames(param)
+	case "min":
+		switch fieldErr.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map:
+			return "must contain at least " + param + " item(s)"
+		case reflect.String:
+			return "must be at least " + param + " character(s) long"
+		default:
+			return "must be at least " + param
+		}
+	case "max":
+		switch fieldErr.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map:
+			return "must contain at most " + param + " item(s)"
+		case reflect.String:
+			return "must be at most " + param + " character(s) long"
+		default:
+			return "must be at most " + param
+		}
+	default:
+		if param != "" {
+			return fmt.Sprintf("failed the %s=%s rule", fieldErr.Tag(), param)
+		}
+		return fmt.Sprintf("failed the %s rule", fieldErr.Tag())
+	}
+}
+
+// jsonFieldNames turns a validator parameter naming Go fields, such as
+// "Location ConfigurationID", into "location, configuration_id"
+func jsonFieldNames(param string) string {
+	fields := strings.Fields(param)
+	for i, field := range fields {
+		fields[i] = snakeCase(field)
+	}
+	return strings.Join(fields, ", ")
+}
+
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prevLower := unicode.IsLower(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (nextLower && unicode.IsUpper(runes[i-1])) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+func jsonTypeName(t reflect.Type) string {
+	if t == nil {
+		return "a valid value"
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	case reflect.Pointer:
+		return jsonTypeName(t.Elem())
+	default:
+		return "a valid value"
+	}
+}
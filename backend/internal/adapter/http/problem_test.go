@@ -0,0 +1,103 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+	"github.com/Schieck/packs-calculator/internal/dto"
+	"github.com/Schieck/packs-calculator/pkg/middleware"
+)
+
+func bindCalculationRequest(body string) (*httptest.ResponseRecorder, errs.Problem) {
+	gin.SetMode(gin.TestMode)
+	validate := newValidator()
+
+	router := gin.New()
+	router.Use(middleware.RequestID())
+	router.POST("/calculate", func(c *gin.Context) {
+		var req dto.CalculationRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondInvalidBody(c, err)
+			return
+		}
+		if err := validate.Struct(&req); err != nil {
+			respondInvalidBody(c, err)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/calculate", strings.NewReader(body)))
+
+	var problem errs.Problem
+	_ = json.Unmarshal(w.Body.Bytes(), &problem)
+	return w, problem
+}
+
+func TestRespondInvalidBodyValidation(t *testing.T) {
+	w, problem := bindCalculationRequest(`{"items": 10, "pack_sizes": [250, 500, 0], "configuration_version": 2}`)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, middleware.ProblemContentType, w.Header().Get("Content-Type"))
+	assert.Equal(t, "/problems/validation_failed", problem.Type)
+	assert.Equal(t, "Validation failed", problem.Title)
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	assert.Equal(t, "/calculate", problem.Instance)
+	assert.NotEmpty(t, problem.RequestID)
+	assert.ElementsMatch(t, []errs.FieldError{
+		{Field: "pack_sizes[2]", Message: "must be at least 1"},
+		{Field: "configuration_version", Message: "is only allowed together with configuration_id"},
+	}, problem.Errors)
+	assert.NotContains(t, w.Body.String(), "CalculationRequest")
+}
+
+func TestRespondInvalidBodyRequiredAlternatives(t *testing.T) {
+	_, problem := bindCalculationRequest(`{"items": 10}`)
+
+	assert.Equal(t, []errs.FieldError{
+		{Field: "pack_sizes", Message: "is required unless one of location, configuration_id is given"},
+	}, problem.Errors)
+}
+
+func TestRespondInvalidBodyDecoding(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		detail string
+		errors []errs.FieldError
+	}{
+		{"wrong type", `{"items": "ten", "pack_sizes": [250]}`, "A field has the wrong type",
+			[]errs.FieldError{{Field: "items", Message: "must be an integer"}}},
+		{"wrong element type", `{"items": 10, "pack_sizes": [250, "500"]}`, "A field has the wrong type",
+			[]errs.FieldError{{Field: "pack_sizes[1]", Message: "must be an integer"}}},
+		{"malformed", `{"items": 10,}`, "Malformed JSON at offset 14", nil},
+		{"empty", ``, "The request body is empty", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, problem := bindCalculationRequest(tt.body)
+
+			require.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Equal(t, "invalid_request_body", problem.Code)
+			assert.Equal(t, tt.detail, problem.Detail)
+			assert.Equal(t, tt.errors, problem.Errors)
+		})
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	assert.Equal(t, "location", snakeCase("Location"))
+	assert.Equal(t, "configuration_id", snakeCase("ConfigurationID"))
+	assert.Equal(t, "pack_sizes", snakeCase("PackSizes"))
+	assert.Equal(t, "http_status_code", snakeCase("HTTPStatusCode"))
+}
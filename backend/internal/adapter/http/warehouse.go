@@ -0,0 +1,201 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/Schieck/packs-calculator/internal/dto"
+	warehouseUseCase "github.com/Schieck/packs-calculator/internal/usecase/warehouse"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type WarehouseHandler struct {
+	getAllWarehousesUseCase *warehouseUseCase.GetAllWarehousesUseCase
+	getWarehouseByIDUseCase *warehouseUseCase.GetWarehouseByIDUseCase
+	createWarehouseUseCase  *warehouseUseCase.CreateWarehouseUseCase
+	deleteWarehouseUseCase  *warehouseUseCase.DeleteWarehouseUseCase
+	planSourcingUseCase     *warehouseUseCase.PlanSourcingUseCase
+	logger                  *slog.Logger
+	validator               *validator.Validate
+}
+
+func NewWarehouseHandler(
+	getAllWarehousesUseCase *warehouseUseCase.GetAllWarehousesUseCase,
+	getWarehouseByIDUseCase *warehouseUseCase.GetWarehouseByIDUseCase,
+	createWarehouseUseCase *warehouseUseCase.CreateWarehouseUseCase,
+	deleteWarehouseUseCase *warehouseUseCase.DeleteWarehouseUseCase,
+	planSourcingUseCase *warehouseUseCase.PlanSourcingUseCase,
+	logger *slog.Logger,
+) *WarehouseHandler {
+	return &WarehouseHandler{
+		getAllWarehousesUseCase: getAllWarehousesUseCase,
+		getWarehouseByIDUseCase: getWarehouseByIDUseCase,
+		createWarehouseUseCase:  createWarehouseUseCase,
+		deleteWarehouseUseCase:  deleteWarehouseUseCase,
+		planSourcingUseCase:     planSourcingUseCase,
+		logger:                  logger,
+		validator:               newValidator(),
+	}
+}
+
+// GetAllWarehouses handles GET /warehouses
+// @Summary Get All Warehouses
+// @Description Retrieve all active warehouses
+// @Tags warehouses
+// @Accept json
+// @Produce json
+// @Success 200 {object} dto.WarehouseListResponse
+// @Failure 500 {object} errs.Problem
+// @Security BearerAuth
+// @Router /warehouses [get]
+func (h WarehouseHandler) GetAllWarehouses(c *gin.Context) {
+	warehouses, err := h.getAllWarehousesUseCase.Execute(c.Request.Context(), requestTenant(c))
+	if err != nil {
+		h.logger.Error("Get all warehouses use case failed", "error", err)
+		respondError(c, err, "Failed to retrieve warehouses")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToWarehouseListResponse(warehouses))
+}
+
+// GetWarehouseByID handles GET /warehouses/:id
+// @Summary Get Warehouse by ID
+// @Description Retrieve a specific warehouse by its ID
+// @Tags warehouses
+// @Accept json
+// @Produce json
+// @Param id path int true "Warehouse ID"
+// @Success 200 {object} dto.WarehouseResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 404 {object} errs.Problem
+// @Security BearerAuth
+// @Router /warehouses/{id} [get]
+func (h WarehouseHandler) GetWarehouseByID(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		h.logger.Warn("Invalid warehouse ID", "id", idParam, "error", err)
+		respondInvalidParameter(c, "Invalid warehouse ID", "id must be an integer")
+		return
+	}
+
+	warehouse, err := h.getWarehouseByIDUseCase.Execute(c.Request.Context(), requestTenant(c), id)
+	if err != nil {
+		h.logger.Error("Get warehouse by ID use case failed", "id", id, "error", err)
+		respondError(c, err, "Failed to retrieve warehouse")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToWarehouseResponse(warehouse))
+}
+
+// CreateWarehouse handles POST /warehouses
+// @Summary Create Warehouse
+// @Description Create a warehouse bound to a stock location and a pack configuration
+// @Tags warehouses
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateWarehouseRequest true "Warehouse data"
+// @Success 201 {object} dto.WarehouseResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 409 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Security BearerAuth
+// @Router /warehouses [post]
+func (h WarehouseHandler) CreateWarehouse(c *gin.Context) {
+	var dtoReq dto.CreateWarehouseRequest
+
+	if err := c.ShouldBindJSON(&dtoReq); err != nil {
+		h.logger.Warn("Invalid request body", "error", err)
+		respondInvalidBody(c, err)
+		return
+	}
+
+	if err := h.validator.Struct(&dtoReq); err != nil {
+		h.logger.Warn("Request validation failed", "error", err)
+		respondInvalidBody(c, err)
+		return
+	}
+
+	warehouse, err := h.createWarehouseUseCase.Execute(c.Request.Context(), requestTenant(c), dtoReq.Name, dtoReq.Location, dtoReq.PackConfigurationID)
+	if err != nil {
+		h.logger.Error("Create warehouse use case failed", "error", err)
+		respondError(c, err, "Failed to create warehouse")
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ToWarehouseResponse(warehouse))
+}
+
+// DeleteWarehouse handles DELETE /warehouses/:id
+// @Summary Delete Warehouse
+// @Description Delete a warehouse (soft delete)
+// @Tags warehouses
+// @Accept json
+// @Produce json
+// @Param id path int true "Warehouse ID"
+// @Success 204
+// @Failure 400 {object} errs.Problem
+// @Failure 404 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Security BearerAuth
+// @Router /warehouses/{id} [delete]
+func (h WarehouseHandler) DeleteWarehouse(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		h.logger.Warn("Invalid warehouse ID", "id", idParam, "error", err)
+		respondInvalidParameter(c, "Invalid warehouse ID", "id must be an integer")
+		return
+	}
+
+	if err := h.deleteWarehouseUseCase.Execute(c.Request.Context(), requestTenant(c), id); err != nil {
+		h.logger.Error("Delete warehouse use case failed", "id", id, "error", err)
+		respondError(c, err, "Failed to delete warehouse")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Calculate handles POST /warehouses/calculate
+// @Summary Calculate Warehouse Sourcing
+// @Description Decide which warehouse fulfils an order, splitting it across warehouses when that lowers surplus and packs by more than the split penalty
+// @Tags warehouses
+// @Accept json
+// @Produce json
+// @Param request body dto.SourcingRequest true "Sourcing parameters"
+// @Success 200 {object} dto.SourcingResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 404 {object} errs.Problem
+// @Failure 422 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Security BearerAuth
+// @Router /warehouses/calculate [post]
+func (h WarehouseHandler) Calculate(c *gin.Context) {
+	var dtoReq dto.SourcingRequest
+
+	if err := c.ShouldBindJSON(&dtoReq); err != nil {
+		h.logger.Warn("Invalid request body", "error", err)
+		respondInvalidBody(c, err)
+		return
+	}
+
+	if err := h.validator.Struct(&dtoReq); err != nil {
+		h.logger.Warn("Request validation failed", "error", err)
+		respondInvalidBody(c, err)
+		return
+	}
+
+	plan, warehouses, err := h.planSourcingUseCase.Execute(c.Request.Context(), requestTenant(c), dtoReq.Items, dtoReq.WarehouseIDs, dtoReq.SplitPenalty)
+	if err != nil {
+		h.logger.Error("Plan sourcing use case failed", "error", err)
+		respondError(c, err, "Sourcing calculation failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToSourcingResponse(plan, warehouses))
+}
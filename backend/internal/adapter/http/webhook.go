@@ -0,0 +1,225 @@
+package http
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/Schieck/packs-calculator/internal/dto"
+	webhookService "github.com/Schieck/packs-calculator/internal/service/webhook"
+	webhookUseCase "github.com/Schieck/packs-calculator/internal/usecase/webhook"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type WebhookHandler struct {
+	listWebhooksUseCase    *webhookUseCase.ListWebhooksUseCase
+	createWebhookUseCase   *webhookUseCase.CreateWebhookUseCase
+	deleteWebhookUseCase   *webhookUseCase.DeleteWebhookUseCase
+	listDeadLettersUseCase *webhookUseCase.ListDeadLettersUseCase
+	redeliverUseCase       *webhookUseCase.RedeliverUseCase
+	logger                 *slog.Logger
+	validator              *validator.Validate
+}
+
+func NewWebhookHandler(
+	listWebhooksUseCase *webhookUseCase.ListWebhooksUseCase,
+	createWebhookUseCase *webhookUseCase.CreateWebhookUseCase,
+	deleteWebhookUseCase *webhookUseCase.DeleteWebhookUseCase,
+	listDeadLettersUseCase *webhookUseCase.ListDeadLettersUseCase,
+	redeliverUseCase *webhookUseCase.RedeliverUseCase,
+	logger *slog.Logger,
+) *WebhookHandler {
+	return &WebhookHandler{
+		listWebhooksUseCase:    listWebhooksUseCase,
+		createWebhookUseCase:   createWebhookUseCase,
+		deleteWebhookUseCase:   deleteWebhookUseCase,
+		listDeadLettersUseCase: listDeadLettersUseCase,
+		redeliverUseCase:       redeliverUseCase,
+		logger:                 logger,
+		validator:              newValidator(),
+	}
+}
+
+// ListWebhooks handles GET /webhooks
+// @Summary List Webhooks
+// @Description Retrieve the webhooks registered for the tenant. Secrets are never shown.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Success 200 {object} dto.WebhookListResponse
+// @Failure 403 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Failure 504 {object} errs.Problem
+// @Security BearerAuth
+// @Router /webhooks [get]
+func (h WebhookHandler) ListWebhooks(c *gin.Context) {
+	webhooks, err := h.listWebhooksUseCase.Execute(c.Request.Context(), requestTenant(c))
+	if err != nil {
+		h.logger.Error("List webhooks use case failed", "error", err)
+		respondError(c, err, "Failed to retrieve webhooks")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToWebhookListResponse(webhooks))
+}
+
+// CreateWebhook handles POST /webhooks
+// @Summary Register Webhook
+// @Description Register a URL to receive the tenant's pack configuration change events, all of them unless event_types is given. Deliveries are signed with the secret, which is generated when omitted and shown only in this response.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateWebhookRequest true "Webhook data"
+// @Success 201 {object} dto.CreatedWebhookResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 403 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Failure 504 {object} errs.Problem
+// @Security BearerAuth
+// @Router /webhooks [post]
+func (h WebhookHandler) CreateWebhook(c *gin.Context) {
+	var dtoReq dto.CreateWebhookRequest
+
+	if err := c.ShouldBindJSON(&dtoReq); err != nil {
+		h.logger.Warn("Invalid request body", "error", err)
+		respondInvalidBody(c, err)
+		return
+	}
+
+	if err := h.validator.Struct(&dtoReq); err != nil {
+		h.logger.Warn("Request validation failed", "error", err)
+		respondInvalidBody(c, err)
+		return
+	}
+
+	webhook, err := h.createWebhookUseCase.Execute(c.Request.Context(), requestTenant(c), dtoReq.URL, dtoReq.Secret, dto.ToEventTypes(dtoReq.EventTypes))
+	if err != nil {
+		h.logger.Error("Create webhook use case failed", "error", err)
+		respondError(c, err, "Failed to create webhook")
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ToCreatedWebhookResponse(webhook))
+}
+
+// DeleteWebhook handles DELETE /webhooks/:id
+// @Summary Delete Webhook
+// @Description Stop delivering events to a webhook and drop its deliveries, dead letters included
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Success 204
+// @Failure 400 {object} errs.Problem
+// @Failure 403 {object} errs.Problem
+// @Failure 404 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Failure 504 {object} errs.Problem
+// @Security BearerAuth
+// @Router /webhooks/{id} [delete]
+func (h WebhookHandler) DeleteWebhook(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		h.logger.Warn("Invalid webhook ID", "id", idParam, "error", err)
+		respondInvalidParameter(c, "Invalid webhook ID", "id must be an integer")
+		return
+	}
+
+	if err := h.deleteWebhookUseCase.Execute(c.Request.Context(), requestTenant(c), id); err != nil {
+		h.logger.Error("Delete webhook use case failed", "id", id, "error", err)
+		respondError(c, err, "Failed to delete webhook")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeadLetters handles GET /webhooks/dead-letters
+// @Summary List Dead Letters
+// @Description Retrieve the deliveries that ran out of attempts, newest first
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param webhook_id query int false "Filter by webhook ID"
+// @Param limit query int false "Page size (default 50, max 500)"
+// @Param offset query int false "Number of deliveries to skip"
+// @Success 200 {object} dto.WebhookDeadLetterListResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 403 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Failure 504 {object} errs.Problem
+// @Security BearerAuth
+// @Router /webhooks/dead-letters [get]
+func (h WebhookHandler) ListDeadLetters(c *gin.Context) {
+	webhookID, limit, offset, err := parseDeadLetterQuery(c)
+	if err != nil {
+		h.logger.Warn("Invalid dead letter query", "error", err)
+		respondInvalidParameter(c, "Invalid query parameters", err.Error())
+		return
+	}
+
+	limit, offset = webhookService.NormalizePage(limit, offset)
+
+	deliveries, total, err := h.listDeadLettersUseCase.Execute(c.Request.Context(), requestTenant(c), webhookID, limit, offset)
+	if err != nil {
+		h.logger.Error("List dead letters use case failed", "error", err)
+		respondError(c, err, "Failed to retrieve dead letters")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToWebhookDeadLetterListResponse(deliveries, total, limit, offset))
+}
+
+// Redeliver handles POST /webhooks/dead-letters/:id/redeliver
+// @Summary Redeliver Dead Letter
+// @Description Queue a dead letter for delivery again with a fresh set of attempts
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path int true "Delivery ID"
+// @Success 202 {object} dto.WebhookDeliveryResponse
+// @Failure 400 {object} errs.Problem
+// @Failure 403 {object} errs.Problem
+// @Failure 404 {object} errs.Problem
+// @Failure 409 {object} errs.Problem
+// @Failure 500 {object} errs.Problem
+// @Failure 504 {object} errs.Problem
+// @Security BearerAuth
+// @Router /webhooks/dead-letters/{id}/redeliver [post]
+func (h WebhookHandler) Redeliver(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil {
+		h.logger.Warn("Invalid webhook delivery ID", "id", idParam, "error", err)
+		respondInvalidParameter(c, "Invalid webhook delivery ID", "id must be an integer")
+		return
+	}
+
+	delivery, err := h.redeliverUseCase.Execute(c.Request.Context(), requestTenant(c), id)
+	if err != nil {
+		h.logger.Error("Redeliver use case failed", "id", id, "error", err)
+		respondError(c, err, "Failed to redeliver webhook delivery")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, dto.ToWebhookDeliveryResponse(delivery))
+}
+
+func parseDeadLetterQuery(c *gin.Context) (webhookID int, limit int, offset int, err error) {
+	if value := c.Query("webhook_id"); value != "" {
+		webhookID, err = strconv.Atoi(value)
+		if err != nil || webhookID <= 0 {
+			return 0, 0, 0, fmt.Errorf("webhook_id must be a positive integer")
+		}
+	}
+	if limit, err = parseNonNegativeQuery(c, "limit"); err != nil {
+		return 0, 0, 0, err
+	}
+	if offset, err = parseNonNegativeQuery(c, "offset"); err != nil {
+		return 0, 0, 0, err
+	}
+	return webhookID, limit, offset, nil
+}
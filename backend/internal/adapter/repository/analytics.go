@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+)
+
+// AnalyticsRepository aggregates the calculations table in SQL so that only
+// the summarised series leave the database
+type AnalyticsRepository struct {
+	db *sql.DB
+}
+
+func NewAnalyticsRepository(db *sql.DB) *AnalyticsRepository {
+	return &AnalyticsRepository{
+		db: db,
+	}
+}
+
+func (r *AnalyticsRepository) SurplusByBucket(filter entity.AnalyticsFilter) ([]*entity.SurplusPoint, error) {
+	where, args := analyticsFilterClause(filter)
+	query := fmt.Sprintf(`
+		SELECT date_trunc($%d, created_at) AS bucket,
+			COUNT(*), COALESCE(SUM(total_items), 0), COALESCE(SUM(surplus), 0)
+		FROM calculations%s
+		GROUP BY 1
+		ORDER BY 1
+	`, len(args)+1, where)
+
+	rows, err := r.db.Query(query, append(args, string(filter.Bucket))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate surplus: %w", err)
+	}
+	defer rows.Close()
+
+	var points []*entity.SurplusPoint
+	for rows.Next() {
+		point := &entity.SurplusPoint{}
+		if err := rows.Scan(&point.Bucket, &point.Calculations, &point.TotalItems, &point.TotalSurplus); err != nil {
+			return nil, fmt.Errorf("failed to scan surplus point: %w", err)
+		}
+		points = append(points, point)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return points, nil
+}
+
+func (r *AnalyticsRepository) ExactMatchRates(filter entity.AnalyticsFilter) ([]*entity.ExactMatchRate, error) {
+	where, args := analyticsFilterClause(filter)
+	query := fmt.Sprintf(`
+		SELECT date_trunc($%d, created_at) AS bucket, configuration_id,
+			COUNT(*), COUNT(*) FILTER (WHERE surplus = 0)
+		FROM calculations%s
+		GROUP BY 1, 2
+		ORDER BY 1, 2 NULLS FIRST
+	`, len(args)+1, where)
+
+	rows, err := r.db.Query(query, append(args, string(filter.Bucket))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate exact match rates: %w", err)
+	}
+	defer rows.Close()
+
+	var rates []*entity.ExactMatchRate
+	for rows.Next() {
+		rate := &entity.ExactMatchRate{}
+		var configurationID sql.NullInt64
+		if err := rows.Scan(&rate.Bucket, &configurationID, &rate.Calculations, &rate.ExactMatches); err != nil {
+			return nil, fmt.Errorf("failed to scan exact match rate: %w", err)
+		}
+		if configurationID.Valid {
+			id := int(configurationID.Int64)
+			rate.ConfigurationID = &id
+		}
+		rates = append(rates, rate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return rates, nil
+}
+
+func (r *AnalyticsRepository) PackSizeUsage(filter entity.AnalyticsFilter) ([]*entity.PackSizeUsage, error) {
+	where, args := analyticsFilterClause(filter)
+	query := fmt.Sprintf(`
+		SELECT (a.key)::int AS pack_size, SUM((a.value)::bigint) AS packs, COUNT(*)
+		FROM calculations, jsonb_each_text(allocation) AS a%s
+		GROUP BY 1
+		ORDER BY 2 DESC, 1
+		LIMIT $%d
+	`, where, len(args)+1)
+
+	rows, err := r.db.Query(query, append(args, filter.Limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate pack size usage: %w", err)
+	}
+	defer rows.Close()
+
+	var usage []*entity.PackSizeUsage
+	for rows.Next() {
+		u := &entity.PackSizeUsage{}
+		if err := rows.Scan(&u.PackSize, &u.Packs, &u.Calculations); err != nil {
+			return nil, fmt.Errorf("failed to scan pack size usage: %w", err)
+		}
+		usage = append(usage, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return usage, nil
+}
+
+func (r *AnalyticsRepository) PacksPerOrder(filter entity.AnalyticsFilter) ([]*entity.PacksPerOrderPoint, error) {
+	where, args := analyticsFilterClause(filter)
+	query := fmt.Sprintf(`
+		SELECT date_trunc($%d, created_at) AS bucket, COUNT(*),
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY total_packs),
+			percentile_cont(0.95) WITHIN GROUP (ORDER BY total_packs)
+		FROM calculations%s
+		GROUP BY 1
+		ORDER BY 1
+	`, len(args)+1, where)
+
+	rows, err := r.db.Query(query, append(args, string(filter.Bucket))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate packs per order: %w", err)
+	}
+	defer rows.Close()
+
+	var points []*entity.PacksPerOrderPoint
+	for rows.Next() {
+		point := &entity.PacksPerOrderPoint{}
+		if err := rows.Scan(&point.Bucket, &point.Calculations, &point.P50, &point.P95); err != nil {
+			return nil, fmt.Errorf("failed to scan packs per order point: %w", err)
+		}
+		points = append(points, point)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return points, nil
+}
+
+// analyticsFilterClause reuses the history filter so both read paths agree on
+// what "in range" means
+func analyticsFilterClause(filter entity.AnalyticsFilter) (string, []interface{}) {
+	from, to := filter.From, filter.To
+	return calculationFilterClause(entity.CalculationFilter{
+		Tenant:          filter.Tenant,
+		ConfigurationID: filter.ConfigurationID,
+		From:            &from,
+		To:              &to,
+	})
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+)
+
+type AuditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{
+		db: db,
+	}
+}
+
+func (r *AuditRepository) Append(entry *entity.AuditEntry) (*entity.AuditEntry, error) {
+	if err := insertAuditEntry(context.Background(), r.db, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// insertAuditEntry appends entry through q, so a change can audit itself in
+// its own transaction
+func insertAuditEntry(ctx context.Context, q contextQueryer, entry *entity.AuditEntry) error {
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO audit_log (tenant_id, entity_type, entity_id, action, actor, request_id, before, after)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8)
+		RETURNING id, created_at
+	`
+
+	err := q.QueryRowContext(ctx, query,
+		entry.Tenant,
+		entry.EntityType,
+		entry.EntityID,
+		string(entry.Action),
+		entry.Actor,
+		entry.RequestID,
+		nullableJSON(entry.Before),
+		nullableJSON(entry.After),
+	).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to append audit entry: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AuditRepository) List(filter entity.AuditFilter) ([]*entity.AuditEntry, int, error) {
+	where, args := auditFilterClause(filter)
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM audit_log`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit entries: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, tenant_id, entity_type, entity_id, action, actor, request_id, before, after, created_at
+		FROM audit_log%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)+1, len(args)+2)
+
+	rows, err := r.db.Query(query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]*entity.AuditEntry, 0, filter.Limit)
+	for rows.Next() {
+		entry := &entity.AuditEntry{}
+		var (
+			action    string
+			requestID sql.NullString
+			before    []byte
+			after     []byte
+		)
+		err := rows.Scan(
+			&entry.ID,
+			&entry.Tenant,
+			&entry.EntityType,
+			&entry.EntityID,
+			&action,
+			&entry.Actor,
+			&requestID,
+			&before,
+			&after,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		entry.Action = entity.AuditAction(action)
+		entry.RequestID = requestID.String
+		entry.Before = before
+		entry.After = after
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return entries, total, nil
+}
+
+// nullableJSON stores an absent snapshot as SQL NULL rather than an empty string
+func nullableJSON(data []byte) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
+
+func auditFilterClause(filter entity.AuditFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Tenant != "" {
+		add("tenant_id = $%d", filter.Tenant)
+	}
+	if filter.EntityType != "" {
+		add("entity_type = $%d", filter.EntityType)
+	}
+	if filter.EntityID != nil {
+		add("entity_id = $%d", *filter.EntityID)
+	}
+	if filter.Actor != "" {
+		add("actor = $%d", filter.Actor)
+	}
+	if filter.From != nil {
+		add("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		add("created_at < $%d", *filter.To)
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
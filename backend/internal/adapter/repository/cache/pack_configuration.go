@@ -0,0 +1,267 @@
+// Package cache keeps recently read pack configurations in process, in front
+// of the repository that stores them.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+)
+
+// key names a cached configuration: the one with id, or the tenant's default
+// when id is zero
+type key struct {
+	tenant string
+	id     int
+}
+
+type entry struct {
+	key     key
+	config  *entity.PackConfiguration
+	expires time.Time
+}
+
+// PackConfigurationRepository answers GetByID and GetDefault from memory for
+// up to ttl, keeping at most maxEntries configurations and dropping the least
+// recently used first. Every other read goes straight to the wrapped
+// repository. Writes through the cache drop the tenant's entries once they
+// return, whether or not they succeeded; writes made elsewhere must be
+// reported through Invalidate, and ttl bounds how long a missed one is seen.
+type PackConfigurationRepository struct {
+	next       entity.PackConfigurationRepository
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu sync.Mutex
+	// lru holds the entries, most recently used first
+	lru     *list.List
+	entries map[key]*list.Element
+	// generations count the invalidations of each tenant and epoch those of
+	// the whole cache, so that a read racing a write never stores what it
+	// read before the write
+	generations map[string]uint64
+	epoch       uint64
+	stats       entity.CacheStats
+}
+
+// NewPackConfigurationRepository caches next's configurations for ttl. A
+// maxEntries of zero or less leaves the cache unbounded.
+func NewPackConfigurationRepository(next entity.PackConfigurationRepository, ttl time.Duration, maxEntries int) *PackConfigurationRepository {
+	return &PackConfigurationRepository{
+		next:        next,
+		ttl:         ttl,
+		maxEntries:  maxEntries,
+		now:         time.Now,
+		lru:         list.New(),
+		entries:     make(map[key]*list.Element),
+		generations: make(map[string]uint64),
+	}
+}
+
+// Stats reports the cache's counters and current size
+func (r *PackConfigurationRepository) Stats() entity.CacheStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := r.stats
+	stats.Entries = r.lru.Len()
+	stats.MaxEntries = r.maxEntries
+	stats.TTL = r.ttl
+	return stats
+}
+
+// Invalidate drops the tenant's configurations, and keeps reads already in
+// flight from storing theirs
+func (r *PackConfigurationRepository) Invalidate(tenant string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.generations[tenant]++
+	for k, element := range r.entries {
+		if k.tenant == tenant {
+			r.remove(element)
+			r.stats.Invalidations++
+		}
+	}
+}
+
+// InvalidateAll drops every configuration, for when writes may have gone
+// unreported
+func (r *PackConfigurationRepository) InvalidateAll() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.epoch++
+	r.stats.Invalidations += uint64(r.lru.Len())
+	r.lru.Init()
+	clear(r.entries)
+}
+
+func (r *PackConfigurationRepository) generation(tenant string) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Both counters only grow, so their sum changes whenever either does
+	return r.epoch + r.generations[tenant]
+}
+
+// lookup returns a copy of the cached configuration under k when it has not
+// expired and satisfies usable
+func (r *PackConfigurationRepository) lookup(k key, usable func(*entity.PackConfiguration) bool) (*entity.PackConfiguration, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	element, ok := r.entries[k]
+	if !ok {
+		r.stats.Misses++
+		return nil, false
+	}
+	cached := element.Value.(*entry)
+	if !r.now().Before(cached.expires) {
+		r.remove(element)
+		r.stats.Expirations++
+		r.stats.Misses++
+		return nil, false
+	}
+	if !usable(cached.config) {
+		r.stats.Misses++
+		return nil, false
+	}
+
+	r.lru.MoveToFront(element)
+	r.stats.Hits++
+	return cached.config.Clone(), true
+}
+
+// store caches a copy of config under k, unless the tenant was invalidated
+// since generation was taken
+func (r *PackConfigurationRepository) store(k key, config *entity.PackConfiguration, generation uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.epoch+r.generations[k.tenant] != generation {
+		return
+	}
+
+	cached := &entry{key: k, config: config.Clone(), expires: r.now().Add(r.ttl)}
+	if element, ok := r.entries[k]; ok {
+		element.Value = cached
+		r.lru.MoveToFront(element)
+		return
+	}
+	r.entries[k] = r.lru.PushFront(cached)
+
+	if r.maxEntries > 0 && r.lru.Len() > r.maxEntries {
+		r.remove(r.lru.Back())
+		r.stats.Evictions++
+	}
+}
+
+func (r *PackConfigurationRepository) remove(element *list.Element) {
+	r.lru.Remove(element)
+	delete(r.entries, element.Value.(*entry).key)
+}
+
+func anyConfiguration(*entity.PackConfiguration) bool {
+	return true
+}
+
+func (r *PackConfigurationRepository) GetByID(ctx context.Context, tenant string, id int) (*entity.PackConfiguration, error) {
+	k := key{tenant: tenant, id: id}
+	if config, ok := r.lookup(k, anyConfiguration); ok {
+		return config, nil
+	}
+
+	generation := r.generation(tenant)
+	config, err := r.next.GetByID(ctx, tenant, id)
+	if err != nil {
+		return nil, err
+	}
+	r.store(k, config, generation)
+	return config, nil
+}
+
+// GetDefault keeps the last default read for each tenant. It answers for any
+// moment within that default's period: defaults never overlap, so no other
+// one can be in effect then.
+func (r *PackConfigurationRepository) GetDefault(ctx context.Context, tenant string, asOf time.Time) (*entity.PackConfiguration, error) {
+	k := key{tenant: tenant}
+	inEffect := func(config *entity.PackConfiguration) bool {
+		return config.EffectiveAt(asOf)
+	}
+	if config, ok := r.lookup(k, inEffect); ok {
+		return config, nil
+	}
+
+	generation := r.generation(tenant)
+	config, err := r.next.GetDefault(ctx, tenant, asOf)
+	if err != nil {
+		return nil, err
+	}
+	r.store(k, config, generation)
+	return config, nil
+}
+
+func (r *PackConfigurationRepository) List(ctx context.Context, query entity.PackConfigurationQuery) ([]*entity.PackConfiguration, int, error) {
+	return r.next.List(ctx, query)
+}
+
+func (r *PackConfigurationRepository) FindByPackSizes(ctx context.Context, tenant string, packSizes []int) ([]*entity.PackConfiguration, error) {
+	return r.next.FindByPackSizes(ctx, tenant, packSizes)
+}
+
+func (r *PackConfigurationRepository) GetVersions(ctx context.Context, tenant string, id int) ([]*entity.PackConfigurationVersion, error) {
+	return r.next.GetVersions(ctx, tenant, id)
+}
+
+func (r *PackConfigurationRepository) GetVersion(ctx context.Context, tenant string, id int, version int) (*entity.PackConfigurationVersion, error) {
+	return r.next.GetVersion(ctx, tenant, id, version)
+}
+
+func (r *PackConfigurationRepository) GetArchivedByID(ctx context.Context, tenant string, id int) (*entity.PackConfiguration, error) {
+	return r.next.GetArchivedByID(ctx, tenant, id)
+}
+
+func (r *PackConfigurationRepository) Create(ctx context.Context, config *entity.PackConfiguration) (*entity.PackConfiguration, error) {
+	defer r.Invalidate(config.Tenant)
+	return r.next.Create(ctx, config)
+}
+
+func (r *PackConfigurationRepository) Update(ctx context.Context, config *entity.PackConfiguration) (*entity.PackConfiguration, error) {
+	defer r.Invalidate(config.Tenant)
+	return r.next.Update(ctx, config)
+}
+
+func (r *PackConfigurationRepository) Delete(ctx context.Context, tenant string, id int, version int) error {
+	defer r.Invalidate(tenant)
+	return r.next.Delete(ctx, tenant, id, version)
+}
+
+func (r *PackConfigurationRepository) SetDefault(ctx context.Context, tenant string, id int, version int) error {
+	defer r.Invalidate(tenant)
+	return r.next.SetDefault(ctx, tenant, id, version)
+}
+
+func (r *PackConfigurationRepository) RestoreVersion(ctx context.Context, tenant string, id int, version int) (*entity.PackConfiguration, error) {
+	defer r.Invalidate(tenant)
+	return r.next.RestoreVersion(ctx, tenant, id, version)
+}
+
+func (r *PackConfigurationRepository) Restore(ctx context.Context, tenant string, id int, name string) (*entity.PackConfiguration, error) {
+	defer r.Invalidate(tenant)
+	return r.next.Restore(ctx, tenant, id, name)
+}
+
+func (r *PackConfigurationRepository) Purge(ctx context.Context, tenant string, id int) error {
+	defer r.Invalidate(tenant)
+	return r.next.Purge(ctx, tenant, id)
+}
+
+func (r *PackConfigurationRepository) Import(ctx context.Context, imp entity.PackConfigurationImport) error {
+	defer r.Invalidate(imp.Tenant)
+	return r.next.Import(ctx, imp)
+}
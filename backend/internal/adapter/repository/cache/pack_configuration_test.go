@@ -0,0 +1,217 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Schieck/packs-calculator/internal/adapter/repository/memory"
+	"github.com/Schieck/packs-calculator/internal/adapter/repository/repositorytest"
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+)
+
+// countingRepository counts the reads that get past the cache, and runs
+// beforeGetByID inside each GetByID to interleave other work with it
+type countingRepository struct {
+	entity.PackConfigurationRepository
+	getByIDCalls    int
+	getDefaultCalls int
+	beforeGetByID   func()
+}
+
+func (r *countingRepository) GetByID(ctx context.Context, tenant string, id int) (*entity.PackConfiguration, error) {
+	r.getByIDCalls++
+	if r.beforeGetByID != nil {
+		r.beforeGetByID()
+	}
+	return r.PackConfigurationRepository.GetByID(ctx, tenant, id)
+}
+
+func (r *countingRepository) GetDefault(ctx context.Context, tenant string, asOf time.Time) (*entity.PackConfiguration, error) {
+	r.getDefaultCalls++
+	return r.PackConfigurationRepository.GetDefault(ctx, tenant, asOf)
+}
+
+func newCachedRepository(maxEntries int) (*PackConfigurationRepository, *countingRepository, *time.Time) {
+	stored := &countingRepository{PackConfigurationRepository: memory.NewPackConfigurationRepository()}
+	repo := NewPackConfigurationRepository(stored, time.Minute, maxEntries)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return now }
+	return repo, stored, &now
+}
+
+func createConfiguration(t *testing.T, repo entity.PackConfigurationRepository, tenant string, name string, packSizes ...int) *entity.PackConfiguration {
+	t.Helper()
+	config, err := entity.NewPackConfiguration(tenant, name, packSizes)
+	require.NoError(t, err)
+	created, err := repo.Create(t.Context(), config)
+	require.NoError(t, err)
+	return created
+}
+
+func TestPackConfigurationRepository(t *testing.T) {
+	repositorytest.RunPackConfigurationRepositoryTests(t, func(t *testing.T) entity.PackConfigurationRepository {
+		return NewPackConfigurationRepository(memory.NewPackConfigurationRepository(), time.Minute, 100)
+	})
+}
+
+func TestPackConfigurationRepositoryCaching(t *testing.T) {
+	t.Run("serves repeated reads from memory", func(t *testing.T) {
+		repo, stored, _ := newCachedRepository(10)
+		config := createConfiguration(t, repo, entity.DefaultTenant, "Standard", 250, 500)
+
+		for range 3 {
+			got, err := repo.GetByID(t.Context(), entity.DefaultTenant, config.ID)
+			require.NoError(t, err)
+			assert.Equal(t, []int{250, 500}, got.PackSizes)
+		}
+
+		assert.Equal(t, 1, stored.getByIDCalls)
+		stats := repo.Stats()
+		assert.Equal(t, uint64(2), stats.Hits)
+		assert.Equal(t, uint64(1), stats.Misses)
+		assert.Equal(t, 1, stats.Entries)
+		assert.InDelta(t, 2.0/3.0, stats.HitRatio(), 1e-9)
+	})
+
+	t.Run("expires entries after the ttl", func(t *testing.T) {
+		repo, stored, now := newCachedRepository(10)
+		config := createConfiguration(t, repo, entity.DefaultTenant, "Standard", 250, 500)
+
+		_, err := repo.GetByID(t.Context(), entity.DefaultTenant, config.ID)
+		require.NoError(t, err)
+		*now = now.Add(time.Minute)
+		_, err = repo.GetByID(t.Context(), entity.DefaultTenant, config.ID)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, stored.getByIDCalls)
+		assert.Equal(t, uint64(1), repo.Stats().Expirations)
+	})
+
+	t.Run("evicts the least recently used entry", func(t *testing.T) {
+		repo, stored, _ := newCachedRepository(2)
+		first := createConfiguration(t, repo, entity.DefaultTenant, "First", 250)
+		second := createConfiguration(t, repo, entity.DefaultTenant, "Second", 500)
+		third := createConfiguration(t, repo, entity.DefaultTenant, "Third", 1000)
+
+		for _, id := range []int{first.ID, second.ID, first.ID, third.ID, first.ID} {
+			_, err := repo.GetByID(t.Context(), entity.DefaultTenant, id)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 3, stored.getByIDCalls, "first stays cached as the most recently used")
+
+		_, err := repo.GetByID(t.Context(), entity.DefaultTenant, second.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 4, stored.getByIDCalls)
+
+		stats := repo.Stats()
+		assert.Equal(t, uint64(2), stats.Evictions)
+		assert.Equal(t, 2, stats.Entries)
+	})
+
+	t.Run("writes invalidate the tenant", func(t *testing.T) {
+		repo, stored, _ := newCachedRepository(10)
+		config := createConfiguration(t, repo, entity.DefaultTenant, "Standard", 250, 500)
+		other := createConfiguration(t, repo, "wholesale", "Bulk", 1000)
+		for _, read := range []*entity.PackConfiguration{config, other} {
+			_, err := repo.GetByID(t.Context(), read.Tenant, read.ID)
+			require.NoError(t, err)
+		}
+
+		config.Name = "Renamed"
+		_, err := repo.Update(t.Context(), config)
+		require.NoError(t, err)
+
+		got, err := repo.GetByID(t.Context(), entity.DefaultTenant, config.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Renamed", got.Name)
+		_, err = repo.GetByID(t.Context(), "wholesale", other.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 3, stored.getByIDCalls, "the other tenant's entry survives")
+		assert.Equal(t, uint64(1), repo.Stats().Invalidations)
+	})
+
+	t.Run("Invalidate drops changes made elsewhere", func(t *testing.T) {
+		repo, stored, _ := newCachedRepository(10)
+		config := createConfiguration(t, repo, entity.DefaultTenant, "Standard", 250, 500)
+		_, err := repo.GetByID(t.Context(), entity.DefaultTenant, config.ID)
+		require.NoError(t, err)
+
+		config.Name = "Renamed elsewhere"
+		_, err = stored.Update(t.Context(), config)
+		require.NoError(t, err)
+		got, err := repo.GetByID(t.Context(), entity.DefaultTenant, config.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Standard", got.Name)
+
+		repo.Invalidate(entity.DefaultTenant)
+		got, err = repo.GetByID(t.Context(), entity.DefaultTenant, config.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Renamed elsewhere", got.Name)
+	})
+
+	t.Run("a read racing a write does not store what it read", func(t *testing.T) {
+		repo, stored, _ := newCachedRepository(10)
+		config := createConfiguration(t, repo, entity.DefaultTenant, "Standard", 250, 500)
+
+		stored.beforeGetByID = func() {
+			stored.beforeGetByID = nil
+			repo.Invalidate(entity.DefaultTenant)
+		}
+		_, err := repo.GetByID(t.Context(), entity.DefaultTenant, config.ID)
+		require.NoError(t, err)
+		assert.Zero(t, repo.Stats().Entries)
+
+		repo.InvalidateAll()
+		stored.beforeGetByID = func() {
+			stored.beforeGetByID = nil
+			repo.InvalidateAll()
+		}
+		_, err = repo.GetByID(t.Context(), entity.DefaultTenant, config.ID)
+		require.NoError(t, err)
+		assert.Zero(t, repo.Stats().Entries)
+	})
+
+	t.Run("the default answers within its period only", func(t *testing.T) {
+		repo, stored, now := newCachedRepository(10)
+		current := createConfiguration(t, repo, entity.DefaultTenant, "Current", 250, 500)
+		switchover := now.Add(24 * time.Hour)
+		current.EffectiveTo = &switchover
+		current.IsDefault = true
+		_, err := repo.Update(t.Context(), current)
+		require.NoError(t, err)
+		next, err := entity.NewPackConfiguration(entity.DefaultTenant, "Next", []int{1000})
+		require.NoError(t, err)
+		next.EffectiveFrom = &switchover
+		next.IsDefault = true
+		_, err = repo.Create(t.Context(), next)
+		require.NoError(t, err)
+
+		for _, asOf := range []time.Time{*now, now.Add(time.Hour)} {
+			got, err := repo.GetDefault(t.Context(), entity.DefaultTenant, asOf)
+			require.NoError(t, err)
+			assert.Equal(t, "Current", got.Name)
+		}
+		assert.Equal(t, 1, stored.getDefaultCalls)
+
+		got, err := repo.GetDefault(t.Context(), entity.DefaultTenant, switchover)
+		require.NoError(t, err)
+		assert.Equal(t, "Next", got.Name)
+		assert.Equal(t, 2, stored.getDefaultCalls)
+	})
+
+	t.Run("returns copies of cached configurations", func(t *testing.T) {
+		repo, _, _ := newCachedRepository(10)
+		config := createConfiguration(t, repo, entity.DefaultTenant, "Standard", 250, 500)
+
+		got, err := repo.GetByID(t.Context(), entity.DefaultTenant, config.ID)
+		require.NoError(t, err)
+		got.PackSizes[0] = 1
+		got, err = repo.GetByID(t.Context(), entity.DefaultTenant, config.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []int{250, 500}, got.PackSizes)
+	})
+}
@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+	"github.com/lib/pq"
+)
+
+type CalculationRepository struct {
+	db *sql.DB
+}
+
+func NewCalculationRepository(db *sql.DB) *CalculationRepository {
+	return &CalculationRepository{
+		db: db,
+	}
+}
+
+const calculationColumns = `id, tenant_id, subject, items, pack_sizes, configuration_id, configuration_version, location,
+		allocation, total_packs, total_items, surplus, solver, duration_us, created_at`
+
+func (r *CalculationRepository) scanCalculation(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*entity.CalculationRecord, error) {
+	record := &entity.CalculationRecord{}
+	var (
+		packSizes       pq.Int64Array
+		configurationID sql.NullInt64
+		version         sql.NullInt64
+		location        sql.NullString
+		allocation      []byte
+		durationMicros  int64
+	)
+
+	err := scanner.Scan(
+		&record.ID,
+		&record.Tenant,
+		&record.Subject,
+		&record.Items,
+		&packSizes,
+		&configurationID,
+		&version,
+		&location,
+		&allocation,
+		&record.TotalPacks,
+		&record.TotalItems,
+		&record.Surplus,
+		&record.Solver,
+		&durationMicros,
+		&record.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	record.PackSizes, err = int64ArrayToIntSlice(packSizes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert pack sizes: %w", err)
+	}
+
+	if configurationID.Valid {
+		id := int(configurationID.Int64)
+		record.ConfigurationID = &id
+	}
+	if version.Valid {
+		v := int(version.Int64)
+		record.ConfigurationVersion = &v
+	}
+	record.Location = location.String
+	record.Duration = time.Duration(durationMicros) * time.Microsecond
+
+	if err := json.Unmarshal(allocation, &record.Allocation); err != nil {
+		return nil, fmt.Errorf("failed to decode allocation: %w", err)
+	}
+
+	return record, nil
+}
+
+func (r *CalculationRepository) Create(record *entity.CalculationRecord) (*entity.CalculationRecord, error) {
+	if err := record.Validate(); err != nil {
+		return nil, err
+	}
+
+	packSizes, err := intSliceToInt64Array(record.PackSizes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert pack sizes: %w", err)
+	}
+
+	allocation, err := json.Marshal(record.Allocation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode allocation: %w", err)
+	}
+
+	query := `
+		INSERT INTO calculations (tenant_id, subject, items, pack_sizes, configuration_id, configuration_version, location,
+			allocation, total_packs, total_items, surplus, solver, duration_us)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, $9, $10, $11, $12, $13)
+		RETURNING id, created_at
+	`
+
+	err = r.db.QueryRow(query,
+		record.Tenant,
+		record.Subject,
+		record.Items,
+		packSizes,
+		record.ConfigurationID,
+		record.ConfigurationVersion,
+		record.Location,
+		allocation,
+		record.TotalPacks,
+		record.TotalItems,
+		record.Surplus,
+		record.Solver,
+		record.Duration.Microseconds(),
+	).Scan(&record.ID, &record.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create calculation: %w", err)
+	}
+
+	return record, nil
+}
+
+func (r *CalculationRepository) GetByID(tenant string, id int64) (*entity.CalculationRecord, error) {
+	query := `SELECT ` + calculationColumns + ` FROM calculations WHERE id = $1 AND tenant_id = $2`
+
+	record, err := r.scanCalculation(r.db.QueryRow(query, id, tenant))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("calculation with id %d: %w", id, errs.ErrCalculationNotFound)
+		}
+		return nil, fmt.Errorf("failed to get calculation: %w", err)
+	}
+
+	return record, nil
+}
+
+func (r *CalculationRepository) List(filter entity.CalculationFilter) ([]*entity.CalculationRecord, int, error) {
+	where, args := calculationFilterClause(filter)
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM calculations`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count calculations: %w", err)
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM calculations%s ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d`,
+		calculationColumns, where, len(args)+1, len(args)+2)
+
+	rows, err := r.db.Query(query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query calculations: %w", err)
+	}
+	defer rows.Close()
+
+	records := make([]*entity.CalculationRecord, 0, filter.Limit)
+	for rows.Next() {
+		record, err := r.scanCalculation(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan calculation: %w", err)
+		}
+		records = append(records, record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return records, total, nil
+}
+
+func (r *CalculationRepository) DeleteOlderThan(cutoff time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM calculations WHERE created_at < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune calculations: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// calculationFilterClause builds a parameterised WHERE clause shared by the
+// listing and count queries
+func calculationFilterClause(filter entity.CalculationFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Tenant != "" {
+		add("tenant_id = $%d", filter.Tenant)
+	}
+	if filter.Subject != "" {
+		add("subject = $%d", filter.Subject)
+	}
+	if filter.ConfigurationID != nil {
+		add("configuration_id = $%d", *filter.ConfigurationID)
+	}
+	if filter.From != nil {
+		add("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		add("created_at < $%d", *filter.To)
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+)
+
+func TestCalculationFilterClause(t *testing.T) {
+	configurationID := 7
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		filter        entity.CalculationFilter
+		expectedWhere string
+		expectedArgs  []interface{}
+	}{
+		{
+			name:          "no filters",
+			filter:        entity.CalculationFilter{Limit: 50},
+			expectedWhere: "",
+			expectedArgs:  nil,
+		},
+		{
+			name:          "subject only",
+			filter:        entity.CalculationFilter{Subject: "authenticated-user"},
+			expectedWhere: " WHERE subject = $1",
+			expectedArgs:  []interface{}{"authenticated-user"},
+		},
+		{
+			name: "all filters",
+			filter: entity.CalculationFilter{
+				Tenant:          "retail",
+				Subject:         "authenticated-user",
+				ConfigurationID: &configurationID,
+				From:            &from,
+				To:              &to,
+			},
+			expectedWhere: " WHERE tenant_id = $1 AND subject = $2 AND configuration_id = $3 AND created_at >= $4 AND created_at < $5",
+			expectedArgs:  []interface{}{"retail", "authenticated-user", 7, from, to},
+		},
+		{
+			name:          "time range only",
+			filter:        entity.CalculationFilter{From: &from, To: &to},
+			expectedWhere: " WHERE created_at >= $1 AND created_at < $2",
+			expectedArgs:  []interface{}{from, to},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, args := calculationFilterClause(tt.filter)
+
+			assert.Equal(t, tt.expectedWhere, where)
+			assert.Equal(t, tt.expectedArgs, args)
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+	"github.com/lib/pq"
+)
+
+type InventoryRepository struct {
+	db *sql.DB
+}
+
+func NewInventoryRepository(db *sql.DB) *InventoryRepository {
+	return &InventoryRepository{
+		db: db,
+	}
+}
+
+func (r *InventoryRepository) GetStock(ctx context.Context, tenant string, location string) ([]*entity.StockLevel, error) {
+	query := `
+		SELECT id, tenant_id, location, pack_size, on_hand, reserved, updated_at
+		FROM stock_levels
+		WHERE tenant_id = $1 AND location = $2
+		ORDER BY pack_size ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tenant, location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stock levels: %w", err)
+	}
+	defer rows.Close()
+
+	var levels []*entity.StockLevel
+	for rows.Next() {
+		level := &entity.StockLevel{}
+		if err := rows.Scan(
+			&level.ID,
+			&level.Tenant,
+			&level.Location,
+			&level.PackSize,
+			&level.OnHand,
+			&level.Reserved,
+			&level.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan stock level: %w", err)
+		}
+		levels = append(levels, level)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return levels, nil
+}
+
+func (r *InventoryRepository) SetStock(ctx context.Context, stock *entity.StockLevel) (*entity.StockLevel, error) {
+	if err := stock.Validate(); err != nil {
+		return nil, err
+	}
+
+	// The reserved <= on_hand check constraint rejects shrinking stock below what is promised
+	query := `
+		INSERT INTO stock_levels (tenant_id, location, pack_size, on_hand)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id, location, pack_size)
+		DO UPDATE SET on_hand = EXCLUDED.on_hand, updated_at = CURRENT_TIMESTAMP
+		RETURNING id, reserved, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, stock.Tenant, stock.Location, stock.PackSize, stock.OnHand).Scan(
+		&stock.ID,
+		&stock.Reserved,
+		&stock.UpdatedAt,
+	)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "check_violation" {
+			return nil, errs.ErrInsufficientStock.Withf("on hand quantity %d is below reserved packs", stock.OnHand)
+		}
+		return nil, fmt.Errorf("failed to set stock level: %w", err)
+	}
+
+	return stock, nil
+}
+
+func (r *InventoryRepository) Reserve(ctx context.Context, reservation *entity.Reservation) (*entity.Reservation, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	sizes := allocatedPackSizes(reservation.Allocation)
+	packSizes := make(pq.Int64Array, len(sizes))
+	for i, size := range sizes {
+		packSizes[i] = int64(size)
+	}
+
+	// Lock the affected stock rows so concurrent reservations serialise on
+	// them, always in pack size order so that two of them cannot deadlock
+	rows, err := tx.QueryContext(ctx, `
+		SELECT pack_size, on_hand - reserved
+		FROM stock_levels
+		WHERE tenant_id = $1 AND location = $2 AND pack_size = ANY($3)
+		ORDER BY pack_size
+		FOR UPDATE
+	`, reservation.Tenant, reservation.Location, packSizes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock stock levels: %w", err)
+	}
+
+	available := make(map[int]int, len(packSizes))
+	for rows.Next() {
+		var size, count int
+		if err := rows.Scan(&size, &count); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan stock level: %w", err)
+		}
+		available[size] = count
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	for size, count := range reservation.Allocation {
+		if available[size] < count {
+			return nil, errs.ErrInsufficientStock.Withf("pack size %d at %s has %d available, need %d",
+				size, reservation.Location, available[size], count)
+		}
+	}
+
+	for _, size := range sizes {
+		count := reservation.Allocation[size]
+		_, err := tx.ExecContext(ctx, `
+			UPDATE stock_levels
+			SET reserved = reserved + $1, updated_at = CURRENT_TIMESTAMP
+			WHERE tenant_id = $2 AND location = $3 AND pack_size = $4
+		`, count, reservation.Tenant, reservation.Location, size)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reserve pack size %d: %w", size, err)
+		}
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO reservations (tenant_id, location, order_quantity, surplus, status)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`, reservation.Tenant, reservation.Location, reservation.OrderQuantity, reservation.Surplus, reservation.Status).Scan(
+		&reservation.ID,
+		&reservation.CreatedAt,
+		&reservation.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reservation: %w", err)
+	}
+
+	for size, count := range reservation.Allocation {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO reservation_items (reservation_id, pack_size, quantity)
+			VALUES ($1, $2, $3)
+		`, reservation.ID, size, count)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create reservation item: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit reservation: %w", err)
+	}
+
+	return reservation, nil
+}
+
+func (r *InventoryRepository) GetReservation(ctx context.Context, tenant string, id int) (*entity.Reservation, error) {
+	return r.getReservation(ctx, r.db, tenant, id, false)
+}
+
+func (r *InventoryRepository) Release(ctx context.Context, tenant string, id int) (*entity.Reservation, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	reservation, err := r.getReservation(ctx, tx, tenant, id, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if !reservation.IsActive() {
+		return nil, fmt.Errorf("reservation %d is %s: %w", id, reservation.Status, errs.ErrReservationNotActive)
+	}
+
+	// Release in the order Reserve locks in
+	for _, size := range allocatedPackSizes(reservation.Allocation) {
+		count := reservation.Allocation[size]
+		_, err := tx.ExecContext(ctx, `
+			UPDATE stock_levels
+			SET reserved = reserved - $1, updated_at = CURRENT_TIMESTAMP
+			WHERE tenant_id = $2 AND location = $3 AND pack_size = $4
+		`, count, reservation.Tenant, reservation.Location, size)
+		if err != nil {
+			return nil, fmt.Errorf("failed to release pack size %d: %w", size, err)
+		}
+	}
+
+	reservation.Status = entity.ReservationStatusCancelled
+	reservation.UpdatedAt = time.Now()
+
+	_, err = tx.ExecContext(ctx, `UPDATE reservations SET status = $1, updated_at = $2 WHERE id = $3`,
+		reservation.Status, reservation.UpdatedAt, reservation.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel reservation: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit release: %w", err)
+	}
+
+	return reservation, nil
+}
+
+// allocatedPackSizes returns the pack sizes of an allocation in ascending
+// order, the order stock rows are locked in
+func allocatedPackSizes(allocation map[int]int) []int {
+	sizes := make([]int, 0, len(allocation))
+	for size := range allocation {
+		sizes = append(sizes, size)
+	}
+	sort.Ints(sizes)
+	return sizes
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func (r *InventoryRepository) getReservation(ctx context.Context, q queryer, tenant string, id int, forUpdate bool) (*entity.Reservation, error) {
+	query := `
+		SELECT id, tenant_id, location, order_quantity, surplus, status, created_at, updated_at
+		FROM reservations
+		WHERE tenant_id = $1 AND id = $2
+	`
+	if forUpdate {
+		query += " FOR UPDATE"
+	}
+
+	reservation := &entity.Reservation{}
+	err := q.QueryRowContext(ctx, query, tenant, id).Scan(
+		&reservation.ID,
+		&reservation.Tenant,
+		&reservation.Location,
+		&reservation.OrderQuantity,
+		&reservation.Surplus,
+		&reservation.Status,
+		&reservation.CreatedAt,
+		&reservation.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("reservation with id %d: %w", id, errs.ErrReservationNotFound)
+		}
+		return nil, fmt.Errorf("failed to get reservation: %w", err)
+	}
+
+	rows, err := q.QueryContext(ctx, `SELECT pack_size, quantity FROM reservation_items WHERE reservation_id = $1`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reservation items: %w", err)
+	}
+	defer rows.Close()
+
+	reservation.Allocation = make(map[int]int)
+	for rows.Next() {
+		var size, count int
+		if err := rows.Scan(&size, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan reservation item: %w", err)
+		}
+		reservation.Allocation[size] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return reservation, nil
+}
@@ -0,0 +1,87 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+)
+
+// TestInventoryTenantIsolation gives two tenants of their own the same
+// location and checks that neither sees or touches the other's stock,
+// reservations or warehouses
+func TestInventoryTenantIsolation(t *testing.T) {
+	database := openTestDatabase(t, testDSN())
+	configurations := NewPackConfigurationRepository(database.DB, 5*time.Second)
+	inventory := NewInventoryRepository(database.DB)
+	warehouses := NewWarehouseRepository(database.DB)
+	suffix := time.Now().UnixNano()
+	retail, acme := fmt.Sprintf("retail-%d", suffix), fmt.Sprintf("acme-%d", suffix)
+	const location = "shared-dock"
+
+	for tenant, onHand := range map[string]int{retail: 4, acme: 9} {
+		stock, err := entity.NewStockLevel(tenant, location, 250, onHand)
+		require.NoError(t, err)
+		_, err = inventory.SetStock(t.Context(), stock)
+		require.NoError(t, err)
+	}
+
+	levels, err := inventory.GetStock(t.Context(), retail, location)
+	require.NoError(t, err)
+	require.Len(t, levels, 1)
+	assert.Equal(t, 4, levels[0].OnHand)
+	assert.Equal(t, retail, levels[0].Tenant)
+
+	reservation := &entity.Reservation{
+		Tenant:        acme,
+		Location:      location,
+		OrderQuantity: 1500,
+		Allocation:    map[int]int{250: 6},
+		Status:        entity.ReservationStatusActive,
+	}
+	reserved, err := inventory.Reserve(t.Context(), reservation)
+	require.NoError(t, err, "acme's 9 packs cover 6 even though retail only has 4")
+
+	levels, err = inventory.GetStock(t.Context(), retail, location)
+	require.NoError(t, err)
+	assert.Zero(t, levels[0].Reserved, "acme's reservation leaves retail's stock alone")
+
+	_, err = inventory.GetReservation(t.Context(), retail, reserved.ID)
+	assert.ErrorIs(t, err, errs.ErrReservationNotFound)
+	_, err = inventory.Release(t.Context(), retail, reserved.ID)
+	assert.ErrorIs(t, err, errs.ErrReservationNotFound)
+	released, err := inventory.Release(t.Context(), acme, reserved.ID)
+	require.NoError(t, err)
+	assert.False(t, released.IsActive())
+
+	created := make(map[string]*entity.Warehouse, 2)
+	for _, tenant := range []string{retail, acme} {
+		config, err := entity.NewPackConfiguration(tenant, "Dock", []int{250})
+		require.NoError(t, err)
+		config, err = configurations.Create(t.Context(), config)
+		require.NoError(t, err)
+
+		warehouse, err := entity.NewWarehouse(tenant, "Dock", location, config.ID)
+		require.NoError(t, err)
+		created[tenant], err = warehouses.Create(t.Context(), warehouse)
+		require.NoError(t, err, "locations are unique per tenant")
+		t.Cleanup(func() { _ = warehouses.Delete(context.Background(), tenant, created[tenant].ID) })
+	}
+
+	listed, err := warehouses.GetAll(t.Context(), retail)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, created[retail].ID, listed[0].ID)
+
+	_, err = warehouses.GetByID(t.Context(), retail, created[acme].ID)
+	assert.ErrorIs(t, err, errs.ErrWarehouseNotFound)
+	assert.ErrorIs(t, warehouses.Delete(t.Context(), retail, created[acme].ID), errs.ErrWarehouseNotFound)
+}
@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+)
+
+// AuditRepository keeps the audit log in process, in append order. The
+// configurations repository it is created with appends the entries of its
+// writes itself.
+type AuditRepository struct {
+	mu      sync.RWMutex
+	entries []entity.AuditEntry
+}
+
+func NewAuditRepository(configurations *PackConfigurationRepository) *AuditRepository {
+	r := &AuditRepository{}
+	configurations.audit = r
+	return r
+}
+
+func (r *AuditRepository) Append(entry *entity.AuditEntry) (*entity.AuditEntry, error) {
+	if err := entry.Validate(); err != nil {
+		return nil, err
+	}
+
+	r.append(entry)
+	return entry, nil
+}
+
+// append stores valid entries, numbering and timestamping them
+func (r *AuditRepository) append(entries ...*entity.AuditEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, entry := range entries {
+		entry.ID = int64(len(r.entries) + 1)
+		entry.CreatedAt = time.Now()
+		r.entries = append(r.entries, *entry)
+	}
+}
+
+// List returns one page of entries, newest first
+func (r *AuditRepository) List(filter entity.AuditFilter) ([]*entity.AuditEntry, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []*entity.AuditEntry
+	for i := len(r.entries) - 1; i >= 0; i-- {
+		if entry := r.entries[i]; matchesFilter(&entry, filter) {
+			matched = append(matched, &entry)
+		}
+	}
+
+	total := len(matched)
+	start := min(max(filter.Offset, 0), total)
+	end := min(start+max(filter.Limit, 0), total)
+
+	return append([]*entity.AuditEntry{}, matched[start:end]...), total, nil
+}
+
+func matchesFilter(entry *entity.AuditEntry, filter entity.AuditFilter) bool {
+	return (filter.Tenant == "" || entry.Tenant == filter.Tenant) &&
+		(filter.EntityType == "" || entry.EntityType == filter.EntityType) &&
+		(filter.EntityID == nil || entry.EntityID == *filter.EntityID) &&
+		(filter.Actor == "" || entry.Actor == filter.Actor) &&
+		(filter.From == nil || !entry.CreatedAt.Before(*filter.From)) &&
+		(filter.To == nil || entry.CreatedAt.Before(*filter.To))
+}
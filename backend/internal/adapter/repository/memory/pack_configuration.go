@@ -0,0 +1,750 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+)
+
+// PackConfigurationRepository keeps configurations and their versions in
+// process. It follows the Postgres repository: deletes are soft, a tenant's
+// defaults never overlap, names are unique among active configurations
+// regardless of case and listings are ordered the same way. A single lock
+// serialises every call, so each one is atomic like a transaction. Nothing
+// in it waits on I/O, so it only reads the actor its contexts carry.
+type PackConfigurationRepository struct {
+	mu       sync.RWMutex
+	configs  map[int]*entity.PackConfiguration
+	versions map[int][]*entity.PackConfigurationVersion
+	nextID   int
+	// packTypes resolves referenced pack types when a version is restored;
+	// NewPackTypeRepository sets it
+	packTypes *PackTypeRepository
+	// audit receives the entries of writes made under an actor;
+	// NewAuditRepository sets it
+	audit *AuditRepository
+}
+
+func NewPackConfigurationRepository() *PackConfigurationRepository {
+	return &PackConfigurationRepository{
+		configs:  make(map[int]*entity.PackConfiguration),
+		versions: make(map[int][]*entity.PackConfigurationVersion),
+		nextID:   1,
+	}
+}
+
+func (r *PackConfigurationRepository) List(ctx context.Context, query entity.PackConfigurationQuery) ([]*entity.PackConfiguration, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []*entity.PackConfiguration
+	for _, config := range r.configs {
+		if matchesQuery(config, query) {
+			matched = append(matched, config)
+		}
+	}
+	slices.SortFunc(matched, packConfigurationOrder(query))
+
+	total := len(matched)
+	start := min(max(query.Offset, 0), total)
+	end := min(start+max(query.Limit, 0), total)
+
+	configs := make([]*entity.PackConfiguration, 0, end-start)
+	for _, config := range matched[start:end] {
+		configs = append(configs, config.Clone())
+	}
+	return configs, total, nil
+}
+
+// matchesQuery applies the same filters as the Postgres listing
+func matchesQuery(config *entity.PackConfiguration, query entity.PackConfigurationQuery) bool {
+	if config.Tenant != query.Tenant || config.IsActive == query.Archived {
+		return false
+	}
+	if query.Search != "" && !strings.HasPrefix(strings.ToLower(config.Name), strings.ToLower(query.Search)) {
+		return false
+	}
+	if query.PackSize != nil && !slices.Contains(config.PackSizes, *query.PackSize) {
+		return false
+	}
+	for key, value := range query.Labels {
+		if stored, ok := config.Labels[key]; !ok || stored != value {
+			return false
+		}
+	}
+	if query.CreatedFrom != nil && config.CreatedAt.Before(*query.CreatedFrom) {
+		return false
+	}
+	if query.CreatedTo != nil && !config.CreatedAt.Before(*query.CreatedTo) {
+		return false
+	}
+	return true
+}
+
+// packConfigurationOrder mirrors the Postgres ORDER BY, with id as the
+// tie-breaker so pages are stable
+func packConfigurationOrder(query entity.PackConfigurationQuery) func(a, b *entity.PackConfiguration) int {
+	direction := 1
+	if query.Descending {
+		direction = -1
+	}
+
+	byID := func(a, b *entity.PackConfiguration) int {
+		return a.ID - b.ID
+	}
+
+	switch query.Sort {
+	case entity.PackConfigurationSortName:
+		return func(a, b *entity.PackConfiguration) int {
+			if c := strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)); c != 0 {
+				return direction * c
+			}
+			return direction * byID(a, b)
+		}
+	case entity.PackConfigurationSortCreatedAt:
+		return func(a, b *entity.PackConfiguration) int {
+			if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+				return direction * c
+			}
+			return direction * byID(a, b)
+		}
+	case entity.PackConfigurationSortUpdatedAt:
+		return func(a, b *entity.PackConfiguration) int {
+			if c := a.UpdatedAt.Compare(b.UpdatedAt); c != 0 {
+				return direction * c
+			}
+			return direction * byID(a, b)
+		}
+	}
+
+	if query.Archived {
+		return func(a, b *entity.PackConfiguration) int {
+			if c := b.UpdatedAt.Compare(a.UpdatedAt); c != 0 {
+				return c
+			}
+			return byID(b, a)
+		}
+	}
+	return func(a, b *entity.PackConfiguration) int {
+		if a.IsDefault != b.IsDefault {
+			if a.IsDefault {
+				return -1
+			}
+			return 1
+		}
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return byID(b, a)
+	}
+}
+
+func (r *PackConfigurationRepository) GetByID(ctx context.Context, tenant string, id int) (*entity.PackConfiguration, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	config, ok := r.active(tenant, id)
+	if !ok {
+		return nil, fmt.Errorf("pack configuration with id %d: %w", id, errs.ErrPackConfigurationNotFound)
+	}
+	return config.Clone(), nil
+}
+
+// GetDefault resolves the default in effect at asOf. Defaults never overlap,
+// so there is at most one candidate.
+func (r *PackConfigurationRepository) GetDefault(ctx context.Context, tenant string, asOf time.Time) (*entity.PackConfiguration, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, config := range r.configs {
+		if config.Tenant == tenant && config.IsDefault && config.IsActive && config.EffectiveAt(asOf) {
+			return config.Clone(), nil
+		}
+	}
+	return nil, errs.ErrNoDefaultConfiguration
+}
+
+func (r *PackConfigurationRepository) FindByPackSizes(ctx context.Context, tenant string, packSizes []int) ([]*entity.PackConfiguration, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var configs []*entity.PackConfiguration
+	for _, config := range r.sorted() {
+		if config.Tenant == tenant && config.IsActive && slices.Equal(config.PackSizes, packSizes) {
+			configs = append(configs, config.Clone())
+		}
+	}
+	return configs, nil
+}
+
+func (r *PackConfigurationRepository) Create(ctx context.Context, config *entity.PackConfiguration) (*entity.PackConfiguration, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if config.IsActive {
+		if conflict := r.nameConflict(config.Tenant, config.Name, 0); conflict != nil {
+			return nil, conflict
+		}
+	}
+	if err := r.checkPackTypes(ctx, config); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if config.IsDefault {
+		if overlap := r.defaultOverlap(config.Tenant, 0, config.EffectivePeriod); overlap != nil {
+			return nil, overlap
+		}
+	}
+	config.ID = r.nextID
+	config.Version = 1
+	config.CreatedAt = now
+	config.UpdatedAt = now
+	r.nextID++
+
+	r.configs[config.ID] = config.Clone()
+	r.insertVersion(config)
+
+	if err := r.recordAudit(ctx, entity.AuditActionCreate, nil, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Update writes a new version of the configuration's contents. Like the
+// Postgres repository it only ever moves the default flag onto config.
+func (r *PackConfigurationRepository) Update(ctx context.Context, config *entity.PackConfiguration) (*entity.PackConfiguration, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, err := r.atVersion(config.Tenant, config.ID, config.Version)
+	if err != nil {
+		return nil, err
+	}
+	if conflict := r.nameConflict(config.Tenant, config.Name, config.ID); conflict != nil {
+		return nil, conflict
+	}
+	if err := r.checkPackTypes(ctx, config); err != nil {
+		return nil, err
+	}
+	if stored.IsDefault {
+		if overlap := r.defaultOverlap(config.Tenant, config.ID, config.EffectivePeriod); overlap != nil {
+			return nil, overlap
+		}
+	}
+
+	makeDefault := config.IsDefault
+	config.Version = stored.Version + 1
+	config.IsDefault = stored.IsDefault
+	config.IsActive = true
+	config.CreatedAt = stored.CreatedAt
+	config.UpdatedAt = time.Now()
+	r.configs[config.ID] = config.Clone()
+
+	if makeDefault && !config.IsDefault {
+		r.moveDefault(config.Tenant, config.ID, true, config.ID)
+		config.IsDefault = true
+		config.UpdatedAt = r.configs[config.ID].UpdatedAt
+	}
+
+	r.insertVersion(config)
+
+	if err := r.recordAudit(ctx, entity.AuditActionUpdate, stored, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (r *PackConfigurationRepository) Delete(ctx context.Context, tenant string, id int, version int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	config, err := r.atVersion(tenant, id, version)
+	if err != nil {
+		return err
+	}
+	before := config.Clone()
+	config.IsActive = false
+	config.UpdatedAt = time.Now()
+
+	return r.recordAudit(ctx, entity.AuditActionDelete, before, nil)
+}
+
+func (r *PackConfigurationRepository) SetDefault(ctx context.Context, tenant string, id int, version int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	config, err := r.atVersion(tenant, id, version)
+	if err != nil {
+		return err
+	}
+	before := config.Clone()
+	r.moveDefault(tenant, id, true)
+
+	return r.recordAudit(ctx, entity.AuditActionSetDefault, before, config)
+}
+
+// moveDefault makes id the tenant's default for its effective period, taking
+// the flag off every other default whose period overlaps. Every configuration
+// whose flag changes gets a new version, except those in written, whose new
+// version the caller writes itself, and a new updated_at when touch is set.
+func (r *PackConfigurationRepository) moveDefault(tenant string, id int, touch bool, written ...int) {
+	target := r.configs[id]
+	now := time.Now()
+	for _, other := range r.configs {
+		if other.Tenant == tenant && other.IsDefault && other.ID != id && other.Overlaps(target.EffectivePeriod) {
+			r.flagDefault(other, false, touch, now, written)
+		}
+	}
+	if !target.IsDefault {
+		r.flagDefault(target, true, touch, now, written)
+	}
+}
+
+// flagDefault sets config's default flag, as a new version of config unless
+// config is in written
+func (r *PackConfigurationRepository) flagDefault(config *entity.PackConfiguration, isDefault bool, touch bool, now time.Time, written []int) {
+	config.IsDefault = isDefault
+	if !slices.Contains(written, config.ID) {
+		config.Version++
+		r.insertVersion(config)
+	}
+	if touch {
+		config.UpdatedAt = now
+	}
+}
+
+// defaultOverlap returns a DefaultPeriodOverlapError when another of the
+// tenant's defaults, archived ones included, overlaps period. The earliest
+// such default is named, like the Postgres repository does.
+func (r *PackConfigurationRepository) defaultOverlap(tenant string, id int, period entity.EffectivePeriod) error {
+	for _, other := range r.sortedByPeriod() {
+		if other.Tenant == tenant && other.IsDefault && other.ID != id && other.Overlaps(period) {
+			return &errs.DefaultPeriodOverlapError{ID: id, ExistingID: other.ID}
+		}
+	}
+	return nil
+}
+
+// nameConflict returns a NameConflictError when another active configuration
+// of the tenant uses name, ignoring case
+func (r *PackConfigurationRepository) nameConflict(tenant string, name string, id int) error {
+	for _, other := range r.sorted() {
+		if other.Tenant == tenant && other.IsActive && other.ID != id && strings.EqualFold(other.Name, name) {
+			return &errs.NameConflictError{Name: name, ExistingID: other.ID}
+		}
+	}
+	return nil
+}
+
+// active returns the tenant's active configuration with id
+func (r *PackConfigurationRepository) active(tenant string, id int) (*entity.PackConfiguration, bool) {
+	config, ok := r.configs[id]
+	if !ok || config.Tenant != tenant || !config.IsActive {
+		return nil, false
+	}
+	return config, true
+}
+
+// atVersion returns the stored configuration a version-conditioned write
+// applies to, or explains why there is none: either the configuration is
+// gone or it has moved past the expected version
+func (r *PackConfigurationRepository) atVersion(tenant string, id int, expected int) (*entity.PackConfiguration, error) {
+	config, ok := r.active(tenant, id)
+	if !ok {
+		return nil, fmt.Errorf("pack configuration with id %d not found or inactive: %w", id, errs.ErrPackConfigurationNotFound)
+	}
+	if config.Version != expected {
+		return nil, &errs.VersionConflictError{ID: id, ExpectedVersion: expected, CurrentVersion: config.Version}
+	}
+	return config, nil
+}
+
+// sorted returns the stored configurations ordered by id
+func (r *PackConfigurationRepository) sorted() []*entity.PackConfiguration {
+	configs := slices.Collect(maps.Values(r.configs))
+	slices.SortFunc(configs, func(a, b *entity.PackConfiguration) int {
+		return a.ID - b.ID
+	})
+	return configs
+}
+
+// sortedByPeriod returns the stored configurations ordered by the start of
+// their effective period, open starts first, then by id
+func (r *PackConfigurationRepository) sortedByPeriod() []*entity.PackConfiguration {
+	configs := r.sorted()
+	slices.SortStableFunc(configs, func(a, b *entity.PackConfiguration) int {
+		switch {
+		case a.EffectiveFrom == nil && b.EffectiveFrom == nil:
+			return 0
+		case a.EffectiveFrom == nil:
+			return -1
+		case b.EffectiveFrom == nil:
+			return 1
+		}
+		return a.EffectiveFrom.Compare(*b.EffectiveFrom)
+	})
+	return configs
+}
+
+// recordAudit appends the change to the audit log when ctx carries an actor,
+// while the caller still holds the lock that makes its change atomic
+func (r *PackConfigurationRepository) recordAudit(ctx context.Context, action entity.AuditAction, before, after *entity.PackConfiguration) error {
+	if r.audit == nil {
+		return nil
+	}
+	entry, err := entity.PackConfigurationAuditEntry(ctx, action, before, after)
+	if err != nil {
+		return fmt.Errorf("failed to build audit entry: %w", err)
+	}
+	if entry != nil {
+		r.audit.append(entry)
+	}
+	return nil
+}
+
+// insertVersion snapshots the configuration's current contents under its current version
+func (r *PackConfigurationRepository) insertVersion(config *entity.PackConfiguration) {
+	r.versions[config.ID] = append(r.versions[config.ID], config.Snapshot())
+}
+
+func (r *PackConfigurationRepository) GetVersions(ctx context.Context, tenant string, id int) ([]*entity.PackConfigurationVersion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	config, ok := r.configs[id]
+	if !ok || config.Tenant != tenant || len(r.versions[id]) == 0 {
+		return nil, fmt.Errorf("pack configuration with id %d: %w", id, errs.ErrPackConfigurationNotFound)
+	}
+
+	stored := r.versions[id]
+	versions := make([]*entity.PackConfigurationVersion, 0, len(stored))
+	for i := len(stored) - 1; i >= 0; i-- {
+		versions = append(versions, stored[i].Clone())
+	}
+	return versions, nil
+}
+
+func (r *PackConfigurationRepository) GetVersion(ctx context.Context, tenant string, id int, version int) (*entity.PackConfigurationVersion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	snapshot, err := r.getVersion(tenant, id, version)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.Clone(), nil
+}
+
+func (r *PackConfigurationRepository) getVersion(tenant string, id int, version int) (*entity.PackConfigurationVersion, error) {
+	if config, ok := r.configs[id]; ok && config.Tenant == tenant {
+		for _, snapshot := range r.versions[id] {
+			if snapshot.Version == version {
+				return snapshot, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("pack configuration %d version %d: %w", id, version, errs.ErrConfigurationVersionNotFound)
+}
+
+func (r *PackConfigurationRepository) RestoreVersion(ctx context.Context, tenant string, id int, version int) (*entity.PackConfiguration, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshot, err := r.getVersion(tenant, id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	config, ok := r.active(tenant, id)
+	if !ok {
+		return nil, fmt.Errorf("pack configuration with id %d not found or inactive: %w", id, errs.ErrPackConfigurationNotFound)
+	}
+	if conflict := r.nameConflict(tenant, snapshot.Name, id); conflict != nil {
+		return nil, conflict
+	}
+
+	restored := config.Clone()
+	snapshot.Restore(restored)
+	if restored.IsDefault {
+		if overlap := r.defaultOverlap(tenant, id, restored.EffectivePeriod); overlap != nil {
+			return nil, overlap
+		}
+	}
+
+	restored.PackTypeIDs = r.offeredPackTypes(ctx, tenant, restored)
+	restored.Version++
+	restored.UpdatedAt = time.Now()
+	r.configs[id] = restored
+	r.insertVersion(restored)
+
+	if err := r.recordAudit(ctx, entity.AuditActionRestore, config, restored); err != nil {
+		return nil, err
+	}
+	return restored.Clone(), nil
+}
+
+// storedPackTypes returns the pack types the configuration references. The
+// caller holds r.mu, which pack type writes take before their own lock.
+func (r *PackConfigurationRepository) storedPackTypes(ctx context.Context, tenant string, config *entity.PackConfiguration) []*entity.PackType {
+	if len(config.PackTypeIDs) == 0 || r.packTypes == nil {
+		return nil
+	}
+	packTypes, _ := r.packTypes.GetByIDs(ctx, tenant, config.PackTypeIDs)
+	return packTypes
+}
+
+// checkPackTypes fails when a pack type the configuration references was
+// deleted or changed after the service resolved it
+func (r *PackConfigurationRepository) checkPackTypes(ctx context.Context, config *entity.PackConfiguration) error {
+	if len(config.PackTypeIDs) == 0 {
+		return nil
+	}
+	return config.CheckPackTypes(r.storedPackTypes(ctx, config.Tenant, config))
+}
+
+// offeredPackTypes keeps the pack types that are active and whose item count
+// the configuration still offers
+func (r *PackConfigurationRepository) offeredPackTypes(ctx context.Context, tenant string, config *entity.PackConfiguration) []int {
+	return config.OfferedPackTypeIDs(r.storedPackTypes(ctx, tenant, config))
+}
+
+// references counts the tenant's active configurations referencing a pack
+// type. The caller holds r.mu.
+func (r *PackConfigurationRepository) references(tenant string, packTypeID int) int {
+	count := 0
+	for _, config := range r.configs {
+		if config.Tenant == tenant && config.IsActive && slices.Contains(config.PackTypeIDs, packTypeID) {
+			count++
+		}
+	}
+	return count
+}
+
+func (r *PackConfigurationRepository) GetArchivedByID(ctx context.Context, tenant string, id int) (*entity.PackConfiguration, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	config, ok := r.configs[id]
+	if !ok || config.Tenant != tenant || config.IsActive {
+		return nil, fmt.Errorf("archived pack configuration with id %d: %w", id, errs.ErrPackConfigurationNotFound)
+	}
+	return config.Clone(), nil
+}
+
+func (r *PackConfigurationRepository) Restore(ctx context.Context, tenant string, id int, name string) (*entity.PackConfiguration, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	config, ok := r.configs[id]
+	if !ok || config.Tenant != tenant || config.IsActive {
+		return nil, fmt.Errorf("archived pack configuration with id %d: %w", id, errs.ErrPackConfigurationNotFound)
+	}
+
+	if name == "" {
+		name = config.Name
+	}
+	if conflict := r.nameConflict(tenant, name, id); conflict != nil {
+		return nil, conflict
+	}
+	archived := config.Clone()
+
+	// Renaming, or dropping pack types deleted or changed while archived,
+	// changes the configuration's contents, so it gets a new version. The
+	// restored configuration comes back as a non-default; making it the
+	// default again is up to SetDefault.
+	offered := r.offeredPackTypes(ctx, tenant, config)
+	changed := name != config.Name || len(offered) != len(config.PackTypeIDs)
+	config.IsDefault = false
+	config.IsActive = true
+	config.Name = name
+	config.PackTypeIDs = offered
+	config.UpdatedAt = time.Now()
+	if changed {
+		config.Version++
+		r.insertVersion(config)
+	}
+
+	if err := r.recordAudit(ctx, entity.AuditActionUnarchive, archived, config); err != nil {
+		return nil, err
+	}
+	return config.Clone(), nil
+}
+
+// Purge permanently removes an archived configuration and its versions.
+// Warehouses and calculation history are not kept in memory, so nothing
+// else can reference it.
+func (r *PackConfigurationRepository) Purge(ctx context.Context, tenant string, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	config, ok := r.configs[id]
+	if !ok || config.Tenant != tenant {
+		return fmt.Errorf("pack configuration with id %d: %w", id, errs.ErrPackConfigurationNotFound)
+	}
+	if config.IsActive {
+		return errs.ErrConfigurationNotArchived.Withf("pack configuration %d must be deleted before it is purged", id)
+	}
+
+	delete(r.configs, id)
+	delete(r.versions, id)
+
+	return r.recordAudit(ctx, entity.AuditActionPurge, config, nil)
+}
+
+// Import applies every write or none of them: the stored state is copied
+// first and put back when a write fails
+func (r *PackConfigurationRepository) Import(ctx context.Context, imp entity.PackConfigurationImport) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	configs := make(map[int]*entity.PackConfiguration, len(r.configs))
+	for id, config := range r.configs {
+		configs[id] = config.Clone()
+	}
+	versions := make(map[int][]*entity.PackConfigurationVersion, len(r.versions))
+	for id, stored := range r.versions {
+		versions[id] = slices.Clone(stored)
+	}
+	nextID := r.nextID
+
+	entries, err := r.importWrites(ctx, imp)
+	if err != nil {
+		r.configs, r.versions, r.nextID = configs, versions, nextID
+		return err
+	}
+	if r.audit != nil {
+		r.audit.append(entries...)
+	}
+	return nil
+}
+
+// importWrites applies the import and returns the audit entries recording it
+func (r *PackConfigurationRepository) importWrites(ctx context.Context, imp entity.PackConfigurationImport) ([]*entity.AuditEntry, error) {
+	defaultID := imp.DefaultID
+	// The configurations the import writes, and what those it changes held before
+	var imported []int
+	before := make(map[int]*entity.PackConfiguration)
+	for _, config := range imp.Creates {
+		if err := config.Validate(); err != nil {
+			return nil, err
+		}
+		if conflict := r.nameConflict(imp.Tenant, config.Name, 0); conflict != nil {
+			return nil, conflict
+		}
+
+		stored := config.Clone()
+		stored.ID = r.nextID
+		stored.Tenant = imp.Tenant
+		stored.IsDefault = false
+		stored.IsActive = true
+		stored.Version = 1
+		if err := r.checkPackTypes(ctx, stored); err != nil {
+			return nil, err
+		}
+		r.nextID++
+		r.configs[stored.ID] = stored
+		r.insertVersion(stored)
+		imported = append(imported, stored.ID)
+
+		config.ID, config.Version = stored.ID, stored.Version
+		if config.IsDefault {
+			defaultID = config.ID
+		}
+	}
+
+	for _, config := range imp.Updates {
+		if err := config.Validate(); err != nil {
+			return nil, err
+		}
+		existing, err := r.atVersion(imp.Tenant, config.ID, config.Version)
+		if err != nil {
+			return nil, err
+		}
+		if conflict := r.nameConflict(imp.Tenant, config.Name, config.ID); conflict != nil {
+			return nil, conflict
+		}
+		if existing.IsDefault {
+			if overlap := r.defaultOverlap(imp.Tenant, config.ID, config.EffectivePeriod); overlap != nil {
+				return nil, overlap
+			}
+		}
+
+		stored := config.Clone()
+		stored.Tenant = imp.Tenant
+		stored.IsDefault = existing.IsDefault
+		stored.IsActive = true
+		stored.Version = existing.Version + 1
+		if err := r.checkPackTypes(ctx, stored); err != nil {
+			return nil, err
+		}
+
+		before[config.ID] = existing
+		imported = append(imported, config.ID)
+		r.configs[stored.ID] = stored
+		r.insertVersion(stored)
+
+		config.Version = stored.Version
+		if config.IsDefault {
+			defaultID = config.ID
+		}
+	}
+
+	if defaultID != 0 {
+		// The import takes timestamps from the file, so moving the default
+		// leaves them alone
+		target, ok := r.active(imp.Tenant, defaultID)
+		if !ok {
+			return nil, fmt.Errorf("pack configuration with id %d: %w", defaultID, errs.ErrPackConfigurationNotFound)
+		}
+		if !slices.Contains(imported, defaultID) {
+			before[defaultID] = target.Clone()
+		}
+		r.moveDefault(imp.Tenant, defaultID, false, imported...)
+	}
+
+	return r.importAuditEntries(ctx, imported, defaultID, before)
+}
+
+// importAuditEntries records each configuration the import wrote, and the
+// default it moved onto a configuration it left alone, as the import leaves them
+func (r *PackConfigurationRepository) importAuditEntries(ctx context.Context, imported []int, defaultID int, before map[int]*entity.PackConfiguration) ([]*entity.AuditEntry, error) {
+	var entries []*entity.AuditEntry
+	add := func(action entity.AuditAction, id int) error {
+		entry, err := entity.PackConfigurationAuditEntry(ctx, action, before[id], r.configs[id])
+		if err != nil {
+			return fmt.Errorf("failed to build audit entry: %w", err)
+		}
+		if entry != nil {
+			entries = append(entries, entry)
+		}
+		return nil
+	}
+
+	if defaultID != 0 && !slices.Contains(imported, defaultID) {
+		if err := add(entity.AuditActionSetDefault, defaultID); err != nil {
+			return nil, err
+		}
+	}
+	for _, id := range imported {
+		if err := add(entity.AuditActionImport, id); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,28 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/Schieck/packs-calculator/internal/adapter/repository/repositorytest"
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+)
+
+func TestPackConfigurationRepository(t *testing.T) {
+	repositorytest.RunPackConfigurationRepositoryTests(t, func(t *testing.T) entity.PackConfigurationRepository {
+		return NewPackConfigurationRepository()
+	})
+}
+
+func TestPackConfigurationRepositoryAudit(t *testing.T) {
+	repositorytest.RunPackConfigurationAuditTests(t, func(t *testing.T) (entity.PackConfigurationRepository, entity.AuditRepository) {
+		repo := NewPackConfigurationRepository()
+		return repo, NewAuditRepository(repo)
+	})
+}
+
+func TestPackTypeReferences(t *testing.T) {
+	repositorytest.RunPackTypeReferenceTests(t, func(t *testing.T) (entity.PackConfigurationRepository, entity.PackTypeRepository) {
+		repo := NewPackConfigurationRepository()
+		return repo, NewPackTypeRepository(repo)
+	})
+}
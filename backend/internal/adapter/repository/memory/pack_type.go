@@ -0,0 +1,184 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+)
+
+// PackTypeRepository keeps pack types in process. Codes are unique within a
+// tenant regardless of case, deleted pack types included, and references are
+// checked against the configurations repository it was created with. Writes
+// that check them lock that repository first, as its writes resolve pack
+// types while holding its lock.
+type PackTypeRepository struct {
+	mu             sync.RWMutex
+	packTypes      map[int]*entity.PackType
+	nextID         int
+	configurations *PackConfigurationRepository
+}
+
+func NewPackTypeRepository(configurations *PackConfigurationRepository) *PackTypeRepository {
+	r := &PackTypeRepository{
+		packTypes:      make(map[int]*entity.PackType),
+		nextID:         1,
+		configurations: configurations,
+	}
+	configurations.packTypes = r
+	return r
+}
+
+func clonePackType(packType *entity.PackType) *entity.PackType {
+	cloned := *packType
+	return &cloned
+}
+
+// collect returns clones of the pack types keep selects, ordered by cmp
+func (r *PackTypeRepository) collect(keep func(*entity.PackType) bool, cmp func(a, b *entity.PackType) int) []*entity.PackType {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	packTypes := []*entity.PackType{}
+	for _, packType := range r.packTypes {
+		if keep(packType) {
+			packTypes = append(packTypes, clonePackType(packType))
+		}
+	}
+	slices.SortFunc(packTypes, cmp)
+	return packTypes
+}
+
+func byPackTypeID(a, b *entity.PackType) int {
+	return a.ID - b.ID
+}
+
+func (r *PackTypeRepository) GetAll(ctx context.Context, tenant string, includeInactive bool) ([]*entity.PackType, error) {
+	return r.collect(func(packType *entity.PackType) bool {
+		return packType.Tenant == tenant && (packType.IsActive || includeInactive)
+	}, func(a, b *entity.PackType) int {
+		if c := strings.Compare(strings.ToLower(a.Code), strings.ToLower(b.Code)); c != 0 {
+			return c
+		}
+		return byPackTypeID(a, b)
+	}), nil
+}
+
+func (r *PackTypeRepository) GetByID(ctx context.Context, tenant string, id int) (*entity.PackType, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	packType, ok := r.packTypes[id]
+	if !ok || packType.Tenant != tenant {
+		return nil, fmt.Errorf("pack type with id %d: %w", id, errs.ErrPackTypeNotFound)
+	}
+	return clonePackType(packType), nil
+}
+
+func (r *PackTypeRepository) GetByIDs(ctx context.Context, tenant string, ids []int) ([]*entity.PackType, error) {
+	return r.collect(func(packType *entity.PackType) bool {
+		return packType.Tenant == tenant && slices.Contains(ids, packType.ID)
+	}, byPackTypeID), nil
+}
+
+func (r *PackTypeRepository) GetByCodes(ctx context.Context, tenant string, codes []string) ([]*entity.PackType, error) {
+	return r.collect(func(packType *entity.PackType) bool {
+		return packType.Tenant == tenant && packType.IsActive && slices.ContainsFunc(codes, func(code string) bool {
+			return strings.EqualFold(code, packType.Code)
+		})
+	}, byPackTypeID), nil
+}
+
+// codeConflict reports another of the tenant's pack types using the code
+func (r *PackTypeRepository) codeConflict(packType *entity.PackType) error {
+	for _, other := range r.packTypes {
+		if other.ID != packType.ID && other.Tenant == packType.Tenant && strings.EqualFold(other.Code, packType.Code) {
+			return fmt.Errorf("code %q: %w", packType.Code, errs.ErrPackTypeCodeConflict)
+		}
+	}
+	return nil
+}
+
+func (r *PackTypeRepository) Create(ctx context.Context, packType *entity.PackType) (*entity.PackType, error) {
+	if err := packType.Validate(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	packType.ID = 0
+	if err := r.codeConflict(packType); err != nil {
+		return nil, err
+	}
+
+	packType.ID = r.nextID
+	packType.CreatedAt = time.Now()
+	packType.UpdatedAt = packType.CreatedAt
+	r.nextID++
+	r.packTypes[packType.ID] = clonePackType(packType)
+
+	return packType, nil
+}
+
+func (r *PackTypeRepository) Update(ctx context.Context, packType *entity.PackType) (*entity.PackType, error) {
+	if err := packType.Validate(); err != nil {
+		return nil, err
+	}
+
+	r.configurations.mu.Lock()
+	defer r.configurations.mu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.packTypes[packType.ID]
+	if !ok || stored.Tenant != packType.Tenant {
+		return nil, fmt.Errorf("pack type with id %d: %w", packType.ID, errs.ErrPackTypeNotFound)
+	}
+	if err := r.codeConflict(packType); err != nil {
+		return nil, err
+	}
+	if packType.ItemCount != stored.ItemCount {
+		if err := r.inUse(packType.Tenant, packType.ID, "change the item count of"); err != nil {
+			return nil, err
+		}
+	}
+
+	packType.CreatedAt = stored.CreatedAt
+	packType.UpdatedAt = time.Now()
+	r.packTypes[packType.ID] = clonePackType(packType)
+
+	return packType, nil
+}
+
+func (r *PackTypeRepository) Delete(ctx context.Context, tenant string, id int) error {
+	r.configurations.mu.Lock()
+	defer r.configurations.mu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	packType, ok := r.packTypes[id]
+	if !ok || packType.Tenant != tenant || !packType.IsActive {
+		return fmt.Errorf("pack type with id %d: %w", id, errs.ErrPackTypeNotFound)
+	}
+	if err := r.inUse(tenant, id, "delete"); err != nil {
+		return err
+	}
+	packType.IsActive = false
+	packType.UpdatedAt = time.Now()
+	return nil
+}
+
+// inUse refuses the action while active configurations reference the pack
+// type. The caller holds the configurations' lock.
+func (r *PackTypeRepository) inUse(tenant string, id int, action string) error {
+	if count := r.configurations.references(tenant, id); count > 0 {
+		return errs.ErrPackTypeInUse.Withf("cannot %s pack type %d: %d active pack configuration(s) reference it", action, id, count)
+	}
+	return nil
+}
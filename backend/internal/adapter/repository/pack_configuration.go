@@ -1,25 +1,44 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+	"github.com/Schieck/packs-calculator/pkg/db"
 	"github.com/lib/pq"
 )
 
+// PackConfigurationRepository bounds each of its operations by queryTimeout,
+// a transaction counting as one. Every write records the events it causes in
+// the outbox, in its own transaction, for the webhook dispatcher to deliver,
+// and its audit entry when the context carries an actor.
 type PackConfigurationRepository struct {
-	db *sql.DB
+	db           *sql.DB
+	queryTimeout time.Duration
 }
 
-func NewPackConfigurationRepository(db *sql.DB) *PackConfigurationRepository {
+func NewPackConfigurationRepository(database *sql.DB, queryTimeout time.Duration) *PackConfigurationRepository {
 	return &PackConfigurationRepository{
-		db: db,
+		db:           database,
+		queryTimeout: queryTimeout,
 	}
 }
 
+// contextQueryer is what both *sql.DB and *sql.Tx offer for reads under a context
+type contextQueryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
 // When using[]int with Postgres we need to convert to pq.Int64Array
 func intSliceToInt64Array(intSlice []int) (pq.Int64Array, error) {
 	int64Array := make(pq.Int64Array, len(intSlice))
@@ -43,20 +62,57 @@ func int64ArrayToIntSlice(int64Array pq.Int64Array) ([]int, error) {
 	return intSlice, nil
 }
 
+// labelsArg encodes labels for a JSONB parameter. A map of strings always
+// encodes.
+func labelsArg(labels entity.Labels) string {
+	if labels == nil {
+		return "{}"
+	}
+	data, _ := json.Marshal(labels)
+	return string(data)
+}
+
+// packTypeIDsArg passes the referenced pack types, an empty array when there are none
+func packTypeIDsArg(ids []int) pq.Int64Array {
+	array := make(pq.Int64Array, len(ids))
+	for i, id := range ids {
+		array[i] = int64(id)
+	}
+	return array
+}
+
+// metadataArg passes metadata as text, since lib/pq sends []byte in the
+// binary format JSONB does not accept, and absent metadata as NULL
+func metadataArg(metadata json.RawMessage) interface{} {
+	if metadata == nil {
+		return nil
+	}
+	return string(metadata)
+}
+
 func (r *PackConfigurationRepository) scanPackConfiguration(scanner interface {
 	Scan(dest ...interface{}) error
 }) (*entity.PackConfiguration, error) {
 	config := &entity.PackConfiguration{}
-	var packSizes pq.Int64Array
+	var packSizes, packTypeIDs pq.Int64Array
+	var labels, metadata []byte
 
 	err := scanner.Scan(
 		&config.ID,
+		&config.Tenant,
 		&config.Name,
 		&packSizes,
 		&config.IsDefault,
 		&config.IsActive,
+		&config.Version,
 		&config.CreatedAt,
 		&config.UpdatedAt,
+		&config.EffectiveFrom,
+		&config.EffectiveTo,
+		&config.Description,
+		&labels,
+		&metadata,
+		&packTypeIDs,
 	)
 	if err != nil {
 		return nil, err
@@ -66,51 +122,80 @@ func (r *PackConfigurationRepository) scanPackConfiguration(scanner interface {
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert pack sizes: %w", err)
 	}
+	if len(packTypeIDs) > 0 {
+		config.PackTypeIDs, err = int64ArrayToIntSlice(packTypeIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert pack type IDs: %w", err)
+		}
+	}
+
+	if err := json.Unmarshal(labels, &config.Labels); err != nil {
+		return nil, fmt.Errorf("failed to decode labels: %w", err)
+	}
+	if len(config.Labels) == 0 {
+		config.Labels = nil
+	}
+	if metadata != nil {
+		config.Metadata = json.RawMessage(metadata)
+	}
 
 	return config, nil
 }
 
-func (r *PackConfigurationRepository) GetAll() ([]*entity.PackConfiguration, error) {
-	query := `
-		SELECT id, name, pack_sizes, is_default, is_active, created_at, updated_at 
-		FROM pack_configurations 
-		WHERE is_active = true
-		ORDER BY is_default DESC, created_at DESC
-	`
+func (r *PackConfigurationRepository) List(ctx context.Context, query entity.PackConfigurationQuery) (_ []*entity.PackConfiguration, _ int, err error) {
+	ctx, finish := db.WithQueryTimeout(ctx, r.queryTimeout, "List")
+	defer finish(&err)
+
+	where, args := packConfigurationQueryClause(query)
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM pack_configurations`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count pack configurations: %w", err)
+	}
 
-	rows, err := r.db.Query(query)
+	sqlQuery := fmt.Sprintf(`
+		SELECT id, tenant_id, name, pack_sizes, is_default, is_active, version, created_at, updated_at, effective_from, effective_to, description, labels, metadata, pack_type_ids
+		FROM pack_configurations%s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d
+	`, where, packConfigurationOrderClause(query), len(args)+1, len(args)+2)
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, append(args, query.Limit, query.Offset)...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query pack configurations: %w", err)
+		return nil, 0, fmt.Errorf("failed to query pack configurations: %w", err)
 	}
 	defer rows.Close()
 
-	var configs []*entity.PackConfiguration
+	configs := make([]*entity.PackConfiguration, 0, query.Limit)
 	for rows.Next() {
 		config, err := r.scanPackConfiguration(rows)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan pack configuration: %w", err)
+			return nil, 0, fmt.Errorf("failed to scan pack configuration: %w", err)
 		}
 		configs = append(configs, config)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("row iteration error: %w", err)
+		return nil, 0, fmt.Errorf("row iteration error: %w", err)
 	}
 
-	return configs, nil
+	return configs, total, nil
 }
 
-func (r *PackConfigurationRepository) GetByID(id int) (*entity.PackConfiguration, error) {
+func (r *PackConfigurationRepository) GetByID(ctx context.Context, tenant string, id int) (_ *entity.PackConfiguration, err error) {
+	ctx, finish := db.WithQueryTimeout(ctx, r.queryTimeout, "GetByID")
+	defer finish(&err)
+
 	query := `
-		SELECT id, name, pack_sizes, is_default, is_active, created_at, updated_at 
+		SELECT id, tenant_id, name, pack_sizes, is_default, is_active, version, created_at, updated_at, effective_from, effective_to, description, labels, metadata, pack_type_ids 
 		FROM pack_configurations 
-		WHERE id = $1 AND is_active = true
+		WHERE id = $1 AND tenant_id = $2 AND is_active = true
 	`
 
-	config, err := r.scanPackConfiguration(r.db.QueryRow(query, id))
+	config, err := r.scanPackConfiguration(r.db.QueryRowContext(ctx, query, id, tenant))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("pack configuration with id %d not found", id)
+			return nil, fmt.Errorf("pack configuration with id %d: %w", id, errs.ErrPackConfigurationNotFound)
 		}
 		return nil, fmt.Errorf("failed to get pack configuration: %w", err)
 	}
@@ -118,18 +203,24 @@ func (r *PackConfigurationRepository) GetByID(id int) (*entity.PackConfiguration
 	return config, nil
 }
 
-func (r *PackConfigurationRepository) GetDefault() (*entity.PackConfiguration, error) {
+// GetDefault resolves the default in effect at asOf. The exclusion
+// constraint on default periods leaves at most one candidate.
+func (r *PackConfigurationRepository) GetDefault(ctx context.Context, tenant string, asOf time.Time) (_ *entity.PackConfiguration, err error) {
+	ctx, finish := db.WithQueryTimeout(ctx, r.queryTimeout, "GetDefault")
+	defer finish(&err)
+
 	query := `
-		SELECT id, name, pack_sizes, is_default, is_active, created_at, updated_at 
+		SELECT id, tenant_id, name, pack_sizes, is_default, is_active, version, created_at, updated_at, effective_from, effective_to, description, labels, metadata, pack_type_ids 
 		FROM pack_configurations 
-		WHERE is_default = true AND is_active = true
+		WHERE tenant_id = $1 AND is_default = true AND is_active = true
+			AND tstzrange(effective_from, effective_to) @> $2::timestamptz
 		LIMIT 1
 	`
 
-	config, err := r.scanPackConfiguration(r.db.QueryRow(query))
+	config, err := r.scanPackConfiguration(r.db.QueryRowContext(ctx, query, tenant, asOf))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("no default pack configuration found")
+			return nil, errs.ErrNoDefaultConfiguration
 		}
 		return nil, fmt.Errorf("failed to get default pack configuration: %w", err)
 	}
@@ -137,15 +228,54 @@ func (r *PackConfigurationRepository) GetDefault() (*entity.PackConfiguration, e
 	return config, nil
 }
 
-func (r *PackConfigurationRepository) Create(config *entity.PackConfiguration) (*entity.PackConfiguration, error) {
+func (r *PackConfigurationRepository) FindByPackSizes(ctx context.Context, tenant string, packSizes []int) (_ []*entity.PackConfiguration, err error) {
+	ctx, finish := db.WithQueryTimeout(ctx, r.queryTimeout, "FindByPackSizes")
+	defer finish(&err)
+
+	sizes, err := intSliceToInt64Array(packSizes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert pack sizes: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, tenant_id, name, pack_sizes, is_default, is_active, version, created_at, updated_at, effective_from, effective_to, description, labels, metadata, pack_type_ids
+		FROM pack_configurations
+		WHERE tenant_id = $1 AND is_active = true AND pack_sizes = $2
+		ORDER BY id
+	`, tenant, sizes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pack configurations by pack sizes: %w", err)
+	}
+	defer rows.Close()
+
+	var configs []*entity.PackConfiguration
+	for rows.Next() {
+		config, err := r.scanPackConfiguration(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan pack configuration: %w", err)
+		}
+		configs = append(configs, config)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return configs, nil
+}
+
+func (r *PackConfigurationRepository) Create(ctx context.Context, config *entity.PackConfiguration) (_ *entity.PackConfiguration, err error) {
+	ctx, finish := db.WithQueryTimeout(ctx, r.queryTimeout, "Create")
+	defer finish(&err)
+
 	if err := config.Validate(); err != nil {
 		return nil, err
 	}
 
 	query := `
-		INSERT INTO pack_configurations (name, pack_sizes, is_default, is_active) 
-		VALUES ($1, $2, $3, $4) 
-		RETURNING id, created_at, updated_at
+		INSERT INTO pack_configurations (tenant_id, name, pack_sizes, is_default, is_active, effective_from, effective_to, description, labels, metadata, pack_type_ids, version) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9::jsonb, $10::jsonb, $11, 1) 
+		RETURNING id, version, created_at, updated_at
 	`
 
 	packSizes, err := intSliceToInt64Array(config.PackSizes)
@@ -153,19 +283,56 @@ func (r *PackConfigurationRepository) Create(config *entity.PackConfiguration) (
 		return nil, fmt.Errorf("failed to convert pack sizes: %w", err)
 	}
 
-	err = r.db.QueryRow(query, config.Name, packSizes, config.IsDefault, config.IsActive).Scan(
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := r.checkPackTypes(ctx, tx, config.Tenant, config); err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRowContext(ctx, query, config.Tenant, config.Name, packSizes, config.IsDefault, config.IsActive, config.EffectiveFrom, config.EffectiveTo,
+		config.Description, labelsArg(config.Labels), metadataArg(config.Metadata), packTypeIDsArg(config.PackTypeIDs)).Scan(
 		&config.ID,
+		&config.Version,
 		&config.CreatedAt,
 		&config.UpdatedAt,
 	)
 	if err != nil {
+		if conflict := r.nameConflict(ctx, err, config.Tenant, config.Name, 0); conflict != nil {
+			return nil, conflict
+		}
 		return nil, fmt.Errorf("failed to create pack configuration: %w", err)
 	}
 
+	if err := r.insertVersion(ctx, tx, config); err != nil {
+		return nil, err
+	}
+
+	if err := recordEvent(ctx, tx, entity.EventPackConfigurationCreated, config, nil); err != nil {
+		return nil, err
+	}
+
+	if err := recordAudit(ctx, tx, entity.AuditActionCreate, nil, config); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit pack configuration: %w", err)
+	}
+
 	return config, nil
 }
 
-func (r *PackConfigurationRepository) Update(config *entity.PackConfiguration) (*entity.PackConfiguration, error) {
+// Update writes a new version of the configuration's contents. The default
+// flag is only ever moved onto the configuration, in the same transaction
+// and the same way as SetDefault; clearing it is the service's concern.
+func (r *PackConfigurationRepository) Update(ctx context.Context, config *entity.PackConfiguration) (_ *entity.PackConfiguration, err error) {
+	ctx, finish := db.WithQueryTimeout(ctx, r.queryTimeout, "Update")
+	defer finish(&err)
+
 	if err := config.Validate(); err != nil {
 		return nil, err
 	}
@@ -174,9 +341,10 @@ func (r *PackConfigurationRepository) Update(config *entity.PackConfiguration) (
 
 	query := `
 		UPDATE pack_configurations 
-		SET name = $1, pack_sizes = $2, is_default = $3, is_active = $4, updated_at = $5
-		WHERE id = $6 AND is_active = true
-		RETURNING created_at
+		SET name = $1, pack_sizes = $2, effective_from = $3, effective_to = $4, updated_at = $5, version = version + 1,
+			description = $9, labels = $10::jsonb, metadata = $11::jsonb, pack_type_ids = $12
+		WHERE id = $6 AND tenant_id = $7 AND is_active = true AND version = $8
+		RETURNING version, is_default, created_at
 	`
 
 	packSizes, err := intSliceToInt64Array(config.PackSizes)
@@ -184,72 +352,1031 @@ func (r *PackConfigurationRepository) Update(config *entity.PackConfiguration) (
 		return nil, fmt.Errorf("failed to convert pack sizes: %w", err)
 	}
 
-	err = r.db.QueryRow(query,
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Take the defaults lock before the row lock, in the same order as SetDefault
+	makeDefault := config.IsDefault
+	if makeDefault {
+		if err := lockDefaults(ctx, tx, config.Tenant); err != nil {
+			return nil, err
+		}
+	}
+
+	before, err := r.lockConfiguration(ctx, tx, config.Tenant, config.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.checkPackTypes(ctx, tx, config.Tenant, config); err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRowContext(ctx, query,
 		config.Name,
 		packSizes,
-		config.IsDefault,
-		config.IsActive,
+		config.EffectiveFrom,
+		config.EffectiveTo,
 		config.UpdatedAt,
 		config.ID,
-	).Scan(&config.CreatedAt)
+		config.Tenant,
+		config.Version,
+		config.Description,
+		labelsArg(config.Labels),
+		metadataArg(config.Metadata),
+		packTypeIDsArg(config.PackTypeIDs),
+	).Scan(&config.Version, &config.IsDefault, &config.CreatedAt)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("pack configuration with id %d not found or inactive", config.ID)
+			return nil, r.versionConflict(ctx, tx, config.Tenant, config.ID, config.Version)
+		}
+		if conflict := r.nameConflict(ctx, err, config.Tenant, config.Name, config.ID); conflict != nil {
+			return nil, conflict
+		}
+		if overlap := r.defaultOverlap(ctx, err, config.Tenant, config.ID, config.EffectivePeriod); overlap != nil {
+			return nil, overlap
 		}
 		return nil, fmt.Errorf("failed to update pack configuration: %w", err)
 	}
 
+	if err := recordEvent(ctx, tx, entity.EventPackConfigurationUpdated, config, nil); err != nil {
+		return nil, err
+	}
+
+	if makeDefault && !config.IsDefault {
+		if _, err := r.moveDefault(ctx, tx, config.Tenant, config.ID, config.Version, config.ID); err != nil {
+			return nil, err
+		}
+		config.IsDefault = true
+	}
+
+	if err := r.insertVersion(ctx, tx, config); err != nil {
+		return nil, err
+	}
+
+	if err := recordAudit(ctx, tx, entity.AuditActionUpdate, before, config); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit pack configuration update: %w", err)
+	}
+
 	return config, nil
 }
 
-func (r *PackConfigurationRepository) Delete(id int) error {
-	query := `UPDATE pack_configurations SET is_active = false, updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND is_active = true`
+func (r *PackConfigurationRepository) Delete(ctx context.Context, tenant string, id int, version int) (err error) {
+	ctx, finish := db.WithQueryTimeout(ctx, r.queryTimeout, "Delete")
+	defer finish(&err)
+
+	query := `
+		UPDATE pack_configurations SET is_active = false, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND tenant_id = $2 AND is_active = true AND version = $3
+		RETURNING id, tenant_id, name, pack_sizes, is_default, is_active, version, created_at, updated_at, effective_from, effective_to, description, labels, metadata, pack_type_ids
+	`
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
-	result, err := r.db.Exec(query, id)
+	before, err := r.lockConfiguration(ctx, tx, tenant, id)
 	if err != nil {
+		return err
+	}
+
+	config, err := r.scanPackConfiguration(tx.QueryRowContext(ctx, query, id, tenant, version))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return r.versionConflict(ctx, tx, tenant, id, version)
+		}
 		return fmt.Errorf("failed to delete pack configuration: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	if err := recordEvent(ctx, tx, entity.EventPackConfigurationDeleted, config, nil); err != nil {
+		return err
+	}
+
+	if err := recordAudit(ctx, tx, entity.AuditActionDelete, before, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *PackConfigurationRepository) SetDefault(ctx context.Context, tenant string, id int, version int) (err error) {
+	ctx, finish := db.WithQueryTimeout(ctx, r.queryTimeout, "SetDefault")
+	defer finish(&err)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Take the defaults lock before the row lock, in the same order as Update
+	if err := lockDefaults(ctx, tx, tenant); err != nil {
+		return err
+	}
+
+	before, err := r.lockConfiguration(ctx, tx, tenant, id)
+	if err != nil {
+		return err
+	}
+
+	config, err := r.moveDefault(ctx, tx, tenant, id, version)
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return err
+	}
+
+	if err := recordAudit(ctx, tx, entity.AuditActionSetDefault, before, config); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// moveDefault makes id the tenant's default for its effective period,
+// provided it is still active and at version, taking the flag off every
+// default whose period overlaps, and returns it as it leaves it. Every
+// configuration whose flag changes gets a new version, except those in
+// written, whose new version tx already holds. Every default change goes
+// through here.
+func (r *PackConfigurationRepository) moveDefault(ctx context.Context, tx *sql.Tx, tenant string, id int, version int, written ...int) (*entity.PackConfiguration, error) {
+	if err := lockDefaults(ctx, tx, tenant); err != nil {
+		return nil, err
+	}
+
+	// First, unset the tenant's defaults that overlap the new one
+	previousDefaultIDs, err := r.unsetOverlappingDefaults(ctx, tx, tenant, id, true, written)
+	if err != nil {
+		return nil, err
+	}
+
+	// Then set the new default, leaving it alone when it already is one
+	config, err := r.scanPackConfiguration(tx.QueryRowContext(ctx, `
+		UPDATE pack_configurations
+		SET is_default = true,
+			version = CASE WHEN is_default OR id = ANY($4) THEN version ELSE version + 1 END,
+			updated_at = CASE WHEN is_default THEN updated_at ELSE CURRENT_TIMESTAMP END
+		WHERE id = $1 AND tenant_id = $2 AND is_active = true AND version = $3
+		RETURNING id, tenant_id, name, pack_sizes, is_default, is_active, version, created_at, updated_at, effective_from, effective_to, description, labels, metadata, pack_type_ids
+	`, id, tenant, version, pq.Array(written)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, r.versionConflict(ctx, tx, tenant, id, version)
+		}
+		return nil, fmt.Errorf("failed to set new default: %w", err)
+	}
+	if config.Version != version {
+		if err := r.insertVersion(ctx, tx, config); err != nil {
+			return nil, err
+		}
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("pack configuration with id %d not found or already inactive", id)
+	if err := recordEvent(ctx, tx, entity.EventPackConfigurationDefaultChanged, config, previousDefaultIDs); err != nil {
+		return nil, err
 	}
+	return config, nil
+}
 
+// lockDefaults serialises the transactions moving a tenant's default until
+// tx ends. Two of them running at once would each miss the default the other
+// sets, and the later one would trip the exclusion constraint instead of
+// taking the flag off.
+func lockDefaults(ctx context.Context, tx *sql.Tx, tenant string) error {
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('pack_configuration_defaults'), hashtext($1))`, tenant); err != nil {
+		return fmt.Errorf("failed to lock default pack configurations: %w", err)
+	}
 	return nil
 }
 
-func (r *PackConfigurationRepository) SetDefault(id int) error {
-	tx, err := r.db.Begin()
+// unsetOverlappingDefaults takes the default flag off the tenant's other
+// configurations whose effective period overlaps id's, bumping their
+// updated_at when touch is set, and returns their IDs. Each of them gets a
+// new version unless it is in written.
+func (r *PackConfigurationRepository) unsetOverlappingDefaults(ctx context.Context, tx *sql.Tx, tenant string, id int, touch bool, written []int) ([]int, error) {
+	rows, err := tx.QueryContext(ctx, `
+		UPDATE pack_configurations other
+		SET is_default = false,
+			version = CASE WHEN other.id = ANY($4) THEN other.version ELSE other.version + 1 END,
+			updated_at = CASE WHEN $3 THEN CURRENT_TIMESTAMP ELSE other.updated_at END
+		FROM pack_configurations target
+		WHERE target.id = $1 AND target.tenant_id = $2
+			AND other.tenant_id = $2 AND other.is_default = true AND other.id <> $1
+			AND tstzrange(other.effective_from, other.effective_to) && tstzrange(target.effective_from, target.effective_to)
+		RETURNING other.id, other.tenant_id, other.name, other.pack_sizes, other.is_default, other.is_active, other.version,
+			other.created_at, other.updated_at, other.effective_from, other.effective_to, other.description, other.labels,
+			other.metadata, other.pack_type_ids
+	`, id, tenant, touch, pq.Array(written))
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return nil, fmt.Errorf("failed to unset existing defaults: %w", err)
+	}
+	defer rows.Close()
+
+	var unset []*entity.PackConfiguration
+	for rows.Next() {
+		config, err := r.scanPackConfiguration(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan unset default: %w", err)
+		}
+		unset = append(unset, config)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to unset existing defaults: %w", err)
+	}
+	rows.Close()
+
+	ids := make([]int, 0, len(unset))
+	for _, config := range unset {
+		if !slices.Contains(written, config.ID) {
+			if err := r.insertVersion(ctx, tx, config); err != nil {
+				return nil, err
+			}
+		}
+		ids = append(ids, config.ID)
+	}
+	return ids, nil
+}
+
+// defaultPeriodConstraint keeps the effective periods of a tenant's
+// defaults from overlapping
+const defaultPeriodConstraint = "excl_pack_configurations_tenant_default_period"
+
+// defaultOverlap turns a violation of defaultPeriodConstraint, by id taking
+// period, into a DefaultPeriodOverlapError naming the default it overlaps,
+// and returns nil for any other error. Like nameConflict, the lookup runs
+// outside the aborted transaction.
+func (r *PackConfigurationRepository) defaultOverlap(ctx context.Context, err error, tenant string, id int, period entity.EffectivePeriod) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code.Name() != "exclusion_violation" || pqErr.Constraint != defaultPeriodConstraint {
+		return nil
+	}
+
+	overlap := &errs.DefaultPeriodOverlapError{ID: id}
+	err = r.db.QueryRowContext(ctx, `
+		SELECT id FROM pack_configurations
+		WHERE tenant_id = $1 AND is_default = true AND id <> $2
+			AND tstzrange(effective_from, effective_to) && tstzrange($3::timestamptz, $4::timestamptz)
+		ORDER BY effective_from NULLS FIRST, id
+		LIMIT 1
+	`, tenant, id, period.EffectiveFrom, period.EffectiveTo).Scan(&overlap.ExistingID)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to find the overlapping default: %w", err)
+	}
+	return overlap
+}
+
+// uniqueNameIndex keeps active configuration names unique within a tenant
+// regardless of case
+const uniqueNameIndex = "idx_pack_configurations_active_tenant_lower_name"
+
+// nameConflict turns a violation of uniqueNameIndex into a
+// NameConflictError naming the configuration that holds name, and returns
+// nil for any other error. The lookup runs outside the failed transaction,
+// which Postgres has aborted.
+func (r *PackConfigurationRepository) nameConflict(ctx context.Context, err error, tenant string, name string, id int) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code.Name() != "unique_violation" || pqErr.Constraint != uniqueNameIndex {
+		return nil
+	}
+
+	conflict := &errs.NameConflictError{Name: name}
+	err = r.db.QueryRowContext(ctx, `
+		SELECT id FROM pack_configurations
+		WHERE tenant_id = $1 AND is_active = true AND lower(name) = lower($2) AND id <> $3
+		LIMIT 1
+	`, tenant, name, id).Scan(&conflict.ExistingID)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to find pack configuration named %q: %w", name, err)
+	}
+	return conflict
+}
+
+// versionConflict explains why a version-conditioned write matched no row:
+// either the configuration is gone or it has moved past the expected version
+func (r *PackConfigurationRepository) versionConflict(ctx context.Context, q contextQueryer, tenant string, id int, expected int) error {
+	var current int
+	err := q.QueryRowContext(ctx, `SELECT version FROM pack_configurations WHERE id = $1 AND tenant_id = $2 AND is_active = true`, id, tenant).Scan(&current)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("pack configuration with id %d not found or inactive: %w", id, errs.ErrPackConfigurationNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check pack configuration version: %w", err)
+	}
+	return &errs.VersionConflictError{ID: id, ExpectedVersion: expected, CurrentVersion: current}
+}
+
+func (r *PackConfigurationRepository) GetVersions(ctx context.Context, tenant string, id int) (_ []*entity.PackConfigurationVersion, err error) {
+	ctx, finish := db.WithQueryTimeout(ctx, r.queryTimeout, "GetVersions")
+	defer finish(&err)
+
+	query := `
+		SELECT ` + packConfigurationVersionColumns + `
+		FROM pack_configuration_versions v
+		JOIN pack_configurations pc ON pc.id = v.configuration_id
+		WHERE v.configuration_id = $1 AND pc.tenant_id = $2
+		ORDER BY v.version DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, id, tenant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pack configuration versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []*entity.PackConfigurationVersion
+	for rows.Next() {
+		version, err := scanPackConfigurationVersion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan pack configuration version: %w", err)
+		}
+		versions = append(versions, version)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("pack configuration with id %d: %w", id, errs.ErrPackConfigurationNotFound)
+	}
+
+	return versions, nil
+}
+
+func (r *PackConfigurationRepository) GetVersion(ctx context.Context, tenant string, id int, version int) (_ *entity.PackConfigurationVersion, err error) {
+	ctx, finish := db.WithQueryTimeout(ctx, r.queryTimeout, "GetVersion")
+	defer finish(&err)
+
+	return r.getVersion(ctx, r.db, tenant, id, version)
+}
+
+func (r *PackConfigurationRepository) RestoreVersion(ctx context.Context, tenant string, id int, version int) (_ *entity.PackConfiguration, err error) {
+	ctx, finish := db.WithQueryTimeout(ctx, r.queryTimeout, "RestoreVersion")
+	defer finish(&err)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
-	// First, unset all existing defaults
-	_, err = tx.Exec(`UPDATE pack_configurations SET is_default = false, updated_at = CURRENT_TIMESTAMP WHERE is_default = true`)
+	snapshot, err := r.getVersion(ctx, tx, tenant, id, version)
 	if err != nil {
-		return fmt.Errorf("failed to unset existing defaults: %w", err)
+		return nil, err
 	}
 
-	// Then set the new default
-	result, err := tx.Exec(`UPDATE pack_configurations SET is_default = true, updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND is_active = true`, id)
+	before, err := r.lockConfiguration(ctx, tx, tenant, id)
 	if err != nil {
-		return fmt.Errorf("failed to set new default: %w", err)
+		return nil, err
+	}
+
+	restored := &entity.PackConfiguration{}
+	snapshot.Restore(restored)
+	packSizes, err := intSliceToInt64Array(restored.PackSizes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert pack sizes: %w", err)
+	}
+
+	// Pack types deleted since, or whose item count the snapshot does not
+	// offer, are dropped
+	packTypeIDs, err := r.offeredPackTypes(ctx, tx, tenant, restored)
+	if err != nil {
+		return nil, err
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	query := `
+		UPDATE pack_configurations
+		SET name = $1, pack_sizes = $2, updated_at = CURRENT_TIMESTAMP, version = version + 1, pack_type_ids = $5,
+			effective_from = $6, effective_to = $7, description = $8, labels = $9, metadata = $10
+		WHERE id = $3 AND tenant_id = $4 AND is_active = true
+		RETURNING id, tenant_id, name, pack_sizes, is_default, is_active, version, created_at, updated_at, effective_from, effective_to, description, labels, metadata, pack_type_ids
+	`
+
+	config, err := r.scanPackConfiguration(tx.QueryRowContext(ctx, query, restored.Name, packSizes, id, tenant,
+		packTypeIDsArg(packTypeIDs), restored.EffectiveFrom, restored.EffectiveTo,
+		restored.Description, labelsArg(restored.Labels), metadataArg(restored.Metadata)))
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("pack configuration with id %d not found or inactive: %w", id, errs.ErrPackConfigurationNotFound)
+		}
+		if conflict := r.nameConflict(ctx, err, tenant, restored.Name, id); conflict != nil {
+			return nil, conflict
+		}
+		if overlap := r.defaultOverlap(ctx, err, tenant, id, restored.EffectivePeriod); overlap != nil {
+			return nil, overlap
+		}
+		return nil, fmt.Errorf("failed to restore pack configuration: %w", err)
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("pack configuration with id %d not found or inactive", id)
+	if err := r.insertVersion(ctx, tx, config); err != nil {
+		return nil, err
+	}
+
+	if err := recordEvent(ctx, tx, entity.EventPackConfigurationUpdated, config, nil); err != nil {
+		return nil, err
+	}
+
+	if err := recordAudit(ctx, tx, entity.AuditActionRestore, before, config); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit pack configuration restore: %w", err)
+	}
+
+	return config, nil
+}
+
+func (r *PackConfigurationRepository) getVersion(ctx context.Context, q contextQueryer, tenant string, id int, version int) (*entity.PackConfigurationVersion, error) {
+	query := `
+		SELECT ` + packConfigurationVersionColumns + `
+		FROM pack_configuration_versions v
+		JOIN pack_configurations pc ON pc.id = v.configuration_id
+		WHERE v.configuration_id = $1 AND v.version = $2 AND pc.tenant_id = $3
+	`
+
+	snapshot, err := scanPackConfigurationVersion(q.QueryRowContext(ctx, query, id, version, tenant))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("pack configuration %d version %d: %w", id, version, errs.ErrConfigurationVersionNotFound)
+		}
+		return nil, fmt.Errorf("failed to get pack configuration version: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+// packConfigurationVersionColumns are the snapshot columns scanPackConfigurationVersion reads
+const packConfigurationVersionColumns = `v.configuration_id, v.version, v.name, v.pack_sizes, v.pack_type_ids,
+		v.effective_from, v.effective_to, v.description, v.labels, v.metadata, v.created_at`
+
+// insertVersion snapshots the configuration's current contents under its current version
+func (r *PackConfigurationRepository) insertVersion(ctx context.Context, tx *sql.Tx, config *entity.PackConfiguration) error {
+	packSizes, err := intSliceToInt64Array(config.PackSizes)
+	if err != nil {
+		return fmt.Errorf("failed to convert pack sizes: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO pack_configuration_versions (configuration_id, version, name, pack_sizes, pack_type_ids,
+			effective_from, effective_to, description, labels, metadata)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, config.ID, config.Version, config.Name, packSizes, packTypeIDsArg(config.PackTypeIDs),
+		config.EffectiveFrom, config.EffectiveTo, config.Description, labelsArg(config.Labels), metadataArg(config.Metadata))
+	if err != nil {
+		return fmt.Errorf("failed to record pack configuration version: %w", err)
+	}
+
+	return nil
+}
+
+func scanPackConfigurationVersion(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*entity.PackConfigurationVersion, error) {
+	snapshot := &entity.PackConfigurationVersion{}
+	var packSizes, packTypeIDs pq.Int64Array
+	var labels, metadata []byte
+
+	err := scanner.Scan(
+		&snapshot.ConfigurationID,
+		&snapshot.Version,
+		&snapshot.Name,
+		&packSizes,
+		&packTypeIDs,
+		&snapshot.EffectiveFrom,
+		&snapshot.EffectiveTo,
+		&snapshot.Description,
+		&labels,
+		&metadata,
+		&snapshot.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot.PackSizes, err = int64ArrayToIntSlice(packSizes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert pack sizes: %w", err)
+	}
+	if len(packTypeIDs) > 0 {
+		snapshot.PackTypeIDs, err = int64ArrayToIntSlice(packTypeIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert pack type IDs: %w", err)
+		}
+	}
+
+	if err := json.Unmarshal(labels, &snapshot.Labels); err != nil {
+		return nil, fmt.Errorf("failed to decode labels: %w", err)
+	}
+	if len(snapshot.Labels) == 0 {
+		snapshot.Labels = nil
+	}
+	if metadata != nil {
+		snapshot.Metadata = json.RawMessage(metadata)
+	}
+
+	return snapshot, nil
+}
+
+func (r *PackConfigurationRepository) GetArchivedByID(ctx context.Context, tenant string, id int) (_ *entity.PackConfiguration, err error) {
+	ctx, finish := db.WithQueryTimeout(ctx, r.queryTimeout, "GetArchivedByID")
+	defer finish(&err)
+
+	query := `
+		SELECT id, tenant_id, name, pack_sizes, is_default, is_active, version, created_at, updated_at, effective_from, effective_to, description, labels, metadata, pack_type_ids
+		FROM pack_configurations
+		WHERE id = $1 AND tenant_id = $2 AND is_active = false
+	`
+
+	config, err := r.scanPackConfiguration(r.db.QueryRowContext(ctx, query, id, tenant))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("archived pack configuration with id %d: %w", id, errs.ErrPackConfigurationNotFound)
+		}
+		return nil, fmt.Errorf("failed to get archived pack configuration: %w", err)
+	}
+
+	return config, nil
+}
+
+func (r *PackConfigurationRepository) Restore(ctx context.Context, tenant string, id int, name string) (_ *entity.PackConfiguration, err error) {
+	ctx, finish := db.WithQueryTimeout(ctx, r.queryTimeout, "Restore")
+	defer finish(&err)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	archived, err := r.lockConfiguration(ctx, tx, tenant, id)
+	if err != nil {
+		return nil, err
+	}
+	if archived == nil || archived.IsActive {
+		return nil, fmt.Errorf("archived pack configuration with id %d: %w", id, errs.ErrPackConfigurationNotFound)
+	}
+
+	if name == "" {
+		name = archived.Name
+	}
+
+	var conflictID int
+	err = tx.QueryRowContext(ctx, `
+		SELECT id FROM pack_configurations
+		WHERE tenant_id = $1 AND is_active = true AND lower(name) = lower($2) AND id <> $3
+		LIMIT 1
+	`, tenant, name, id).Scan(&conflictID)
+	if err == nil {
+		return nil, &errs.NameConflictError{Name: name, ExistingID: conflictID}
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to check pack configuration name: %w", err)
+	}
+
+	// Renaming, or dropping pack types deleted or changed while archived,
+	// changes the configuration's contents, so it gets a new version. The
+	// restored configuration comes back as a non-default; making it the
+	// default again is up to SetDefault.
+	packTypeIDs, err := r.offeredPackTypes(ctx, tx, tenant, archived)
+	if err != nil {
+		return nil, err
+	}
+	changed := name != archived.Name || len(packTypeIDs) != len(archived.PackTypeIDs)
+	query := `
+		UPDATE pack_configurations
+		SET is_active = true,
+			name = $1,
+			pack_type_ids = $5,
+			version = CASE WHEN $2 THEN version + 1 ELSE version END,
+			is_default = false,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND tenant_id = $4
+		RETURNING id, tenant_id, name, pack_sizes, is_default, is_active, version, created_at, updated_at, effective_from, effective_to, description, labels, metadata, pack_type_ids
+	`
+
+	config, err := r.scanPackConfiguration(tx.QueryRowContext(ctx, query, name, changed, id, tenant, packTypeIDsArg(packTypeIDs)))
+	if err != nil {
+		if conflict := r.nameConflict(ctx, err, tenant, name, id); conflict != nil {
+			return nil, conflict
+		}
+		return nil, fmt.Errorf("failed to restore pack configuration: %w", err)
+	}
+
+	if changed {
+		if err := r.insertVersion(ctx, tx, config); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := recordEvent(ctx, tx, entity.EventPackConfigurationRestored, config, nil); err != nil {
+		return nil, err
+	}
+
+	if err := recordAudit(ctx, tx, entity.AuditActionUnarchive, archived, config); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit pack configuration restore: %w", err)
+	}
+
+	return config, nil
+}
+
+func (r *PackConfigurationRepository) Purge(ctx context.Context, tenant string, id int) (err error) {
+	ctx, finish := db.WithQueryTimeout(ctx, r.queryTimeout, "Purge")
+	defer finish(&err)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	archived, err := r.lockConfiguration(ctx, tx, tenant, id)
+	if err != nil {
+		return err
+	}
+	if archived == nil {
+		return fmt.Errorf("pack configuration with id %d: %w", id, errs.ErrPackConfigurationNotFound)
+	}
+	if archived.IsActive {
+		return errs.ErrConfigurationNotArchived.Withf("pack configuration %d must be deleted before it is purged", id)
+	}
+
+	var warehouses int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM warehouses WHERE pack_configuration_id = $1`, id).Scan(&warehouses); err != nil {
+		return fmt.Errorf("failed to check warehouse references: %w", err)
+	}
+	if warehouses > 0 {
+		return errs.ErrConfigurationInUse.Withf("pack configuration %d is referenced by %d warehouses", id, warehouses)
+	}
+
+	// Stored calculations keep their own copy of the pack sizes, so they
+	// survive the purge without the reference
+	statements := []string{
+		`UPDATE calculations SET configuration_id = NULL WHERE configuration_id = $1`,
+		`DELETE FROM pack_configuration_versions WHERE configuration_id = $1`,
+		`DELETE FROM pack_configurations WHERE id = $1`,
+	}
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement, id); err != nil {
+			return fmt.Errorf("failed to purge pack configuration: %w", err)
+		}
+	}
+
+	if err := recordAudit(ctx, tx, entity.AuditActionPurge, archived, nil); err != nil {
+		return err
 	}
 
 	return tx.Commit()
 }
+
+func (r *PackConfigurationRepository) Import(ctx context.Context, imp entity.PackConfigurationImport) (err error) {
+	ctx, finish := db.WithQueryTimeout(ctx, r.queryTimeout, "Import")
+	defer finish(&err)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	defaultID := imp.DefaultID
+	// The configurations the import writes, and what those it changes held before
+	var imported []int
+	before := make(map[int]*entity.PackConfiguration)
+	for _, config := range imp.Creates {
+		if err := config.Validate(); err != nil {
+			return err
+		}
+		packSizes, err := intSliceToInt64Array(config.PackSizes)
+		if err != nil {
+			return fmt.Errorf("failed to convert pack sizes: %w", err)
+		}
+		if err := r.checkPackTypes(ctx, tx, imp.Tenant, config); err != nil {
+			return err
+		}
+
+		created, err := r.scanPackConfiguration(tx.QueryRowContext(ctx, `
+			INSERT INTO pack_configurations (tenant_id, name, pack_sizes, is_default, is_active, version, created_at, updated_at, effective_from, effective_to, description, labels, metadata, pack_type_ids)
+			VALUES ($1, $2, $3, false, true, 1, $4, $5, $6, $7, $8, $9::jsonb, $10::jsonb, $11)
+			RETURNING id, tenant_id, name, pack_sizes, is_default, is_active, version, created_at, updated_at, effective_from, effective_to, description, labels, metadata, pack_type_ids
+		`, imp.Tenant, config.Name, packSizes, config.CreatedAt, config.UpdatedAt, config.EffectiveFrom, config.EffectiveTo,
+			config.Description, labelsArg(config.Labels), metadataArg(config.Metadata), packTypeIDsArg(config.PackTypeIDs)))
+		if err != nil {
+			if conflict := r.nameConflict(ctx, err, imp.Tenant, config.Name, 0); conflict != nil {
+				return conflict
+			}
+			return fmt.Errorf("failed to import pack configuration %q: %w", config.Name, err)
+		}
+		config.ID, config.Version = created.ID, created.Version
+		imported = append(imported, config.ID)
+		if err := r.insertVersion(ctx, tx, config); err != nil {
+			return err
+		}
+		if err := recordEvent(ctx, tx, entity.EventPackConfigurationCreated, created, nil); err != nil {
+			return err
+		}
+		if config.IsDefault {
+			defaultID = config.ID
+		}
+	}
+
+	for _, config := range imp.Updates {
+		if err := config.Validate(); err != nil {
+			return err
+		}
+		packSizes, err := intSliceToInt64Array(config.PackSizes)
+		if err != nil {
+			return fmt.Errorf("failed to convert pack sizes: %w", err)
+		}
+
+		if before[config.ID], err = r.lockConfiguration(ctx, tx, imp.Tenant, config.ID); err != nil {
+			return err
+		}
+		imported = append(imported, config.ID)
+		if err := r.checkPackTypes(ctx, tx, imp.Tenant, config); err != nil {
+			return err
+		}
+
+		expected := config.Version
+		updated, err := r.scanPackConfiguration(tx.QueryRowContext(ctx, `
+			UPDATE pack_configurations
+			SET name = $1, pack_sizes = $2, created_at = $3, updated_at = $4, effective_from = $5, effective_to = $6, version = version + 1,
+				description = $10, labels = $11::jsonb, metadata = $12::jsonb, pack_type_ids = $13
+			WHERE id = $7 AND tenant_id = $8 AND is_active = true AND version = $9
+			RETURNING id, tenant_id, name, pack_sizes, is_default, is_active, version, created_at, updated_at, effective_from, effective_to, description, labels, metadata, pack_type_ids
+		`, config.Name, packSizes, config.CreatedAt, config.UpdatedAt, config.EffectiveFrom, config.EffectiveTo, config.ID, imp.Tenant, expected,
+			config.Description, labelsArg(config.Labels), metadataArg(config.Metadata), packTypeIDsArg(config.PackTypeIDs)))
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return r.versionConflict(ctx, tx, imp.Tenant, config.ID, expected)
+			}
+			if conflict := r.nameConflict(ctx, err, imp.Tenant, config.Name, config.ID); conflict != nil {
+				return conflict
+			}
+			if overlap := r.defaultOverlap(ctx, err, imp.Tenant, config.ID, config.EffectivePeriod); overlap != nil {
+				return overlap
+			}
+			return fmt.Errorf("failed to import pack configuration %q: %w", config.Name, err)
+		}
+		config.Version = updated.Version
+		if err := r.insertVersion(ctx, tx, config); err != nil {
+			return err
+		}
+		if err := recordEvent(ctx, tx, entity.EventPackConfigurationUpdated, updated, nil); err != nil {
+			return err
+		}
+		if config.IsDefault {
+			defaultID = config.ID
+		}
+	}
+
+	if defaultID != 0 {
+		if !slices.Contains(imported, defaultID) {
+			if before[defaultID], err = r.lockConfiguration(ctx, tx, imp.Tenant, defaultID); err != nil {
+				return err
+			}
+		}
+		if err := r.importDefault(ctx, tx, imp.Tenant, defaultID, imported); err != nil {
+			return err
+		}
+	}
+
+	if err := r.auditImport(ctx, tx, imp.Tenant, imported, defaultID, before); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit pack configuration import: %w", err)
+	}
+	return nil
+}
+
+// auditImport records each configuration the import wrote, and the default
+// it moved onto a configuration it left alone, as the import leaves them
+func (r *PackConfigurationRepository) auditImport(ctx context.Context, tx *sql.Tx, tenant string, imported []int, defaultID int, before map[int]*entity.PackConfiguration) error {
+	if _, audited := entity.ActorFrom(ctx); !audited {
+		return nil
+	}
+
+	if defaultID != 0 && !slices.Contains(imported, defaultID) {
+		after, err := r.lockConfiguration(ctx, tx, tenant, defaultID)
+		if err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, tx, entity.AuditActionSetDefault, before[defaultID], after); err != nil {
+			return err
+		}
+	}
+
+	for _, id := range imported {
+		after, err := r.lockConfiguration(ctx, tx, tenant, id)
+		if err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, tx, entity.AuditActionImport, before[id], after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importDefault moves the default flag to id, like moveDefault, without
+// touching timestamps, which an import takes from the file
+func (r *PackConfigurationRepository) importDefault(ctx context.Context, tx *sql.Tx, tenant string, id int, imported []int) error {
+	if err := lockDefaults(ctx, tx, tenant); err != nil {
+		return err
+	}
+
+	previousDefaultIDs, err := r.unsetOverlappingDefaults(ctx, tx, tenant, id, false, imported)
+	if err != nil {
+		return err
+	}
+
+	var previousVersion int
+	err = tx.QueryRowContext(ctx, `SELECT version FROM pack_configurations WHERE id = $1 AND tenant_id = $2`, id, tenant).Scan(&previousVersion)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to set imported default: %w", err)
+	}
+
+	config, err := r.scanPackConfiguration(tx.QueryRowContext(ctx, `
+		UPDATE pack_configurations
+		SET is_default = true,
+			version = CASE WHEN is_default OR id = ANY($3) THEN version ELSE version + 1 END
+		WHERE id = $1 AND tenant_id = $2 AND is_active = true
+		RETURNING id, tenant_id, name, pack_sizes, is_default, is_active, version, created_at, updated_at, effective_from, effective_to, description, labels, metadata, pack_type_ids
+	`, id, tenant, pq.Array(imported)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("pack configuration with id %d: %w", id, errs.ErrPackConfigurationNotFound)
+		}
+		return fmt.Errorf("failed to set imported default: %w", err)
+	}
+	if config.Version != previousVersion {
+		if err := r.insertVersion(ctx, tx, config); err != nil {
+			return err
+		}
+	}
+	return recordEvent(ctx, tx, entity.EventPackConfigurationDefaultChanged, config, previousDefaultIDs)
+}
+
+// lockConfiguration reads the tenant's configuration id, active or archived,
+// locking it until tx ends, or returns nil when there is none
+func (r *PackConfigurationRepository) lockConfiguration(ctx context.Context, tx *sql.Tx, tenant string, id int) (*entity.PackConfiguration, error) {
+	config, err := r.scanPackConfiguration(tx.QueryRowContext(ctx, `
+		SELECT id, tenant_id, name, pack_sizes, is_default, is_active, version, created_at, updated_at, effective_from, effective_to, description, labels, metadata, pack_type_ids
+		FROM pack_configurations
+		WHERE id = $1 AND tenant_id = $2
+		FOR UPDATE
+	`, id, tenant))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock pack configuration: %w", err)
+	}
+	return config, nil
+}
+
+// lockPackTypes reads the state of the pack types the configuration
+// references, share-locking their rows until tx ends so that pack type
+// writes, which lock the row before checking for references, wait for it
+func (r *PackConfigurationRepository) lockPackTypes(ctx context.Context, tx *sql.Tx, tenant string, config *entity.PackConfiguration) ([]*entity.PackType, error) {
+	if len(config.PackTypeIDs) == 0 {
+		return nil, nil
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, item_count, is_active FROM pack_types
+		WHERE tenant_id = $1 AND id = ANY($2)
+		ORDER BY id
+		FOR SHARE
+	`, tenant, packTypeIDsArg(config.PackTypeIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock referenced pack types: %w", err)
+	}
+	defer rows.Close()
+
+	var packTypes []*entity.PackType
+	for rows.Next() {
+		packType := &entity.PackType{}
+		if err := rows.Scan(&packType.ID, &packType.ItemCount, &packType.IsActive); err != nil {
+			return nil, fmt.Errorf("failed to scan pack type: %w", err)
+		}
+		packTypes = append(packTypes, packType)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	return packTypes, nil
+}
+
+// checkPackTypes fails when a pack type the configuration references was
+// deleted or changed after the service resolved it
+func (r *PackConfigurationRepository) checkPackTypes(ctx context.Context, tx *sql.Tx, tenant string, config *entity.PackConfiguration) error {
+	packTypes, err := r.lockPackTypes(ctx, tx, tenant, config)
+	if err != nil {
+		return err
+	}
+	return config.CheckPackTypes(packTypes)
+}
+
+// offeredPackTypes keeps the referenced pack types that are active and whose
+// item count the configuration offers
+func (r *PackConfigurationRepository) offeredPackTypes(ctx context.Context, tx *sql.Tx, tenant string, config *entity.PackConfiguration) ([]int, error) {
+	packTypes, err := r.lockPackTypes(ctx, tx, tenant, config)
+	if err != nil {
+		return nil, err
+	}
+	return config.OfferedPackTypeIDs(packTypes), nil
+}
+
+// recordAudit appends the change to the audit log in its transaction when ctx
+// carries an actor, so a change is never committed without its entry
+func recordAudit(ctx context.Context, tx *sql.Tx, action entity.AuditAction, before, after *entity.PackConfiguration) error {
+	entry, err := entity.PackConfigurationAuditEntry(ctx, action, before, after)
+	if err != nil {
+		return fmt.Errorf("failed to build audit entry: %w", err)
+	}
+	if entry == nil {
+		return nil
+	}
+	return insertAuditEntry(ctx, tx, entry)
+}
+
+// recordEvent adds eventType, with the configuration as the change left it,
+// to the outbox in the change's transaction
+func recordEvent(ctx context.Context, tx *sql.Tx, eventType entity.EventType, config *entity.PackConfiguration, previousDefaultIDs []int) error {
+	return insertOutboxEvent(ctx, tx, config.Tenant, eventType, entity.PackConfigurationEvent{
+		Configuration:      config,
+		PreviousDefaultIDs: previousDefaultIDs,
+	})
+}
+
+// likeEscaper escapes LIKE wildcards so a search is matched literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// packConfigurationQueryClause builds a WHERE clause whose predicates are
+// served by the listing indexes: tenant, status and created range by
+// (tenant_id, is_active, created_at), the name prefix by lower(name)
+// text_pattern_ops, the pack size by the GIN index on pack_sizes and the
+// label selector by the GIN index on labels.
+func packConfigurationQueryClause(query entity.PackConfigurationQuery) (string, []interface{}) {
+	args := []interface{}{query.Tenant, !query.Archived}
+	conditions := []string{"tenant_id = $1", "is_active = $2"}
+
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if query.Search != "" {
+		add("lower(name) LIKE $%d", likeEscaper.Replace(strings.ToLower(query.Search))+"%")
+	}
+	if query.PackSize != nil {
+		add("pack_sizes @> ARRAY[$%d]::integer[]", *query.PackSize)
+	}
+	if len(query.Labels) > 0 {
+		add("labels @> $%d::jsonb", labelsArg(query.Labels))
+	}
+	if query.CreatedFrom != nil {
+		add("created_at >= $%d", *query.CreatedFrom)
+	}
+	if query.CreatedTo != nil {
+		add("created_at < $%d", *query.CreatedTo)
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// packConfigurationOrderClause maps the requested sort onto a fixed column
+// list, with id as a tie-breaker so pages are stable
+func packConfigurationOrderClause(query entity.PackConfigurationQuery) string {
+	direction := "ASC"
+	if query.Descending {
+		direction = "DESC"
+	}
+
+	switch query.Sort {
+	case entity.PackConfigurationSortName:
+		return fmt.Sprintf("lower(name) %s, id %s", direction, direction)
+	case entity.PackConfigurationSortCreatedAt:
+		return fmt.Sprintf("created_at %s, id %s", direction, direction)
+	case entity.PackConfigurationSortUpdatedAt:
+		return fmt.Sprintf("updated_at %s, id %s", direction, direction)
+	}
+
+	if query.Archived {
+		return "updated_at DESC, id DESC"
+	}
+	return "is_default DESC, created_at DESC, id DESC"
+}
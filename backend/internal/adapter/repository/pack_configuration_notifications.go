@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// PackConfigurationChangesChannel is the channel a trigger on
+// pack_configurations notifies on commit, with the changed configuration's
+// tenant as payload
+const PackConfigurationChangesChannel = "pack_configuration_changes"
+
+// packConfigurationListenerPing is how often an idle listener checks its
+// connection, so that a silently dropped one is noticed and re-established
+const packConfigurationListenerPing = time.Minute
+
+// PackConfigurationInvalidator drops what it keeps of changed configurations
+type PackConfigurationInvalidator interface {
+	Invalidate(tenant string)
+	InvalidateAll()
+}
+
+// ListenForPackConfigurationChanges reports every configuration change
+// committed to the Postgres dsn names, by any replica, to invalidator. While
+// the connection is down changes go unseen, so invalidator drops everything
+// when it is lost and again once it is back. The returned function stops
+// listening.
+func ListenForPackConfigurationChanges(dsn string, invalidator PackConfigurationInvalidator) (func(), error) {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		switch event {
+		case pq.ListenerEventDisconnected:
+			slog.Warn("Lost the pack configuration change listener connection", "error", err)
+			invalidator.InvalidateAll()
+		case pq.ListenerEventReconnected:
+			slog.Info("Pack configuration change listener reconnected")
+			invalidator.InvalidateAll()
+		case pq.ListenerEventConnectionAttemptFailed:
+			slog.Warn("Failed to reconnect the pack configuration change listener", "error", err)
+		}
+	})
+	if err := listener.Listen(PackConfigurationChangesChannel); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(packConfigurationListenerPing)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case notification := <-listener.Notify:
+				// A nil notification follows a reconnect, which the event
+				// callback has already handled
+				if notification != nil {
+					invalidator.Invalidate(notification.Extra)
+				}
+			case <-ticker.C:
+				go listener.Ping()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	slog.Info("Listening for pack configuration changes", "channel", PackConfigurationChangesChannel)
+	return func() {
+		close(done)
+		listener.Close()
+	}, nil
+}
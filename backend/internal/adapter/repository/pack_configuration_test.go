@@ -3,10 +3,13 @@ package repository
 import (
 	"math"
 	"testing"
+	"time"
 
 	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
 )
 
 func TestIntSliceToInt64Array(t *testing.T) {
@@ -137,3 +140,70 @@ func TestRoundTripConversion(t *testing.T) {
 	// Should be identical to original
 	assert.Equal(t, original, result)
 }
+
+func TestPackConfigurationQueryClause(t *testing.T) {
+	packSize := 250
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		query         entity.PackConfigurationQuery
+		expectedWhere string
+		expectedArgs  []interface{}
+	}{
+		{
+			name:          "active only",
+			query:         entity.PackConfigurationQuery{Tenant: "default", Limit: 50},
+			expectedWhere: " WHERE tenant_id = $1 AND is_active = $2",
+			expectedArgs:  []interface{}{"default", true},
+		},
+		{
+			name:          "archived search escapes wildcards",
+			query:         entity.PackConfigurationQuery{Tenant: "retail", Archived: true, Search: "Big_50%"},
+			expectedWhere: " WHERE tenant_id = $1 AND is_active = $2 AND lower(name) LIKE $3",
+			expectedArgs:  []interface{}{"retail", false, `big\_50\%%`},
+		},
+		{
+			name: "all filters",
+			query: entity.PackConfigurationQuery{
+				Tenant:      "retail",
+				Search:      "std",
+				PackSize:    &packSize,
+				Labels:      entity.Labels{"region": "eu", "customer": "acme"},
+				CreatedFrom: &from,
+				CreatedTo:   &to,
+			},
+			expectedWhere: " WHERE tenant_id = $1 AND is_active = $2 AND lower(name) LIKE $3 AND pack_sizes @> ARRAY[$4]::integer[] AND labels @> $5::jsonb AND created_at >= $6 AND created_at < $7",
+			expectedArgs:  []interface{}{"retail", true, "std%", 250, `{"customer":"acme","region":"eu"}`, from, to},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, args := packConfigurationQueryClause(tt.query)
+
+			assert.Equal(t, tt.expectedWhere, where)
+			assert.Equal(t, tt.expectedArgs, args)
+		})
+	}
+}
+
+func TestPackConfigurationOrderClause(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    entity.PackConfigurationQuery
+		expected string
+	}{
+		{"default active", entity.PackConfigurationQuery{}, "is_default DESC, created_at DESC, id DESC"},
+		{"default archived", entity.PackConfigurationQuery{Archived: true}, "updated_at DESC, id DESC"},
+		{"name ascending", entity.PackConfigurationQuery{Sort: entity.PackConfigurationSortName}, "lower(name) ASC, id ASC"},
+		{"created descending", entity.PackConfigurationQuery{Sort: entity.PackConfigurationSortCreatedAt, Descending: true}, "created_at DESC, id DESC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, packConfigurationOrderClause(tt.query))
+		})
+	}
+}
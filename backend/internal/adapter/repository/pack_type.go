@@ -0,0 +1,275 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+	"github.com/lib/pq"
+)
+
+const packTypeColumns = `id, tenant_id, code, label, item_count, length_mm, width_mm, height_mm, cost_cents, is_active, created_at, updated_at`
+
+type PackTypeRepository struct {
+	db *sql.DB
+}
+
+func NewPackTypeRepository(db *sql.DB) *PackTypeRepository {
+	return &PackTypeRepository{
+		db: db,
+	}
+}
+
+func (r *PackTypeRepository) scanPackType(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*entity.PackType, error) {
+	packType := &entity.PackType{}
+
+	err := scanner.Scan(
+		&packType.ID,
+		&packType.Tenant,
+		&packType.Code,
+		&packType.Label,
+		&packType.ItemCount,
+		&packType.LengthMM,
+		&packType.WidthMM,
+		&packType.HeightMM,
+		&packType.CostCents,
+		&packType.IsActive,
+		&packType.CreatedAt,
+		&packType.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return packType, nil
+}
+
+func (r *PackTypeRepository) query(ctx context.Context, query string, args ...interface{}) ([]*entity.PackType, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pack types: %w", err)
+	}
+	defer rows.Close()
+
+	packTypes := []*entity.PackType{}
+	for rows.Next() {
+		packType, err := r.scanPackType(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan pack type: %w", err)
+		}
+		packTypes = append(packTypes, packType)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return packTypes, nil
+}
+
+func (r *PackTypeRepository) GetAll(ctx context.Context, tenant string, includeInactive bool) ([]*entity.PackType, error) {
+	query := `
+		SELECT ` + packTypeColumns + `
+		FROM pack_types
+		WHERE tenant_id = $1 AND (is_active = true OR $2)
+		ORDER BY lower(code) ASC
+	`
+
+	return r.query(ctx, query, tenant, includeInactive)
+}
+
+func (r *PackTypeRepository) GetByID(ctx context.Context, tenant string, id int) (*entity.PackType, error) {
+	query := `
+		SELECT ` + packTypeColumns + `
+		FROM pack_types
+		WHERE id = $1 AND tenant_id = $2
+	`
+
+	packType, err := r.scanPackType(r.db.QueryRowContext(ctx, query, id, tenant))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("pack type with id %d: %w", id, errs.ErrPackTypeNotFound)
+		}
+		return nil, fmt.Errorf("failed to get pack type: %w", err)
+	}
+
+	return packType, nil
+}
+
+func (r *PackTypeRepository) GetByIDs(ctx context.Context, tenant string, ids []int) ([]*entity.PackType, error) {
+	query := `
+		SELECT ` + packTypeColumns + `
+		FROM pack_types
+		WHERE tenant_id = $1 AND id = ANY($2)
+		ORDER BY id ASC
+	`
+
+	return r.query(ctx, query, tenant, packTypeIDsArg(ids))
+}
+
+func (r *PackTypeRepository) GetByCodes(ctx context.Context, tenant string, codes []string) ([]*entity.PackType, error) {
+	lowered := make([]string, len(codes))
+	for i, code := range codes {
+		lowered[i] = strings.ToLower(code)
+	}
+
+	query := `
+		SELECT ` + packTypeColumns + `
+		FROM pack_types
+		WHERE tenant_id = $1 AND is_active = true AND lower(code) = ANY($2)
+		ORDER BY id ASC
+	`
+
+	return r.query(ctx, query, tenant, pq.StringArray(lowered))
+}
+
+func (r *PackTypeRepository) Create(ctx context.Context, packType *entity.PackType) (*entity.PackType, error) {
+	if err := packType.Validate(); err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO pack_types (tenant_id, code, label, item_count, length_mm, width_mm, height_mm, cost_cents, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		packType.Tenant,
+		packType.Code,
+		packType.Label,
+		packType.ItemCount,
+		packType.LengthMM,
+		packType.WidthMM,
+		packType.HeightMM,
+		packType.CostCents,
+		packType.IsActive,
+	).Scan(
+		&packType.ID,
+		&packType.CreatedAt,
+		&packType.UpdatedAt,
+	)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			return nil, fmt.Errorf("code %q: %w", packType.Code, errs.ErrPackTypeCodeConflict)
+		}
+		return nil, fmt.Errorf("failed to create pack type: %w", err)
+	}
+
+	return packType, nil
+}
+
+// Update and Delete lock the pack type's row before counting the active
+// configurations referencing it, and configuration writes share-lock the
+// rows of the pack types they reference, so neither can change under the
+// other's check.
+func (r *PackTypeRepository) Update(ctx context.Context, packType *entity.PackType) (*entity.PackType, error) {
+	if err := packType.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var itemCount int
+	err = tx.QueryRowContext(ctx, `SELECT item_count FROM pack_types WHERE id = $1 AND tenant_id = $2 FOR UPDATE`, packType.ID, packType.Tenant).Scan(&itemCount)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("pack type with id %d: %w", packType.ID, errs.ErrPackTypeNotFound)
+		}
+		return nil, fmt.Errorf("failed to lock pack type: %w", err)
+	}
+	if itemCount != packType.ItemCount {
+		if err := inUse(ctx, tx, packType.Tenant, packType.ID, "change the item count of"); err != nil {
+			return nil, err
+		}
+	}
+
+	query := `
+		UPDATE pack_types
+		SET code = $1, label = $2, item_count = $3, length_mm = $4, width_mm = $5, height_mm = $6, cost_cents = $7,
+			is_active = $8, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $9 AND tenant_id = $10
+		RETURNING created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		packType.Code,
+		packType.Label,
+		packType.ItemCount,
+		packType.LengthMM,
+		packType.WidthMM,
+		packType.HeightMM,
+		packType.CostCents,
+		packType.IsActive,
+		packType.ID,
+		packType.Tenant,
+	).Scan(
+		&packType.CreatedAt,
+		&packType.UpdatedAt,
+	)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			return nil, fmt.Errorf("code %q: %w", packType.Code, errs.ErrPackTypeCodeConflict)
+		}
+		return nil, fmt.Errorf("failed to update pack type: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit pack type update: %w", err)
+	}
+	return packType, nil
+}
+
+func (r *PackTypeRepository) Delete(ctx context.Context, tenant string, id int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var locked int
+	err = tx.QueryRowContext(ctx, `SELECT id FROM pack_types WHERE id = $1 AND tenant_id = $2 AND is_active = true FOR UPDATE`, id, tenant).Scan(&locked)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("pack type with id %d: %w", id, errs.ErrPackTypeNotFound)
+		}
+		return fmt.Errorf("failed to lock pack type: %w", err)
+	}
+	if err := inUse(ctx, tx, tenant, id, "delete"); err != nil {
+		return err
+	}
+
+	query := `UPDATE pack_types SET is_active = false, updated_at = CURRENT_TIMESTAMP WHERE id = $1`
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to delete pack type: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// inUse refuses the action while active configurations reference the pack type
+func inUse(ctx context.Context, tx *sql.Tx, tenant string, id int, action string) error {
+	query := `
+		SELECT COUNT(*)
+		FROM pack_configurations
+		WHERE tenant_id = $1 AND is_active = true AND $2 = ANY(pack_type_ids)
+	`
+
+	var count int
+	if err := tx.QueryRowContext(ctx, query, tenant, id).Scan(&count); err != nil {
+		return fmt.Errorf("failed to count configurations referencing pack type: %w", err)
+	}
+	if count > 0 {
+		return errs.ErrPackTypeInUse.Withf("cannot %s pack type %d: %d active pack configuration(s) reference it", action, id, count)
+	}
+	return nil
+}
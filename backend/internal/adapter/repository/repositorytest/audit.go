@@ -0,0 +1,191 @@
+package repositorytest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+)
+
+// AuditedRepositoryFactory returns an empty configurations repository and
+// the audit log its writes append to, for one test
+type AuditedRepositoryFactory func(t *testing.T) (entity.PackConfigurationRepository, entity.AuditRepository)
+
+// RunPackConfigurationAuditTests runs the shared auditing behaviour against
+// the repositories newRepositories returns, each subtest with fresh ones
+func RunPackConfigurationAuditTests(t *testing.T, newRepositories AuditedRepositoryFactory) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, repo entity.PackConfigurationRepository, audit entity.AuditRepository)
+	}{
+		{"AuditsWrites", testAuditsWrites},
+		{"AuditsImports", testAuditsImports},
+		{"SkipsFailedAndAnonymousWrites", testSkipsFailedAndAnonymousWrites},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, audit := newRepositories(t)
+			tt.test(t, repo, audit)
+		})
+	}
+}
+
+var auditActor = entity.Actor{Subject: "alice", Tenant: entity.DefaultTenant, RequestID: "req-1"}
+
+func asActor(t *testing.T) context.Context {
+	return entity.WithActor(t.Context(), auditActor)
+}
+
+// auditEntries lists the tenant's entries, oldest first
+func auditEntries(t *testing.T, audit entity.AuditRepository) []*entity.AuditEntry {
+	t.Helper()
+	entries, _, err := audit.List(entity.AuditFilter{Tenant: entity.DefaultTenant, Limit: 100})
+	require.NoError(t, err)
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries
+}
+
+// auditSnapshot decodes one side of an entry, nil when it is absent
+func auditSnapshot(t *testing.T, data json.RawMessage) *entity.PackConfiguration {
+	t.Helper()
+	if data == nil {
+		return nil
+	}
+	config := &entity.PackConfiguration{}
+	require.NoError(t, json.Unmarshal(data, config))
+	return config
+}
+
+func testAuditsWrites(t *testing.T, repo entity.PackConfigurationRepository, audit entity.AuditRepository) {
+	config, err := entity.NewPackConfiguration(entity.DefaultTenant, "Standard", []int{250, 500})
+	require.NoError(t, err)
+	config, err = repo.Create(asActor(t), config)
+	require.NoError(t, err)
+
+	config.Name = "Renamed"
+	config, err = repo.Update(asActor(t), config)
+	require.NoError(t, err)
+	require.NoError(t, repo.SetDefault(asActor(t), entity.DefaultTenant, config.ID, config.Version))
+	_, err = repo.RestoreVersion(asActor(t), entity.DefaultTenant, config.ID, 1)
+	require.NoError(t, err)
+
+	spare := createConfiguration(t, repo, "Spare", 750)
+	require.NoError(t, repo.Delete(asActor(t), entity.DefaultTenant, spare.ID, spare.Version))
+	_, err = repo.Restore(asActor(t), entity.DefaultTenant, spare.ID, "Spare (restored)")
+	require.NoError(t, err)
+	restored, err := repo.GetByID(t.Context(), entity.DefaultTenant, spare.ID)
+	require.NoError(t, err)
+	require.NoError(t, repo.Delete(t.Context(), entity.DefaultTenant, spare.ID, restored.Version))
+	require.NoError(t, repo.Purge(asActor(t), entity.DefaultTenant, spare.ID))
+
+	entries := auditEntries(t, audit)
+	actions := make([]entity.AuditAction, len(entries))
+	for i, entry := range entries {
+		actions[i] = entry.Action
+		assert.Equal(t, entity.AuditEntityPackConfiguration, entry.EntityType)
+		assert.Equal(t, "alice", entry.Actor)
+		assert.Equal(t, "req-1", entry.RequestID)
+	}
+	require.Equal(t, []entity.AuditAction{
+		entity.AuditActionCreate,
+		entity.AuditActionUpdate,
+		entity.AuditActionSetDefault,
+		entity.AuditActionRestore,
+		entity.AuditActionDelete,
+		entity.AuditActionUnarchive,
+		entity.AuditActionPurge,
+	}, actions)
+
+	create, update, setDefault, restore := entries[0], entries[1], entries[2], entries[3]
+	assert.Equal(t, config.ID, create.EntityID)
+	assert.Nil(t, create.Before)
+	assert.Equal(t, []int{250, 500}, auditSnapshot(t, create.After).PackSizes)
+	assert.Equal(t, "Standard", auditSnapshot(t, update.Before).Name)
+	assert.Equal(t, "Renamed", auditSnapshot(t, update.After).Name)
+	assert.False(t, auditSnapshot(t, setDefault.Before).IsDefault)
+	assert.True(t, auditSnapshot(t, setDefault.After).IsDefault)
+	assert.Equal(t, "Renamed", auditSnapshot(t, restore.Before).Name)
+	assert.Equal(t, "Standard", auditSnapshot(t, restore.After).Name)
+
+	deleted, unarchived, purged := entries[4], entries[5], entries[6]
+	assert.Equal(t, spare.ID, deleted.EntityID)
+	assert.True(t, auditSnapshot(t, deleted.Before).IsActive)
+	assert.Nil(t, deleted.After)
+	assert.Equal(t, "Spare", auditSnapshot(t, unarchived.Before).Name)
+	assert.Equal(t, "Spare (restored)", auditSnapshot(t, unarchived.After).Name)
+	assert.False(t, auditSnapshot(t, purged.Before).IsActive)
+	assert.Nil(t, purged.After)
+}
+
+func testAuditsImports(t *testing.T, repo entity.PackConfigurationRepository, audit entity.AuditRepository) {
+	existing := createConfiguration(t, repo, "Existing", 100)
+	untouched := createConfiguration(t, repo, "Untouched", 200)
+
+	now := time.Now()
+	created, err := entity.NewPackConfiguration(entity.DefaultTenant, "Imported", []int{5})
+	require.NoError(t, err)
+	created.CreatedAt, created.UpdatedAt = now, now
+	updated := existing.Clone()
+	updated.PackSizes = []int{100, 300}
+	updated.CreatedAt, updated.UpdatedAt = now, now
+
+	require.NoError(t, repo.Import(asActor(t), entity.PackConfigurationImport{
+		Tenant:  entity.DefaultTenant,
+		Creates: []*entity.PackConfiguration{created},
+		Updates: []*entity.PackConfiguration{updated},
+	}))
+
+	entries := auditEntries(t, audit)
+	require.Len(t, entries, 2)
+	assert.Equal(t, entity.AuditActionImport, entries[0].Action)
+	assert.Equal(t, created.ID, entries[0].EntityID)
+	assert.Nil(t, entries[0].Before)
+	assert.Equal(t, entity.AuditActionImport, entries[1].Action)
+	assert.Equal(t, []int{100}, auditSnapshot(t, entries[1].Before).PackSizes)
+	assert.Equal(t, []int{100, 300}, auditSnapshot(t, entries[1].After).PackSizes)
+
+	// Moving the default onto a configuration the file leaves alone audits that move
+	require.NoError(t, repo.Import(asActor(t), entity.PackConfigurationImport{
+		Tenant:    entity.DefaultTenant,
+		DefaultID: untouched.ID,
+	}))
+
+	entries = auditEntries(t, audit)
+	require.Len(t, entries, 3)
+	assert.Equal(t, entity.AuditActionSetDefault, entries[2].Action)
+	assert.Equal(t, untouched.ID, entries[2].EntityID)
+	assert.False(t, auditSnapshot(t, entries[2].Before).IsDefault)
+	assert.True(t, auditSnapshot(t, entries[2].After).IsDefault)
+}
+
+func testSkipsFailedAndAnonymousWrites(t *testing.T, repo entity.PackConfigurationRepository, audit entity.AuditRepository) {
+	// Writes under a context without an actor are not audited
+	config := createConfiguration(t, repo, "Standard", 250)
+
+	stale := config.Clone()
+	stale.Version++
+	_, err := repo.Update(asActor(t), stale)
+	assert.ErrorIs(t, err, errs.ErrConfigurationVersionConflict)
+	assert.ErrorIs(t, repo.Delete(asActor(t), entity.DefaultTenant, config.ID, config.Version+1), errs.ErrConfigurationVersionConflict)
+	assert.ErrorIs(t, repo.Purge(asActor(t), entity.DefaultTenant, config.ID), errs.ErrConfigurationNotArchived)
+
+	clash, err := entity.NewPackConfiguration(entity.DefaultTenant, "standard", []int{5})
+	require.NoError(t, err)
+	clash.CreatedAt, clash.UpdatedAt = time.Now(), time.Now()
+	err = repo.Import(asActor(t), entity.PackConfigurationImport{
+		Tenant:  entity.DefaultTenant,
+		Creates: []*entity.PackConfiguration{clash},
+	})
+	assert.ErrorIs(t, err, errs.ErrConfigurationNameConflict)
+
+	assert.Empty(t, auditEntries(t, audit))
+}
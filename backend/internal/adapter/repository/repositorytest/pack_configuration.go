@@ -0,0 +1,493 @@
+// Package repositorytest holds the behaviour every PackConfigurationRepository
+// implementation shares, run against each of them from their own tests
+package repositorytest
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+)
+
+// PackConfigurationRepositoryFactory returns an empty repository for one test
+type PackConfigurationRepositoryFactory func(t *testing.T) entity.PackConfigurationRepository
+
+// RunPackConfigurationRepositoryTests runs the shared behaviour against the
+// repositories newRepository returns, each subtest with a fresh one
+func RunPackConfigurationRepositoryTests(t *testing.T, newRepository PackConfigurationRepositoryFactory) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, repo entity.PackConfigurationRepository)
+	}{
+		{"SoftDelete", testSoftDelete},
+		{"Ordering", testOrdering},
+		{"DefaultExclusivity", testDefaultExclusivity},
+		{"VersionConflicts", testVersionConflicts},
+		{"RestoreVersion", testRestoreVersion},
+		{"UpdateInactive", testUpdateInactive},
+		{"ConcurrentSetDefault", testConcurrentSetDefault},
+		{"ConcurrentUpdate", testConcurrentUpdate},
+		{"ImportIsAtomic", testImportIsAtomic},
+		{"ImportMovesDefault", testImportMovesDefault},
+		{"ReturnsCopies", testReturnsCopies},
+		{"TenantIsolation", testTenantIsolation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newRepository(t))
+		})
+	}
+}
+
+func createConfiguration(t *testing.T, repo entity.PackConfigurationRepository, name string, packSizes ...int) *entity.PackConfiguration {
+	t.Helper()
+	return createConfigurationFor(t, repo, entity.EffectivePeriod{}, name, packSizes...)
+}
+
+func createConfigurationFor(t *testing.T, repo entity.PackConfigurationRepository, period entity.EffectivePeriod, name string, packSizes ...int) *entity.PackConfiguration {
+	t.Helper()
+	config, err := entity.NewPackConfiguration(entity.DefaultTenant, name, packSizes)
+	require.NoError(t, err)
+	config.EffectivePeriod = period
+	created, err := repo.Create(t.Context(), config)
+	require.NoError(t, err)
+	return created
+}
+
+// defaultIDs lists the active configurations holding the default flag
+func defaultIDs(t *testing.T, repo entity.PackConfigurationRepository) []int {
+	t.Helper()
+	configs, _, err := repo.List(t.Context(), entity.PackConfigurationQuery{Tenant: entity.DefaultTenant, Limit: 100})
+	require.NoError(t, err)
+	var result []int
+	for _, config := range configs {
+		if config.IsDefault {
+			result = append(result, config.ID)
+		}
+	}
+	return result
+}
+
+func testSoftDelete(t *testing.T, repo entity.PackConfigurationRepository) {
+	config := createConfiguration(t, repo, "Standard", 250, 500)
+	require.NoError(t, repo.SetDefault(t.Context(), entity.DefaultTenant, config.ID, config.Version))
+	config, err := repo.GetByID(t.Context(), entity.DefaultTenant, config.ID)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.Delete(t.Context(), entity.DefaultTenant, config.ID, config.Version))
+
+	_, err = repo.GetByID(t.Context(), entity.DefaultTenant, config.ID)
+	assert.ErrorIs(t, err, errs.ErrPackConfigurationNotFound)
+	archived, err := repo.GetArchivedByID(t.Context(), entity.DefaultTenant, config.ID)
+	require.NoError(t, err)
+	assert.False(t, archived.IsActive)
+
+	err = repo.Delete(t.Context(), entity.DefaultTenant, config.ID, config.Version)
+	assert.ErrorIs(t, err, errs.ErrPackConfigurationNotFound)
+
+	// The name is free again while the configuration is archived
+	createConfiguration(t, repo, "standard", 1000)
+	_, err = repo.Restore(t.Context(), entity.DefaultTenant, config.ID, "")
+	var conflict *errs.NameConflictError
+	assert.ErrorAs(t, err, &conflict)
+
+	restored, err := repo.Restore(t.Context(), entity.DefaultTenant, config.ID, "Standard (restored)")
+	require.NoError(t, err)
+	assert.True(t, restored.IsActive)
+	assert.Equal(t, config.Version+1, restored.Version)
+
+	// A restored default comes back without the flag, even with no other
+	// default in place
+	assert.False(t, restored.IsDefault)
+	assert.Empty(t, defaultIDs(t, repo))
+}
+
+func testOrdering(t *testing.T, repo entity.PackConfigurationRepository) {
+	first := createConfiguration(t, repo, "Beta", 10)
+	second := createConfiguration(t, repo, "alpha", 20)
+	third := createConfiguration(t, repo, "Gamma", 30)
+	require.NoError(t, repo.SetDefault(t.Context(), entity.DefaultTenant, first.ID, first.Version))
+
+	configs, total, err := repo.List(t.Context(), entity.PackConfigurationQuery{Tenant: entity.DefaultTenant, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Equal(t, []int{first.ID, third.ID, second.ID}, ids(configs))
+
+	configs, _, err = repo.List(t.Context(), entity.PackConfigurationQuery{Tenant: entity.DefaultTenant, Sort: entity.PackConfigurationSortName, Limit: 2, Offset: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []int{first.ID, third.ID}, ids(configs))
+
+	configs, _, err = repo.List(t.Context(), entity.PackConfigurationQuery{Tenant: entity.DefaultTenant, Sort: entity.PackConfigurationSortCreatedAt, Descending: true, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []int{third.ID, second.ID, first.ID}, ids(configs))
+
+	// Archived configurations are listed most recently archived first
+	require.NoError(t, repo.Delete(t.Context(), entity.DefaultTenant, second.ID, second.Version))
+	require.NoError(t, repo.Delete(t.Context(), entity.DefaultTenant, third.ID, third.Version))
+	configs, total, err = repo.List(t.Context(), entity.PackConfigurationQuery{Tenant: entity.DefaultTenant, Archived: true, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, []int{third.ID, second.ID}, ids(configs))
+}
+
+func testDefaultExclusivity(t *testing.T, repo entity.PackConfigurationRepository) {
+	first := createConfiguration(t, repo, "First", 10)
+	second := createConfiguration(t, repo, "Second", 20)
+
+	require.NoError(t, repo.SetDefault(t.Context(), entity.DefaultTenant, first.ID, first.Version))
+	require.NoError(t, repo.SetDefault(t.Context(), entity.DefaultTenant, second.ID, second.Version))
+	assert.Equal(t, []int{second.ID}, defaultIDs(t, repo))
+
+	// Every configuration whose flag moves gets a new version, so a client
+	// holding the old one cannot overwrite the change
+	first, second = reload(t, repo, first), reload(t, repo, second)
+	assert.Equal(t, 3, first.Version)
+	assert.Equal(t, 2, second.Version)
+	versions, err := repo.GetVersions(t.Context(), entity.DefaultTenant, first.ID)
+	require.NoError(t, err)
+	assert.Len(t, versions, 3)
+
+	// Setting the default again changes nothing
+	require.NoError(t, repo.SetDefault(t.Context(), entity.DefaultTenant, second.ID, second.Version))
+	assert.Equal(t, 2, reload(t, repo, second).Version)
+
+	// Defaults whose periods do not overlap hold the flag together
+	switchover := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+	second.EffectiveTo = &switchover
+	second, err = repo.Update(t.Context(), second)
+	require.NoError(t, err)
+	assert.True(t, second.IsDefault)
+	third := createConfigurationFor(t, repo, entity.EffectivePeriod{EffectiveFrom: &switchover}, "Third", 30)
+	require.NoError(t, repo.SetDefault(t.Context(), entity.DefaultTenant, third.ID, third.Version))
+	assert.ElementsMatch(t, []int{second.ID, third.ID}, defaultIDs(t, repo))
+	assert.Equal(t, second.Version, reload(t, repo, second).Version)
+
+	current, err := repo.GetDefault(t.Context(), entity.DefaultTenant, switchover.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, second.ID, current.ID)
+	current, err = repo.GetDefault(t.Context(), entity.DefaultTenant, switchover)
+	require.NoError(t, err)
+	assert.Equal(t, third.ID, current.ID)
+
+	// A default cannot be stretched over another default's period
+	second.EffectiveTo = nil
+	_, err = repo.Update(t.Context(), second)
+	var overlap *errs.DefaultPeriodOverlapError
+	require.ErrorAs(t, err, &overlap)
+	assert.Equal(t, third.ID, overlap.ExistingID)
+
+	// An update that takes the default writes one version of its own
+	first.IsDefault = true
+	first, err = repo.Update(t.Context(), first)
+	require.NoError(t, err)
+	assert.True(t, first.IsDefault)
+	assert.Equal(t, 4, first.Version)
+	assert.Equal(t, 4, reload(t, repo, first).Version)
+
+	// An open-ended default takes the flag from every default it overlaps
+	assert.Equal(t, []int{first.ID}, defaultIDs(t, repo))
+	assert.Equal(t, second.Version+1, reload(t, repo, second).Version)
+	assert.Equal(t, third.Version+2, reload(t, repo, third).Version)
+}
+
+// reload reads config again as it is stored now
+func reload(t *testing.T, repo entity.PackConfigurationRepository, config *entity.PackConfiguration) *entity.PackConfiguration {
+	t.Helper()
+	stored, err := repo.GetByID(t.Context(), entity.DefaultTenant, config.ID)
+	require.NoError(t, err)
+	return stored
+}
+
+func testVersionConflicts(t *testing.T, repo entity.PackConfigurationRepository) {
+	config := createConfiguration(t, repo, "Standard", 250)
+
+	config.Name = "Renamed"
+	updated, err := repo.Update(t.Context(), config)
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated.Version)
+
+	stale := *updated
+	stale.Version = 1
+	_, err = repo.Update(t.Context(), &stale)
+	var conflict *errs.VersionConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, 2, conflict.CurrentVersion)
+
+	assert.ErrorIs(t, repo.SetDefault(t.Context(), entity.DefaultTenant, config.ID, 1), errs.ErrConfigurationVersionConflict)
+
+	versions, err := repo.GetVersions(t.Context(), entity.DefaultTenant, config.ID)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, "Renamed", versions[0].Name)
+	assert.Equal(t, "Standard", versions[1].Name)
+}
+
+func testRestoreVersion(t *testing.T, repo entity.PackConfigurationRepository) {
+	start := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+	config, err := entity.NewPackConfiguration(entity.DefaultTenant, "Summer", []int{250, 500})
+	require.NoError(t, err)
+	config.EffectiveFrom = &start
+	config.Description = "Boxes for the summer sale"
+	config.Labels = entity.Labels{"region": "eu"}
+	config.Metadata = json.RawMessage(`{"owner": "logistics"}`)
+	config, err = repo.Create(t.Context(), config)
+	require.NoError(t, err)
+
+	config.Name = "Winter"
+	config.PackSizes = []int{1000}
+	config.EffectiveFrom = nil
+	config.Description = ""
+	config.Labels = entity.Labels{"region": "us"}
+	config.Metadata = nil
+	config, err = repo.Update(t.Context(), config)
+	require.NoError(t, err)
+
+	// Every version is a complete snapshot
+	snapshot, err := repo.GetVersion(t.Context(), entity.DefaultTenant, config.ID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Summer", snapshot.Name)
+	require.NotNil(t, snapshot.EffectiveFrom)
+	assert.True(t, start.Equal(*snapshot.EffectiveFrom))
+	assert.Equal(t, "Boxes for the summer sale", snapshot.Description)
+	assert.Equal(t, entity.Labels{"region": "eu"}, snapshot.Labels)
+	assert.JSONEq(t, `{"owner": "logistics"}`, string(snapshot.Metadata))
+
+	restored, err := repo.RestoreVersion(t.Context(), entity.DefaultTenant, config.ID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 3, restored.Version)
+	assert.Equal(t, "Summer", restored.Name)
+	assert.Equal(t, []int{250, 500}, restored.PackSizes)
+	require.NotNil(t, restored.EffectiveFrom)
+	assert.True(t, start.Equal(*restored.EffectiveFrom))
+	assert.Nil(t, restored.EffectiveTo)
+	assert.Equal(t, "Boxes for the summer sale", restored.Description)
+	assert.Equal(t, entity.Labels{"region": "eu"}, restored.Labels)
+	assert.JSONEq(t, `{"owner": "logistics"}`, string(restored.Metadata))
+
+	stored, err := repo.GetByID(t.Context(), entity.DefaultTenant, config.ID)
+	require.NoError(t, err)
+	assert.Equal(t, restored.Description, stored.Description)
+	require.NotNil(t, stored.EffectiveFrom)
+	assert.True(t, start.Equal(*stored.EffectiveFrom))
+
+	// A default's restored period must not overlap another default
+	require.NoError(t, repo.SetDefault(t.Context(), entity.DefaultTenant, restored.ID, restored.Version))
+	earlier := createConfigurationFor(t, repo, entity.EffectivePeriod{EffectiveTo: &start}, "Spring", 10)
+	require.NoError(t, repo.SetDefault(t.Context(), entity.DefaultTenant, earlier.ID, earlier.Version))
+	_, err = repo.RestoreVersion(t.Context(), entity.DefaultTenant, config.ID, 2)
+	var overlap *errs.DefaultPeriodOverlapError
+	require.ErrorAs(t, err, &overlap)
+	assert.Equal(t, earlier.ID, overlap.ExistingID)
+}
+
+func testUpdateInactive(t *testing.T, repo entity.PackConfigurationRepository) {
+	config := createConfiguration(t, repo, "Standard", 250)
+	require.NoError(t, repo.Delete(t.Context(), entity.DefaultTenant, config.ID, config.Version))
+
+	// An archived configuration accepts no writes but restoring it
+	config.Name = "Renamed"
+	_, err := repo.Update(t.Context(), config)
+	assert.ErrorIs(t, err, errs.ErrPackConfigurationNotFound)
+	assert.ErrorIs(t, repo.SetDefault(t.Context(), entity.DefaultTenant, config.ID, config.Version), errs.ErrPackConfigurationNotFound)
+	_, err = repo.RestoreVersion(t.Context(), entity.DefaultTenant, config.ID, 1)
+	assert.ErrorIs(t, err, errs.ErrPackConfigurationNotFound)
+
+	missing := *config
+	missing.ID += 100
+	_, err = repo.Update(t.Context(), &missing)
+	assert.ErrorIs(t, err, errs.ErrPackConfigurationNotFound)
+
+	archived, err := repo.GetArchivedByID(t.Context(), entity.DefaultTenant, config.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Standard", archived.Name)
+	assert.Equal(t, 1, archived.Version)
+}
+
+func testConcurrentSetDefault(t *testing.T, repo entity.PackConfigurationRepository) {
+	var configs []*entity.PackConfiguration
+	for _, name := range []string{"A", "B", "C", "D", "E", "F", "G", "H"} {
+		configs = append(configs, createConfiguration(t, repo, name, len(name)*10))
+	}
+
+	var wg sync.WaitGroup
+	for _, config := range configs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, repo.SetDefault(t.Context(), entity.DefaultTenant, config.ID, config.Version))
+		}()
+	}
+	wg.Wait()
+
+	defaults, _, err := repo.List(t.Context(), entity.PackConfigurationQuery{Tenant: entity.DefaultTenant, Limit: 100})
+	require.NoError(t, err)
+	count := 0
+	for _, config := range defaults {
+		if config.IsDefault {
+			count++
+		}
+	}
+	assert.Equal(t, 1, count)
+	_, err = repo.GetDefault(t.Context(), entity.DefaultTenant, time.Now())
+	assert.NoError(t, err)
+}
+
+func testConcurrentUpdate(t *testing.T, repo entity.PackConfigurationRepository) {
+	config := createConfiguration(t, repo, "Standard", 250)
+
+	var wg sync.WaitGroup
+	var succeeded atomic.Int32
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			update := *config
+			update.Name = fmt.Sprintf("Standard %d", i)
+			if _, err := repo.Update(t.Context(), &update); err != nil {
+				assert.ErrorIs(t, err, errs.ErrConfigurationVersionConflict)
+				return
+			}
+			succeeded.Add(1)
+		}()
+	}
+	wg.Wait()
+
+	// Every writer read version 1, so only the first one to write wins
+	assert.Equal(t, int32(1), succeeded.Load())
+	loaded, err := repo.GetByID(t.Context(), entity.DefaultTenant, config.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, loaded.Version)
+}
+
+func testImportIsAtomic(t *testing.T, repo entity.PackConfigurationRepository) {
+	existing := createConfiguration(t, repo, "Existing", 100)
+
+	now := time.Now()
+	imported, err := entity.NewPackConfiguration(entity.DefaultTenant, "Imported", []int{5})
+	require.NoError(t, err)
+	clash, err := entity.NewPackConfiguration(entity.DefaultTenant, "existing", []int{7})
+	require.NoError(t, err)
+	imported.CreatedAt, clash.CreatedAt = now, now
+	imported.UpdatedAt, clash.UpdatedAt = now, now
+
+	err = repo.Import(t.Context(), entity.PackConfigurationImport{
+		Tenant:  entity.DefaultTenant,
+		Creates: []*entity.PackConfiguration{imported, clash},
+	})
+	var conflict *errs.NameConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, existing.ID, conflict.ExistingID)
+
+	configs, total, err := repo.List(t.Context(), entity.PackConfigurationQuery{Tenant: entity.DefaultTenant, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, []int{existing.ID}, ids(configs))
+}
+
+func testImportMovesDefault(t *testing.T, repo entity.PackConfigurationRepository) {
+	previous := createConfiguration(t, repo, "Previous", 100)
+	require.NoError(t, repo.SetDefault(t.Context(), entity.DefaultTenant, previous.ID, previous.Version))
+	previous = reload(t, repo, previous)
+	untouched := createConfiguration(t, repo, "Untouched", 200)
+
+	now := time.Now()
+	created, err := entity.NewPackConfiguration(entity.DefaultTenant, "Created", []int{5})
+	require.NoError(t, err)
+	created.CreatedAt, created.UpdatedAt = now, now
+	created.IsDefault = true
+	require.NoError(t, repo.Import(t.Context(), entity.PackConfigurationImport{
+		Tenant:  entity.DefaultTenant,
+		Creates: []*entity.PackConfiguration{created},
+	}))
+
+	// The configuration the import writes keeps the version it wrote; the
+	// one losing the flag gets a new one
+	assert.Equal(t, []int{created.ID}, defaultIDs(t, repo))
+	assert.Equal(t, 1, reload(t, repo, created).Version)
+	assert.Equal(t, previous.Version+1, reload(t, repo, previous).Version)
+
+	require.NoError(t, repo.Import(t.Context(), entity.PackConfigurationImport{
+		Tenant:    entity.DefaultTenant,
+		DefaultID: untouched.ID,
+	}))
+	assert.Equal(t, []int{untouched.ID}, defaultIDs(t, repo))
+	assert.Equal(t, untouched.Version+1, reload(t, repo, untouched).Version)
+	assert.Equal(t, 2, reload(t, repo, created).Version)
+}
+
+func testTenantIsolation(t *testing.T, repo entity.PackConfigurationRepository) {
+	const other = "acme"
+	ours := createConfiguration(t, repo, "Standard", 250, 500)
+	require.NoError(t, repo.SetDefault(t.Context(), entity.DefaultTenant, ours.ID, ours.Version))
+	ours = reload(t, repo, ours)
+
+	// Names are unique per tenant, so the other tenant can use the same one
+	config, err := entity.NewPackConfiguration(other, "standard", []int{250, 500})
+	require.NoError(t, err)
+	theirs, err := repo.Create(t.Context(), config)
+	require.NoError(t, err)
+	assert.Equal(t, other, theirs.Tenant)
+
+	_, err = repo.GetByID(t.Context(), other, ours.ID)
+	assert.ErrorIs(t, err, errs.ErrPackConfigurationNotFound)
+	_, err = repo.GetByID(t.Context(), entity.DefaultTenant, theirs.ID)
+	assert.ErrorIs(t, err, errs.ErrPackConfigurationNotFound)
+
+	configs, total, err := repo.List(t.Context(), entity.PackConfigurationQuery{Tenant: other, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, []int{theirs.ID}, ids(configs))
+
+	// Each tenant has its own default
+	require.NoError(t, repo.SetDefault(t.Context(), other, theirs.ID, theirs.Version))
+	assert.Equal(t, []int{ours.ID}, defaultIDs(t, repo))
+	assert.Equal(t, ours.Version, reload(t, repo, ours).Version)
+	current, err := repo.GetDefault(t.Context(), other, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, theirs.ID, current.ID)
+	assert.ErrorIs(t, repo.SetDefault(t.Context(), other, ours.ID, ours.Version), errs.ErrPackConfigurationNotFound)
+
+	// Another tenant can neither change nor delete the configuration
+	foreign := ours.Clone()
+	foreign.Tenant = other
+	foreign.Name = "Hijacked"
+	_, err = repo.Update(t.Context(), foreign)
+	assert.ErrorIs(t, err, errs.ErrPackConfigurationNotFound)
+	assert.ErrorIs(t, repo.Delete(t.Context(), other, ours.ID, ours.Version), errs.ErrPackConfigurationNotFound)
+
+	stored := reload(t, repo, ours)
+	assert.Equal(t, "Standard", stored.Name)
+	assert.Equal(t, ours.Version, stored.Version)
+	assert.True(t, stored.IsDefault)
+}
+
+func testReturnsCopies(t *testing.T, repo entity.PackConfigurationRepository) {
+	config := createConfiguration(t, repo, "Standard", 250, 500)
+
+	config.PackSizes[0] = 1
+	loaded, err := repo.GetByID(t.Context(), entity.DefaultTenant, config.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []int{250, 500}, loaded.PackSizes)
+
+	loaded.Name = "Changed"
+	again, err := repo.GetByID(t.Context(), entity.DefaultTenant, config.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Standard", again.Name)
+}
+
+func ids(configs []*entity.PackConfiguration) []int {
+	var result []int
+	for _, config := range configs {
+		result = append(result, config.ID)
+	}
+	return result
+}
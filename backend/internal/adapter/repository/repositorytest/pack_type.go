@@ -0,0 +1,163 @@
+package repositorytest
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+)
+
+// PackTypeRepositoriesFactory returns an empty configurations repository and
+// the pack types repository sharing its storage, for one test
+type PackTypeRepositoriesFactory func(t *testing.T) (entity.PackConfigurationRepository, entity.PackTypeRepository)
+
+// RunPackTypeReferenceTests runs the shared behaviour of pack types that
+// configurations reference against the repositories newRepositories
+// returns, each subtest with fresh ones
+func RunPackTypeReferenceTests(t *testing.T, newRepositories PackTypeRepositoriesFactory) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, configs entity.PackConfigurationRepository, packTypes entity.PackTypeRepository)
+	}{
+		{"ReferencedPackTypes", testReferencedPackTypes},
+		{"StalePackTypes", testStalePackTypes},
+		{"ConcurrentDeleteAndReference", testConcurrentDeleteAndReference},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configs, packTypes := newRepositories(t)
+			tt.test(t, configs, packTypes)
+		})
+	}
+}
+
+func createPackType(t *testing.T, repo entity.PackTypeRepository, code string, itemCount int) *entity.PackType {
+	t.Helper()
+	packType, err := repo.Create(t.Context(), &entity.PackType{
+		Tenant: entity.DefaultTenant, Code: code, Label: code, ItemCount: itemCount, IsActive: true,
+	})
+	require.NoError(t, err)
+	return packType
+}
+
+// configurationWith builds a configuration offering the pack types' item counts
+func configurationWith(t *testing.T, name string, packTypes ...*entity.PackType) *entity.PackConfiguration {
+	t.Helper()
+	var packSizes []int
+	for _, packType := range packTypes {
+		packSizes = append(packSizes, packType.ItemCount)
+	}
+	config, err := entity.NewPackConfiguration(entity.DefaultTenant, name, packSizes)
+	require.NoError(t, err)
+	for _, packType := range packTypes {
+		config.PackTypeIDs = append(config.PackTypeIDs, packType.ID)
+	}
+	return config
+}
+
+func testReferencedPackTypes(t *testing.T, configs entity.PackConfigurationRepository, packTypes entity.PackTypeRepository) {
+	box := createPackType(t, packTypes, "BOX-250", 250)
+	config, err := configs.Create(t.Context(), configurationWith(t, "Standard", box))
+	require.NoError(t, err)
+
+	relabelled := *box
+	relabelled.Label = "Carton of 250"
+	_, err = packTypes.Update(t.Context(), &relabelled)
+	require.NoError(t, err, "relabelling keeps the item count the configuration offers")
+
+	resized := relabelled
+	resized.ItemCount = 300
+	_, err = packTypes.Update(t.Context(), &resized)
+	assert.ErrorIs(t, err, errs.ErrPackTypeInUse)
+	assert.ErrorIs(t, packTypes.Delete(t.Context(), entity.DefaultTenant, box.ID), errs.ErrPackTypeInUse)
+
+	stored, err := packTypes.GetByID(t.Context(), entity.DefaultTenant, box.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 250, stored.ItemCount)
+	assert.True(t, stored.IsActive)
+
+	require.NoError(t, configs.Delete(t.Context(), entity.DefaultTenant, config.ID, config.Version))
+	require.NoError(t, packTypes.Delete(t.Context(), entity.DefaultTenant, box.ID), "archived configurations do not hold pack types")
+}
+
+func testStalePackTypes(t *testing.T, configs entity.PackConfigurationRepository, packTypes entity.PackTypeRepository) {
+	box := createPackType(t, packTypes, "BOX-250", 250)
+	crate := createPackType(t, packTypes, "CRATE-500", 500)
+
+	archived, err := configs.Create(t.Context(), configurationWith(t, "Archived", box, crate))
+	require.NoError(t, err)
+	require.NoError(t, configs.Delete(t.Context(), entity.DefaultTenant, archived.ID, archived.Version))
+
+	// The service resolved the pack types before another request deleted or
+	// resized them
+	require.NoError(t, packTypes.Delete(t.Context(), entity.DefaultTenant, box.ID))
+	_, err = configs.Create(t.Context(), configurationWith(t, "Standard", box))
+	assert.ErrorIs(t, err, errs.ErrUnknownPackType)
+
+	config, err := configs.Create(t.Context(), configurationWith(t, "Crates", crate))
+	require.NoError(t, err)
+	bin := createPackType(t, packTypes, "BIN-1000", 1000)
+	stale := configurationWith(t, "Crates", crate, bin)
+	resized := *bin
+	resized.ItemCount = 1200
+	_, err = packTypes.Update(t.Context(), &resized)
+	require.NoError(t, err)
+
+	stale.ID, stale.Version = config.ID, config.Version
+	_, err = configs.Update(t.Context(), stale)
+	assert.ErrorIs(t, err, errs.ErrUnknownPackType)
+
+	// Unarchiving drops the pack types that no longer offer a pack size
+	restored, err := configs.Restore(t.Context(), entity.DefaultTenant, archived.ID, "")
+	require.NoError(t, err)
+	assert.Equal(t, []int{crate.ID}, restored.PackTypeIDs)
+	assert.Equal(t, archived.Version+1, restored.Version)
+	assert.Equal(t, []int{250, 500}, restored.PackSizes)
+
+	restored.Description = "Still in use"
+	_, err = configs.Update(t.Context(), restored)
+	assert.NoError(t, err)
+}
+
+// testConcurrentDeleteAndReference races configurations referencing a pack
+// type against its deletion: whichever commits first, no active
+// configuration may end up referencing a deleted pack type
+func testConcurrentDeleteAndReference(t *testing.T, configs entity.PackConfigurationRepository, packTypes entity.PackTypeRepository) {
+	const rounds = 10
+	for round := range rounds {
+		packType := createPackType(t, packTypes, fmt.Sprintf("BOX-%d", round), 250)
+		config := configurationWith(t, fmt.Sprintf("Round %d", round), packType)
+
+		var wg sync.WaitGroup
+		var created *entity.PackConfiguration
+		var createErr, deleteErr error
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			created, createErr = configs.Create(t.Context(), config)
+		}()
+		go func() {
+			defer wg.Done()
+			deleteErr = packTypes.Delete(t.Context(), entity.DefaultTenant, packType.ID)
+		}()
+		wg.Wait()
+
+		stored, err := packTypes.GetByID(t.Context(), entity.DefaultTenant, packType.ID)
+		require.NoError(t, err)
+		if createErr == nil {
+			assert.ErrorIs(t, deleteErr, errs.ErrPackTypeInUse, "round %d", round)
+			assert.True(t, stored.IsActive, "round %d", round)
+			require.NoError(t, configs.Delete(t.Context(), entity.DefaultTenant, created.ID, created.Version))
+		} else {
+			assert.ErrorIs(t, createErr, errs.ErrUnknownPackType, "round %d", round)
+			assert.NoError(t, deleteErr, "round %d", round)
+			assert.False(t, stored.IsActive, "round %d", round)
+		}
+	}
+}
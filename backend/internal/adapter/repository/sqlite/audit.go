@@ -0,0 +1,174 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+)
+
+type AuditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{
+		db: db,
+	}
+}
+
+func (r *AuditRepository) Append(entry *entity.AuditEntry) (*entity.AuditEntry, error) {
+	if err := insertAuditEntry(context.Background(), r.db, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// execer is what both *sql.DB and *sql.Tx offer for writes
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// insertAuditEntry appends entry through e, so a change can audit itself in
+// its own transaction
+func insertAuditEntry(ctx context.Context, e execer, entry *entity.AuditEntry) error {
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO audit_log (tenant_id, entity_type, entity_id, action, actor, request_id, before, after, created_at)
+		VALUES (?, ?, ?, ?, ?, NULLIF(?, ''), ?, ?, ?)
+	`
+
+	createdAt := timeArg(time.Now())
+	result, err := e.ExecContext(ctx, query,
+		entry.Tenant,
+		entry.EntityType,
+		entry.EntityID,
+		string(entry.Action),
+		entry.Actor,
+		entry.RequestID,
+		nullableJSON(entry.Before),
+		nullableJSON(entry.After),
+		createdAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to append audit entry: %w", err)
+	}
+
+	if entry.ID, err = result.LastInsertId(); err != nil {
+		return fmt.Errorf("failed to get audit entry ID: %w", err)
+	}
+	entry.CreatedAt = createdAt
+
+	return nil
+}
+
+func (r *AuditRepository) List(filter entity.AuditFilter) ([]*entity.AuditEntry, int, error) {
+	where, args := auditFilterClause(filter)
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM audit_log`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit entries: %w", err)
+	}
+
+	query := `
+		SELECT id, tenant_id, entity_type, entity_id, action, actor, request_id, before, after, created_at
+		FROM audit_log` + where + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT ? OFFSET ?
+	`
+
+	rows, err := r.db.Query(query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]*entity.AuditEntry, 0, filter.Limit)
+	for rows.Next() {
+		entry := &entity.AuditEntry{}
+		var (
+			action    string
+			requestID sql.NullString
+			before    sql.NullString
+			after     sql.NullString
+		)
+		err := rows.Scan(
+			&entry.ID,
+			&entry.Tenant,
+			&entry.EntityType,
+			&entry.EntityID,
+			&action,
+			&entry.Actor,
+			&requestID,
+			&before,
+			&after,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		entry.Action = entity.AuditAction(action)
+		entry.RequestID = requestID.String
+		if before.Valid {
+			entry.Before = []byte(before.String)
+		}
+		if after.Valid {
+			entry.After = []byte(after.String)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return entries, total, nil
+}
+
+// nullableJSON stores an absent snapshot as SQL NULL rather than an empty string
+func nullableJSON(data []byte) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
+
+func auditFilterClause(filter entity.AuditFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, condition)
+	}
+
+	if filter.Tenant != "" {
+		add("tenant_id = ?", filter.Tenant)
+	}
+	if filter.EntityType != "" {
+		add("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != nil {
+		add("entity_id = ?", *filter.EntityID)
+	}
+	if filter.Actor != "" {
+		add("actor = ?", filter.Actor)
+	}
+	if filter.From != nil {
+		add("created_at >= ?", timeArg(*filter.From))
+	}
+	if filter.To != nil {
+		add("created_at < ?", timeArg(*filter.To))
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
@@ -0,0 +1,1208 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+	"github.com/Schieck/packs-calculator/pkg/db"
+)
+
+const packConfigurationColumns = `id, tenant_id, name, pack_sizes, is_default, is_active, version, created_at, updated_at, effective_from, effective_to, description, labels, metadata, pack_type_ids`
+
+// queryer is what both *sql.DB and *sql.Tx offer for reads
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// PackConfigurationRepository stores configurations in a SQLite file with the
+// same behaviour as the Postgres repository. SQLite has neither arrays nor
+// exclusion constraints, so sizes are JSON arrays and the checks Postgres
+// leaves to constraints run inside the transaction, which holds the
+// database's write lock from the start. Each operation is bounded by
+// queryTimeout, a transaction counting as one. Writes under a context
+// carrying an actor append their audit entry in the same transaction.
+type PackConfigurationRepository struct {
+	db           *sql.DB
+	queryTimeout time.Duration
+}
+
+func NewPackConfigurationRepository(database *sql.DB, queryTimeout time.Duration) *PackConfigurationRepository {
+	return &PackConfigurationRepository{
+		db:           database,
+		queryTimeout: queryTimeout,
+	}
+}
+
+// intsArg encodes integers as a JSON array, an empty one for nil
+func intsArg(values []int) string {
+	if values == nil {
+		return "[]"
+	}
+	data, _ := json.Marshal(values)
+	return string(data)
+}
+
+// stringsArg encodes strings as a JSON array
+func stringsArg(values []string) string {
+	data, _ := json.Marshal(append([]string{}, values...))
+	return string(data)
+}
+
+// labelsArg encodes labels as a JSON object. A map of strings always encodes.
+func labelsArg(labels entity.Labels) string {
+	if labels == nil {
+		return "{}"
+	}
+	data, _ := json.Marshal(labels)
+	return string(data)
+}
+
+// metadataArg stores absent metadata as NULL
+func metadataArg(metadata json.RawMessage) interface{} {
+	if metadata == nil {
+		return nil
+	}
+	return string(metadata)
+}
+
+// timeArg writes times in UTC, so that comparing the stored text orders
+// them in time
+func timeArg(t time.Time) time.Time {
+	return t.UTC()
+}
+
+func optionalTimeArg(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
+
+func (r *PackConfigurationRepository) scanPackConfiguration(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*entity.PackConfiguration, error) {
+	config := &entity.PackConfiguration{}
+	var packSizes, labels, packTypeIDs string
+	var metadata sql.NullString
+	var effectiveFrom, effectiveTo sql.NullTime
+
+	err := scanner.Scan(
+		&config.ID,
+		&config.Tenant,
+		&config.Name,
+		&packSizes,
+		&config.IsDefault,
+		&config.IsActive,
+		&config.Version,
+		&config.CreatedAt,
+		&config.UpdatedAt,
+		&effectiveFrom,
+		&effectiveTo,
+		&config.Description,
+		&labels,
+		&metadata,
+		&packTypeIDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(packSizes), &config.PackSizes); err != nil {
+		return nil, fmt.Errorf("failed to decode pack sizes: %w", err)
+	}
+	if err := json.Unmarshal([]byte(packTypeIDs), &config.PackTypeIDs); err != nil {
+		return nil, fmt.Errorf("failed to decode pack type IDs: %w", err)
+	}
+	if len(config.PackTypeIDs) == 0 {
+		config.PackTypeIDs = nil
+	}
+	if err := json.Unmarshal([]byte(labels), &config.Labels); err != nil {
+		return nil, fmt.Errorf("failed to decode labels: %w", err)
+	}
+	if len(config.Labels) == 0 {
+		config.Labels = nil
+	}
+	if metadata.Valid {
+		config.Metadata = json.RawMessage(metadata.String)
+	}
+	if effectiveFrom.Valid {
+		config.EffectiveFrom = &effectiveFrom.Time
+	}
+	if effectiveTo.Valid {
+		config.EffectiveTo = &effectiveTo.Time
+	}
+
+	return config, nil
+}
+
+func (r *PackConfigurationRepository) query(ctx context.Context, q queryer, query string, args ...interface{}) ([]*entity.PackConfiguration, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pack configurations: %w", err)
+	}
+	defer rows.Close()
+
+	var configs []*entity.PackConfiguration
+	for rows.Next() {
+		config, err := r.scanPackConfiguration(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan pack configuration: %w", err)
+		}
+		configs = append(configs, config)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return configs, nil
+}
+
+func (r *PackConfigurationRepository) List(ctx context.Context, query entity.PackConfigurationQuery) (_ []*entity.PackConfiguration, _ int, err error) {
+	ctx, finish := db.WithQueryTimeout(ctx, r.queryTimeout, "List")
+	defer finish(&err)
+
+	where, args := packConfigurationQueryClause(query)
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM pack_configurations`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count pack configurations: %w", err)
+	}
+
+	configs, err := r.query(ctx, r.db, `
+		SELECT `+packConfigurationColumns+`
+		FROM pack_configurations`+where+`
+		ORDER BY `+packConfigurationOrderClause(query)+`
+		LIMIT ? OFFSET ?
+	`, append(args, query.Limit, query.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	if configs == nil {
+		configs = []*entity.PackConfiguration{}
+	}
+
+	return configs, total, nil
+}
+
+func (r *PackConfigurationRepository) GetByID(ctx context.Context, tenant string, id int) (_ *entity.PackConfiguration, err error) {
+	ctx, finish := db.WithQueryTimeout(ctx, r.queryTimeout, "GetByID")
+	defer finish(&err)
+
+	return r.getByID(ctx, r.db, tenant, id)
+}
+
+func (r *PackConfigurationRepository) getByID(ctx context.Context, q queryer, tenant string, id int) (*entity.PackConfiguration, error) {
+	query := `SELECT ` + packConfigurationColumns + ` FROM pack_configurations WHERE id = ? AND tenant_id = ? AND is_active = true`
+
+	config, err := r.scanPackConfiguration(q.QueryRowContext(ctx, query, id, tenant))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("pack configuration with id %d: %w", id, errs.ErrPackConfigurationNotFound)
+		}
+		return nil, fmt.Errorf("failed to get pack configuration: %w", err)
+	}
+
+	return config, nil
+}
+
+// GetDefault resolves the default in effect at asOf. Defaults never overlap,
+// so at most one of the tenant's defaults qualifies.
+func (r *PackConfigurationRepository) GetDefault(ctx context.Context, tenant string, asOf time.Time) (_ *entity.PackConfiguration, err error) {
+	ctx, finish := db.WithQueryTimeout(ctx, r.queryTimeout, "GetDefault")
+	defer finish(&err)
+
+	defaults, err := r.defaults(ctx, r.db, tenant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get default pack configuration: %w", err)
+	}
+
+	for _, config := range defaults {
+		if config.IsActive && config.EffectiveAt(asOf) {
+			return config, nil
+		}
+	}
+	return nil, errs.ErrNoDefaultConfiguration
+}
+
+func (r *PackConfigurationRepository) FindByPackSizes(ctx context.Context, tenant string, packSizes []int) (_ []*entity.PackConfiguration, err error) {
+	ctx, finish := db.WithQueryTimeout(ctx, r.queryTimeout, "FindByPackSizes")
+	defer finish(&err)
+
+	return r.query(ctx, r.db, `
+		SELECT `+packConfigurationColumns+`
+		FROM pack_configurations
+		WHERE tenant_id = ? AND is_active = true AND pack_sizes = ?
+		ORDER BY id
+	`, tenant, intsArg(packSizes))
+}
+
+func (r *PackConfigurationRepository) Create(ctx context.Context, config *entity.PackConfiguration) (_ *entity.PackConfiguration, err error) {
+	ctx, finish := db.WithQueryTimeout(ctx, r.queryTimeout, "Create")
+	defer finish(&err)
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if config.IsActive {
+		if err := r.nameConflict(ctx, tx, config.Tenant, config.Name, 0); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.checkPackTypes(ctx, tx, config.Tenant, config); err != nil {
+		return nil, err
+	}
+	if config.IsDefault {
+		if err := r.defaultOverlap(ctx, tx, config.Tenant, 0, config.EffectivePeriod); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now().UTC()
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO pack_configurations (tenant_id, name, pack_sizes, is_default, is_active, effective_from, effective_to, description, labels, metadata, pack_type_ids, version, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 1, ?, ?)
+	`, config.Tenant, config.Name, intsArg(config.PackSizes), config.IsDefault, config.IsActive,
+		optionalTimeArg(config.EffectiveFrom), optionalTimeArg(config.EffectiveTo),
+		config.Description, labelsArg(config.Labels), metadataArg(config.Metadata), intsArg(config.PackTypeIDs), now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pack configuration: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pack configuration ID: %w", err)
+	}
+	config.ID = int(id)
+	config.Version = 1
+	config.CreatedAt = now
+	config.UpdatedAt = now
+
+	if err := r.insertVersion(ctx, tx, config); err != nil {
+		return nil, err
+	}
+
+	if err := recordAudit(ctx, tx, entity.AuditActionCreate, nil, config); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit pack configuration: %w", err)
+	}
+
+	return config, nil
+}
+
+// Update writes a new version of the configuration's contents. The default
+// flag is only ever moved onto the configuration, in the same transaction
+// and the same way as SetDefault; clearing it is the service's concern.
+func (r *PackConfigurationRepository) Update(ctx context.Context, config *entity.PackConfiguration) (_ *entity.PackConfiguration, err error) {
+	ctx, finish := db.WithQueryTimeout(ctx, r.queryTimeout, "Update")
+	defer finish(&err)
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stored, err := r.atVersion(ctx, tx, config.Tenant, config.ID, config.Version)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.nameConflict(ctx, tx, config.Tenant, config.Name, config.ID); err != nil {
+		return nil, err
+	}
+	if err := r.checkPackTypes(ctx, tx, config.Tenant, config); err != nil {
+		return nil, err
+	}
+	if stored.IsDefault {
+		if err := r.defaultOverlap(ctx, tx, config.Tenant, config.ID, config.EffectivePeriod); err != nil {
+			return nil, err
+		}
+	}
+
+	config.UpdatedAt = time.Now().UTC()
+	_, err = tx.ExecContext(ctx, `
+		UPDATE pack_configurations
+		SET name = ?, pack_sizes = ?, effective_from = ?, effective_to = ?, updated_at = ?, version = version + 1,
+			description = ?, labels = ?, metadata = ?, pack_type_ids = ?
+		WHERE id = ?
+	`, config.Name, intsArg(config.PackSizes), optionalTimeArg(config.EffectiveFrom), optionalTimeArg(config.EffectiveTo), config.UpdatedAt,
+		config.Description, labelsArg(config.Labels), metadataArg(config.Metadata), intsArg(config.PackTypeIDs), config.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update pack configuration: %w", err)
+	}
+
+	makeDefault := config.IsDefault
+	config.Version = stored.Version + 1
+	config.IsDefault = stored.IsDefault
+	config.IsActive = true
+	config.CreatedAt = stored.CreatedAt
+
+	if makeDefault && !config.IsDefault {
+		if err := r.moveDefault(ctx, tx, config.Tenant, config.ID, config.Version, config.ID); err != nil {
+			return nil, err
+		}
+		config.IsDefault = true
+	}
+
+	if err := r.insertVersion(ctx, tx, config); err != nil {
+		return nil, err
+	}
+
+	if err := r.auditChange(ctx, tx, entity.AuditActionUpdate, stored); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit pack configuration update: %w", err)
+	}
+
+	return config, nil
+}
+
+func (r *PackConfigurationRepository) Delete(ctx context.Context, tenant string, id int, version int) (err error) {
+	ctx, finish := db.WithQueryTimeout(ctx, r.queryTimeout, "Delete")
+	defer finish(&err)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stored, err := r.atVersion(ctx, tx, tenant, id, version)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE pack_configurations SET is_active = false, updated_at = ? WHERE id = ?`
+	if _, err := tx.ExecContext(ctx, query, timeArg(time.Now()), id); err != nil {
+		return fmt.Errorf("failed to delete pack configuration: %w", err)
+	}
+
+	if err := recordAudit(ctx, tx, entity.AuditActionDelete, stored, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *PackConfigurationRepository) SetDefault(ctx context.Context, tenant string, id int, version int) (err error) {
+	ctx, finish := db.WithQueryTimeout(ctx, r.queryTimeout, "SetDefault")
+	defer finish(&err)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	before, err := r.atVersion(ctx, tx, tenant, id, version)
+	if err != nil {
+		return err
+	}
+
+	if err := r.moveDefault(ctx, tx, tenant, id, version); err != nil {
+		return err
+	}
+
+	if err := r.auditChange(ctx, tx, entity.AuditActionSetDefault, before); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// moveDefault makes id the tenant's default for its effective period,
+// provided it is still active and at version, taking the flag off every
+// default whose period overlaps. Every configuration whose flag changes gets
+// a new version, except those in written, whose new version tx already
+// holds. Every default change goes through here.
+func (r *PackConfigurationRepository) moveDefault(ctx context.Context, tx *sql.Tx, tenant string, id int, version int, written ...int) error {
+	target, err := r.atVersion(ctx, tx, tenant, id, version)
+	if err != nil {
+		return err
+	}
+
+	now := timeArg(time.Now())
+	if err := r.unsetOverlappingDefaults(ctx, tx, target, &now, written); err != nil {
+		return err
+	}
+
+	if target.IsDefault {
+		return nil
+	}
+	if err := r.flagDefault(ctx, tx, target, true, &now, written); err != nil {
+		return fmt.Errorf("failed to set new default: %w", err)
+	}
+	return nil
+}
+
+// unsetOverlappingDefaults takes the default flag off the tenant's other
+// configurations whose effective period overlaps target's, setting their
+// updated_at to touchedAt unless it is nil. Each of them gets a new version
+// unless it is in written.
+func (r *PackConfigurationRepository) unsetOverlappingDefaults(ctx context.Context, tx *sql.Tx, target *entity.PackConfiguration, touchedAt *time.Time, written []int) error {
+	defaults, err := r.defaults(ctx, tx, target.Tenant)
+	if err != nil {
+		return fmt.Errorf("failed to find existing defaults: %w", err)
+	}
+
+	for _, other := range defaults {
+		if other.ID == target.ID || !other.Overlaps(target.EffectivePeriod) {
+			continue
+		}
+		if err := r.flagDefault(ctx, tx, other, false, touchedAt, written); err != nil {
+			return fmt.Errorf("failed to unset existing defaults: %w", err)
+		}
+	}
+	return nil
+}
+
+// flagDefault sets config's default flag, and its updated_at to touchedAt
+// unless it is nil. The change is a new version of config unless config is
+// in written.
+func (r *PackConfigurationRepository) flagDefault(ctx context.Context, tx *sql.Tx, config *entity.PackConfiguration, isDefault bool, touchedAt *time.Time, written []int) error {
+	config.IsDefault = isDefault
+	if !slices.Contains(written, config.ID) {
+		config.Version++
+		if err := r.insertVersion(ctx, tx, config); err != nil {
+			return err
+		}
+	}
+
+	_, err := tx.ExecContext(ctx, `UPDATE pack_configurations SET is_default = ?, version = ?, updated_at = COALESCE(?, updated_at) WHERE id = ?`,
+		config.IsDefault, config.Version, optionalTimeArg(touchedAt), config.ID)
+	return err
+}
+
+// defaults returns the tenant's configurations holding the default flag,
+// archived ones included, ordered by the start of their period with open
+// starts first
+func (r *PackConfigurationRepository) defaults(ctx context.Context, q queryer, tenant string) ([]*entity.PackConfiguration, error) {
+	return r.query(ctx, q, `
+		SELECT `+packConfigurationColumns+`
+		FROM pack_configurations
+		WHERE tenant_id = ? AND is_default = true
+		ORDER BY effective_from IS NOT NULL, effective_from, id
+	`, tenant)
+}
+
+// defaultOverlap returns a DefaultPeriodOverlapError naming the first of the
+// tenant's other defaults whose period overlaps period, which Postgres
+// enforces with an exclusion constraint
+func (r *PackConfigurationRepository) defaultOverlap(ctx context.Context, q queryer, tenant string, id int, period entity.EffectivePeriod) error {
+	defaults, err := r.defaults(ctx, q, tenant)
+	if err != nil {
+		return fmt.Errorf("failed to find the overlapping default: %w", err)
+	}
+
+	for _, other := range defaults {
+		if other.ID != id && other.Overlaps(period) {
+			return &errs.DefaultPeriodOverlapError{ID: id, ExistingID: other.ID}
+		}
+	}
+	return nil
+}
+
+// nameConflict returns a NameConflictError when another active configuration
+// of the tenant uses name, ignoring case, which Postgres enforces with a
+// unique index
+func (r *PackConfigurationRepository) nameConflict(ctx context.Context, q queryer, tenant string, name string, id int) error {
+	var existingID int
+	err := q.QueryRowContext(ctx, `
+		SELECT id FROM pack_configurations
+		WHERE tenant_id = ? AND is_active = true AND lower(name) = lower(?) AND id <> ?
+		ORDER BY id
+		LIMIT 1
+	`, tenant, name, id).Scan(&existingID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check pack configuration name: %w", err)
+	}
+	return &errs.NameConflictError{Name: name, ExistingID: existingID}
+}
+
+// atVersion returns the active configuration a version-conditioned write
+// applies to, or explains why there is none: either the configuration is
+// gone or it has moved past the expected version
+func (r *PackConfigurationRepository) atVersion(ctx context.Context, q queryer, tenant string, id int, expected int) (*entity.PackConfiguration, error) {
+	config, err := r.getByID(ctx, q, tenant, id)
+	if err != nil {
+		if errors.Is(err, errs.ErrPackConfigurationNotFound) {
+			return nil, fmt.Errorf("pack configuration with id %d not found or inactive: %w", id, errs.ErrPackConfigurationNotFound)
+		}
+		return nil, fmt.Errorf("failed to check pack configuration version: %w", err)
+	}
+	if config.Version != expected {
+		return nil, &errs.VersionConflictError{ID: id, ExpectedVersion: expected, CurrentVersion: config.Version}
+	}
+	return config, nil
+}
+
+func (r *PackConfigurationRepository) GetVersions(ctx context.Context, tenant string, id int) (_ []*entity.PackConfigurationVersion, err error) {
+	ctx, finish := db.WithQueryTimeout(ctx, r.queryTimeout, "GetVersions")
+	defer finish(&err)
+
+	query := `
+		SELECT ` + packConfigurationVersionColumns + `
+		FROM pack_configuration_versions v
+		JOIN pack_configurations pc ON pc.id = v.configuration_id
+		WHERE v.configuration_id = ? AND pc.tenant_id = ?
+		ORDER BY v.version DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, id, tenant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pack configuration versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []*entity.PackConfigurationVersion
+	for rows.Next() {
+		version, err := scanPackConfigurationVersion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan pack configuration version: %w", err)
+		}
+		versions = append(versions, version)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("pack configuration with id %d: %w", id, errs.ErrPackConfigurationNotFound)
+	}
+
+	return versions, nil
+}
+
+func (r *PackConfigurationRepository) GetVersion(ctx context.Context, tenant string, id int, version int) (_ *entity.PackConfigurationVersion, err error) {
+	ctx, finish := db.WithQueryTimeout(ctx, r.queryTimeout, "GetVersion")
+	defer finish(&err)
+
+	return r.getVersion(ctx, r.db, tenant, id, version)
+}
+
+func (r *PackConfigurationRepository) getVersion(ctx context.Context, q queryer, tenant string, id int, version int) (*entity.PackConfigurationVersion, error) {
+	query := `
+		SELECT ` + packConfigurationVersionColumns + `
+		FROM pack_configuration_versions v
+		JOIN pack_configurations pc ON pc.id = v.configuration_id
+		WHERE v.configuration_id = ? AND v.version = ? AND pc.tenant_id = ?
+	`
+
+	snapshot, err := scanPackConfigurationVersion(q.QueryRowContext(ctx, query, id, version, tenant))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("pack configuration %d version %d: %w", id, version, errs.ErrConfigurationVersionNotFound)
+		}
+		return nil, fmt.Errorf("failed to get pack configuration version: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+// packConfigurationVersionColumns are the snapshot columns scanPackConfigurationVersion reads
+const packConfigurationVersionColumns = `v.configuration_id, v.version, v.name, v.pack_sizes, v.pack_type_ids,
+		v.effective_from, v.effective_to, v.description, v.labels, v.metadata, v.created_at`
+
+// insertVersion snapshots the configuration's current contents under its current version
+func (r *PackConfigurationRepository) insertVersion(ctx context.Context, tx *sql.Tx, config *entity.PackConfiguration) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO pack_configuration_versions (configuration_id, version, name, pack_sizes, pack_type_ids,
+			effective_from, effective_to, description, labels, metadata, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, config.ID, config.Version, config.Name, intsArg(config.PackSizes), intsArg(config.PackTypeIDs),
+		optionalTimeArg(config.EffectiveFrom), optionalTimeArg(config.EffectiveTo),
+		config.Description, labelsArg(config.Labels), metadataArg(config.Metadata), timeArg(time.Now()))
+	if err != nil {
+		return fmt.Errorf("failed to record pack configuration version: %w", err)
+	}
+
+	return nil
+}
+
+func scanPackConfigurationVersion(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*entity.PackConfigurationVersion, error) {
+	snapshot := &entity.PackConfigurationVersion{}
+	var packSizes, packTypeIDs, labels string
+	var metadata sql.NullString
+	var effectiveFrom, effectiveTo sql.NullTime
+
+	err := scanner.Scan(
+		&snapshot.ConfigurationID,
+		&snapshot.Version,
+		&snapshot.Name,
+		&packSizes,
+		&packTypeIDs,
+		&effectiveFrom,
+		&effectiveTo,
+		&snapshot.Description,
+		&labels,
+		&metadata,
+		&snapshot.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(packSizes), &snapshot.PackSizes); err != nil {
+		return nil, fmt.Errorf("failed to decode pack sizes: %w", err)
+	}
+	if err := json.Unmarshal([]byte(packTypeIDs), &snapshot.PackTypeIDs); err != nil {
+		return nil, fmt.Errorf("failed to decode pack type IDs: %w", err)
+	}
+	if len(snapshot.PackTypeIDs) == 0 {
+		snapshot.PackTypeIDs = nil
+	}
+	if err := json.Unmarshal([]byte(labels), &snapshot.Labels); err != nil {
+		return nil, fmt.Errorf("failed to decode labels: %w", err)
+	}
+	if len(snapshot.Labels) == 0 {
+		snapshot.Labels = nil
+	}
+	if metadata.Valid {
+		snapshot.Metadata = json.RawMessage(metadata.String)
+	}
+	if effectiveFrom.Valid {
+		snapshot.EffectiveFrom = &effectiveFrom.Time
+	}
+	if effectiveTo.Valid {
+		snapshot.EffectiveTo = &effectiveTo.Time
+	}
+
+	return snapshot, nil
+}
+
+func (r *PackConfigurationRepository) RestoreVersion(ctx context.Context, tenant string, id int, version int) (_ *entity.PackConfiguration, err error) {
+	ctx, finish := db.WithQueryTimeout(ctx, r.queryTimeout, "RestoreVersion")
+	defer finish(&err)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	snapshot, err := r.getVersion(ctx, tx, tenant, id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := r.getByID(ctx, tx, tenant, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.nameConflict(ctx, tx, tenant, snapshot.Name, id); err != nil {
+		return nil, err
+	}
+
+	before := config.Clone()
+	snapshot.Restore(config)
+	if config.IsDefault {
+		if err := r.defaultOverlap(ctx, tx, tenant, id, config.EffectivePeriod); err != nil {
+			return nil, err
+		}
+	}
+
+	// Pack types deleted since, or whose item count the snapshot does not
+	// offer, are dropped
+	packTypeIDs, err := r.offeredPackTypes(ctx, tx, tenant, config)
+	if err != nil {
+		return nil, err
+	}
+
+	config.PackTypeIDs = packTypeIDs
+	config.Version++
+	config.UpdatedAt = timeArg(time.Now())
+	_, err = tx.ExecContext(ctx, `
+		UPDATE pack_configurations
+		SET name = ?, pack_sizes = ?, pack_type_ids = ?, effective_from = ?, effective_to = ?,
+			description = ?, labels = ?, metadata = ?, updated_at = ?, version = ?
+		WHERE id = ?
+	`, config.Name, intsArg(config.PackSizes), intsArg(config.PackTypeIDs), optionalTimeArg(config.EffectiveFrom), optionalTimeArg(config.EffectiveTo),
+		config.Description, labelsArg(config.Labels), metadataArg(config.Metadata), config.UpdatedAt, config.Version, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore pack configuration: %w", err)
+	}
+
+	if err := r.insertVersion(ctx, tx, config); err != nil {
+		return nil, err
+	}
+
+	if err := recordAudit(ctx, tx, entity.AuditActionRestore, before, config); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit pack configuration restore: %w", err)
+	}
+
+	return config, nil
+}
+
+// referencedPackTypes reads the state of the pack types the configuration
+// references. Inside a transaction it holds the database's write lock, which
+// pack type writes take too.
+func (r *PackConfigurationRepository) referencedPackTypes(ctx context.Context, q queryer, tenant string, config *entity.PackConfiguration) ([]*entity.PackType, error) {
+	if len(config.PackTypeIDs) == 0 {
+		return nil, nil
+	}
+
+	rows, err := q.QueryContext(ctx, `
+		SELECT id, item_count, is_active FROM pack_types
+		WHERE tenant_id = ? AND id IN (SELECT value FROM json_each(?))
+		ORDER BY id
+	`, tenant, intsArg(config.PackTypeIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check referenced pack types: %w", err)
+	}
+	defer rows.Close()
+
+	var packTypes []*entity.PackType
+	for rows.Next() {
+		packType := &entity.PackType{}
+		if err := rows.Scan(&packType.ID, &packType.ItemCount, &packType.IsActive); err != nil {
+			return nil, fmt.Errorf("failed to scan pack type: %w", err)
+		}
+		packTypes = append(packTypes, packType)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	return packTypes, nil
+}
+
+// checkPackTypes fails when a pack type the configuration references was
+// deleted or changed after the service resolved it
+func (r *PackConfigurationRepository) checkPackTypes(ctx context.Context, tx *sql.Tx, tenant string, config *entity.PackConfiguration) error {
+	packTypes, err := r.referencedPackTypes(ctx, tx, tenant, config)
+	if err != nil {
+		return err
+	}
+	return config.CheckPackTypes(packTypes)
+}
+
+// offeredPackTypes keeps the referenced pack types that are active and whose
+// item count the configuration offers
+func (r *PackConfigurationRepository) offeredPackTypes(ctx context.Context, tx *sql.Tx, tenant string, config *entity.PackConfiguration) ([]int, error) {
+	packTypes, err := r.referencedPackTypes(ctx, tx, tenant, config)
+	if err != nil {
+		return nil, err
+	}
+	return config.OfferedPackTypeIDs(packTypes), nil
+}
+
+func (r *PackConfigurationRepository) GetArchivedByID(ctx context.Context, tenant string, id int) (_ *entity.PackConfiguration, err error) {
+	ctx, finish := db.WithQueryTimeout(ctx, r.queryTimeout, "GetArchivedByID")
+	defer finish(&err)
+
+	return r.getArchivedByID(ctx, r.db, tenant, id)
+}
+
+func (r *PackConfigurationRepository) getArchivedByID(ctx context.Context, q queryer, tenant string, id int) (*entity.PackConfiguration, error) {
+	query := `SELECT ` + packConfigurationColumns + ` FROM pack_configurations WHERE id = ? AND tenant_id = ? AND is_active = false`
+
+	config, err := r.scanPackConfiguration(q.QueryRowContext(ctx, query, id, tenant))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("archived pack configuration with id %d: %w", id, errs.ErrPackConfigurationNotFound)
+		}
+		return nil, fmt.Errorf("failed to get archived pack configuration: %w", err)
+	}
+
+	return config, nil
+}
+
+func (r *PackConfigurationRepository) Restore(ctx context.Context, tenant string, id int, name string) (_ *entity.PackConfiguration, err error) {
+	ctx, finish := db.WithQueryTimeout(ctx, r.queryTimeout, "Restore")
+	defer finish(&err)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	config, err := r.getArchivedByID(ctx, tx, tenant, id)
+	if err != nil {
+		return nil, err
+	}
+	archived := config.Clone()
+
+	if name == "" {
+		name = config.Name
+	}
+	if err := r.nameConflict(ctx, tx, tenant, name, id); err != nil {
+		return nil, err
+	}
+
+	// Renaming, or dropping pack types deleted or changed while archived,
+	// changes the configuration's contents, so it gets a new version. The
+	// restored configuration comes back as a non-default; making it the
+	// default again is up to SetDefault.
+	packTypeIDs, err := r.offeredPackTypes(ctx, tx, tenant, config)
+	if err != nil {
+		return nil, err
+	}
+	changed := name != config.Name || len(packTypeIDs) != len(config.PackTypeIDs)
+	config.Name = name
+	config.PackTypeIDs = packTypeIDs
+	config.IsActive = true
+	config.IsDefault = false
+	config.UpdatedAt = timeArg(time.Now())
+	if changed {
+		config.Version++
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE pack_configurations
+		SET is_active = true, name = ?, pack_type_ids = ?, version = ?, is_default = ?, updated_at = ?
+		WHERE id = ?
+	`, config.Name, intsArg(config.PackTypeIDs), config.Version, config.IsDefault, config.UpdatedAt, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore pack configuration: %w", err)
+	}
+
+	if changed {
+		if err := r.insertVersion(ctx, tx, config); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := recordAudit(ctx, tx, entity.AuditActionUnarchive, archived, config); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit pack configuration restore: %w", err)
+	}
+
+	return config, nil
+}
+
+// Purge permanently removes an archived configuration and its versions.
+// Warehouses and calculation history are Postgres-only, so nothing else can
+// reference it.
+func (r *PackConfigurationRepository) Purge(ctx context.Context, tenant string, id int) (err error) {
+	ctx, finish := db.WithQueryTimeout(ctx, r.queryTimeout, "Purge")
+	defer finish(&err)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	archived, err := r.configuration(ctx, tx, tenant, id)
+	if err != nil {
+		return err
+	}
+	if archived == nil {
+		return fmt.Errorf("pack configuration with id %d: %w", id, errs.ErrPackConfigurationNotFound)
+	}
+	if archived.IsActive {
+		return errs.ErrConfigurationNotArchived.Withf("pack configuration %d must be deleted before it is purged", id)
+	}
+
+	statements := []string{
+		`DELETE FROM pack_configuration_versions WHERE configuration_id = ?`,
+		`DELETE FROM pack_configurations WHERE id = ?`,
+	}
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement, id); err != nil {
+			return fmt.Errorf("failed to purge pack configuration: %w", err)
+		}
+	}
+
+	if err := recordAudit(ctx, tx, entity.AuditActionPurge, archived, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *PackConfigurationRepository) Import(ctx context.Context, imp entity.PackConfigurationImport) (err error) {
+	ctx, finish := db.WithQueryTimeout(ctx, r.queryTimeout, "Import")
+	defer finish(&err)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	defaultID := imp.DefaultID
+	// The configurations the import writes, and what those it changes held before
+	var imported []int
+	before := make(map[int]*entity.PackConfiguration)
+	for _, config := range imp.Creates {
+		if err := config.Validate(); err != nil {
+			return err
+		}
+		if err := r.nameConflict(ctx, tx, imp.Tenant, config.Name, 0); err != nil {
+			return err
+		}
+		if err := r.checkPackTypes(ctx, tx, imp.Tenant, config); err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO pack_configurations (tenant_id, name, pack_sizes, is_default, is_active, version, created_at, updated_at, effective_from, effective_to, description, labels, metadata, pack_type_ids)
+			VALUES (?, ?, ?, false, true, 1, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, imp.Tenant, config.Name, intsArg(config.PackSizes), timeArg(config.CreatedAt), timeArg(config.UpdatedAt),
+			optionalTimeArg(config.EffectiveFrom), optionalTimeArg(config.EffectiveTo),
+			config.Description, labelsArg(config.Labels), metadataArg(config.Metadata), intsArg(config.PackTypeIDs))
+		if err != nil {
+			return fmt.Errorf("failed to import pack configuration %q: %w", config.Name, err)
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("failed to get pack configuration ID: %w", err)
+		}
+		config.ID = int(id)
+		config.Version = 1
+		imported = append(imported, config.ID)
+
+		if err := r.insertVersion(ctx, tx, config); err != nil {
+			return err
+		}
+		if config.IsDefault {
+			defaultID = config.ID
+		}
+	}
+
+	for _, config := range imp.Updates {
+		if err := config.Validate(); err != nil {
+			return err
+		}
+
+		existing, err := r.atVersion(ctx, tx, imp.Tenant, config.ID, config.Version)
+		if err != nil {
+			return err
+		}
+		before[config.ID] = existing
+		imported = append(imported, config.ID)
+		if err := r.nameConflict(ctx, tx, imp.Tenant, config.Name, config.ID); err != nil {
+			return err
+		}
+		if err := r.checkPackTypes(ctx, tx, imp.Tenant, config); err != nil {
+			return err
+		}
+		if existing.IsDefault {
+			if err := r.defaultOverlap(ctx, tx, imp.Tenant, config.ID, config.EffectivePeriod); err != nil {
+				return err
+			}
+		}
+
+		config.Version = existing.Version + 1
+		_, err = tx.ExecContext(ctx, `
+			UPDATE pack_configurations
+			SET name = ?, pack_sizes = ?, created_at = ?, updated_at = ?, effective_from = ?, effective_to = ?, version = ?,
+				description = ?, labels = ?, metadata = ?, pack_type_ids = ?
+			WHERE id = ?
+		`, config.Name, intsArg(config.PackSizes), timeArg(config.CreatedAt), timeArg(config.UpdatedAt),
+			optionalTimeArg(config.EffectiveFrom), optionalTimeArg(config.EffectiveTo), config.Version,
+			config.Description, labelsArg(config.Labels), metadataArg(config.Metadata), intsArg(config.PackTypeIDs), config.ID)
+		if err != nil {
+			return fmt.Errorf("failed to import pack configuration %q: %w", config.Name, err)
+		}
+		if err := r.insertVersion(ctx, tx, config); err != nil {
+			return err
+		}
+		if config.IsDefault {
+			defaultID = config.ID
+		}
+	}
+
+	if defaultID != 0 {
+		if !slices.Contains(imported, defaultID) {
+			if before[defaultID], err = r.configuration(ctx, tx, imp.Tenant, defaultID); err != nil {
+				return err
+			}
+		}
+		if err := r.importDefault(ctx, tx, imp.Tenant, defaultID, imported); err != nil {
+			return err
+		}
+	}
+
+	if err := r.auditImport(ctx, tx, imp.Tenant, imported, defaultID, before); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit pack configuration import: %w", err)
+	}
+	return nil
+}
+
+// auditImport records each configuration the import wrote, and the default
+// it moved onto a configuration it left alone, as the import leaves them
+func (r *PackConfigurationRepository) auditImport(ctx context.Context, tx *sql.Tx, tenant string, imported []int, defaultID int, before map[int]*entity.PackConfiguration) error {
+	if _, audited := entity.ActorFrom(ctx); !audited {
+		return nil
+	}
+
+	if defaultID != 0 && !slices.Contains(imported, defaultID) {
+		if err := r.auditChange(ctx, tx, entity.AuditActionSetDefault, before[defaultID]); err != nil {
+			return err
+		}
+	}
+
+	for _, id := range imported {
+		after, err := r.configuration(ctx, tx, tenant, id)
+		if err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, tx, entity.AuditActionImport, before[id], after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importDefault moves the default flag to id, like moveDefault, without
+// touching timestamps, which an import takes from the file
+func (r *PackConfigurationRepository) importDefault(ctx context.Context, tx *sql.Tx, tenant string, id int, imported []int) error {
+	target, err := r.getByID(ctx, tx, tenant, id)
+	if err != nil {
+		return err
+	}
+
+	if err := r.unsetOverlappingDefaults(ctx, tx, target, nil, imported); err != nil {
+		return err
+	}
+
+	if target.IsDefault {
+		return nil
+	}
+	if err := r.flagDefault(ctx, tx, target, true, nil, imported); err != nil {
+		return fmt.Errorf("failed to set imported default: %w", err)
+	}
+	return nil
+}
+
+// configuration returns the tenant's configuration id, active or archived, or
+// nil when there is none
+func (r *PackConfigurationRepository) configuration(ctx context.Context, q queryer, tenant string, id int) (*entity.PackConfiguration, error) {
+	query := `SELECT ` + packConfigurationColumns + ` FROM pack_configurations WHERE id = ? AND tenant_id = ?`
+
+	config, err := r.scanPackConfiguration(q.QueryRowContext(ctx, query, id, tenant))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pack configuration: %w", err)
+	}
+	return config, nil
+}
+
+// auditChange records the change from before to the configuration as tx now
+// holds it
+func (r *PackConfigurationRepository) auditChange(ctx context.Context, tx *sql.Tx, action entity.AuditAction, before *entity.PackConfiguration) error {
+	if _, audited := entity.ActorFrom(ctx); !audited {
+		return nil
+	}
+	after, err := r.configuration(ctx, tx, before.Tenant, before.ID)
+	if err != nil {
+		return err
+	}
+	return recordAudit(ctx, tx, action, before, after)
+}
+
+// recordAudit appends the change to the audit log in its transaction when ctx
+// carries an actor, so a change is never committed without its entry
+func recordAudit(ctx context.Context, tx *sql.Tx, action entity.AuditAction, before, after *entity.PackConfiguration) error {
+	entry, err := entity.PackConfigurationAuditEntry(ctx, action, before, after)
+	if err != nil {
+		return fmt.Errorf("failed to build audit entry: %w", err)
+	}
+	if entry == nil {
+		return nil
+	}
+	return insertAuditEntry(ctx, tx, entry)
+}
+
+// likeEscaper escapes LIKE wildcards so a search is matched literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// packConfigurationQueryClause builds the same WHERE clause as the Postgres
+// listing, reading the JSON columns with json_each
+func packConfigurationQueryClause(query entity.PackConfigurationQuery) (string, []interface{}) {
+	args := []interface{}{query.Tenant, !query.Archived}
+	conditions := []string{"tenant_id = ?", "is_active = ?"}
+
+	add := func(condition string, conditionArgs ...interface{}) {
+		args = append(args, conditionArgs...)
+		conditions = append(conditions, condition)
+	}
+
+	if query.Search != "" {
+		add(`lower(name) LIKE ? ESCAPE '\'`, likeEscaper.Replace(strings.ToLower(query.Search))+"%")
+	}
+	if query.PackSize != nil {
+		add("EXISTS (SELECT 1 FROM json_each(pack_sizes) WHERE value = ?)", *query.PackSize)
+	}
+	for _, key := range slices.Sorted(maps.Keys(query.Labels)) {
+		add("EXISTS (SELECT 1 FROM json_each(labels) WHERE key = ? AND value = ?)", key, query.Labels[key])
+	}
+	if query.CreatedFrom != nil {
+		add("created_at >= ?", timeArg(*query.CreatedFrom))
+	}
+	if query.CreatedTo != nil {
+		add("created_at < ?", timeArg(*query.CreatedTo))
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// packConfigurationOrderClause maps the requested sort onto a fixed column
+// list, with id as a tie-breaker so pages are stable
+func packConfigurationOrderClause(query entity.PackConfigurationQuery) string {
+	direction := "ASC"
+	if query.Descending {
+		direction = "DESC"
+	}
+
+	switch query.Sort {
+	case entity.PackConfigurationSortName:
+		return fmt.Sprintf("lower(name) %s, id %s", direction, direction)
+	case entity.PackConfigurationSortCreatedAt:
+		return fmt.Sprintf("created_at %s, id %s", direction, direction)
+	case entity.PackConfigurationSortUpdatedAt:
+		return fmt.Sprintf("updated_at %s, id %s", direction, direction)
+	}
+
+	if query.Archived {
+		return "updated_at DESC, id DESC"
+	}
+	return "is_default DESC, created_at DESC, id DESC"
+}
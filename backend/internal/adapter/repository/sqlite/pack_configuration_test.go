@@ -0,0 +1,104 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Schieck/packs-calculator/internal/adapter/repository/repositorytest"
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+	"github.com/Schieck/packs-calculator/pkg/db"
+)
+
+func openDatabase(t *testing.T) *db.DB {
+	database, err := db.Open("sqlite://"+t.TempDir()+"/packs.db", "../../../../migrations")
+	require.NoError(t, err)
+	t.Cleanup(func() { database.Close() })
+	return database
+}
+
+func newRepository(t *testing.T) *PackConfigurationRepository {
+	return NewPackConfigurationRepository(openDatabase(t).DB, time.Second)
+}
+
+func TestPackConfigurationRepository(t *testing.T) {
+	repositorytest.RunPackConfigurationRepositoryTests(t, func(t *testing.T) entity.PackConfigurationRepository {
+		return newRepository(t)
+	})
+}
+
+func TestPackConfigurationRepositoryAudit(t *testing.T) {
+	repositorytest.RunPackConfigurationAuditTests(t, func(t *testing.T) (entity.PackConfigurationRepository, entity.AuditRepository) {
+		database := openDatabase(t)
+		return NewPackConfigurationRepository(database.DB, time.Second), NewAuditRepository(database.DB)
+	})
+}
+
+func TestPackTypeReferences(t *testing.T) {
+	repositorytest.RunPackTypeReferenceTests(t, func(t *testing.T) (entity.PackConfigurationRepository, entity.PackTypeRepository) {
+		database := openDatabase(t)
+		return NewPackConfigurationRepository(database.DB, time.Second), NewPackTypeRepository(database.DB)
+	})
+}
+
+func TestPackConfigurationRepositoryJSONFilters(t *testing.T) {
+	repo := newRepository(t)
+	create := func(name string, labels entity.Labels, packSizes ...int) *entity.PackConfiguration {
+		config, err := entity.NewPackConfiguration(entity.DefaultTenant, name, packSizes)
+		require.NoError(t, err)
+		config.Labels = labels
+		created, err := repo.Create(t.Context(), config)
+		require.NoError(t, err)
+		return created
+	}
+	retail := create("Retail_1", entity.Labels{"channel": "retail"}, 250, 500)
+	wholesale := create("Retail 2", entity.Labels{"channel": "wholesale", "region": "eu"}, 500, 1000)
+
+	list := func(query entity.PackConfigurationQuery) []int {
+		query.Tenant, query.Limit = entity.DefaultTenant, 10
+		configs, _, err := repo.List(t.Context(), query)
+		require.NoError(t, err)
+		var ids []int
+		for _, config := range configs {
+			ids = append(ids, config.ID)
+		}
+		return ids
+	}
+
+	size := 1000
+	assert.Equal(t, []int{wholesale.ID}, list(entity.PackConfigurationQuery{PackSize: &size}))
+	assert.Equal(t, []int{retail.ID}, list(entity.PackConfigurationQuery{Labels: entity.Labels{"channel": "retail"}}))
+	assert.Empty(t, list(entity.PackConfigurationQuery{Labels: entity.Labels{"channel": "retail", "region": "eu"}}))
+	// Wildcards in the search are matched literally
+	assert.Equal(t, []int{retail.ID}, list(entity.PackConfigurationQuery{Search: "retail_"}))
+
+	found, err := repo.FindByPackSizes(t.Context(), entity.DefaultTenant, []int{250, 500})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, retail.ID, found[0].ID)
+	assert.Equal(t, entity.Labels{"channel": "retail"}, found[0].Labels)
+}
+
+func TestPackConfigurationRepositoryDeadline(t *testing.T) {
+	repo := newRepository(t)
+	config, err := entity.NewPackConfiguration(entity.DefaultTenant, "Standard", []int{250})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithDeadline(t.Context(), time.Now().Add(-time.Second))
+	defer cancel()
+	_, err = repo.Create(ctx, config)
+
+	var timeoutErr *errs.QueryTimeoutError
+	require.ErrorAs(t, err, &timeoutErr)
+	assert.Equal(t, "Create", timeoutErr.Operation)
+	assert.Zero(t, timeoutErr.Timeout, "the caller's deadline expired first")
+	assert.ErrorIs(t, err, errs.ErrQueryTimeout)
+
+	_, total, err := repo.List(t.Context(), entity.PackConfigurationQuery{Tenant: entity.DefaultTenant, Limit: 10})
+	require.NoError(t, err)
+	assert.Zero(t, total)
+}
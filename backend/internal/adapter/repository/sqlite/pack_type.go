@@ -0,0 +1,281 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+)
+
+const packTypeColumns = `id, tenant_id, code, label, item_count, length_mm, width_mm, height_mm, cost_cents, is_active, created_at, updated_at`
+
+type PackTypeRepository struct {
+	db *sql.DB
+}
+
+func NewPackTypeRepository(db *sql.DB) *PackTypeRepository {
+	return &PackTypeRepository{
+		db: db,
+	}
+}
+
+func (r *PackTypeRepository) scanPackType(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*entity.PackType, error) {
+	packType := &entity.PackType{}
+
+	err := scanner.Scan(
+		&packType.ID,
+		&packType.Tenant,
+		&packType.Code,
+		&packType.Label,
+		&packType.ItemCount,
+		&packType.LengthMM,
+		&packType.WidthMM,
+		&packType.HeightMM,
+		&packType.CostCents,
+		&packType.IsActive,
+		&packType.CreatedAt,
+		&packType.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return packType, nil
+}
+
+func (r *PackTypeRepository) query(ctx context.Context, query string, args ...interface{}) ([]*entity.PackType, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pack types: %w", err)
+	}
+	defer rows.Close()
+
+	packTypes := []*entity.PackType{}
+	for rows.Next() {
+		packType, err := r.scanPackType(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan pack type: %w", err)
+		}
+		packTypes = append(packTypes, packType)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return packTypes, nil
+}
+
+func (r *PackTypeRepository) GetAll(ctx context.Context, tenant string, includeInactive bool) ([]*entity.PackType, error) {
+	query := `
+		SELECT ` + packTypeColumns + `
+		FROM pack_types
+		WHERE tenant_id = ? AND (is_active = true OR ?)
+		ORDER BY lower(code) ASC, id ASC
+	`
+
+	return r.query(ctx, query, tenant, includeInactive)
+}
+
+func (r *PackTypeRepository) GetByID(ctx context.Context, tenant string, id int) (*entity.PackType, error) {
+	query := `
+		SELECT ` + packTypeColumns + `
+		FROM pack_types
+		WHERE id = ? AND tenant_id = ?
+	`
+
+	packType, err := r.scanPackType(r.db.QueryRowContext(ctx, query, id, tenant))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("pack type with id %d: %w", id, errs.ErrPackTypeNotFound)
+		}
+		return nil, fmt.Errorf("failed to get pack type: %w", err)
+	}
+
+	return packType, nil
+}
+
+func (r *PackTypeRepository) GetByIDs(ctx context.Context, tenant string, ids []int) ([]*entity.PackType, error) {
+	query := `
+		SELECT ` + packTypeColumns + `
+		FROM pack_types
+		WHERE tenant_id = ? AND id IN (SELECT value FROM json_each(?))
+		ORDER BY id ASC
+	`
+
+	return r.query(ctx, query, tenant, intsArg(ids))
+}
+
+func (r *PackTypeRepository) GetByCodes(ctx context.Context, tenant string, codes []string) ([]*entity.PackType, error) {
+	lowered := make([]string, len(codes))
+	for i, code := range codes {
+		lowered[i] = strings.ToLower(code)
+	}
+
+	query := `
+		SELECT ` + packTypeColumns + `
+		FROM pack_types
+		WHERE tenant_id = ? AND is_active = true AND lower(code) IN (SELECT value FROM json_each(?))
+		ORDER BY id ASC
+	`
+
+	return r.query(ctx, query, tenant, stringsArg(lowered))
+}
+
+func (r *PackTypeRepository) Create(ctx context.Context, packType *entity.PackType) (*entity.PackType, error) {
+	if err := packType.Validate(); err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO pack_types (tenant_id, code, label, item_count, length_mm, width_mm, height_mm, cost_cents, is_active, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	now := timeArg(time.Now())
+	result, err := r.db.ExecContext(ctx, query,
+		packType.Tenant,
+		packType.Code,
+		packType.Label,
+		packType.ItemCount,
+		packType.LengthMM,
+		packType.WidthMM,
+		packType.HeightMM,
+		packType.CostCents,
+		packType.IsActive,
+		now,
+		now,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, fmt.Errorf("code %q: %w", packType.Code, errs.ErrPackTypeCodeConflict)
+		}
+		return nil, fmt.Errorf("failed to create pack type: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pack type ID: %w", err)
+	}
+	packType.ID = int(id)
+	packType.CreatedAt = now
+	packType.UpdatedAt = now
+
+	return packType, nil
+}
+
+// Update and Delete check for referencing configurations in the transaction
+// that writes, which holds the database's write lock: configuration writes,
+// which check the pack types they reference, cannot interleave.
+func (r *PackTypeRepository) Update(ctx context.Context, packType *entity.PackType) (*entity.PackType, error) {
+	if err := packType.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var itemCount int
+	err = tx.QueryRowContext(ctx, `SELECT item_count FROM pack_types WHERE id = ? AND tenant_id = ?`, packType.ID, packType.Tenant).Scan(&itemCount)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("pack type with id %d: %w", packType.ID, errs.ErrPackTypeNotFound)
+		}
+		return nil, fmt.Errorf("failed to get pack type: %w", err)
+	}
+	if itemCount != packType.ItemCount {
+		if err := inUse(ctx, tx, packType.Tenant, packType.ID, "change the item count of"); err != nil {
+			return nil, err
+		}
+	}
+
+	query := `
+		UPDATE pack_types
+		SET code = ?, label = ?, item_count = ?, length_mm = ?, width_mm = ?, height_mm = ?, cost_cents = ?,
+			is_active = ?, updated_at = ?
+		WHERE id = ? AND tenant_id = ?
+		RETURNING created_at
+	`
+
+	packType.UpdatedAt = timeArg(time.Now())
+	err = tx.QueryRowContext(ctx, query,
+		packType.Code,
+		packType.Label,
+		packType.ItemCount,
+		packType.LengthMM,
+		packType.WidthMM,
+		packType.HeightMM,
+		packType.CostCents,
+		packType.IsActive,
+		packType.UpdatedAt,
+		packType.ID,
+		packType.Tenant,
+	).Scan(&packType.CreatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, fmt.Errorf("code %q: %w", packType.Code, errs.ErrPackTypeCodeConflict)
+		}
+		return nil, fmt.Errorf("failed to update pack type: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit pack type update: %w", err)
+	}
+	return packType, nil
+}
+
+func (r *PackTypeRepository) Delete(ctx context.Context, tenant string, id int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE pack_types SET is_active = false, updated_at = ? WHERE id = ? AND tenant_id = ? AND is_active = true`
+
+	result, err := tx.ExecContext(ctx, query, timeArg(time.Now()), id, tenant)
+	if err != nil {
+		return fmt.Errorf("failed to delete pack type: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("pack type with id %d: %w", id, errs.ErrPackTypeNotFound)
+	}
+	if err := inUse(ctx, tx, tenant, id, "delete"); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// inUse refuses the action while active configurations reference the pack type
+func inUse(ctx context.Context, tx *sql.Tx, tenant string, id int, action string) error {
+	query := `
+		SELECT COUNT(*)
+		FROM pack_configurations
+		WHERE tenant_id = ? AND is_active = true AND EXISTS (SELECT 1 FROM json_each(pack_type_ids) WHERE value = ?)
+	`
+
+	var count int
+	if err := tx.QueryRowContext(ctx, query, tenant, id).Scan(&count); err != nil {
+		return fmt.Errorf("failed to count configurations referencing pack type: %w", err)
+	}
+	if count > 0 {
+		return errs.ErrPackTypeInUse.Withf("cannot %s pack type %d: %d active pack configuration(s) reference it", action, id, count)
+	}
+	return nil
+}
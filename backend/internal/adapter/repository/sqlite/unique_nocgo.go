@@ -0,0 +1,9 @@
+//go:build !cgo
+
+package sqlite
+
+// isUniqueViolation reports a write rejected by a unique index. Without cgo
+// the go-sqlite3 driver cannot open a database, so no write gets that far.
+func isUniqueViolation(err error) bool {
+	return false
+}
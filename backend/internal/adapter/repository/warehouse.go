@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+	"github.com/lib/pq"
+)
+
+type WarehouseRepository struct {
+	db *sql.DB
+}
+
+func NewWarehouseRepository(db *sql.DB) *WarehouseRepository {
+	return &WarehouseRepository{
+		db: db,
+	}
+}
+
+func (r *WarehouseRepository) scanWarehouse(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*entity.Warehouse, error) {
+	warehouse := &entity.Warehouse{}
+
+	err := scanner.Scan(
+		&warehouse.ID,
+		&warehouse.Tenant,
+		&warehouse.Name,
+		&warehouse.Location,
+		&warehouse.PackConfigurationID,
+		&warehouse.IsActive,
+		&warehouse.CreatedAt,
+		&warehouse.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return warehouse, nil
+}
+
+func (r *WarehouseRepository) GetAll(ctx context.Context, tenant string) ([]*entity.Warehouse, error) {
+	query := `
+		SELECT id, tenant_id, name, location, pack_configuration_id, is_active, created_at, updated_at
+		FROM warehouses
+		WHERE tenant_id = $1 AND is_active = true
+		ORDER BY id ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tenant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query warehouses: %w", err)
+	}
+	defer rows.Close()
+
+	var warehouses []*entity.Warehouse
+	for rows.Next() {
+		warehouse, err := r.scanWarehouse(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan warehouse: %w", err)
+		}
+		warehouses = append(warehouses, warehouse)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return warehouses, nil
+}
+
+func (r *WarehouseRepository) GetByID(ctx context.Context, tenant string, id int) (*entity.Warehouse, error) {
+	query := `
+		SELECT id, tenant_id, name, location, pack_configuration_id, is_active, created_at, updated_at
+		FROM warehouses
+		WHERE tenant_id = $1 AND id = $2 AND is_active = true
+	`
+
+	warehouse, err := r.scanWarehouse(r.db.QueryRowContext(ctx, query, tenant, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("warehouse with id %d: %w", id, errs.ErrWarehouseNotFound)
+		}
+		return nil, fmt.Errorf("failed to get warehouse: %w", err)
+	}
+
+	return warehouse, nil
+}
+
+func (r *WarehouseRepository) Create(ctx context.Context, warehouse *entity.Warehouse) (*entity.Warehouse, error) {
+	if err := warehouse.Validate(); err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO warehouses (tenant_id, name, location, pack_configuration_id, is_active)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		warehouse.Tenant,
+		warehouse.Name,
+		warehouse.Location,
+		warehouse.PackConfigurationID,
+		warehouse.IsActive,
+	).Scan(
+		&warehouse.ID,
+		&warehouse.CreatedAt,
+		&warehouse.UpdatedAt,
+	)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			return nil, fmt.Errorf("location %q: %w", warehouse.Location, errs.ErrLocationInUse)
+		}
+		return nil, fmt.Errorf("failed to create warehouse: %w", err)
+	}
+
+	return warehouse, nil
+}
+
+func (r *WarehouseRepository) Delete(ctx context.Context, tenant string, id int) error {
+	query := `UPDATE warehouses SET is_active = false, updated_at = CURRENT_TIMESTAMP WHERE tenant_id = $1 AND id = $2 AND is_active = true`
+
+	result, err := r.db.ExecContext(ctx, query, tenant, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete warehouse: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("warehouse with id %d: %w", id, errs.ErrWarehouseNotFound)
+	}
+
+	return nil
+}
@@ -0,0 +1,318 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+	"github.com/lib/pq"
+)
+
+// WebhookRepository keeps webhooks and their deliveries, and is the outbox
+// the webhook dispatcher drains
+type WebhookRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) *WebhookRepository {
+	return &WebhookRepository{
+		db: db,
+	}
+}
+
+// insertOutboxEvent records an event in the transaction of the change it
+// describes, so that it is published if and only if the change commits
+func insertOutboxEvent(ctx context.Context, tx *sql.Tx, tenant string, eventType entity.EventType, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO outbox_events (tenant_id, event_type, payload) VALUES ($1, $2, $3::jsonb)`,
+		tenant, string(eventType), string(payload))
+	if err != nil {
+		return fmt.Errorf("failed to record %s event: %w", eventType, err)
+	}
+	return nil
+}
+
+func eventTypesArg(eventTypes []entity.EventType) pq.StringArray {
+	values := make(pq.StringArray, len(eventTypes))
+	for i, eventType := range eventTypes {
+		values[i] = string(eventType)
+	}
+	return values
+}
+
+func (r *WebhookRepository) scanWebhook(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*entity.Webhook, error) {
+	webhook := &entity.Webhook{}
+	var eventTypes pq.StringArray
+	if err := scanner.Scan(&webhook.ID, &webhook.Tenant, &webhook.URL, &webhook.Secret, &eventTypes, &webhook.CreatedAt); err != nil {
+		return nil, err
+	}
+	webhook.EventTypes = make([]entity.EventType, len(eventTypes))
+	for i, eventType := range eventTypes {
+		webhook.EventTypes[i] = entity.EventType(eventType)
+	}
+	return webhook, nil
+}
+
+func (r *WebhookRepository) List(ctx context.Context, tenant string) ([]*entity.Webhook, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, tenant_id, url, secret, event_types, created_at
+		FROM webhooks WHERE tenant_id = $1 ORDER BY id
+	`, tenant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	webhooks := []*entity.Webhook{}
+	for rows.Next() {
+		webhook, err := r.scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+func (r *WebhookRepository) Create(ctx context.Context, webhook *entity.Webhook) (*entity.Webhook, error) {
+	if err := webhook.Validate(); err != nil {
+		return nil, err
+	}
+
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO webhooks (tenant_id, url, secret, event_types)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`, webhook.Tenant, webhook.URL, webhook.Secret, eventTypesArg(webhook.EventTypes)).Scan(&webhook.ID, &webhook.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+func (r *WebhookRepository) Delete(ctx context.Context, tenant string, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhooks WHERE id = $1 AND tenant_id = $2`, id, tenant)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("webhook with id %d: %w", id, errs.ErrWebhookNotFound)
+	}
+	return nil
+}
+
+// webhookDeliveryColumns are the columns scanWebhookDelivery reads, from
+// webhook_deliveries joined as d with outbox_events as e
+const webhookDeliveryColumns = `d.id, d.webhook_id, d.event_id, e.event_type, d.status, d.attempts, d.next_attempt_at,
+	d.last_status_code, d.last_error, d.created_at, d.delivered_at`
+
+func scanWebhookDelivery(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*entity.WebhookDelivery, error) {
+	delivery := &entity.WebhookDelivery{}
+	var (
+		eventType  string
+		status     string
+		statusCode sql.NullInt64
+		lastError  sql.NullString
+	)
+	err := scanner.Scan(&delivery.ID, &delivery.WebhookID, &delivery.EventID, &eventType, &status, &delivery.Attempts,
+		&delivery.NextAttemptAt, &statusCode, &lastError, &delivery.CreatedAt, &delivery.DeliveredAt)
+	if err != nil {
+		return nil, err
+	}
+	delivery.EventType = entity.EventType(eventType)
+	delivery.Status = entity.WebhookDeliveryStatus(status)
+	delivery.LastStatusCode = int(statusCode.Int64)
+	delivery.LastError = lastError.String
+	return delivery, nil
+}
+
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, filter entity.WebhookDeliveryFilter) ([]*entity.WebhookDelivery, int, error) {
+	where := ` WHERE w.tenant_id = $1`
+	args := []interface{}{filter.Tenant}
+	if filter.WebhookID != 0 {
+		args = append(args, filter.WebhookID)
+		where += fmt.Sprintf(` AND d.webhook_id = $%d`, len(args))
+	}
+	if filter.Status != "" {
+		args = append(args, string(filter.Status))
+		where += fmt.Sprintf(` AND d.status = $%d`, len(args))
+	}
+	from := ` FROM webhook_deliveries d JOIN webhooks w ON w.id = d.webhook_id JOIN outbox_events e ON e.id = d.event_id`
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*)`+from+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count webhook deliveries: %w", err)
+	}
+
+	query := fmt.Sprintf(`SELECT %s%s%s ORDER BY d.id DESC LIMIT $%d OFFSET $%d`,
+		webhookDeliveryColumns, from, where, len(args)+1, len(args)+2)
+	rows, err := r.db.QueryContext(ctx, query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := make([]*entity.WebhookDelivery, 0, filter.Limit)
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate webhook deliveries: %w", err)
+	}
+	return deliveries, total, nil
+}
+
+func (r *WebhookRepository) Redeliver(ctx context.Context, tenant string, deliveryID int64) (*entity.WebhookDelivery, error) {
+	delivery, err := scanWebhookDelivery(r.db.QueryRowContext(ctx, `
+		WITH redelivered AS (
+			UPDATE webhook_deliveries d
+			SET status = 'pending', attempts = 0, next_attempt_at = CURRENT_TIMESTAMP
+			FROM webhooks w
+			WHERE d.id = $1 AND w.id = d.webhook_id AND w.tenant_id = $2 AND d.status = 'dead'
+			RETURNING d.*
+		)
+		SELECT `+webhookDeliveryColumns+`
+		FROM redelivered d JOIN outbox_events e ON e.id = d.event_id
+	`, deliveryID, tenant))
+	if err == nil {
+		return delivery, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to redeliver webhook delivery: %w", err)
+	}
+
+	// Tell a delivery that is not dead from one that does not exist
+	var status string
+	err = r.db.QueryRowContext(ctx, `
+		SELECT d.status FROM webhook_deliveries d JOIN webhooks w ON w.id = d.webhook_id
+		WHERE d.id = $1 AND w.tenant_id = $2
+	`, deliveryID, tenant).Scan(&status)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("webhook delivery with id %d: %w", deliveryID, errs.ErrWebhookDeliveryNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	return nil, errs.ErrDeliveryNotDead.Withf("webhook delivery %d is %s", deliveryID, status)
+}
+
+// FanOut locks the events it takes and skips those another dispatcher holds
+func (r *WebhookRepository) FanOut(ctx context.Context, limit int) (int, error) {
+	result, err := r.db.ExecContext(ctx, `
+		WITH events AS (
+			SELECT id, tenant_id, event_type FROM outbox_events
+			WHERE dispatched_at IS NULL
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		), deliveries AS (
+			INSERT INTO webhook_deliveries (webhook_id, event_id)
+			SELECT w.id, e.id
+			FROM events e
+			JOIN webhooks w ON w.tenant_id = e.tenant_id
+				AND (cardinality(w.event_types) = 0 OR e.event_type = ANY(w.event_types))
+			ON CONFLICT (webhook_id, event_id) DO NOTHING
+		)
+		UPDATE outbox_events SET dispatched_at = CURRENT_TIMESTAMP
+		WHERE id IN (SELECT id FROM events)
+	`, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fan out outbox events: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(rowsAffected), nil
+}
+
+// ClaimDue leases the deliveries by pushing their next attempt past lease;
+// recording the attempt replaces it, and a dispatcher that dies holding them
+// lets the lease run out
+func (r *WebhookRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*entity.DueWebhookDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH due AS (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= CURRENT_TIMESTAMP
+			ORDER BY next_attempt_at, id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = CURRENT_TIMESTAMP + $2 * interval '1 millisecond'
+		FROM due, webhooks w, outbox_events e
+		WHERE d.id = due.id AND w.id = d.webhook_id AND e.id = d.event_id
+		RETURNING d.id, d.attempts, w.url, w.secret, e.id, e.tenant_id, e.event_type, e.payload, e.created_at
+	`, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var claimed []*entity.DueWebhookDelivery
+	for rows.Next() {
+		due := &entity.DueWebhookDelivery{}
+		var eventType string
+		var payload []byte
+		err := rows.Scan(&due.ID, &due.Attempts, &due.URL, &due.Secret,
+			&due.Event.ID, &due.Event.Tenant, &eventType, &payload, &due.Event.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan claimed webhook delivery: %w", err)
+		}
+		due.Event.Type = entity.EventType(eventType)
+		due.Event.Data = json.RawMessage(payload)
+		claimed = append(claimed, due)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate claimed webhook deliveries: %w", err)
+	}
+	return claimed, nil
+}
+
+func (r *WebhookRepository) RecordAttempt(ctx context.Context, attempt entity.WebhookAttempt) error {
+	status := entity.WebhookDeliveryPending
+	switch {
+	case attempt.Delivered:
+		status = entity.WebhookDeliveryDelivered
+	case attempt.NextAttemptAt == nil:
+		status = entity.WebhookDeliveryDead
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $2,
+			attempts = attempts + 1,
+			next_attempt_at = COALESCE($3, next_attempt_at),
+			last_status_code = NULLIF($4, 0),
+			last_error = NULLIF($5, ''),
+			delivered_at = CASE WHEN $6 THEN CURRENT_TIMESTAMP END
+		WHERE id = $1
+	`, attempt.DeliveryID, string(status), attempt.NextAttemptAt, attempt.StatusCode, attempt.Error, attempt.Delivered)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook delivery attempt: %w", err)
+	}
+	return nil
+}
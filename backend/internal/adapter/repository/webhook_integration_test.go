@@ -0,0 +1,95 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+)
+
+// TestWebhookOutbox follows a configuration change from the outbox to a dead
+// letter and back, in a tenant of its own so other tests' events stay out
+func TestWebhookOutbox(t *testing.T) {
+	database := openTestDatabase(t, testDSN())
+	configurations := NewPackConfigurationRepository(database.DB, 5*time.Second)
+	webhooks := NewWebhookRepository(database.DB)
+	tenant := fmt.Sprintf("outbox-%d", time.Now().UnixNano())
+
+	// Fan out whatever other tests left behind, before this tenant has a webhook
+	for {
+		fannedOut, err := webhooks.FanOut(t.Context(), 100)
+		require.NoError(t, err)
+		if fannedOut < 100 {
+			break
+		}
+	}
+
+	webhook, err := webhooks.Create(t.Context(), &entity.Webhook{
+		Tenant:     tenant,
+		URL:        "http://localhost:9090/",
+		Secret:     "0123456789abcdef",
+		EventTypes: []entity.EventType{entity.EventPackConfigurationCreated},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = webhooks.Delete(context.Background(), tenant, webhook.ID) })
+
+	config, err := entity.NewPackConfiguration(tenant, "Outbox", []int{250, 500})
+	require.NoError(t, err)
+	created, err := configurations.Create(t.Context(), config)
+	require.NoError(t, err)
+	require.NoError(t, configurations.Delete(t.Context(), tenant, created.ID, created.Version))
+	t.Cleanup(func() { _ = configurations.Purge(context.Background(), tenant, created.ID) })
+
+	fannedOut, err := webhooks.FanOut(t.Context(), 100)
+	require.NoError(t, err)
+	assert.Equal(t, 2, fannedOut, "the create and the delete")
+
+	due, err := webhooks.ClaimDue(t.Context(), 100, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, due, 1, "the webhook only subscribes to creations")
+	assert.Equal(t, webhook.URL, due[0].URL)
+	assert.Equal(t, entity.EventPackConfigurationCreated, due[0].Event.Type)
+	assert.Equal(t, tenant, due[0].Event.Tenant)
+	var event entity.PackConfigurationEvent
+	require.NoError(t, json.Unmarshal(due[0].Event.Data, &event))
+	assert.Equal(t, created.ID, event.Configuration.ID)
+
+	claimedAgain, err := webhooks.ClaimDue(t.Context(), 100, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, claimedAgain, "claimed deliveries are leased")
+
+	_, err = webhooks.Redeliver(t.Context(), tenant, due[0].ID)
+	assert.ErrorIs(t, err, errs.ErrDeliveryNotDead)
+
+	require.NoError(t, webhooks.RecordAttempt(t.Context(), entity.WebhookAttempt{
+		DeliveryID: due[0].ID,
+		StatusCode: 503,
+		Error:      "unexpected status 503 Service Unavailable",
+	}))
+
+	deadLetters, total, err := webhooks.ListDeliveries(t.Context(), entity.WebhookDeliveryFilter{
+		Tenant: tenant, Status: entity.WebhookDeliveryDead, Limit: 10,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, deadLetters, 1)
+	assert.Equal(t, 1, deadLetters[0].Attempts)
+	assert.Equal(t, 503, deadLetters[0].LastStatusCode)
+
+	redelivered, err := webhooks.Redeliver(t.Context(), tenant, due[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, entity.WebhookDeliveryPending, redelivered.Status)
+	assert.Zero(t, redelivered.Attempts)
+
+	_, err = webhooks.Redeliver(t.Context(), "another-tenant", due[0].ID)
+	assert.ErrorIs(t, err, errs.ErrWebhookDeliveryNotFound)
+}
@@ -0,0 +1,101 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeBucket is the granularity analytics series are grouped by.
+// Values match PostgreSQL date_trunc fields.
+type TimeBucket string
+
+const (
+	BucketHour  TimeBucket = "hour"
+	BucketDay   TimeBucket = "day"
+	BucketWeek  TimeBucket = "week"
+	BucketMonth TimeBucket = "month"
+)
+
+func ParseTimeBucket(value string) (TimeBucket, error) {
+	switch bucket := TimeBucket(value); bucket {
+	case BucketHour, BucketDay, BucketWeek, BucketMonth:
+		return bucket, nil
+	case "":
+		return BucketDay, nil
+	default:
+		return "", fmt.Errorf("unsupported time bucket %q: use hour, day, week or month", value)
+	}
+}
+
+// Duration is the nominal length of a bucket, used to bound series size
+func (b TimeBucket) Duration() time.Duration {
+	switch b {
+	case BucketHour:
+		return time.Hour
+	case BucketWeek:
+		return 7 * 24 * time.Hour
+	case BucketMonth:
+		return 30 * 24 * time.Hour
+	default:
+		return 24 * time.Hour
+	}
+}
+
+// AnalyticsFilter scopes an aggregation to one tenant's calculations in a
+// half-open [From, To) range
+type AnalyticsFilter struct {
+	Tenant          string
+	From            time.Time
+	To              time.Time
+	ConfigurationID *int
+	Bucket          TimeBucket
+	Limit           int
+}
+
+// SurplusPoint is the shipped surplus within one time bucket
+type SurplusPoint struct {
+	Bucket       time.Time
+	Calculations int
+	TotalItems   int64
+	TotalSurplus int64
+}
+
+// ExactMatchRate is the share of calculations with zero surplus for one
+// configuration within one time bucket. ConfigurationID is nil for
+// calculations made with ad-hoc pack sizes.
+type ExactMatchRate struct {
+	Bucket          time.Time
+	ConfigurationID *int
+	Calculations    int
+	ExactMatches    int
+}
+
+func (r *ExactMatchRate) Rate() float64 {
+	if r.Calculations == 0 {
+		return 0
+	}
+	return float64(r.ExactMatches) / float64(r.Calculations)
+}
+
+// PackSizeUsage counts how often a pack size was shipped
+type PackSizeUsage struct {
+	PackSize     int
+	Packs        int64
+	Calculations int
+}
+
+// PacksPerOrderPoint holds packs-per-order percentiles within one time bucket
+type PacksPerOrderPoint struct {
+	Bucket       time.Time
+	Calculations int
+	P50          float64
+	P95          float64
+}
+
+type AnalyticsRepository interface {
+	SurplusByBucket(filter AnalyticsFilter) ([]*SurplusPoint, error)
+	ExactMatchRates(filter AnalyticsFilter) ([]*ExactMatchRate, error)
+	// PackSizeUsage returns the most shipped pack sizes first, up to filter.Limit
+	PackSizeUsage(filter AnalyticsFilter) ([]*PackSizeUsage, error)
+	PacksPerOrder(filter AnalyticsFilter) ([]*PacksPerOrderPoint, error)
+}
@@ -0,0 +1,161 @@
+package entity
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Audited entity types
+const (
+	AuditEntityPackConfiguration = "pack_configuration"
+)
+
+type AuditAction string
+
+const (
+	AuditActionCreate     AuditAction = "create"
+	AuditActionUpdate     AuditAction = "update"
+	AuditActionDelete     AuditAction = "delete"
+	AuditActionSetDefault AuditAction = "set_default"
+	AuditActionRestore    AuditAction = "restore"
+	AuditActionUnarchive  AuditAction = "unarchive"
+	AuditActionPurge      AuditAction = "purge"
+	AuditActionImport     AuditAction = "import"
+)
+
+// Actor identifies who made a change, the tenant it acted for and the
+// request it came from
+type Actor struct {
+	Subject   string
+	Tenant    string
+	RequestID string
+}
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx carrying actor. Repositories append the
+// changes they write under it to the audit log, in the change's own
+// transaction; writes under a context without an actor are not audited.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom returns the actor ctx carries, if any
+func ActorFrom(ctx context.Context) (Actor, bool) {
+	actor, ok := ctx.Value(actorKey{}).(Actor)
+	return actor, ok
+}
+
+// AuditEntry is one append-only record of a change. Before is empty for
+// creations and After is empty for deletions.
+type AuditEntry struct {
+	ID         int64           `db:"id" json:"id"`
+	Tenant     string          `db:"tenant_id" json:"tenant"`
+	EntityType string          `db:"entity_type" json:"entity_type"`
+	EntityID   int             `db:"entity_id" json:"entity_id"`
+	Action     AuditAction     `db:"action" json:"action"`
+	Actor      string          `db:"actor" json:"actor"`
+	RequestID  string          `db:"request_id" json:"request_id,omitempty"`
+	Before     json.RawMessage `db:"before" json:"before,omitempty"`
+	After      json.RawMessage `db:"after" json:"after,omitempty"`
+	CreatedAt  time.Time       `db:"created_at" json:"created_at"`
+}
+
+// NewAuditEntry snapshots before and after as JSON. Pass nil for a missing side.
+func NewAuditEntry(entityType string, entityID int, action AuditAction, actor Actor, before, after interface{}) (*AuditEntry, error) {
+	entry := &AuditEntry{
+		Tenant:     actor.Tenant,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		Actor:      actor.Subject,
+		RequestID:  actor.RequestID,
+		CreatedAt:  time.Now(),
+	}
+
+	var err error
+	if entry.Before, err = snapshot(before); err != nil {
+		return nil, fmt.Errorf("failed to snapshot audit before state: %w", err)
+	}
+	if entry.After, err = snapshot(after); err != nil {
+		return nil, fmt.Errorf("failed to snapshot audit after state: %w", err)
+	}
+
+	if err := entry.Validate(); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// PackConfigurationAuditEntry builds the entry recording a configuration
+// change made under ctx, or returns nil when ctx carries no actor
+func PackConfigurationAuditEntry(ctx context.Context, action AuditAction, before, after *PackConfiguration) (*AuditEntry, error) {
+	actor, ok := ActorFrom(ctx)
+	if !ok {
+		return nil, nil
+	}
+
+	id := 0
+	if after != nil {
+		id = after.ID
+	} else if before != nil {
+		id = before.ID
+	}
+	return NewAuditEntry(AuditEntityPackConfiguration, id, action, actor, before, after)
+}
+
+func (ae *AuditEntry) Validate() error {
+	if ae.Tenant == "" {
+		return fmt.Errorf("audit tenant cannot be empty")
+	}
+
+	if ae.EntityType == "" {
+		return fmt.Errorf("audit entity type cannot be empty")
+	}
+
+	if ae.EntityID <= 0 {
+		return fmt.Errorf("audit entity ID must be positive, got %d", ae.EntityID)
+	}
+
+	if ae.Action == "" {
+		return fmt.Errorf("audit action cannot be empty")
+	}
+
+	if ae.Actor == "" {
+		return fmt.Errorf("audit actor cannot be empty")
+	}
+
+	return nil
+}
+
+func snapshot(state interface{}) (json.RawMessage, error) {
+	if state == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(state)
+	if err != nil || string(data) == "null" {
+		return nil, err
+	}
+	return data, nil
+}
+
+// AuditFilter narrows audit listings. Zero values mean "no constraint".
+type AuditFilter struct {
+	Tenant     string
+	EntityType string
+	EntityID   *int
+	Actor      string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
+
+type AuditRepository interface {
+	Append(entry *AuditEntry) (*AuditEntry, error)
+	// List returns one page of entries, newest first, plus the total matching the filter
+	List(filter AuditFilter) ([]*AuditEntry, int, error)
+}
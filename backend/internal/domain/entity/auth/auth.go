@@ -10,13 +10,14 @@ type AuthRequest struct {
 
 type AuthResult struct {
 	Token     string
+	Tenant    string
 	ExpiresAt time.Time
 }
 
 type AuthService interface {
 	Authenticate(req AuthRequest) (*AuthResult, error)
 	ValidateToken(token string) (*JWTClaims, error)
-	GenerateToken(subject string) (*AuthResult, error)
+	GenerateToken(subject, tenant string) (*AuthResult, error)
 }
 
 type AuthenticateUseCase interface {
@@ -25,6 +26,8 @@ type AuthenticateUseCase interface {
 
 type AuthConfig interface {
 	GetAuthSecret() string
+	// GetTenantSecrets maps each tenant to the secret that authenticates it
+	GetTenantSecrets() map[string]string
 	GetTokenExpiration() time.Duration
 	GetIssuer() string
 }
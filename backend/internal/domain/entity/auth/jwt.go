@@ -4,13 +4,25 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+
+	domain "github.com/Schieck/packs-calculator/internal/domain/entity"
 )
 
 type JWTClaims struct {
 	Subject string `json:"sub"`
+	Tenant  string `json:"tenant,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// TenantOrDefault is the tenant the token acts for. Tokens issued before
+// tenants existed carry no claim and act for the default tenant.
+func (c JWTClaims) TenantOrDefault() string {
+	if c.Tenant == "" {
+		return domain.DefaultTenant
+	}
+	return c.Tenant
+}
+
 type ValidateTokenUseCase interface {
 	Execute(token string) (*JWTClaims, error)
 }
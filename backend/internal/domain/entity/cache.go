@@ -0,0 +1,29 @@
+package entity
+
+import "time"
+
+// CacheStats is a snapshot of a cache's counters since it started
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+	// Evictions counts entries dropped to stay within MaxEntries
+	Evictions uint64
+	// Expirations counts entries found older than TTL
+	Expirations uint64
+	// Invalidations counts entries dropped because of a write, here or on
+	// another replica
+	Invalidations uint64
+	Entries       int
+	MaxEntries    int
+	TTL           time.Duration
+}
+
+// HitRatio is the share of lookups answered from the cache, or zero before
+// the first lookup
+func (s CacheStats) HitRatio() float64 {
+	lookups := s.Hits + s.Misses
+	if lookups == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(lookups)
+}
@@ -0,0 +1,103 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// Solver names recorded with each stored calculation
+const (
+	SolverUnboundedDP = "unbounded-dp"
+	SolverBoundedDP   = "bounded-dp"
+)
+
+// CalculationRecord is a committed calculation kept for audits and disputes
+type CalculationRecord struct {
+	ID                   int64         `db:"id" json:"id"`
+	Tenant               string        `db:"tenant_id" json:"tenant"`
+	Subject              string        `db:"subject" json:"subject"`
+	Items                int           `db:"items" json:"items"`
+	PackSizes            []int         `db:"pack_sizes" json:"pack_sizes"`
+	ConfigurationID      *int          `db:"configuration_id" json:"configuration_id,omitempty"`
+	ConfigurationVersion *int          `db:"configuration_version" json:"configuration_version,omitempty"`
+	Location             string        `db:"location" json:"location,omitempty"`
+	Allocation           map[int]int   `db:"allocation" json:"allocation"`
+	TotalPacks           int           `db:"total_packs" json:"total_packs"`
+	TotalItems           int           `db:"total_items" json:"total_items"`
+	Surplus              int           `db:"surplus" json:"surplus"`
+	Solver               string        `db:"solver" json:"solver"`
+	Duration             time.Duration `db:"duration_us" json:"duration"`
+	CreatedAt            time.Time     `db:"created_at" json:"created_at"`
+}
+
+func NewCalculationRecord(tenant string, subject string, items int, packSizes []int, result *CalculationResult, solver string, duration time.Duration) (*CalculationRecord, error) {
+	if result == nil {
+		return nil, fmt.Errorf("calculation record requires a result")
+	}
+
+	record := &CalculationRecord{
+		Tenant:     tenant,
+		Subject:    subject,
+		Items:      items,
+		PackSizes:  packSizes,
+		Allocation: result.Allocation.GetAllocation(),
+		TotalPacks: result.Allocation.TotalPacks(),
+		TotalItems: result.Allocation.TotalItems(),
+		Surplus:    result.Surplus,
+		Solver:     solver,
+		Duration:   duration,
+		CreatedAt:  time.Now(),
+	}
+
+	if err := record.Validate(); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+func (cr *CalculationRecord) Validate() error {
+	if cr.Subject == "" {
+		return fmt.Errorf("calculation subject cannot be empty")
+	}
+
+	if cr.Tenant == "" {
+		return fmt.Errorf("calculation tenant cannot be empty")
+	}
+
+	if cr.Items < 0 {
+		return fmt.Errorf("calculation items cannot be negative, got %d", cr.Items)
+	}
+
+	if cr.Solver == "" {
+		return fmt.Errorf("calculation solver cannot be empty")
+	}
+
+	return nil
+}
+
+func (cr *CalculationRecord) IsExactMatch() bool {
+	return cr.Surplus == 0
+}
+
+// CalculationFilter narrows calculation history listings.
+// Zero values mean "no constraint"; Limit is always applied.
+type CalculationFilter struct {
+	// Tenant is always set by request handlers; it is only empty for
+	// system-wide maintenance
+	Tenant          string
+	Subject         string
+	ConfigurationID *int
+	From            *time.Time
+	To              *time.Time
+	Limit           int
+	Offset          int
+}
+
+type CalculationRepository interface {
+	Create(record *CalculationRecord) (*CalculationRecord, error)
+	GetByID(tenant string, id int64) (*CalculationRecord, error)
+	// List returns one page of records, newest first, plus the total matching the filter
+	List(filter CalculationFilter) ([]*CalculationRecord, int, error)
+	DeleteOlderThan(cutoff time.Time) (int64, error)
+}
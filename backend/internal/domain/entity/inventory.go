@@ -0,0 +1,137 @@
+package entity
+
+import (
+	"context"
+	"time"
+
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+)
+
+// StockLevel tracks how many packs of one size a tenant's location holds and
+// how many of those are already promised to reservations.
+type StockLevel struct {
+	ID        int       `db:"id" json:"id"`
+	Tenant    string    `db:"tenant_id" json:"tenant"`
+	Location  string    `db:"location" json:"location"`
+	PackSize  int       `db:"pack_size" json:"pack_size"`
+	OnHand    int       `db:"on_hand" json:"on_hand"`
+	Reserved  int       `db:"reserved" json:"reserved"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func NewStockLevel(tenant string, location string, packSize int, onHand int) (*StockLevel, error) {
+	stock := &StockLevel{
+		Tenant:    tenant,
+		Location:  location,
+		PackSize:  packSize,
+		OnHand:    onHand,
+		UpdatedAt: time.Now(),
+	}
+
+	if err := stock.Validate(); err != nil {
+		return nil, err
+	}
+
+	return stock, nil
+}
+
+func (s *StockLevel) Validate() error {
+	if s.Tenant == "" {
+		return errs.ErrInvalidStockLevel.Withf("stock tenant cannot be empty")
+	}
+
+	if s.Location == "" {
+		return errs.ErrInvalidStockLevel.Withf("stock location cannot be empty")
+	}
+
+	if s.PackSize <= 0 {
+		return errs.ErrInvalidStockLevel.Withf("pack size must be positive, got %d", s.PackSize)
+	}
+
+	if s.OnHand < 0 {
+		return errs.ErrInvalidStockLevel.Withf("on hand quantity cannot be negative, got %d", s.OnHand)
+	}
+
+	return nil
+}
+
+// Available is the number of packs that can still be reserved
+func (s *StockLevel) Available() int {
+	available := s.OnHand - s.Reserved
+	if available < 0 {
+		return 0
+	}
+	return available
+}
+
+// AvailableStock indexes stock levels by pack size for the stock-aware solver
+func AvailableStock(levels []*StockLevel) map[int]int {
+	stock := make(map[int]int, len(levels))
+	for _, level := range levels {
+		stock[level.PackSize] += level.Available()
+	}
+	return stock
+}
+
+type ReservationStatus string
+
+const (
+	ReservationStatusActive    ReservationStatus = "active"
+	ReservationStatusCancelled ReservationStatus = "cancelled"
+)
+
+// Reservation is a calculation committed as an order: the packs in its
+// allocation are held at the location until the reservation is cancelled.
+type Reservation struct {
+	ID            int               `db:"id" json:"id"`
+	Tenant        string            `db:"tenant_id" json:"tenant"`
+	Location      string            `db:"location" json:"location"`
+	OrderQuantity int               `db:"order_quantity" json:"order_quantity"`
+	Allocation    map[int]int       `db:"-" json:"allocation"`
+	Surplus       int               `db:"surplus" json:"surplus"`
+	Status        ReservationStatus `db:"status" json:"status"`
+	CreatedAt     time.Time         `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time         `db:"updated_at" json:"updated_at"`
+}
+
+func NewReservation(tenant string, location string, orderQuantity int, result *CalculationResult) (*Reservation, error) {
+	if tenant == "" {
+		return nil, errs.ErrInvalidReservation.Withf("reservation tenant cannot be empty")
+	}
+
+	if location == "" {
+		return nil, errs.ErrInvalidReservation.Withf("reservation location cannot be empty")
+	}
+
+	if result == nil || result.Allocation.IsEmpty() {
+		return nil, errs.ErrInvalidReservation.Withf("reservation requires a non-empty allocation")
+	}
+
+	return &Reservation{
+		Tenant:        tenant,
+		Location:      location,
+		OrderQuantity: orderQuantity,
+		Allocation:    result.Allocation.GetAllocation(),
+		Surplus:       result.Surplus,
+		Status:        ReservationStatusActive,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}, nil
+}
+
+func (r *Reservation) IsActive() bool {
+	return r.Status == ReservationStatusActive
+}
+
+// InventoryRepository scopes every stock level and reservation to a tenant:
+// another tenant's locations hold no stock and its reservations are not found.
+type InventoryRepository interface {
+	GetStock(ctx context.Context, tenant string, location string) ([]*StockLevel, error)
+	SetStock(ctx context.Context, stock *StockLevel) (*StockLevel, error)
+	// Reserve holds the reservation's packs and stores it in one transaction,
+	// failing with errs.ErrInsufficientStock if any size ran out meanwhile.
+	Reserve(ctx context.Context, reservation *Reservation) (*Reservation, error)
+	GetReservation(ctx context.Context, tenant string, id int) (*Reservation, error)
+	// Release returns an active reservation's packs to available stock
+	Release(ctx context.Context, tenant string, id int) (*Reservation, error)
+}
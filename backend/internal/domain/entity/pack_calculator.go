@@ -1,7 +1,7 @@
 package entity
 
 import (
-	"fmt"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
 )
 
 type PackSizes struct {
@@ -37,7 +37,7 @@ type OrderQuantity struct {
 
 func NewOrderQuantity(quantity int) (*OrderQuantity, error) {
 	if quantity < 0 {
-		return nil, fmt.Errorf("order quantity cannot be negative, got %d", quantity)
+		return nil, errs.ErrInvalidOrder.Withf("order quantity cannot be negative, got %d", quantity)
 	}
 	return &OrderQuantity{Quantity: quantity}, nil
 }
@@ -122,3 +122,64 @@ type PackCalculator interface {
 type PackSizeProcessor interface {
 	ProcessPackSizes(rawSizes []int) (*PackSizes, error)
 }
+
+// StockAwarePackCalculator plans allocations that never use more packs than are on hand
+type StockAwarePackCalculator interface {
+	CalculateOptimalPacksWithStock(packSizes *PackSizes, stock map[int]int, orderQuantity *OrderQuantity) *CalculationResult
+}
+
+// SourcingCandidate is one stock location the sourcing solver may draw packs from
+type SourcingCandidate struct {
+	SourceID  int
+	PackSizes *PackSizes
+	Stock     map[int]int
+}
+
+type SourcingStrategy string
+
+const (
+	SourcingStrategySingle SourcingStrategy = "single"
+	SourcingStrategySplit  SourcingStrategy = "split"
+)
+
+// SourcingPlan assigns an order's packs to one or more sources
+type SourcingPlan struct {
+	Strategy    SourcingStrategy
+	Allocations map[int]*PackAllocation
+	Surplus     int
+}
+
+func (sp *SourcingPlan) TotalPacks() int {
+	total := 0
+	for _, allocation := range sp.Allocations {
+		total += allocation.TotalPacks()
+	}
+	return total
+}
+
+func (sp *SourcingPlan) TotalItems() int {
+	total := 0
+	for _, allocation := range sp.Allocations {
+		total += allocation.TotalItems()
+	}
+	return total
+}
+
+func (sp *SourcingPlan) SourceCount() int {
+	return len(sp.Allocations)
+}
+
+func (sp *SourcingPlan) IsEmpty() bool {
+	return len(sp.Allocations) == 0
+}
+
+func (sp *SourcingPlan) IsUnfulfillable() bool {
+	return sp.Surplus > 0 && sp.IsEmpty()
+}
+
+// SourcingSolver decides which sources fulfil an order. A split plan only
+// wins when its surplus plus splitPenalty items per extra source beats the
+// best single-source plan, or ties it with fewer packs.
+type SourcingSolver interface {
+	PlanSourcing(candidates []*SourcingCandidate, orderQuantity *OrderQuantity, splitPenalty int) *SourcingPlan
+}
@@ -1,40 +1,134 @@
 package entity
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
 	"time"
+
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
 )
 
 type PackConfiguration struct {
-	ID        int       `db:"id" json:"id"`
-	Name      string    `db:"name" json:"name"`
-	PackSizes []int     `db:"pack_sizes" json:"pack_sizes"`
-	IsDefault bool      `db:"is_default" json:"is_default"`
-	IsActive  bool      `db:"is_active" json:"is_active"`
-	CreatedAt time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+	ID        int    `db:"id" json:"id"`
+	Tenant    string `db:"tenant_id" json:"tenant"`
+	Name      string `db:"name" json:"name"`
+	PackSizes []int  `db:"pack_sizes" json:"pack_sizes"`
+	// PackTypeIDs are the pack types the configuration offers, ordered by
+	// ID. Each one's item count is also among PackSizes.
+	PackTypeIDs []int     `db:"pack_type_ids" json:"pack_type_ids,omitempty"`
+	IsDefault   bool      `db:"is_default" json:"is_default"`
+	IsActive    bool      `db:"is_active" json:"is_active"`
+	Version     int       `db:"version" json:"version"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+	EffectivePeriod
+	PackConfigurationDetails
+	// PackTypes are the pack types behind PackTypeIDs, filled in by the
+	// service when the configuration is read
+	PackTypes []*PackType `db:"-" json:"-"`
+}
+
+// Clone copies everything a caller could mutate, so that stores keeping
+// configurations never share memory with the ones they hand out. PackTypes,
+// filled in per read, are left out.
+func (pc *PackConfiguration) Clone() *PackConfiguration {
+	cloned := *pc
+	cloned.PackSizes = slices.Clone(pc.PackSizes)
+	cloned.PackTypeIDs = slices.Clone(pc.PackTypeIDs)
+	cloned.PackTypes = nil
+	cloned.EffectiveFrom = cloneTime(pc.EffectiveFrom)
+	cloned.EffectiveTo = cloneTime(pc.EffectiveTo)
+	cloned.Labels = maps.Clone(pc.Labels)
+	if len(cloned.Labels) == 0 {
+		cloned.Labels = nil
+	}
+	if pc.Metadata != nil {
+		cloned.Metadata = json.RawMessage(slices.Clone(pc.Metadata))
+	}
+	return &cloned
+}
+
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	copied := *t
+	return &copied
+}
+
+// PackType returns the referenced pack type holding size items, if any
+func (pc *PackConfiguration) PackType(size int) *PackType {
+	for _, packType := range pc.PackTypes {
+		if packType.ItemCount == size {
+			return packType
+		}
+	}
+	return nil
+}
+
+// EffectivePeriod is when a configuration is in effect: from From, inclusive,
+// until To, exclusive. A nil bound leaves that side open.
+type EffectivePeriod struct {
+	EffectiveFrom *time.Time `db:"effective_from" json:"effective_from,omitempty"`
+	EffectiveTo   *time.Time `db:"effective_to" json:"effective_to,omitempty"`
+}
+
+func (p EffectivePeriod) Validate() error {
+	if p.EffectiveFrom != nil && p.EffectiveTo != nil && !p.EffectiveFrom.Before(*p.EffectiveTo) {
+		return errs.ErrInvalidPackConfiguration.Withf("effective_to must be after effective_from")
+	}
+	return nil
+}
+
+// EffectiveAt reports whether t falls within the period
+func (p EffectivePeriod) EffectiveAt(t time.Time) bool {
+	return (p.EffectiveFrom == nil || !t.Before(*p.EffectiveFrom)) &&
+		(p.EffectiveTo == nil || t.Before(*p.EffectiveTo))
 }
 
-func NewPackConfiguration(name string, packSizes []int) (*PackConfiguration, error) {
+// Overlaps reports whether some moment falls within both periods
+func (p EffectivePeriod) Overlaps(other EffectivePeriod) bool {
+	return (p.EffectiveFrom == nil || other.EffectiveTo == nil || p.EffectiveFrom.Before(*other.EffectiveTo)) &&
+		(other.EffectiveFrom == nil || p.EffectiveTo == nil || other.EffectiveFrom.Before(*p.EffectiveTo))
+}
+
+// Equal reports whether both periods have the same bounds
+func (p EffectivePeriod) Equal(other EffectivePeriod) bool {
+	return equalBound(p.EffectiveFrom, other.EffectiveFrom) && equalBound(p.EffectiveTo, other.EffectiveTo)
+}
+
+func equalBound(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func NewPackConfiguration(tenant string, name string, packSizes []int) (*PackConfiguration, error) {
 	if name == "" {
-		return nil, fmt.Errorf("pack configuration name cannot be empty")
+		return nil, errs.ErrInvalidPackConfiguration.Withf("pack configuration name cannot be empty")
 	}
 
 	if len(packSizes) == 0 {
-		return nil, fmt.Errorf("pack configuration must have at least one pack size")
+		return nil, errs.ErrInvalidPackConfiguration.Withf("pack configuration must have at least one pack size")
 	}
 
 	for _, size := range packSizes {
 		if size <= 0 {
-			return nil, fmt.Errorf("pack size must be positive, got %d", size)
+			return nil, errs.ErrInvalidPackConfiguration.Withf("pack size must be positive, got %d", size)
 		}
 	}
 
 	return &PackConfiguration{
+		Tenant:    tenant,
 		Name:      name,
-		PackSizes: packSizes,
+		PackSizes: CanonicalPackSizes(packSizes),
 		IsDefault: false,
 		IsActive:  true,
+		Version:   1,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}, nil
@@ -42,32 +136,309 @@ func NewPackConfiguration(name string, packSizes []int) (*PackConfiguration, err
 
 func (pc *PackConfiguration) Validate() error {
 	if pc.Name == "" {
-		return fmt.Errorf("pack configuration name cannot be empty")
+		return errs.ErrInvalidPackConfiguration.Withf("pack configuration name cannot be empty")
 	}
 
 	if len(pc.PackSizes) == 0 {
-		return fmt.Errorf("pack configuration must have at least one pack size")
+		return errs.ErrInvalidPackConfiguration.Withf("pack configuration must have at least one pack size")
 	}
 
 	for _, size := range pc.PackSizes {
 		if size <= 0 {
-			return fmt.Errorf("pack size must be positive, got %d", size)
+			return errs.ErrInvalidPackConfiguration.Withf("pack size must be positive, got %d", size)
 		}
 	}
 
-	return nil
+	if err := pc.EffectivePeriod.Validate(); err != nil {
+		return err
+	}
+	return pc.PackConfigurationDetails.Validate()
 }
 
 func (pc *PackConfiguration) GetRawPackSizes() []int {
 	return pc.PackSizes
 }
 
+// PackConfigurationPatch is a partial update. Nil fields are left as they
+// are; AddPackSizes and RemovePackSizes apply after PackSizes.
+type PackConfigurationPatch struct {
+	Name            *string
+	PackSizes       []int
+	AddPackSizes    []int
+	RemovePackSizes []int
+	IsDefault       *bool
+	EffectiveFrom   *TimePatch
+	EffectiveTo     *TimePatch
+	Description     *string
+	// Labels sets each label, or removes it when the value is nil, after
+	// ClearLabels has removed every existing one
+	Labels      map[string]*string
+	ClearLabels bool
+	// Metadata is a JSON Merge Patch of the metadata; "null" removes it
+	Metadata json.RawMessage
+	// PackTypes replaces the referenced pack types by code; an empty slice
+	// removes them all. The service resolves the codes, since Apply cannot.
+	PackTypes []string
+}
+
+// TimePatch replaces an optional timestamp; a nil Value removes it
+type TimePatch struct {
+	Value *time.Time
+}
+
+// Apply returns a copy of config with the patch's content changes. The default flag is left to the caller, since moving it touches
+// other configurations.
+func (p PackConfigurationPatch) Apply(config *PackConfiguration) *PackConfiguration {
+	patched := *config
+	if p.Name != nil {
+		patched.Name = *p.Name
+	}
+
+	packSizes := config.PackSizes
+	if p.PackSizes != nil {
+		packSizes = p.PackSizes
+	}
+	packSizes = slices.Clone(packSizes)
+	for _, size := range p.AddPackSizes {
+		if !slices.Contains(packSizes, size) {
+			packSizes = append(packSizes, size)
+		}
+	}
+	packSizes = slices.DeleteFunc(packSizes, func(size int) bool {
+		return slices.Contains(p.RemovePackSizes, size)
+	})
+	patched.PackSizes = packSizes
+
+	if p.EffectiveFrom != nil {
+		patched.EffectiveFrom = p.EffectiveFrom.Value
+	}
+	if p.EffectiveTo != nil {
+		patched.EffectiveTo = p.EffectiveTo.Value
+	}
+
+	if p.Description != nil {
+		patched.Description = *p.Description
+	}
+	if p.ClearLabels || p.Labels != nil {
+		labels := Labels{}
+		if !p.ClearLabels {
+			maps.Copy(labels, config.Labels)
+		}
+		for key, value := range p.Labels {
+			if value == nil {
+				delete(labels, key)
+			} else {
+				labels[key] = *value
+			}
+		}
+		patched.Labels = labels
+	}
+	if p.Metadata != nil {
+		patched.Metadata = mergeJSON(config.Metadata, p.Metadata)
+	}
+
+	return &patched
+}
+
+// ChangesContents reports whether patched differs from config in a way
+// that needs a new version
+func (pc *PackConfiguration) ChangesContents(patched *PackConfiguration) bool {
+	return pc.Name != patched.Name || !slices.Equal(pc.PackSizes, patched.PackSizes) ||
+		!slices.Equal(pc.PackTypeIDs, patched.PackTypeIDs) ||
+		!pc.EffectivePeriod.Equal(patched.EffectivePeriod) ||
+		!pc.PackConfigurationDetails.Equal(patched.PackConfigurationDetails)
+}
+
+// PackConfigurationVersion is an immutable snapshot of a configuration's
+// contents: everything ChangesContents compares. A new one is written
+// whenever they change, and restoring it brings all of them back.
+type PackConfigurationVersion struct {
+	ConfigurationID int    `db:"configuration_id" json:"configuration_id"`
+	Version         int    `db:"version" json:"version"`
+	Name            string `db:"name" json:"name"`
+	PackSizes       []int  `db:"pack_sizes" json:"pack_sizes"`
+	PackTypeIDs     []int  `db:"pack_type_ids" json:"pack_type_ids,omitempty"`
+	EffectivePeriod
+	PackConfigurationDetails
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// Snapshot captures the configuration's current contents under its current version
+func (pc *PackConfiguration) Snapshot() *PackConfigurationVersion {
+	cloned := pc.Clone()
+	return &PackConfigurationVersion{
+		ConfigurationID:          cloned.ID,
+		Version:                  cloned.Version,
+		Name:                     cloned.Name,
+		PackSizes:                cloned.PackSizes,
+		PackTypeIDs:              cloned.PackTypeIDs,
+		EffectivePeriod:          cloned.EffectivePeriod,
+		PackConfigurationDetails: cloned.PackConfigurationDetails,
+		CreatedAt:                time.Now(),
+	}
+}
+
+// Clone copies everything a caller could mutate
+func (pv *PackConfigurationVersion) Clone() *PackConfigurationVersion {
+	cloned := *pv
+	cloned.PackSizes = slices.Clone(pv.PackSizes)
+	cloned.PackTypeIDs = slices.Clone(pv.PackTypeIDs)
+	cloned.EffectiveFrom = cloneTime(pv.EffectiveFrom)
+	cloned.EffectiveTo = cloneTime(pv.EffectiveTo)
+	cloned.Labels = maps.Clone(pv.Labels)
+	if pv.Metadata != nil {
+		cloned.Metadata = json.RawMessage(slices.Clone(pv.Metadata))
+	}
+	return &cloned
+}
+
+// Restore puts the snapshot's contents back into config. Snapshots written
+// before sizes were canonicalised are restored canonical; callers drop the
+// pack types they no longer reference correctly.
+func (pv *PackConfigurationVersion) Restore(config *PackConfiguration) {
+	snapshot := pv.Clone()
+	config.Name = snapshot.Name
+	config.PackSizes = CanonicalPackSizes(snapshot.PackSizes)
+	config.PackTypeIDs = snapshot.PackTypeIDs
+	config.EffectivePeriod = snapshot.EffectivePeriod
+	config.PackConfigurationDetails = snapshot.PackConfigurationDetails
+}
+
+func (pv *PackConfigurationVersion) GetRawPackSizes() []int {
+	return pv.PackSizes
+}
+
+// PackConfigurationSort is a column configuration listings can be ordered by
+type PackConfigurationSort string
+
+const (
+	// PackConfigurationSortDefault lists the default configuration first, then newest first
+	PackConfigurationSortDefault   PackConfigurationSort = ""
+	PackConfigurationSortName      PackConfigurationSort = "name"
+	PackConfigurationSortCreatedAt PackConfigurationSort = "created_at"
+	PackConfigurationSortUpdatedAt PackConfigurationSort = "updated_at"
+)
+
+func ParsePackConfigurationSort(value string) (PackConfigurationSort, error) {
+	switch sort := PackConfigurationSort(value); sort {
+	case PackConfigurationSortDefault, PackConfigurationSortName, PackConfigurationSortCreatedAt, PackConfigurationSortUpdatedAt:
+		return sort, nil
+	}
+	return "", fmt.Errorf("unknown sort %q: use name, created_at or updated_at", value)
+}
+
+// PackConfigurationQuery narrows and pages configuration listings.
+// Zero values mean "no constraint"; Limit is always applied.
+type PackConfigurationQuery struct {
+	Tenant string
+	// Archived lists soft-deleted configurations instead of active ones
+	Archived bool
+	// Search matches the start of the name, ignoring case
+	Search string
+	// PackSize keeps configurations that offer this pack size
+	PackSize *int
+	// Labels keeps configurations carrying every one of these labels
+	Labels      Labels
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Sort        PackConfigurationSort
+	Descending  bool
+	Limit       int
+	Offset      int
+}
+
+// ImportAction is what an import does with one configuration
+type ImportAction string
+
+const (
+	ImportActionCreate    ImportAction = "create"
+	ImportActionUpdate    ImportAction = "update"
+	ImportActionUnchanged ImportAction = "unchanged"
+)
+
+// PackConfigurationImportRow is one configuration read from an import file.
+// Missing timestamps are filled in when the row is written.
+type PackConfigurationImportRow struct {
+	Name      string
+	PackSizes []int
+	// PackTypes are the codes of the pack types the row references
+	PackTypes []string
+	IsDefault bool
+	EffectivePeriod
+	PackConfigurationDetails
+	CreatedAt *time.Time
+	UpdatedAt *time.Time
+}
+
+// ImportOptions control how rows are matched and whether they are written
+type ImportOptions struct {
+	// Upsert updates the active configuration with the same name instead of
+	// rejecting the row
+	Upsert bool
+	// DryRun validates and plans the import without writing anything
+	DryRun bool
+}
+
+// PackConfigurationImportResult is what an import did, or would do, with the
+// row at Index. ID is 0 for rows a dry run would create.
+type PackConfigurationImportResult struct {
+	Index  int
+	Name   string
+	Action ImportAction
+	ID     int
+}
+
+// PackConfigurationImport is the set of writes an import applies in a single
+// transaction. Creates and Updates keep the timestamps they carry; Updates
+// only apply at their Version.
+type PackConfigurationImport struct {
+	Tenant  string
+	Creates []*PackConfiguration
+	Updates []*PackConfiguration
+	// DefaultID makes an existing configuration the default when none of
+	// the written ones has IsDefault set
+	DefaultID int
+}
+
+// PackConfigurationRepository stores configurations per tenant. Every method
+// only sees the tenant it is given (query.Tenant, config.Tenant or
+// imp.Tenant), and each tenant has its own defaults and its own unique names.
+// A tenant may have several defaults as long as their effective periods do
+// not overlap; a write that would overlap two fails with a
+// DefaultPeriodOverlapError. Database-backed implementations give up when
+// ctx is done and fail with a QueryTimeoutError when a deadline cut them short.
+// A write under a ctx carrying an actor (see WithActor) appends its audit
+// entry atomically with the change.
 type PackConfigurationRepository interface {
-	GetAll() ([]*PackConfiguration, error)
-	GetByID(id int) (*PackConfiguration, error)
-	GetDefault() (*PackConfiguration, error)
-	Create(config *PackConfiguration) (*PackConfiguration, error)
-	Update(config *PackConfiguration) (*PackConfiguration, error)
-	Delete(id int) error
-	SetDefault(id int) error
+	// List returns one page of configurations plus the total matching the query
+	List(ctx context.Context, query PackConfigurationQuery) ([]*PackConfiguration, int, error)
+	GetByID(ctx context.Context, tenant string, id int) (*PackConfiguration, error)
+	// GetDefault returns the default configuration in effect at asOf
+	GetDefault(ctx context.Context, tenant string, asOf time.Time) (*PackConfiguration, error)
+	// FindByPackSizes returns the active configurations whose stored sizes
+	// equal packSizes, which must be canonical
+	FindByPackSizes(ctx context.Context, tenant string, packSizes []int) ([]*PackConfiguration, error)
+	Create(ctx context.Context, config *PackConfiguration) (*PackConfiguration, error)
+	// Update, Delete and SetDefault only apply while the stored version still
+	// matches (config.Version for Update) and fail with a version conflict otherwise.
+	// Update moves the default flag onto config when config.IsDefault is set,
+	// in the same transaction, but never clears it. Moving the default takes
+	// the flag off every default whose period overlaps the new default's.
+	Update(ctx context.Context, config *PackConfiguration) (*PackConfiguration, error)
+	Delete(ctx context.Context, tenant string, id int, version int) error
+	SetDefault(ctx context.Context, tenant string, id int, version int) error
+	// GetVersions returns the configuration's history, newest first
+	GetVersions(ctx context.Context, tenant string, id int) ([]*PackConfigurationVersion, error)
+	GetVersion(ctx context.Context, tenant string, id int, version int) (*PackConfigurationVersion, error)
+	// RestoreVersion copies an old snapshot forward as a new version
+	RestoreVersion(ctx context.Context, tenant string, id int, version int) (*PackConfiguration, error)
+	GetArchivedByID(ctx context.Context, tenant string, id int) (*PackConfiguration, error)
+	// Restore reactivates an archived configuration, optionally under a new name.
+	// It becomes the default only when no default overlaps its period.
+	Restore(ctx context.Context, tenant string, id int, name string) (*PackConfiguration, error)
+	// Purge permanently removes an archived configuration and its versions
+	Purge(ctx context.Context, tenant string, id int) error
+	// Import applies every write or none of them, filling in the IDs and
+	// versions of the written configurations
+	Import(ctx context.Context, imp PackConfigurationImport) error
 }
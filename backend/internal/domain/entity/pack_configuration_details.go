@@ -0,0 +1,197 @@
+package entity
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+)
+
+const (
+	maxDescriptionLength = 2000
+	maxLabels            = 64
+	maxMetadataBytes     = 16 << 10
+)
+
+// PackConfigurationDetails describe a configuration without affecting the
+// calculations it is used for
+type PackConfigurationDetails struct {
+	Description string `db:"description" json:"description,omitempty"`
+	Labels      Labels `db:"labels" json:"labels,omitempty"`
+	// Metadata is a JSON object, or nil when there is none
+	Metadata json.RawMessage `db:"metadata" json:"metadata,omitempty"`
+}
+
+func (d PackConfigurationDetails) Validate() error {
+	if utf8.RuneCountInString(d.Description) > maxDescriptionLength {
+		return errs.ErrInvalidPackConfiguration.Withf("description cannot be longer than %d characters", maxDescriptionLength)
+	}
+	if err := d.Labels.Validate(); err != nil {
+		return err
+	}
+	if d.Metadata == nil {
+		return nil
+	}
+	if len(d.Metadata) > maxMetadataBytes {
+		return errs.ErrInvalidPackConfiguration.Withf("metadata cannot be larger than %d bytes", maxMetadataBytes)
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(d.Metadata, &object); err != nil || object == nil {
+		return errs.ErrInvalidPackConfiguration.Withf("metadata must be a JSON object")
+	}
+	return nil
+}
+
+// Equal reports whether both carry the same details. Metadata is compared
+// by value, since Postgres does not keep its formatting or key order.
+func (d PackConfigurationDetails) Equal(other PackConfigurationDetails) bool {
+	return d.Description == other.Description && maps.Equal(d.Labels, other.Labels) &&
+		equalJSON(d.Metadata, other.Metadata)
+}
+
+func equalJSON(a, b json.RawMessage) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	var valueA, valueB interface{}
+	if json.Unmarshal(a, &valueA) != nil || json.Unmarshal(b, &valueB) != nil {
+		return bytes.Equal(a, b)
+	}
+	return reflect.DeepEqual(valueA, valueB)
+}
+
+// mergeJSON applies a JSON Merge Patch (RFC 7386) to target. A nil result
+// means the patch removed the document. A patch that is not valid JSON
+// replaces target as it is, so validation reports it.
+func mergeJSON(target, patch json.RawMessage) json.RawMessage {
+	var patchValue interface{}
+	if err := json.Unmarshal(patch, &patchValue); err != nil {
+		return patch
+	}
+	var targetValue interface{}
+	if target != nil {
+		_ = json.Unmarshal(target, &targetValue)
+	}
+
+	merged := mergeJSONValue(targetValue, patchValue)
+	if merged == nil {
+		return nil
+	}
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return patch
+	}
+	return data
+}
+
+func mergeJSONValue(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = mergeJSONValue(targetObject[key], value)
+	}
+	return targetObject
+}
+
+// Labels are free-form key/value pairs, such as customer=acme or region=eu
+type Labels map[string]string
+
+// labelPattern allows up to 63 letters, digits, '-', '_', '.' and '/',
+// starting and ending with a letter or digit
+var labelPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._/-]{0,61}[A-Za-z0-9])?$`)
+
+func (l Labels) Validate() error {
+	if len(l) > maxLabels {
+		return errs.ErrInvalidPackConfiguration.Withf("a pack configuration cannot have more than %d labels", maxLabels)
+	}
+	for _, key := range slices.Sorted(maps.Keys(l)) {
+		if !labelPattern.MatchString(key) {
+			return errs.ErrInvalidPackConfiguration.Withf("invalid label key %q: %s", key, labelRule)
+		}
+		if value := l[key]; value != "" && !labelPattern.MatchString(value) {
+			return errs.ErrInvalidPackConfiguration.Withf("invalid value %q for label %q: %s", value, key, labelRule)
+		}
+	}
+	return nil
+}
+
+const labelRule = "use up to 63 letters, digits, '-', '_', '.' or '/', starting and ending with a letter or digit"
+
+// Matches reports whether the labels hold every pair of the selector
+func (l Labels) Matches(selector Labels) bool {
+	for key, value := range selector {
+		if actual, ok := l[key]; !ok || actual != value {
+			return false
+		}
+	}
+	return true
+}
+
+// String lists the labels as key=value pairs sorted by key and separated by
+// semicolons, the form ParseLabels reads
+func (l Labels) String() string {
+	pairs := make([]string, 0, len(l))
+	for _, key := range slices.Sorted(maps.Keys(l)) {
+		pairs = append(pairs, key+"="+l[key])
+	}
+	return strings.Join(pairs, ";")
+}
+
+// ParseLabels reads key=value pairs separated by sep
+func ParseLabels(value string, sep string) (Labels, error) {
+	labels := Labels{}
+	if strings.TrimSpace(value) == "" {
+		return labels, nil
+	}
+	for _, pair := range strings.Split(value, sep) {
+		key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("label %q must be written as key=value", pair)
+		}
+		if previous, duplicate := labels[key]; duplicate && previous != val {
+			return nil, fmt.Errorf("label %q is given twice", key)
+		}
+		labels[key] = val
+	}
+	return labels, nil
+}
+
+// ParseLabelSelector reads the label query parameters of a listing. Each is
+// a key=value pair, or several separated by commas, and a configuration
+// must carry all of them to match.
+func ParseLabelSelector(values []string) (Labels, error) {
+	selector := Labels{}
+	for _, value := range values {
+		labels, err := ParseLabels(value, ",")
+		if err != nil {
+			return nil, err
+		}
+		for key, val := range labels {
+			if previous, duplicate := selector[key]; duplicate && previous != val {
+				return nil, fmt.Errorf("label %q is selected twice", key)
+			}
+			selector[key] = val
+		}
+	}
+	if len(selector) == 0 {
+		return nil, nil
+	}
+	return selector, nil
+}
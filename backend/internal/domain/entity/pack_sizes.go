@@ -0,0 +1,130 @@
+package entity
+
+import (
+	"math"
+	"slices"
+)
+
+// maxReductionModulus bounds the table ReducePackSizes keeps per residue of
+// the smallest pack size, after dividing out the common divisor. Beyond it
+// only sizes that are multiples of a smaller size are found redundant.
+const maxReductionModulus = 1 << 20
+
+// CanonicalPackSizes returns the sizes sorted ascending without duplicates,
+// the form in which configurations store them
+func CanonicalPackSizes(packSizes []int) []int {
+	canonical := slices.Clone(packSizes)
+	slices.Sort(canonical)
+	return slices.Compact(canonical)
+}
+
+// ReducePackSizes splits canonical sizes into the minimal set that can still
+// make up every quantity the full set can, and the redundant sizes that are
+// sums of smaller ones, e.g. 750 alongside 250 and 500. Removing a redundant
+// size never changes the items shipped for an order, only how many packs.
+func ReducePackSizes(packSizes []int) (minimal []int, redundant []int) {
+	canonical := CanonicalPackSizes(packSizes)
+	if len(canonical) == 0 || canonical[0] <= 0 {
+		return canonical, nil
+	}
+
+	divisor := 0
+	for _, size := range canonical {
+		divisor = gcd(divisor, size)
+	}
+	modulus := canonical[0] / divisor
+
+	if modulus > maxReductionModulus {
+		for _, size := range canonical {
+			if slices.ContainsFunc(minimal, func(smaller int) bool { return size%smaller == 0 }) {
+				redundant = append(redundant, size)
+				continue
+			}
+			minimal = append(minimal, size)
+		}
+		return minimal, redundant
+	}
+
+	// smallest[r] is the smallest quantity made of the kept sizes that is
+	// congruent to r modulo the smallest size; a size is redundant exactly
+	// when it is at least smallest[size mod modulus]
+	smallest := make([]int64, modulus)
+	for i := range smallest {
+		smallest[i] = math.MaxInt64
+	}
+	smallest[0] = 0
+
+	minimal = []int{canonical[0]}
+	for _, size := range canonical[1:] {
+		step := size / divisor
+		if smallest[step%modulus] <= int64(step) {
+			redundant = append(redundant, size)
+			continue
+		}
+		minimal = append(minimal, size)
+		addGenerator(smallest, step)
+	}
+	return minimal, redundant
+}
+
+// addGenerator relaxes smallest with one more size. Walking each residue
+// cycle of the step once, starting from its minimum, reaches every
+// improvement.
+func addGenerator(smallest []int64, step int) {
+	modulus := len(smallest)
+	cycles := gcd(modulus, step%modulus)
+
+	for start := 0; start < cycles; start++ {
+		from := start
+		for r := (start + step) % modulus; r != start; r = (r + step) % modulus {
+			if smallest[r] < smallest[from] {
+				from = r
+			}
+		}
+		if smallest[from] == math.MaxInt64 {
+			continue
+		}
+
+		for i, r := 0, from; i < modulus/cycles; i++ {
+			next := (r + step) % modulus
+			if candidate := smallest[r] + int64(step); smallest[r] != math.MaxInt64 && candidate < smallest[next] {
+				smallest[next] = candidate
+			}
+			r = next
+		}
+	}
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// PackSizeMatch says how a configuration relates to a set of pack sizes
+type PackSizeMatch string
+
+const (
+	// PackSizeMatchIdentical configurations have exactly the same sizes
+	PackSizeMatchIdentical PackSizeMatch = "identical"
+	// PackSizeMatchEquivalent configurations differ only by redundant sizes,
+	// so they ship the same items for every order
+	PackSizeMatchEquivalent PackSizeMatch = "equivalent"
+)
+
+// EquivalentConfiguration is a configuration found for a set of pack sizes.
+// RedundantPackSizes are the configuration's own redundant sizes.
+type EquivalentConfiguration struct {
+	Configuration      *PackConfiguration
+	Match              PackSizeMatch
+	RedundantPackSizes []int
+}
+
+// PackSizeEquivalence answers which configurations match a set of sizes
+type PackSizeEquivalence struct {
+	PackSizes          []int
+	MinimalPackSizes   []int
+	RedundantPackSizes []int
+	Configurations     []EquivalentConfiguration
+}
@@ -0,0 +1,116 @@
+package entity
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+)
+
+// PackType is a pack SKU: a stock-keeping code for a pack that holds
+// ItemCount items. Configurations that reference a pack type offer its item
+// count as one of their pack sizes.
+type PackType struct {
+	ID        int    `db:"id" json:"id"`
+	Tenant    string `db:"tenant_id" json:"tenant"`
+	Code      string `db:"code" json:"code"`
+	Label     string `db:"label" json:"label"`
+	ItemCount int    `db:"item_count" json:"item_count"`
+	PackDimensions
+	// CostCents is the cost of one pack in minor currency units
+	CostCents int64     `db:"cost_cents" json:"cost_cents"`
+	IsActive  bool      `db:"is_active" json:"is_active"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// PackDimensions are a pack's outer dimensions in millimetres, zero when
+// unknown
+type PackDimensions struct {
+	LengthMM int `db:"length_mm" json:"length_mm"`
+	WidthMM  int `db:"width_mm" json:"width_mm"`
+	HeightMM int `db:"height_mm" json:"height_mm"`
+}
+
+// packTypeCodePattern allows up to 64 letters, digits, '-', '_' and '.',
+// starting with a letter or digit
+var packTypeCodePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+func (pt *PackType) Validate() error {
+	if !packTypeCodePattern.MatchString(pt.Code) {
+		return errs.ErrInvalidPackType.Withf("invalid code %q: use up to 64 letters, digits, '-', '_' or '.'", pt.Code)
+	}
+
+	if pt.Label == "" {
+		return errs.ErrInvalidPackType.Withf("pack type label cannot be empty")
+	}
+
+	if pt.ItemCount <= 0 {
+		return errs.ErrInvalidPackType.Withf("item count must be positive, got %d", pt.ItemCount)
+	}
+
+	if pt.LengthMM < 0 || pt.WidthMM < 0 || pt.HeightMM < 0 {
+		return errs.ErrInvalidPackType.Withf("dimensions cannot be negative")
+	}
+
+	if pt.CostCents < 0 {
+		return errs.ErrInvalidPackType.Withf("cost cannot be negative, got %d", pt.CostCents)
+	}
+
+	return nil
+}
+
+// Offers reports whether the pack type can back one of packSizes: it is
+// active and holds one of those counts
+func (pt *PackType) Offers(packSizes []int) bool {
+	return pt.IsActive && slices.Contains(packSizes, pt.ItemCount)
+}
+
+// CheckPackTypes fails when a pack type the configuration references no
+// longer offers one of its pack sizes, given packTypes, the stored pack types
+// with its PackTypeIDs: it was deleted or changed after being resolved
+func (pc *PackConfiguration) CheckPackTypes(packTypes []*PackType) error {
+	for _, id := range pc.PackTypeIDs {
+		i := slices.IndexFunc(packTypes, func(packType *PackType) bool { return packType.ID == id })
+		if i < 0 || !packTypes[i].Offers(pc.PackSizes) {
+			return errs.ErrUnknownPackType.Withf("pack type %d was deleted or changed meanwhile", id)
+		}
+	}
+	return nil
+}
+
+// OfferedPackTypeIDs keeps the IDs of the pack types in packTypes that still
+// offer one of the configuration's pack sizes
+func (pc *PackConfiguration) OfferedPackTypeIDs(packTypes []*PackType) []int {
+	var ids []int
+	for _, packType := range packTypes {
+		if slices.Contains(pc.PackTypeIDs, packType.ID) && packType.Offers(pc.PackSizes) {
+			ids = append(ids, packType.ID)
+		}
+	}
+	return ids
+}
+
+// PackTypeRepository stores the pack types of each tenant. Codes are unique
+// within a tenant regardless of case, inactive pack types included.
+type PackTypeRepository interface {
+	// GetAll lists the tenant's pack types by code, inactive ones only when asked
+	GetAll(ctx context.Context, tenant string, includeInactive bool) ([]*PackType, error)
+	// GetByID returns the pack type whether or not it is active
+	GetByID(ctx context.Context, tenant string, id int) (*PackType, error)
+	// GetByIDs returns the pack types with these IDs, inactive ones
+	// included, skipping IDs that do not exist
+	GetByIDs(ctx context.Context, tenant string, ids []int) ([]*PackType, error)
+	// GetByCodes returns the active pack types whose codes match, ignoring
+	// case, skipping codes that match none
+	GetByCodes(ctx context.Context, tenant string, codes []string) ([]*PackType, error)
+	Create(ctx context.Context, packType *PackType) (*PackType, error)
+	// Update refuses with errs.ErrPackTypeInUse to change the item count of
+	// a pack type an active configuration references
+	Update(ctx context.Context, packType *PackType) (*PackType, error)
+	// Delete deactivates the pack type, refusing with errs.ErrPackTypeInUse
+	// while an active configuration references it
+	Delete(ctx context.Context, tenant string, id int) error
+}
@@ -0,0 +1,21 @@
+package entity
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// DefaultTenant owns every row written before tenants existed, and is the
+// tenant of tokens that carry no tenant claim
+const DefaultTenant = "default"
+
+var tenantPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// ValidateTenant accepts lower-case identifiers of up to 64 letters,
+// digits, hyphens and underscores
+func ValidateTenant(tenant string) error {
+	if !tenantPattern.MatchString(tenant) {
+		return fmt.Errorf("invalid tenant %q: use up to 64 lower-case letters, digits, '-' or '_'", tenant)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package entity
+
+import (
+	"context"
+	"time"
+
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+)
+
+// Warehouse is a tenant's stock location with its own pack configuration.
+// Its Location keys the tenant's stock levels tracked by the inventory module.
+type Warehouse struct {
+	ID                  int       `db:"id" json:"id"`
+	Tenant              string    `db:"tenant_id" json:"tenant"`
+	Name                string    `db:"name" json:"name"`
+	Location            string    `db:"location" json:"location"`
+	PackConfigurationID int       `db:"pack_configuration_id" json:"pack_configuration_id"`
+	IsActive            bool      `db:"is_active" json:"is_active"`
+	CreatedAt           time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt           time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func NewWarehouse(tenant string, name string, location string, packConfigurationID int) (*Warehouse, error) {
+	warehouse := &Warehouse{
+		Tenant:              tenant,
+		Name:                name,
+		Location:            location,
+		PackConfigurationID: packConfigurationID,
+		IsActive:            true,
+		CreatedAt:           time.Now(),
+		UpdatedAt:           time.Now(),
+	}
+
+	if err := warehouse.Validate(); err != nil {
+		return nil, err
+	}
+
+	return warehouse, nil
+}
+
+func (w *Warehouse) Validate() error {
+	if w.Tenant == "" {
+		return errs.ErrInvalidWarehouse.Withf("warehouse tenant cannot be empty")
+	}
+
+	if w.Name == "" {
+		return errs.ErrInvalidWarehouse.Withf("warehouse name cannot be empty")
+	}
+
+	if w.Location == "" {
+		return errs.ErrInvalidWarehouse.Withf("warehouse location cannot be empty")
+	}
+
+	if w.PackConfigurationID <= 0 {
+		return errs.ErrInvalidWarehouse.Withf("warehouse must reference a pack configuration, got %d", w.PackConfigurationID)
+	}
+
+	return nil
+}
+
+// WarehouseRepository scopes every warehouse to a tenant: another tenant's
+// warehouses are not found.
+type WarehouseRepository interface {
+	GetAll(ctx context.Context, tenant string) ([]*Warehouse, error)
+	GetByID(ctx context.Context, tenant string, id int) (*Warehouse, error)
+	Create(ctx context.Context, warehouse *Warehouse) (*Warehouse, error)
+	Delete(ctx context.Context, tenant string, id int) error
+}
@@ -0,0 +1,208 @@
+package entity
+
+import (
+	"context"
+	"encoding/json"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+)
+
+// EventType names a change to a pack configuration announced to webhooks
+type EventType string
+
+const (
+	EventPackConfigurationCreated EventType = "pack_configuration.created"
+	EventPackConfigurationUpdated EventType = "pack_configuration.updated"
+	EventPackConfigurationDeleted EventType = "pack_configuration.deleted"
+	// EventPackConfigurationRestored follows unarchiving a configuration
+	EventPackConfigurationRestored EventType = "pack_configuration.restored"
+	// EventPackConfigurationDefaultChanged follows every move of the default
+	// flag onto a configuration, whichever operation moved it
+	EventPackConfigurationDefaultChanged EventType = "pack_configuration.default_changed"
+)
+
+// EventTypes lists every event a webhook can subscribe to
+var EventTypes = []EventType{
+	EventPackConfigurationCreated,
+	EventPackConfigurationUpdated,
+	EventPackConfigurationDeleted,
+	EventPackConfigurationRestored,
+	EventPackConfigurationDefaultChanged,
+}
+
+// PackConfigurationEvent is the data of every pack configuration event: the
+// configuration as the change left it, and for default changes the
+// configurations that lost the default flag
+type PackConfigurationEvent struct {
+	Configuration      *PackConfiguration `json:"configuration"`
+	PreviousDefaultIDs []int              `json:"previous_default_ids,omitempty"`
+}
+
+// OutboxEvent is an event recorded in the transaction of the change it
+// describes, so that it exists exactly when the change was committed
+type OutboxEvent struct {
+	ID        int64           `db:"id" json:"id"`
+	Tenant    string          `db:"tenant_id" json:"tenant"`
+	Type      EventType       `db:"event_type" json:"type"`
+	Data      json.RawMessage `db:"payload" json:"data"`
+	CreatedAt time.Time       `db:"created_at" json:"occurred_at"`
+}
+
+// Webhook secrets are stored as given, in a column of MaxWebhookSecretLength
+const (
+	MinWebhookSecretLength = 16
+	MaxWebhookSecretLength = 128
+)
+
+// Webhook receives the tenant's events of the given types, or all of them
+// when EventTypes is empty, signed with Secret
+type Webhook struct {
+	ID         int         `db:"id" json:"id"`
+	Tenant     string      `db:"tenant_id" json:"tenant"`
+	URL        string      `db:"url" json:"url"`
+	Secret     string      `db:"secret" json:"-"`
+	EventTypes []EventType `db:"event_types" json:"event_types"`
+	CreatedAt  time.Time   `db:"created_at" json:"created_at"`
+}
+
+func (w *Webhook) Validate() error {
+	target, err := url.Parse(w.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return errs.ErrInvalidWebhook.Withf("url must be an absolute http or https URL, got %q", w.URL)
+	}
+	if len(w.Secret) < MinWebhookSecretLength || len(w.Secret) > MaxWebhookSecretLength {
+		return errs.ErrInvalidWebhook.Withf("secret must be %d to %d characters", MinWebhookSecretLength, MaxWebhookSecretLength)
+	}
+	for _, eventType := range w.EventTypes {
+		if !slices.Contains(EventTypes, eventType) {
+			return errs.ErrInvalidWebhook.Withf("unknown event type %q", eventType)
+		}
+	}
+	return nil
+}
+
+// CheckTarget refuses a URL whose host is localhost or a loopback,
+// link-local, private, unspecified or multicast IP literal. Hosts given by
+// name can still resolve to such addresses: the dispatcher checks every
+// address again when it dials.
+func (w *Webhook) CheckTarget() error {
+	target, err := url.Parse(w.URL)
+	if err != nil {
+		return errs.ErrInvalidWebhook.Withf("url must be an absolute http or https URL, got %q", w.URL)
+	}
+	host := strings.ToLower(strings.TrimSuffix(target.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return errs.ErrInvalidWebhook.Withf("url must not target a private address, got %q", w.URL)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !IsPublicAddress(addr) {
+		return errs.ErrInvalidWebhook.Withf("url must not target a private address, got %q", w.URL)
+	}
+	return nil
+}
+
+// IsPublicAddress reports whether webhooks may be delivered to addr
+func IsPublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() &&
+		!addr.IsLoopback() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsPrivate() &&
+		!addr.IsUnspecified() &&
+		!addr.IsMulticast()
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	// WebhookDeliveryDead marks a dead letter: a delivery that ran out of
+	// attempts and waits to be redelivered by hand
+	WebhookDeliveryDead WebhookDeliveryStatus = "dead"
+)
+
+// WebhookDelivery tracks one event's delivery to one webhook
+type WebhookDelivery struct {
+	ID             int64                 `db:"id" json:"id"`
+	WebhookID      int                   `db:"webhook_id" json:"webhook_id"`
+	EventID        int64                 `db:"event_id" json:"event_id"`
+	EventType      EventType             `db:"event_type" json:"event_type"`
+	Status         WebhookDeliveryStatus `db:"status" json:"status"`
+	Attempts       int                   `db:"attempts" json:"attempts"`
+	NextAttemptAt  time.Time             `db:"next_attempt_at" json:"next_attempt_at"`
+	LastStatusCode int                   `db:"last_status_code" json:"last_status_code,omitempty"`
+	LastError      string                `db:"last_error" json:"last_error,omitempty"`
+	CreatedAt      time.Time             `db:"created_at" json:"created_at"`
+	DeliveredAt    *time.Time            `db:"delivered_at" json:"delivered_at,omitempty"`
+}
+
+// WebhookDeliveryFilter selects deliveries of the tenant's webhooks, of one
+// webhook when WebhookID is set
+type WebhookDeliveryFilter struct {
+	Tenant    string
+	WebhookID int
+	Status    WebhookDeliveryStatus
+	Limit     int
+	Offset    int
+}
+
+// DueWebhookDelivery is a claimed delivery with what sending it takes
+type DueWebhookDelivery struct {
+	ID       int64
+	Attempts int
+	URL      string
+	Secret   string
+	Event    OutboxEvent
+}
+
+// WebhookAttempt is the outcome of sending a due delivery. A failed attempt
+// is retried at NextAttemptAt, or turns the delivery into a dead letter when
+// NextAttemptAt is nil.
+type WebhookAttempt struct {
+	DeliveryID    int64
+	Delivered     bool
+	StatusCode    int
+	Error         string
+	NextAttemptAt *time.Time
+}
+
+// WebhookDispatchResult counts what one dispatch round did
+type WebhookDispatchResult struct {
+	// Events were fanned out into deliveries
+	Events    int
+	Delivered int
+	// Retried deliveries failed and will be sent again
+	Retried int
+	// DeadLettered deliveries failed for the last time
+	DeadLettered int
+}
+
+// WebhookRepository stores the tenants' webhooks and the deliveries made to them
+type WebhookRepository interface {
+	List(ctx context.Context, tenant string) ([]*Webhook, error)
+	Create(ctx context.Context, webhook *Webhook) (*Webhook, error)
+	// Delete removes the webhook along with its deliveries
+	Delete(ctx context.Context, tenant string, id int) error
+	// ListDeliveries returns one page of deliveries, newest first, plus the
+	// total matching the filter
+	ListDeliveries(ctx context.Context, filter WebhookDeliveryFilter) ([]*WebhookDelivery, int, error)
+	// Redeliver makes a dead letter pending again, with a fresh set of attempts
+	Redeliver(ctx context.Context, tenant string, deliveryID int64) (*WebhookDelivery, error)
+}
+
+// WebhookOutbox is the dispatcher's side of the outbox. Several dispatchers
+// may share one: each event is fanned out and each delivery claimed once.
+type WebhookOutbox interface {
+	// FanOut turns up to limit undispatched events into a delivery for each
+	// subscribed webhook, returning how many events it dispatched
+	FanOut(ctx context.Context, limit int) (int, error)
+	// ClaimDue takes up to limit pending deliveries whose next attempt is
+	// due, oldest first, hiding them from other dispatchers for lease
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*DueWebhookDelivery, error)
+	RecordAttempt(ctx context.Context, attempt WebhookAttempt) error
+}
@@ -0,0 +1,5 @@
+package errs
+
+var (
+	ErrInvalidAnalyticsFilter = New(ErrValidation, "invalid_analytics_filter", "invalid analytics filter")
+)
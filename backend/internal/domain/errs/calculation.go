@@ -0,0 +1,5 @@
+package errs
+
+var (
+	ErrCalculationNotFound = New(ErrNotFound, "calculation_not_found", "calculation not found")
+)
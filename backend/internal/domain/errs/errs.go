@@ -1,10 +1,126 @@
 package errs
 
-type ErrorResponse struct {
-	Error   string `json:"error"`
-	Details string `json:"details"`
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Error kinds. Every domain error belongs to one, and the HTTP layer maps
+// the kind to a status code.
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrConflict   = errors.New("conflict")
+	ErrValidation = errors.New("validation failed")
+	ErrForbidden  = errors.New("forbidden")
+	ErrInfeasible = errors.New("infeasible")
+	// ErrUnsupported is a feature the running server is not set up for
+	ErrUnsupported = errors.New("unsupported")
+	// ErrTimeout is a dependency that did not answer in time
+	ErrTimeout = errors.New("timeout")
+)
+
+// Error is a domain error with a stable machine-readable code. It matches
+// its kind, and any other Error with the same code, under errors.Is.
+// Message summarises the code; Detail, when set, describes the occurrence.
+type Error struct {
+	Kind    error
+	Code    string
+	Message string
+	Detail  string
+}
+
+func New(kind error, code string, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	if e.Detail != "" {
+		return e.Detail
+	}
+	return e.Message
+}
+
+func (e *Error) Is(target error) bool {
+	if target == e.Kind {
+		return true
+	}
+	other, ok := target.(*Error)
+	return ok && other.Code == e.Code
+}
+
+// Withf returns the same error with a more specific detail
+func (e *Error) Withf(format string, args ...interface{}) *Error {
+	return &Error{Kind: e.Kind, Code: e.Code, Message: e.Message, Detail: fmt.Sprintf(format, args...)}
+}
+
+var (
+	ErrInsufficientPermissions = New(ErrForbidden, "insufficient_permissions", "insufficient permissions")
+	ErrInvalidID               = New(ErrValidation, "invalid_id", "invalid ID")
+	ErrInvalidTimeRange        = New(ErrValidation, "invalid_time_range", "from must be before to")
+	ErrStorageUnsupported      = New(ErrUnsupported, "storage_unsupported", "the configured storage does not support this feature")
+	ErrQueryTimeout            = New(ErrTimeout, "query_timeout", "the database did not answer in time")
+)
+
+// QueryTimeoutError reports a repository operation cut short by its
+// deadline: the configured query timeout, or the caller's own when Timeout
+// is zero. It matches ErrQueryTimeout with errors.Is.
+type QueryTimeoutError struct {
+	Operation string
+	Timeout   time.Duration
+}
+
+func (e *QueryTimeoutError) Error() string {
+	return e.Unwrap().Error()
+}
+
+func (e *QueryTimeoutError) Unwrap() error {
+	if e.Timeout > 0 {
+		return ErrQueryTimeout.Withf("%s did not finish within %s", e.Operation, e.Timeout)
+	}
+	return ErrQueryTimeout.Withf("%s did not finish before the request's deadline", e.Operation)
+}
+
+// InvalidFieldsError is a validation failure of several fields at once.
+// It unwraps to Err, which decides its kind and code.
+type InvalidFieldsError struct {
+	Err    *Error
+	Fields []FieldError
+}
+
+func (e *InvalidFieldsError) Error() string {
+	return fmt.Sprintf("%v: %d invalid field(s)", e.Err, len(e.Fields))
+}
+
+func (e *InvalidFieldsError) Unwrap() error {
+	return e.Err
+}
+
+// ExistingRecordError is a conflict with a record that already exists;
+// problem responses name it as existing_id
+type ExistingRecordError interface {
+	error
+	ExistingRecordID() int
+}
+
+// Problem is an RFC 7807 application/problem+json error response. Code
+// and RequestID are extension members; Errors lists per-field failures
+// of a request body, keyed by JSON path. ExistingID names the record a
+// conflicting write collided with.
+type Problem struct {
+	Type       string       `json:"type" example:"/problems/validation_failed"`
+	Title      string       `json:"title" example:"Validation failed"`
+	Status     int          `json:"status" example:"400"`
+	Detail     string       `json:"detail,omitempty" example:"The request body has 1 invalid field"`
+	Instance   string       `json:"instance,omitempty" example:"/api/v1/pack-configurations"`
+	Code       string       `json:"code" example:"validation_failed"`
+	RequestID  string       `json:"request_id,omitempty" example:"4f6c2a9e0d1b4e7f8a3c5d2e1f0a9b8c"`
+	Errors     []FieldError `json:"errors,omitempty"`
+	ExistingID int          `json:"existing_id,omitempty" example:"4"`
 }
 
-func FormatValidationErrors(err error) string {
-	return err.Error()
+// FieldError is one invalid field of a request body
+type FieldError struct {
+	Field   string `json:"field" example:"pack_sizes[2]"`
+	Message string `json:"message" example:"must be at least 1"`
 }
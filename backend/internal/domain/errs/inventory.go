@@ -0,0 +1,10 @@
+package errs
+
+var (
+	ErrInsufficientStock    = New(ErrConflict, "insufficient_stock", "insufficient stock")
+	ErrReservationNotFound  = New(ErrNotFound, "reservation_not_found", "reservation not found")
+	ErrReservationNotActive = New(ErrConflict, "reservation_not_active", "reservation is not active")
+	ErrInvalidStockLevel    = New(ErrValidation, "invalid_stock_level", "invalid stock level")
+	ErrInvalidReservation   = New(ErrValidation, "invalid_reservation", "invalid reservation")
+	ErrUnfulfillableOrder   = New(ErrInfeasible, "unfulfillable_order", "order cannot be fulfilled with available stock")
+)
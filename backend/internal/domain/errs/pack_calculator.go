@@ -0,0 +1,5 @@
+package errs
+
+var (
+	ErrInvalidOrder = New(ErrValidation, "invalid_order", "invalid order")
+)
@@ -0,0 +1,101 @@
+package errs
+
+import (
+	"fmt"
+)
+
+var (
+	ErrPackConfigurationNotFound    = New(ErrNotFound, "pack_configuration_not_found", "pack configuration not found")
+	ErrConfigurationVersionNotFound = New(ErrNotFound, "configuration_version_not_found", "pack configuration version not found")
+	ErrConfigurationNameConflict    = New(ErrConflict, "configuration_name_conflict", "an active pack configuration already uses this name")
+	ErrConfigurationNotArchived     = New(ErrConflict, "configuration_not_archived", "pack configuration is not archived")
+	ErrConfigurationInUse           = New(ErrConflict, "configuration_in_use", "pack configuration is referenced by a warehouse")
+	ErrInvalidConfigurationQuery    = New(ErrValidation, "invalid_configuration_query", "invalid pack configuration query")
+	ErrConfigurationVersionConflict = New(ErrConflict, "version_conflict", "pack configuration was modified by another request")
+	ErrInvalidPackConfiguration     = New(ErrValidation, "invalid_pack_configuration", "invalid pack configuration")
+	ErrNoDefaultConfiguration       = New(ErrNotFound, "no_default_configuration", "no default pack configuration found")
+	ErrDefaultConfigurationDelete   = New(ErrConflict, "default_configuration_delete", "cannot delete the default pack configuration")
+	ErrInvalidImport                = New(ErrValidation, "invalid_import", "import has invalid configurations")
+	ErrDefaultConfigurationUnset    = New(ErrConflict, "default_configuration_unset", "cannot unset the default pack configuration")
+	ErrDuplicatePackSizes           = New(ErrConflict, "duplicate_pack_sizes", "another active pack configuration has the same pack sizes")
+	ErrDefaultPeriodOverlap         = New(ErrConflict, "default_period_overlap", "another default pack configuration is in effect during the same period")
+	ErrConfigurationNotEffective    = New(ErrValidation, "configuration_not_effective", "pack configuration is not in effect at the requested time")
+)
+
+// VersionConflictError reports a write conditioned on a stale configuration
+// version. It matches ErrConfigurationVersionConflict with errors.Is.
+type VersionConflictError struct {
+	ID              int
+	ExpectedVersion int
+	CurrentVersion  int
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("pack configuration %d is at version %d, not %d: %v",
+		e.ID, e.CurrentVersion, e.ExpectedVersion, ErrConfigurationVersionConflict)
+}
+
+func (e *VersionConflictError) Unwrap() error {
+	return ErrConfigurationVersionConflict
+}
+
+// NameConflictError reports a name already used by another active
+// configuration, compared case-insensitively. It matches
+// ErrConfigurationNameConflict with errors.Is.
+type NameConflictError struct {
+	Name       string
+	ExistingID int
+}
+
+func (e *NameConflictError) Error() string {
+	return e.Unwrap().Error()
+}
+
+func (e *NameConflictError) Unwrap() error {
+	return ErrConfigurationNameConflict.Withf("name %q is used by active pack configuration %d", e.Name, e.ExistingID)
+}
+
+func (e *NameConflictError) ExistingRecordID() int {
+	return e.ExistingID
+}
+
+// DuplicatePackSizesError reports pack sizes, in canonical order, that
+// another active configuration already has. It matches
+// ErrDuplicatePackSizes with errors.Is.
+type DuplicatePackSizesError struct {
+	PackSizes    []int
+	ExistingID   int
+	ExistingName string
+}
+
+func (e *DuplicatePackSizesError) Error() string {
+	return e.Unwrap().Error()
+}
+
+func (e *DuplicatePackSizesError) Unwrap() error {
+	return ErrDuplicatePackSizes.Withf("pack sizes %v are already used by active pack configuration %d (%q)", e.PackSizes, e.ExistingID, e.ExistingName)
+}
+
+func (e *DuplicatePackSizesError) ExistingRecordID() int {
+	return e.ExistingID
+}
+
+// DefaultPeriodOverlapError reports a write that would leave two default
+// configurations in effect at the same moment. It matches
+// ErrDefaultPeriodOverlap with errors.Is.
+type DefaultPeriodOverlapError struct {
+	ID         int
+	ExistingID int
+}
+
+func (e *DefaultPeriodOverlapError) Error() string {
+	return e.Unwrap().Error()
+}
+
+func (e *DefaultPeriodOverlapError) Unwrap() error {
+	return ErrDefaultPeriodOverlap.Withf("the effective period of pack configuration %d overlaps default pack configuration %d", e.ID, e.ExistingID)
+}
+
+func (e *DefaultPeriodOverlapError) ExistingRecordID() int {
+	return e.ExistingID
+}
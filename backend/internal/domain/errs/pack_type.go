@@ -0,0 +1,9 @@
+package errs
+
+var (
+	ErrPackTypeNotFound     = New(ErrNotFound, "pack_type_not_found", "pack type not found")
+	ErrInvalidPackType      = New(ErrValidation, "invalid_pack_type", "invalid pack type")
+	ErrPackTypeCodeConflict = New(ErrConflict, "pack_type_code_conflict", "another pack type already uses this code")
+	ErrPackTypeInUse        = New(ErrConflict, "pack_type_in_use", "pack type is referenced by an active pack configuration")
+	ErrUnknownPackType      = New(ErrValidation, "unknown_pack_type", "no active pack type has this code")
+)
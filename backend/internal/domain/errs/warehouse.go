@@ -0,0 +1,7 @@
+package errs
+
+var (
+	ErrWarehouseNotFound = New(ErrNotFound, "warehouse_not_found", "warehouse not found")
+	ErrInvalidWarehouse  = New(ErrValidation, "invalid_warehouse", "invalid warehouse")
+	ErrLocationInUse     = New(ErrConflict, "location_in_use", "location is already assigned to an active warehouse")
+)
@@ -0,0 +1,8 @@
+package errs
+
+var (
+	ErrWebhookNotFound         = New(ErrNotFound, "webhook_not_found", "webhook not found")
+	ErrInvalidWebhook          = New(ErrValidation, "invalid_webhook", "invalid webhook")
+	ErrWebhookDeliveryNotFound = New(ErrNotFound, "webhook_delivery_not_found", "webhook delivery not found")
+	ErrDeliveryNotDead         = New(ErrConflict, "delivery_not_dead", "only dead letters can be redelivered")
+)
@@ -0,0 +1,173 @@
+package dto
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+)
+
+// CSVExporter is implemented by analytics responses that can be downloaded
+// as CSV. The first row is the header.
+type CSVExporter interface {
+	CSVRows() [][]string
+}
+
+type SurplusPointResponse struct {
+	Bucket       time.Time `json:"bucket" example:"2024-01-01T00:00:00Z"`
+	Calculations int       `json:"calculations" example:"120"`
+	TotalItems   int64     `json:"total_items" example:"96000"`
+	TotalSurplus int64     `json:"total_surplus" example:"4350"`
+}
+
+type SurplusTrendResponse struct {
+	Bucket string                  `json:"bucket" example:"day"`
+	Points []*SurplusPointResponse `json:"points"`
+}
+
+type ExactMatchRateResponse struct {
+	Bucket          time.Time `json:"bucket" example:"2024-01-01T00:00:00Z"`
+	ConfigurationID *int      `json:"configuration_id" example:"1"`
+	Calculations    int       `json:"calculations" example:"120"`
+	ExactMatches    int       `json:"exact_matches" example:"30"`
+	Rate            float64   `json:"rate" example:"0.25"`
+}
+
+type ExactMatchRatesResponse struct {
+	Bucket string                    `json:"bucket" example:"day"`
+	Rates  []*ExactMatchRateResponse `json:"rates"`
+}
+
+type PackSizeUsageResponse struct {
+	PackSize     int   `json:"pack_size" example:"500"`
+	Packs        int64 `json:"packs" example:"840"`
+	Calculations int   `json:"calculations" example:"410"`
+}
+
+type PackSizeUsageListResponse struct {
+	PackSizes []*PackSizeUsageResponse `json:"pack_sizes"`
+}
+
+type PacksPerOrderPointResponse struct {
+	Bucket       time.Time `json:"bucket" example:"2024-01-01T00:00:00Z"`
+	Calculations int       `json:"calculations" example:"120"`
+	P50          float64   `json:"p50" example:"2"`
+	P95          float64   `json:"p95" example:"5"`
+}
+
+type PacksPerOrderResponse struct {
+	Bucket string                        `json:"bucket" example:"day"`
+	Points []*PacksPerOrderPointResponse `json:"points"`
+}
+
+func ToSurplusTrendResponse(bucket entity.TimeBucket, points []*entity.SurplusPoint) *SurplusTrendResponse {
+	responses := make([]*SurplusPointResponse, len(points))
+	for i, p := range points {
+		responses[i] = &SurplusPointResponse{
+			Bucket:       p.Bucket,
+			Calculations: p.Calculations,
+			TotalItems:   p.TotalItems,
+			TotalSurplus: p.TotalSurplus,
+		}
+	}
+	return &SurplusTrendResponse{Bucket: string(bucket), Points: responses}
+}
+
+func (r *SurplusTrendResponse) CSVRows() [][]string {
+	rows := [][]string{{"bucket", "calculations", "total_items", "total_surplus"}}
+	for _, p := range r.Points {
+		rows = append(rows, []string{
+			formatCSVTime(p.Bucket),
+			strconv.Itoa(p.Calculations),
+			strconv.FormatInt(p.TotalItems, 10),
+			strconv.FormatInt(p.TotalSurplus, 10),
+		})
+	}
+	return rows
+}
+
+func ToExactMatchRatesResponse(bucket entity.TimeBucket, rates []*entity.ExactMatchRate) *ExactMatchRatesResponse {
+	responses := make([]*ExactMatchRateResponse, len(rates))
+	for i, r := range rates {
+		responses[i] = &ExactMatchRateResponse{
+			Bucket:          r.Bucket,
+			ConfigurationID: r.ConfigurationID,
+			Calculations:    r.Calculations,
+			ExactMatches:    r.ExactMatches,
+			Rate:            r.Rate(),
+		}
+	}
+	return &ExactMatchRatesResponse{Bucket: string(bucket), Rates: responses}
+}
+
+func (r *ExactMatchRatesResponse) CSVRows() [][]string {
+	rows := [][]string{{"bucket", "configuration_id", "calculations", "exact_matches", "rate"}}
+	for _, rate := range r.Rates {
+		configurationID := ""
+		if rate.ConfigurationID != nil {
+			configurationID = strconv.Itoa(*rate.ConfigurationID)
+		}
+		rows = append(rows, []string{
+			formatCSVTime(rate.Bucket),
+			configurationID,
+			strconv.Itoa(rate.Calculations),
+			strconv.Itoa(rate.ExactMatches),
+			strconv.FormatFloat(rate.Rate, 'f', -1, 64),
+		})
+	}
+	return rows
+}
+
+func ToPackSizeUsageListResponse(usage []*entity.PackSizeUsage) *PackSizeUsageListResponse {
+	responses := make([]*PackSizeUsageResponse, len(usage))
+	for i, u := range usage {
+		responses[i] = &PackSizeUsageResponse{
+			PackSize:     u.PackSize,
+			Packs:        u.Packs,
+			Calculations: u.Calculations,
+		}
+	}
+	return &PackSizeUsageListResponse{PackSizes: responses}
+}
+
+func (r *PackSizeUsageListResponse) CSVRows() [][]string {
+	rows := [][]string{{"pack_size", "packs", "calculations"}}
+	for _, u := range r.PackSizes {
+		rows = append(rows, []string{
+			strconv.Itoa(u.PackSize),
+			strconv.FormatInt(u.Packs, 10),
+			strconv.Itoa(u.Calculations),
+		})
+	}
+	return rows
+}
+
+func ToPacksPerOrderResponse(bucket entity.TimeBucket, points []*entity.PacksPerOrderPoint) *PacksPerOrderResponse {
+	responses := make([]*PacksPerOrderPointResponse, len(points))
+	for i, p := range points {
+		responses[i] = &PacksPerOrderPointResponse{
+			Bucket:       p.Bucket,
+			Calculations: p.Calculations,
+			P50:          p.P50,
+			P95:          p.P95,
+		}
+	}
+	return &PacksPerOrderResponse{Bucket: string(bucket), Points: responses}
+}
+
+func (r *PacksPerOrderResponse) CSVRows() [][]string {
+	rows := [][]string{{"bucket", "calculations", "p50", "p95"}}
+	for _, p := range r.Points {
+		rows = append(rows, []string{
+			formatCSVTime(p.Bucket),
+			strconv.Itoa(p.Calculations),
+			strconv.FormatFloat(p.P50, 'f', -1, 64),
+			strconv.FormatFloat(p.P95, 'f', -1, 64),
+		})
+	}
+	return rows
+}
+
+func formatCSVTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,57 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+)
+
+type AuditEntryResponse struct {
+	ID         int64           `json:"id" example:"17"`
+	EntityType string          `json:"entity_type" example:"pack_configuration"`
+	EntityID   int             `json:"entity_id" example:"1"`
+	Action     string          `json:"action" example:"set_default"`
+	Actor      string          `json:"actor" example:"authenticated-user"`
+	RequestID  string          `json:"request_id,omitempty" example:"3f2a9c0e5b7d4e1f8a6b2c9d0e1f2a3b"`
+	Before     json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	After      json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+	CreatedAt  time.Time       `json:"created_at" example:"2024-01-01T00:00:00Z"`
+}
+
+type AuditLogResponse struct {
+	Entries []*AuditEntryResponse `json:"entries"`
+	Count   int                   `json:"count" example:"50"`
+	Total   int                   `json:"total" example:"312"`
+	Limit   int                   `json:"limit" example:"50"`
+	Offset  int                   `json:"offset" example:"0"`
+}
+
+func ToAuditEntryResponse(entry *entity.AuditEntry) *AuditEntryResponse {
+	return &AuditEntryResponse{
+		ID:         entry.ID,
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		Action:     string(entry.Action),
+		Actor:      entry.Actor,
+		RequestID:  entry.RequestID,
+		Before:     entry.Before,
+		After:      entry.After,
+		CreatedAt:  entry.CreatedAt,
+	}
+}
+
+func ToAuditLogResponse(entries []*entity.AuditEntry, total int, filter entity.AuditFilter) *AuditLogResponse {
+	responses := make([]*AuditEntryResponse, len(entries))
+	for i, entry := range entries {
+		responses[i] = ToAuditEntryResponse(entry)
+	}
+
+	return &AuditLogResponse{
+		Entries: responses,
+		Count:   len(responses),
+		Total:   total,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
+	}
+}
@@ -8,5 +8,6 @@ type AuthRequest struct {
 // AuthResponse represents the authentication response
 type AuthResponse struct {
 	Token     string `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	Tenant    string `json:"tenant" example:"default"`
 	ExpiresAt int64  `json:"expires_at" example:"1642492800"`
 }
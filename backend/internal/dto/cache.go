@@ -0,0 +1,15 @@
+package dto
+
+// CacheStatsResponse reports the pack configuration cache's counters since
+// the server started
+type CacheStatsResponse struct {
+	Hits          uint64  `json:"hits"`
+	Misses        uint64  `json:"misses"`
+	HitRatio      float64 `json:"hit_ratio"`
+	Evictions     uint64  `json:"evictions"`
+	Expirations   uint64  `json:"expirations"`
+	Invalidations uint64  `json:"invalidations"`
+	Entries       int     `json:"entries"`
+	MaxEntries    int     `json:"max_entries"`
+	TTLSeconds    float64 `json:"ttl_seconds"`
+}
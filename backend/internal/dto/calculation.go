@@ -0,0 +1,66 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+)
+
+type CalculationRecordResponse struct {
+	ID                   int64       `json:"id" example:"42"`
+	Subject              string      `json:"subject" example:"authenticated-user"`
+	Items                int         `json:"items" example:"251"`
+	PackSizes            []int       `json:"pack_sizes" swaggertype:"array,integer" example:"250,500,1000"`
+	ConfigurationID      *int        `json:"configuration_id,omitempty" example:"1"`
+	ConfigurationVersion *int        `json:"configuration_version,omitempty" example:"3"`
+	Location             string      `json:"location,omitempty" example:"warehouse-a"`
+	Allocation           map[int]int `json:"allocation" swaggertype:"object,integer" example:"500:1"`
+	TotalPacks           int         `json:"total_packs" example:"1"`
+	TotalItems           int         `json:"total_items" example:"500"`
+	Surplus              int         `json:"surplus" example:"249"`
+	Solver               string      `json:"solver" example:"unbounded-dp"`
+	DurationMicros       int64       `json:"duration_us" example:"85"`
+	CreatedAt            time.Time   `json:"created_at" example:"2024-01-01T00:00:00Z"`
+}
+
+type CalculationHistoryResponse struct {
+	Calculations []*CalculationRecordResponse `json:"calculations"`
+	Count        int                          `json:"count" example:"50"`
+	Total        int                          `json:"total" example:"1234"`
+	Limit        int                          `json:"limit" example:"50"`
+	Offset       int                          `json:"offset" example:"0"`
+}
+
+func ToCalculationRecordResponse(record *entity.CalculationRecord) *CalculationRecordResponse {
+	return &CalculationRecordResponse{
+		ID:                   record.ID,
+		Subject:              record.Subject,
+		Items:                record.Items,
+		PackSizes:            record.PackSizes,
+		ConfigurationID:      record.ConfigurationID,
+		ConfigurationVersion: record.ConfigurationVersion,
+		Location:             record.Location,
+		Allocation:           record.Allocation,
+		TotalPacks:           record.TotalPacks,
+		TotalItems:           record.TotalItems,
+		Surplus:              record.Surplus,
+		Solver:               record.Solver,
+		DurationMicros:       record.Duration.Microseconds(),
+		CreatedAt:            record.CreatedAt,
+	}
+}
+
+func ToCalculationHistoryResponse(records []*entity.CalculationRecord, total int, filter entity.CalculationFilter) *CalculationHistoryResponse {
+	responses := make([]*CalculationRecordResponse, len(records))
+	for i, record := range records {
+		responses[i] = ToCalculationRecordResponse(record)
+	}
+
+	return &CalculationHistoryResponse{
+		Calculations: responses,
+		Count:        len(responses),
+		Total:        total,
+		Limit:        filter.Limit,
+		Offset:       filter.Offset,
+	}
+}
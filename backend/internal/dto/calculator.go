@@ -1,13 +1,68 @@
 package dto
 
+import (
+	"sort"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+)
+
 type CalculationRequest struct {
-	Items     int   `json:"items" validate:"required,min=0" example:"251"`
-	PackSizes []int `json:"pack_sizes" validate:"required,min=1,dive,min=1" swaggertype:"array,integer" example:"250,500,1000"`
+	Items                int    `json:"items" validate:"required,min=0" example:"251"`
+	PackSizes            []int  `json:"pack_sizes" validate:"required_without_all=Location ConfigurationID,omitempty,min=1,dive,min=1" swaggertype:"array,integer" example:"250,500,1000"`
+	Location             string `json:"location,omitempty" validate:"omitempty,max=255" example:"warehouse-a"`
+	ConfigurationID      *int   `json:"configuration_id,omitempty" validate:"omitempty,min=1" example:"1"`
+	ConfigurationVersion *int   `json:"configuration_version,omitempty" validate:"excluded_without=ConfigurationID,omitempty,min=1" example:"2"`
 }
 
+// CalculationResponse lists the allocation as a pack size to quantity map.
+// When the configuration references pack types, Packs also lists it pack by
+// pack with the SKU of each size that has one.
 type CalculationResponse struct {
-	Allocation map[int]int `json:"allocation" swaggertype:"object,integer" example:"500:1"`
-	TotalPacks int         `json:"total_packs" example:"1"`
-	TotalItems int         `json:"total_items" example:"500"`
-	Surplus    int         `json:"surplus" example:"249"`
+	Allocation           map[int]int               `json:"allocation" swaggertype:"object,integer" example:"500:1"`
+	Packs                []*PackAllocationResponse `json:"packs,omitempty"`
+	TotalPacks           int                       `json:"total_packs" example:"1"`
+	TotalItems           int                       `json:"total_items" example:"500"`
+	Surplus              int                       `json:"surplus" example:"249"`
+	ConfigurationVersion *int                      `json:"configuration_version,omitempty" example:"2"`
+	CalculationID        *int64                    `json:"calculation_id,omitempty" example:"42"`
+}
+
+type PackAllocationResponse struct {
+	PackSize int    `json:"pack_size" example:"500"`
+	Quantity int    `json:"quantity" example:"1"`
+	SKU      string `json:"sku,omitempty" example:"BOX-500"`
+	Label    string `json:"label,omitempty" example:"Box of 500"`
+}
+
+func ToCalculationResponse(result *entity.CalculationResult) *CalculationResponse {
+	return &CalculationResponse{
+		Allocation: result.Allocation.GetAllocation(),
+		TotalPacks: result.Allocation.TotalPacks(),
+		TotalItems: result.Allocation.TotalItems(),
+		Surplus:    result.Surplus,
+	}
+}
+
+// ToPackAllocations lists an allocation by pack size, largest first, naming
+// the pack type of each size that has one. It is nil without pack types.
+func ToPackAllocations(allocation map[int]int, packTypes []*entity.PackType) []*PackAllocationResponse {
+	if len(packTypes) == 0 {
+		return nil
+	}
+	byItemCount := make(map[int]*entity.PackType, len(packTypes))
+	for _, packType := range packTypes {
+		byItemCount[packType.ItemCount] = packType
+	}
+
+	packs := make([]*PackAllocationResponse, 0, len(allocation))
+	for size, quantity := range allocation {
+		pack := &PackAllocationResponse{PackSize: size, Quantity: quantity}
+		if packType, ok := byItemCount[size]; ok {
+			pack.SKU = packType.Code
+			pack.Label = packType.Label
+		}
+		packs = append(packs, pack)
+	}
+	sort.Slice(packs, func(i, j int) bool { return packs[i].PackSize > packs[j].PackSize })
+	return packs
 }
@@ -0,0 +1,89 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+)
+
+type SetStockRequest struct {
+	OnHand int `json:"on_hand" validate:"min=0" example:"120"`
+}
+
+type StockLevelResponse struct {
+	Location  string    `json:"location" example:"warehouse-a"`
+	PackSize  int       `json:"pack_size" example:"250"`
+	OnHand    int       `json:"on_hand" example:"120"`
+	Reserved  int       `json:"reserved" example:"20"`
+	Available int       `json:"available" example:"100"`
+	UpdatedAt time.Time `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+}
+
+type StockListResponse struct {
+	Location string                `json:"location" example:"warehouse-a"`
+	Stock    []*StockLevelResponse `json:"stock"`
+	Count    int                   `json:"count" example:"3"`
+}
+
+type StockCalculationRequest struct {
+	Items     int   `json:"items" validate:"min=0" example:"251"`
+	PackSizes []int `json:"pack_sizes,omitempty" validate:"omitempty,dive,min=1" swaggertype:"array,integer" example:"250,500,1000"`
+}
+
+type ReservationResponse struct {
+	ID         int         `json:"id" example:"1"`
+	Location   string      `json:"location" example:"warehouse-a"`
+	Items      int         `json:"items" example:"251"`
+	Allocation map[int]int `json:"allocation" swaggertype:"object,integer" example:"500:1"`
+	TotalPacks int         `json:"total_packs" example:"1"`
+	TotalItems int         `json:"total_items" example:"500"`
+	Surplus    int         `json:"surplus" example:"249"`
+	Status     string      `json:"status" example:"active"`
+	CreatedAt  time.Time   `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt  time.Time   `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+}
+
+func ToStockLevelResponse(level *entity.StockLevel) *StockLevelResponse {
+	return &StockLevelResponse{
+		Location:  level.Location,
+		PackSize:  level.PackSize,
+		OnHand:    level.OnHand,
+		Reserved:  level.Reserved,
+		Available: level.Available(),
+		UpdatedAt: level.UpdatedAt,
+	}
+}
+
+func ToStockListResponse(location string, levels []*entity.StockLevel) *StockListResponse {
+	responses := make([]*StockLevelResponse, len(levels))
+	for i, level := range levels {
+		responses[i] = ToStockLevelResponse(level)
+	}
+
+	return &StockListResponse{
+		Location: location,
+		Stock:    responses,
+		Count:    len(responses),
+	}
+}
+
+func ToReservationResponse(reservation *entity.Reservation) *ReservationResponse {
+	totalPacks, totalItems := 0, 0
+	for size, count := range reservation.Allocation {
+		totalPacks += count
+		totalItems += size * count
+	}
+
+	return &ReservationResponse{
+		ID:         reservation.ID,
+		Location:   reservation.Location,
+		Items:      reservation.OrderQuantity,
+		Allocation: reservation.Allocation,
+		TotalPacks: totalPacks,
+		TotalItems: totalItems,
+		Surplus:    reservation.Surplus,
+		Status:     string(reservation.Status),
+		CreatedAt:  reservation.CreatedAt,
+		UpdatedAt:  reservation.UpdatedAt,
+	}
+}
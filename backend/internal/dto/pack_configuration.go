@@ -1,20 +1,114 @@
 package dto
 
 import (
+	"bytes"
+	"encoding/json"
 	"time"
 
 	"github.com/Schieck/packs-calculator/internal/domain/entity"
 )
 
+// CreatePackConfigurationRequest needs pack sizes, pack types or both; each
+// pack type's item count is also offered as a pack size
 type CreatePackConfigurationRequest struct {
-	Name      string `json:"name" validate:"required,min=1,max=255" example:"Standard Packs"`
-	PackSizes []int  `json:"pack_sizes" validate:"required,min=1,dive,min=1" swaggertype:"array,integer" example:"250,500,1000"`
+	Name          string     `json:"name" validate:"required,min=1,max=255" example:"Standard Packs"`
+	PackSizes     []int      `json:"pack_sizes" validate:"required_without=PackTypes,omitempty,min=1,dive,min=1" swaggertype:"array,integer" example:"250,500,1000"`
+	PackTypes     []string   `json:"pack_types,omitempty" validate:"omitempty,dive,required,max=64" example:"BOX-250,BOX-500"`
+	EffectiveFrom *time.Time `json:"effective_from,omitempty" example:"2025-03-01T00:00:00Z"`
+	EffectiveTo   *time.Time `json:"effective_to,omitempty" example:"2025-09-01T00:00:00Z"`
+	PackConfigurationDetailsRequest
 }
 
+// PackConfigurationDetailsRequest carries the descriptive members of a
+// create or update. Metadata must be a JSON object.
+type PackConfigurationDetailsRequest struct {
+	Description string            `json:"description,omitempty" validate:"max=2000" example:"Boxes for the EU web shop"`
+	Labels      map[string]string `json:"labels,omitempty" validate:"max=64" example:"region:eu,customer:acme"`
+	Metadata    json.RawMessage   `json:"metadata,omitempty" swaggertype:"object"`
+}
+
+// UpdatePackConfigurationRequest replaces a configuration's contents; an
+// absent effective bound leaves that side of the period open, and absent
+// details are cleared
 type UpdatePackConfigurationRequest struct {
-	Name      string `json:"name" validate:"required,min=1,max=255" example:"Updated Standard Packs"`
-	PackSizes []int  `json:"pack_sizes" validate:"required,min=1,dive,min=1" swaggertype:"array,integer" example:"250,500,1000,2000"`
-	IsDefault bool   `json:"is_default" example:"false"`
+	Name          string     `json:"name" validate:"required,min=1,max=255" example:"Updated Standard Packs"`
+	PackSizes     []int      `json:"pack_sizes" validate:"required_without=PackTypes,omitempty,min=1,dive,min=1" swaggertype:"array,integer" example:"250,500,1000,2000"`
+	PackTypes     []string   `json:"pack_types,omitempty" validate:"omitempty,dive,required,max=64" example:"BOX-250,BOX-500"`
+	IsDefault     bool       `json:"is_default" example:"false"`
+	EffectiveFrom *time.Time `json:"effective_from,omitempty" example:"2025-03-01T00:00:00Z"`
+	EffectiveTo   *time.Time `json:"effective_to,omitempty" example:"2025-09-01T00:00:00Z"`
+	PackConfigurationDetailsRequest
+}
+
+// PatchPackConfigurationRequest is a JSON Merge Patch (RFC 7386) of a pack
+// configuration. Absent members are left unchanged; only the members tagged
+// patch:"removable" may be null, which removes them.
+// add_pack_sizes and remove_pack_sizes apply after pack_sizes. pack_types
+// replaces the referenced pack types, [] removing them, and without pack_sizes
+// also drops the sizes the old ones offered. labels and metadata are merged
+// in turn: a null label or metadata member is removed.
+type PatchPackConfigurationRequest struct {
+	Name            *string         `json:"name,omitempty" validate:"omitempty,min=1,max=255" example:"Standard Packs"`
+	PackSizes       []int           `json:"pack_sizes,omitempty" validate:"omitempty,dive,min=1" swaggertype:"array,integer" example:"250,500,1000"`
+	AddPackSizes    []int           `json:"add_pack_sizes,omitempty" validate:"omitempty,dive,min=1" swaggertype:"array,integer" example:"2000"`
+	RemovePackSizes []int           `json:"remove_pack_sizes,omitempty" validate:"omitempty,dive,min=1" swaggertype:"array,integer" example:"250"`
+	PackTypes       []string        `json:"pack_types,omitempty" validate:"omitempty,dive,required,max=64" example:"BOX-250,BOX-500"`
+	IsDefault       *bool           `json:"is_default,omitempty" example:"true"`
+	EffectiveFrom   NullableTime    `json:"effective_from,omitempty" patch:"removable" swaggertype:"string" format:"date-time" example:"2025-03-01T00:00:00Z"`
+	EffectiveTo     NullableTime    `json:"effective_to,omitempty" patch:"removable" swaggertype:"string" format:"date-time" example:"2025-09-01T00:00:00Z"`
+	Description     *string         `json:"description,omitempty" validate:"omitempty,max=2000" example:"Boxes for the EU web shop"`
+	Labels          NullableLabels  `json:"labels,omitempty" patch:"removable" swaggertype:"object,string" example:"region:eu"`
+	Metadata        json.RawMessage `json:"metadata,omitempty" patch:"removable" swaggertype:"object"`
+}
+
+// NullableTime is a timestamp member of a merge patch. Set reports whether
+// the member was present; a null member is set with a nil Value.
+type NullableTime struct {
+	Set   bool
+	Value *time.Time
+}
+
+func (t *NullableTime) UnmarshalJSON(data []byte) error {
+	t.Set = true
+	if string(data) == "null" {
+		t.Value = nil
+		return nil
+	}
+	var value time.Time
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	t.Value = &value
+	return nil
+}
+
+// patch turns the member into a domain patch, nil when it was absent
+func (t NullableTime) patch() *entity.TimePatch {
+	if !t.Set {
+		return nil
+	}
+	return &entity.TimePatch{Value: t.Value}
+}
+
+// NullableLabels is the labels member of a merge patch. Set reports whether
+// the member was present; a null member is set with a nil Value, and a null
+// label inside it has a nil value.
+type NullableLabels struct {
+	Set   bool
+	Value map[string]*string
+}
+
+func (l *NullableLabels) UnmarshalJSON(data []byte) error {
+	l.Set = true
+	l.Value = nil
+	if string(data) == "null" {
+		return nil
+	}
+	return json.Unmarshal(data, &l.Value)
+}
+
+type RestorePackConfigurationRequest struct {
+	Name string `json:"name,omitempty" validate:"omitempty,min=1,max=255" example:"Standard Packs (restored)"`
 }
 
 type SetDefaultPackConfigurationRequest struct {
@@ -22,33 +116,119 @@ type SetDefaultPackConfigurationRequest struct {
 }
 
 type PackConfigurationResponse struct {
-	ID        int       `json:"id" example:"1"`
-	Name      string    `json:"name" example:"Main Edge Case"`
-	PackSizes []int     `json:"pack_sizes" swaggertype:"array,integer" example:"23,31,53"`
-	IsDefault bool      `json:"is_default" example:"true"`
-	IsActive  bool      `json:"is_active" example:"true"`
-	CreatedAt time.Time `json:"created_at" example:"2024-01-01T00:00:00Z"`
-	UpdatedAt time.Time `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+	ID            int                          `json:"id" example:"1"`
+	Name          string                       `json:"name" example:"Main Edge Case"`
+	PackSizes     []int                        `json:"pack_sizes" swaggertype:"array,integer" example:"23,31,53"`
+	PackTypes     []*PackTypeReferenceResponse `json:"pack_types,omitempty"`
+	IsDefault     bool                         `json:"is_default" example:"true"`
+	IsActive      bool                         `json:"is_active" example:"true"`
+	EffectiveFrom *time.Time                   `json:"effective_from,omitempty" example:"2025-03-01T00:00:00Z"`
+	EffectiveTo   *time.Time                   `json:"effective_to,omitempty" example:"2025-09-01T00:00:00Z"`
+	Description   string                       `json:"description,omitempty" example:"Boxes for the EU web shop"`
+	Labels        map[string]string            `json:"labels,omitempty" example:"region:eu,customer:acme"`
+	Metadata      json.RawMessage              `json:"metadata,omitempty" swaggertype:"object"`
+	Version       int                          `json:"version" example:"3"`
+	CreatedAt     time.Time                    `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt     time.Time                    `json:"updated_at" example:"2024-01-01T00:00:00Z"`
 }
 
 type PackConfigurationListResponse struct {
 	Configurations []*PackConfigurationResponse `json:"configurations"`
 	Count          int                          `json:"count" example:"3"`
+	Total          int                          `json:"total" example:"240"`
+	Limit          int                          `json:"limit" example:"50"`
+	Offset         int                          `json:"offset" example:"0"`
+}
+
+type PackConfigurationVersionResponse struct {
+	ConfigurationID int               `json:"configuration_id" example:"1"`
+	Version         int               `json:"version" example:"2"`
+	Name            string            `json:"name" example:"Main Edge Case"`
+	PackSizes       []int             `json:"pack_sizes" swaggertype:"array,integer" example:"23,31,53"`
+	PackTypeIDs     []int             `json:"pack_type_ids,omitempty" swaggertype:"array,integer" example:"4,7"`
+	EffectiveFrom   *time.Time        `json:"effective_from,omitempty" example:"2025-03-01T00:00:00Z"`
+	EffectiveTo     *time.Time        `json:"effective_to,omitempty" example:"2025-09-01T00:00:00Z"`
+	Description     string            `json:"description,omitempty" example:"Boxes for the EU web shop"`
+	Labels          map[string]string `json:"labels,omitempty" example:"region:eu,customer:acme"`
+	Metadata        json.RawMessage   `json:"metadata,omitempty" swaggertype:"object"`
+	CreatedAt       time.Time         `json:"created_at" example:"2024-01-01T00:00:00Z"`
+}
+
+type PackConfigurationVersionListResponse struct {
+	Versions []*PackConfigurationVersionResponse `json:"versions"`
+	Count    int                                 `json:"count" example:"3"`
+}
+
+type EquivalentPackConfigurationResponse struct {
+	Configuration      *PackConfigurationResponse `json:"configuration"`
+	Match              string                     `json:"match" enums:"identical,equivalent" example:"equivalent"`
+	RedundantPackSizes []int                      `json:"redundant_pack_sizes" swaggertype:"array,integer" example:"750"`
+}
+
+type PackSizeEquivalenceResponse struct {
+	PackSizes          []int                                  `json:"pack_sizes" swaggertype:"array,integer" example:"250,500,1000"`
+	MinimalPackSizes   []int                                  `json:"minimal_pack_sizes" swaggertype:"array,integer" example:"250"`
+	RedundantPackSizes []int                                  `json:"redundant_pack_sizes" swaggertype:"array,integer" example:"500,1000"`
+	Configurations     []*EquivalentPackConfigurationResponse `json:"configurations"`
+	Count              int                                    `json:"count" example:"2"`
 }
 
 func ToPackConfigurationResponse(config *entity.PackConfiguration) *PackConfigurationResponse {
 	return &PackConfigurationResponse{
-		ID:        config.ID,
-		Name:      config.Name,
-		PackSizes: config.PackSizes,
-		IsDefault: config.IsDefault,
-		IsActive:  config.IsActive,
-		CreatedAt: config.CreatedAt,
-		UpdatedAt: config.UpdatedAt,
+		ID:            config.ID,
+		Name:          config.Name,
+		PackSizes:     config.PackSizes,
+		PackTypes:     ToPackTypeReferences(config.PackTypes),
+		IsDefault:     config.IsDefault,
+		IsActive:      config.IsActive,
+		EffectiveFrom: config.EffectiveFrom,
+		EffectiveTo:   config.EffectiveTo,
+		Description:   config.Description,
+		Labels:        config.Labels,
+		Metadata:      config.Metadata,
+		Version:       config.Version,
+		CreatedAt:     config.CreatedAt,
+		UpdatedAt:     config.UpdatedAt,
+	}
+}
+
+func ToPackConfigurationPatch(req *PatchPackConfigurationRequest) entity.PackConfigurationPatch {
+	return entity.PackConfigurationPatch{
+		Name:            req.Name,
+		PackSizes:       req.PackSizes,
+		AddPackSizes:    req.AddPackSizes,
+		RemovePackSizes: req.RemovePackSizes,
+		PackTypes:       req.PackTypes,
+		IsDefault:       req.IsDefault,
+		EffectiveFrom:   req.EffectiveFrom.patch(),
+		EffectiveTo:     req.EffectiveTo.patch(),
+		Description:     req.Description,
+		Labels:          req.Labels.Value,
+		ClearLabels:     req.Labels.Set && req.Labels.Value == nil,
+		Metadata:        req.Metadata,
 	}
 }
 
-func ToPackConfigurationListResponse(configs []*entity.PackConfiguration) *PackConfigurationListResponse {
+// ToPackConfigurationDetails collects a request's descriptive members;
+// null metadata counts as none
+func ToPackConfigurationDetails(req PackConfigurationDetailsRequest) entity.PackConfigurationDetails {
+	metadata := req.Metadata
+	if bytes.Equal(bytes.TrimSpace(metadata), []byte("null")) {
+		metadata = nil
+	}
+	return entity.PackConfigurationDetails{
+		Description: req.Description,
+		Labels:      req.Labels,
+		Metadata:    metadata,
+	}
+}
+
+// ToEffectivePeriod collects a request's optional effective bounds
+func ToEffectivePeriod(from, to *time.Time) entity.EffectivePeriod {
+	return entity.EffectivePeriod{EffectiveFrom: from, EffectiveTo: to}
+}
+
+func ToPackConfigurationListResponse(configs []*entity.PackConfiguration, total int, query entity.PackConfigurationQuery) *PackConfigurationListResponse {
 	responses := make([]*PackConfigurationResponse, len(configs))
 	for i, config := range configs {
 		responses[i] = ToPackConfigurationResponse(config)
@@ -57,5 +237,63 @@ func ToPackConfigurationListResponse(configs []*entity.PackConfiguration) *PackC
 	return &PackConfigurationListResponse{
 		Configurations: responses,
 		Count:          len(responses),
+		Total:          total,
+		Limit:          query.Limit,
+		Offset:         query.Offset,
+	}
+}
+
+func ToPackConfigurationVersionResponse(version *entity.PackConfigurationVersion) *PackConfigurationVersionResponse {
+	return &PackConfigurationVersionResponse{
+		ConfigurationID: version.ConfigurationID,
+		Version:         version.Version,
+		Name:            version.Name,
+		PackSizes:       version.PackSizes,
+		PackTypeIDs:     version.PackTypeIDs,
+		EffectiveFrom:   version.EffectiveFrom,
+		EffectiveTo:     version.EffectiveTo,
+		Description:     version.Description,
+		Labels:          version.Labels,
+		Metadata:        version.Metadata,
+		CreatedAt:       version.CreatedAt,
+	}
+}
+
+func ToPackConfigurationVersionListResponse(versions []*entity.PackConfigurationVersion) *PackConfigurationVersionListResponse {
+	responses := make([]*PackConfigurationVersionResponse, len(versions))
+	for i, version := range versions {
+		responses[i] = ToPackConfigurationVersionResponse(version)
+	}
+
+	return &PackConfigurationVersionListResponse{
+		Versions: responses,
+		Count:    len(responses),
+	}
+}
+
+func ToPackSizeEquivalenceResponse(equivalence *entity.PackSizeEquivalence) *PackSizeEquivalenceResponse {
+	responses := make([]*EquivalentPackConfigurationResponse, len(equivalence.Configurations))
+	for i, equivalent := range equivalence.Configurations {
+		responses[i] = &EquivalentPackConfigurationResponse{
+			Configuration:      ToPackConfigurationResponse(equivalent.Configuration),
+			Match:              string(equivalent.Match),
+			RedundantPackSizes: nonNilSizes(equivalent.RedundantPackSizes),
+		}
+	}
+
+	return &PackSizeEquivalenceResponse{
+		PackSizes:          nonNilSizes(equivalence.PackSizes),
+		MinimalPackSizes:   nonNilSizes(equivalence.MinimalPackSizes),
+		RedundantPackSizes: nonNilSizes(equivalence.RedundantPackSizes),
+		Configurations:     responses,
+		Count:              len(responses),
+	}
+}
+
+// nonNilSizes keeps empty size lists as [] rather than null in JSON
+func nonNilSizes(sizes []int) []int {
+	if sizes == nil {
+		return []int{}
 	}
+	return sizes
 }
@@ -0,0 +1,330 @@
+package dto
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+)
+
+// packConfigurationCSVHeader lists the CSV columns in export order. Pack
+// sizes share one cell, separated by semicolons, as do pack type codes and
+// key=value labels; metadata is a JSON object.
+var packConfigurationCSVHeader = []string{
+	"name", "pack_sizes", "pack_types", "is_default", "effective_from", "effective_to",
+	"description", "labels", "metadata", "created_at", "updated_at",
+}
+
+// PackConfigurationRecord is one configuration in an export or import file.
+// Timestamps are optional on import, and absent effective bounds are open.
+// PackTypes are pack type codes, whose item counts are also pack sizes.
+type PackConfigurationRecord struct {
+	Name          string                 `json:"name" yaml:"name" example:"Standard Packs"`
+	PackSizes     []int                  `json:"pack_sizes" yaml:"pack_sizes,flow" swaggertype:"array,integer" example:"250,500,1000"`
+	PackTypes     []string               `json:"pack_types,omitempty" yaml:"pack_types,flow,omitempty" example:"BOX-250,BOX-500"`
+	IsDefault     bool                   `json:"is_default" yaml:"is_default" example:"true"`
+	EffectiveFrom *time.Time             `json:"effective_from,omitempty" yaml:"effective_from,omitempty" example:"2025-03-01T00:00:00Z"`
+	EffectiveTo   *time.Time             `json:"effective_to,omitempty" yaml:"effective_to,omitempty" example:"2025-09-01T00:00:00Z"`
+	Description   string                 `json:"description,omitempty" yaml:"description,omitempty" example:"Boxes for the EU web shop"`
+	Labels        map[string]string      `json:"labels,omitempty" yaml:"labels,omitempty" example:"region:eu"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty" yaml:"metadata,omitempty" swaggertype:"object"`
+	CreatedAt     *time.Time             `json:"created_at,omitempty" yaml:"created_at,omitempty" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt     *time.Time             `json:"updated_at,omitempty" yaml:"updated_at,omitempty" example:"2024-01-01T00:00:00Z"`
+}
+
+// PackConfigurationDocument is the file format of
+// GET /pack-configurations/export and POST /pack-configurations/import
+type PackConfigurationDocument struct {
+	Configurations []PackConfigurationRecord `json:"configurations" yaml:"configurations"`
+}
+
+type PackConfigurationImportResultResponse struct {
+	Index  int    `json:"index" example:"0"`
+	Name   string `json:"name" example:"Standard Packs"`
+	Action string `json:"action" enums:"create,update,unchanged" example:"create"`
+	ID     int    `json:"id,omitempty" example:"7"`
+}
+
+type PackConfigurationImportResponse struct {
+	DryRun    bool                                     `json:"dry_run" example:"false"`
+	Created   int                                      `json:"created" example:"1"`
+	Updated   int                                      `json:"updated" example:"2"`
+	Unchanged int                                      `json:"unchanged" example:"0"`
+	Results   []*PackConfigurationImportResultResponse `json:"results"`
+}
+
+func ToPackConfigurationDocument(configs []*entity.PackConfiguration) *PackConfigurationDocument {
+	records := make([]PackConfigurationRecord, len(configs))
+	for i, config := range configs {
+		createdAt := config.CreatedAt.UTC()
+		updatedAt := config.UpdatedAt.UTC()
+		records[i] = PackConfigurationRecord{
+			Name:          config.Name,
+			PackSizes:     config.PackSizes,
+			PackTypes:     packTypeCodes(config.PackTypes),
+			IsDefault:     config.IsDefault,
+			EffectiveFrom: utcTime(config.EffectiveFrom),
+			EffectiveTo:   utcTime(config.EffectiveTo),
+			Description:   config.Description,
+			Labels:        config.Labels,
+			Metadata:      metadataObject(config.Metadata),
+			CreatedAt:     &createdAt,
+			UpdatedAt:     &updatedAt,
+		}
+	}
+	return &PackConfigurationDocument{Configurations: records}
+}
+
+// ImportRows converts the document into the rows an import validates
+func (d *PackConfigurationDocument) ImportRows() []entity.PackConfigurationImportRow {
+	rows := make([]entity.PackConfigurationImportRow, len(d.Configurations))
+	for i, record := range d.Configurations {
+		rows[i] = entity.PackConfigurationImportRow{
+			Name:      record.Name,
+			PackSizes: record.PackSizes,
+			PackTypes: record.PackTypes,
+			IsDefault: record.IsDefault,
+			CreatedAt: record.CreatedAt,
+			UpdatedAt: record.UpdatedAt,
+			EffectivePeriod: entity.EffectivePeriod{
+				EffectiveFrom: record.EffectiveFrom,
+				EffectiveTo:   record.EffectiveTo,
+			},
+			PackConfigurationDetails: entity.PackConfigurationDetails{
+				Description: record.Description,
+				Labels:      record.Labels,
+				Metadata:    metadataJSON(record.Metadata),
+			},
+		}
+	}
+	return rows
+}
+
+func (d *PackConfigurationDocument) CSVRows() [][]string {
+	rows := [][]string{packConfigurationCSVHeader}
+	for _, record := range d.Configurations {
+		sizes := make([]string, len(record.PackSizes))
+		for i, size := range record.PackSizes {
+			sizes[i] = strconv.Itoa(size)
+		}
+		rows = append(rows, []string{
+			record.Name,
+			strings.Join(sizes, ";"),
+			strings.Join(record.PackTypes, ";"),
+			strconv.FormatBool(record.IsDefault),
+			formatRecordTime(record.EffectiveFrom),
+			formatRecordTime(record.EffectiveTo),
+			record.Description,
+			entity.Labels(record.Labels).String(),
+			string(metadataJSON(record.Metadata)),
+			formatRecordTime(record.CreatedAt),
+			formatRecordTime(record.UpdatedAt),
+		})
+	}
+	return rows
+}
+
+// ParsePackConfigurationCSV reads a document in the export's CSV layout. The
+// header must name the name column and the pack_sizes or pack_types column;
+// the others are optional and may come in any order. Cells that cannot be parsed are returned as
+// field errors; err is only set when the CSV itself is malformed.
+func ParsePackConfigurationCSV(r io.Reader) (doc *PackConfigurationDocument, fieldErrors []errs.FieldError, err error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, fmt.Errorf("the CSV has no header row")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		switch name {
+		case "name", "pack_sizes", "pack_types", "is_default", "effective_from", "effective_to",
+			"description", "labels", "metadata", "created_at", "updated_at":
+		default:
+			fieldErrors = append(fieldErrors, errs.FieldError{Field: name, Message: "is not a known column"})
+			continue
+		}
+		if _, duplicate := columns[name]; duplicate {
+			fieldErrors = append(fieldErrors, errs.FieldError{Field: name, Message: "appears more than once in the header"})
+			continue
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["name"]; !ok {
+		fieldErrors = append(fieldErrors, errs.FieldError{Field: "name", Message: "column is required"})
+	}
+	_, hasSizes := columns["pack_sizes"]
+	if _, hasTypes := columns["pack_types"]; !hasSizes && !hasTypes {
+		fieldErrors = append(fieldErrors, errs.FieldError{Field: "pack_sizes", Message: "column is required"})
+	}
+	if len(fieldErrors) > 0 {
+		return nil, fieldErrors, nil
+	}
+
+	doc = &PackConfigurationDocument{Configurations: []PackConfigurationRecord{}}
+	for index := 0; ; index++ {
+		line, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		cell := func(column string) string {
+			i, ok := columns[column]
+			if !ok || i >= len(line) {
+				return ""
+			}
+			return strings.TrimSpace(line[i])
+		}
+		cellError := func(column, message string) {
+			fieldErrors = append(fieldErrors, errs.FieldError{
+				Field:   fmt.Sprintf("configurations[%d].%s", index, column),
+				Message: message,
+			})
+		}
+
+		record := PackConfigurationRecord{Name: cell("name"), Description: cell("description")}
+		if sizes := cell("pack_sizes"); sizes != "" {
+			for _, size := range strings.Split(sizes, ";") {
+				value, err := strconv.Atoi(strings.TrimSpace(size))
+				if err != nil {
+					cellError("pack_sizes", "must be integers separated by semicolons")
+					break
+				}
+				record.PackSizes = append(record.PackSizes, value)
+			}
+		}
+		if codes := cell("pack_types"); codes != "" {
+			for _, code := range strings.Split(codes, ";") {
+				record.PackTypes = append(record.PackTypes, strings.TrimSpace(code))
+			}
+		}
+		if value := cell("is_default"); value != "" {
+			isDefault, err := strconv.ParseBool(value)
+			if err != nil {
+				cellError("is_default", "must be true or false")
+			}
+			record.IsDefault = isDefault
+		}
+		if value := cell("labels"); value != "" {
+			labels, err := entity.ParseLabels(value, ";")
+			if err != nil {
+				cellError("labels", "must be key=value pairs separated by semicolons")
+			}
+			record.Labels = labels
+		}
+		if value := cell("metadata"); value != "" {
+			if err := json.Unmarshal([]byte(value), &record.Metadata); err != nil || record.Metadata == nil {
+				cellError("metadata", "must be a JSON object")
+			}
+		}
+		for _, timestamp := range []struct {
+			column string
+			target **time.Time
+		}{
+			{"effective_from", &record.EffectiveFrom},
+			{"effective_to", &record.EffectiveTo},
+			{"created_at", &record.CreatedAt},
+			{"updated_at", &record.UpdatedAt},
+		} {
+			value := cell(timestamp.column)
+			if value == "" {
+				continue
+			}
+			t, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				cellError(timestamp.column, "must be an RFC3339 timestamp")
+				continue
+			}
+			*timestamp.target = &t
+		}
+		doc.Configurations = append(doc.Configurations, record)
+	}
+
+	return doc, fieldErrors, nil
+}
+
+func ToPackConfigurationImportResponse(results []entity.PackConfigurationImportResult, dryRun bool) *PackConfigurationImportResponse {
+	response := &PackConfigurationImportResponse{
+		DryRun:  dryRun,
+		Results: make([]*PackConfigurationImportResultResponse, len(results)),
+	}
+	for i, result := range results {
+		switch result.Action {
+		case entity.ImportActionCreate:
+			response.Created++
+		case entity.ImportActionUpdate:
+			response.Updated++
+		case entity.ImportActionUnchanged:
+			response.Unchanged++
+		}
+		response.Results[i] = &PackConfigurationImportResultResponse{
+			Index:  result.Index,
+			Name:   result.Name,
+			Action: string(result.Action),
+			ID:     result.ID,
+		}
+	}
+	return response
+}
+
+// metadataObject decodes stored metadata for a record. Stored metadata is
+// always an object.
+func metadataObject(metadata json.RawMessage) map[string]interface{} {
+	var object map[string]interface{}
+	if metadata != nil {
+		_ = json.Unmarshal(metadata, &object)
+	}
+	return object
+}
+
+// metadataJSON encodes a record's metadata, nil when it has none. Decoded
+// JSON and YAML values always encode.
+func metadataJSON(object map[string]interface{}) json.RawMessage {
+	if object == nil {
+		return nil
+	}
+	data, _ := json.Marshal(object)
+	return data
+}
+
+// packTypeCodes lists the codes of a configuration's pack types
+func packTypeCodes(packTypes []*entity.PackType) []string {
+	var codes []string
+	for _, packType := range packTypes {
+		codes = append(codes, packType.Code)
+	}
+	return codes
+}
+
+// utcTime copies an optional timestamp into UTC
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+// formatRecordTime keeps sub-second precision so exports round-trip exactly
+func formatRecordTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,103 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+)
+
+type PackTypeRequest struct {
+	Code      string `json:"code" validate:"required,min=1,max=64" example:"BOX-250"`
+	Label     string `json:"label" validate:"required,min=1,max=255" example:"Box of 250"`
+	ItemCount int    `json:"item_count" validate:"required,min=1" example:"250"`
+	LengthMM  int    `json:"length_mm,omitempty" validate:"min=0" example:"400"`
+	WidthMM   int    `json:"width_mm,omitempty" validate:"min=0" example:"300"`
+	HeightMM  int    `json:"height_mm,omitempty" validate:"min=0" example:"200"`
+	CostCents int64  `json:"cost_cents,omitempty" validate:"min=0" example:"125"`
+}
+
+type PackTypeResponse struct {
+	ID        int       `json:"id" example:"1"`
+	Code      string    `json:"code" example:"BOX-250"`
+	Label     string    `json:"label" example:"Box of 250"`
+	ItemCount int       `json:"item_count" example:"250"`
+	LengthMM  int       `json:"length_mm" example:"400"`
+	WidthMM   int       `json:"width_mm" example:"300"`
+	HeightMM  int       `json:"height_mm" example:"200"`
+	CostCents int64     `json:"cost_cents" example:"125"`
+	IsActive  bool      `json:"is_active" example:"true"`
+	CreatedAt time.Time `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt time.Time `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+}
+
+type PackTypeListResponse struct {
+	PackTypes []*PackTypeResponse `json:"pack_types"`
+	Count     int                 `json:"count" example:"2"`
+}
+
+// PackTypeReferenceResponse is a pack type as a configuration lists it
+type PackTypeReferenceResponse struct {
+	ID        int    `json:"id" example:"1"`
+	Code      string `json:"code" example:"BOX-250"`
+	Label     string `json:"label" example:"Box of 250"`
+	ItemCount int    `json:"item_count" example:"250"`
+}
+
+func ToPackType(req *PackTypeRequest) entity.PackType {
+	return entity.PackType{
+		Code:      req.Code,
+		Label:     req.Label,
+		ItemCount: req.ItemCount,
+		PackDimensions: entity.PackDimensions{
+			LengthMM: req.LengthMM,
+			WidthMM:  req.WidthMM,
+			HeightMM: req.HeightMM,
+		},
+		CostCents: req.CostCents,
+	}
+}
+
+func ToPackTypeResponse(packType *entity.PackType) *PackTypeResponse {
+	return &PackTypeResponse{
+		ID:        packType.ID,
+		Code:      packType.Code,
+		Label:     packType.Label,
+		ItemCount: packType.ItemCount,
+		LengthMM:  packType.LengthMM,
+		WidthMM:   packType.WidthMM,
+		HeightMM:  packType.HeightMM,
+		CostCents: packType.CostCents,
+		IsActive:  packType.IsActive,
+		CreatedAt: packType.CreatedAt,
+		UpdatedAt: packType.UpdatedAt,
+	}
+}
+
+func ToPackTypeListResponse(packTypes []*entity.PackType) *PackTypeListResponse {
+	responses := make([]*PackTypeResponse, len(packTypes))
+	for i, packType := range packTypes {
+		responses[i] = ToPackTypeResponse(packType)
+	}
+
+	return &PackTypeListResponse{
+		PackTypes: responses,
+		Count:     len(responses),
+	}
+}
+
+// ToPackTypeReferences lists a configuration's pack types, nil when it has none
+func ToPackTypeReferences(packTypes []*entity.PackType) []*PackTypeReferenceResponse {
+	if len(packTypes) == 0 {
+		return nil
+	}
+	references := make([]*PackTypeReferenceResponse, len(packTypes))
+	for i, packType := range packTypes {
+		references[i] = &PackTypeReferenceResponse{
+			ID:        packType.ID,
+			Code:      packType.Code,
+			Label:     packType.Label,
+			ItemCount: packType.ItemCount,
+		}
+	}
+	return references
+}
@@ -0,0 +1,107 @@
+package dto
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+)
+
+type CreateWarehouseRequest struct {
+	Name                string `json:"name" validate:"required,min=1,max=255" example:"Rotterdam DC"`
+	Location            string `json:"location" validate:"required,min=1,max=255" example:"warehouse-a"`
+	PackConfigurationID int    `json:"pack_configuration_id" validate:"required,min=1" example:"1"`
+}
+
+type WarehouseResponse struct {
+	ID                  int       `json:"id" example:"1"`
+	Name                string    `json:"name" example:"Rotterdam DC"`
+	Location            string    `json:"location" example:"warehouse-a"`
+	PackConfigurationID int       `json:"pack_configuration_id" example:"1"`
+	IsActive            bool      `json:"is_active" example:"true"`
+	CreatedAt           time.Time `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt           time.Time `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+}
+
+type WarehouseListResponse struct {
+	Warehouses []*WarehouseResponse `json:"warehouses"`
+	Count      int                  `json:"count" example:"2"`
+}
+
+type SourcingRequest struct {
+	Items        int   `json:"items" validate:"min=0" example:"751"`
+	WarehouseIDs []int `json:"warehouse_ids,omitempty" validate:"omitempty,dive,min=1" swaggertype:"array,integer" example:"1,2"`
+	SplitPenalty *int  `json:"split_penalty,omitempty" validate:"omitempty,min=0" example:"100"`
+}
+
+type WarehouseAllocationResponse struct {
+	WarehouseID int         `json:"warehouse_id" example:"1"`
+	Name        string      `json:"name" example:"Rotterdam DC"`
+	Location    string      `json:"location" example:"warehouse-a"`
+	Allocation  map[int]int `json:"allocation" swaggertype:"object,integer" example:"500:1"`
+	TotalPacks  int         `json:"total_packs" example:"1"`
+	TotalItems  int         `json:"total_items" example:"500"`
+}
+
+type SourcingResponse struct {
+	Strategy   string                         `json:"strategy" example:"split"`
+	Warehouses []*WarehouseAllocationResponse `json:"warehouses"`
+	TotalPacks int                            `json:"total_packs" example:"2"`
+	TotalItems int                            `json:"total_items" example:"750"`
+	Surplus    int                            `json:"surplus" example:"0"`
+}
+
+func ToWarehouseResponse(warehouse *entity.Warehouse) *WarehouseResponse {
+	return &WarehouseResponse{
+		ID:                  warehouse.ID,
+		Name:                warehouse.Name,
+		Location:            warehouse.Location,
+		PackConfigurationID: warehouse.PackConfigurationID,
+		IsActive:            warehouse.IsActive,
+		CreatedAt:           warehouse.CreatedAt,
+		UpdatedAt:           warehouse.UpdatedAt,
+	}
+}
+
+func ToWarehouseListResponse(warehouses []*entity.Warehouse) *WarehouseListResponse {
+	responses := make([]*WarehouseResponse, len(warehouses))
+	for i, warehouse := range warehouses {
+		responses[i] = ToWarehouseResponse(warehouse)
+	}
+
+	return &WarehouseListResponse{
+		Warehouses: responses,
+		Count:      len(responses),
+	}
+}
+
+func ToSourcingResponse(plan *entity.SourcingPlan, warehouses []*entity.Warehouse) *SourcingResponse {
+	byID := make(map[int]*entity.Warehouse, len(warehouses))
+	for _, warehouse := range warehouses {
+		byID[warehouse.ID] = warehouse
+	}
+
+	allocations := make([]*WarehouseAllocationResponse, 0, len(plan.Allocations))
+	for id, allocation := range plan.Allocations {
+		response := &WarehouseAllocationResponse{
+			WarehouseID: id,
+			Allocation:  allocation.GetAllocation(),
+			TotalPacks:  allocation.TotalPacks(),
+			TotalItems:  allocation.TotalItems(),
+		}
+		if warehouse, ok := byID[id]; ok {
+			response.Name = warehouse.Name
+			response.Location = warehouse.Location
+		}
+		allocations = append(allocations, response)
+	}
+	sort.Slice(allocations, func(i, j int) bool { return allocations[i].WarehouseID < allocations[j].WarehouseID })
+
+	return &SourcingResponse{
+		Strategy:   string(plan.Strategy),
+		Warehouses: allocations,
+		TotalPacks: plan.TotalPacks(),
+		TotalItems: plan.TotalItems(),
+		Surplus:    plan.Surplus,
+	}
+}
@@ -0,0 +1,125 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+)
+
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" validate:"required,url,max=2048" example:"https://wms.example.com/hooks/packs"`
+	Secret     string   `json:"secret,omitempty" validate:"omitempty,min=16,max=128" example:"3f2a9c0e5b7d4e1f8a6b2c9d0e1f2a3b"`
+	EventTypes []string `json:"event_types,omitempty" validate:"omitempty,dive,min=1" example:"pack_configuration.default_changed"`
+}
+
+type WebhookResponse struct {
+	ID         int       `json:"id" example:"1"`
+	URL        string    `json:"url" example:"https://wms.example.com/hooks/packs"`
+	EventTypes []string  `json:"event_types" example:"pack_configuration.default_changed"`
+	CreatedAt  time.Time `json:"created_at" example:"2024-01-01T00:00:00Z"`
+}
+
+// CreatedWebhookResponse is the only response that shows the signing secret
+type CreatedWebhookResponse struct {
+	WebhookResponse
+	Secret string `json:"secret" example:"3f2a9c0e5b7d4e1f8a6b2c9d0e1f2a3b"`
+}
+
+type WebhookListResponse struct {
+	Webhooks []*WebhookResponse `json:"webhooks"`
+	Count    int                `json:"count" example:"1"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             int64      `json:"id" example:"481"`
+	WebhookID      int        `json:"webhook_id" example:"1"`
+	EventID        int64      `json:"event_id" example:"1207"`
+	EventType      string     `json:"event_type" example:"pack_configuration.default_changed"`
+	Status         string     `json:"status" example:"dead"`
+	Attempts       int        `json:"attempts" example:"8"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" example:"2024-01-01T00:00:00Z"`
+	LastStatusCode int        `json:"last_status_code,omitempty" example:"503"`
+	LastError      string     `json:"last_error,omitempty" example:"unexpected status 503 Service Unavailable"`
+	CreatedAt      time.Time  `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty" example:"2024-01-01T00:00:00Z"`
+}
+
+type WebhookDeadLetterListResponse struct {
+	Deliveries []*WebhookDeliveryResponse `json:"deliveries"`
+	Count      int                        `json:"count" example:"20"`
+	Total      int                        `json:"total" example:"37"`
+	Limit      int                        `json:"limit" example:"50"`
+	Offset     int                        `json:"offset" example:"0"`
+}
+
+func ToEventTypes(values []string) []entity.EventType {
+	eventTypes := make([]entity.EventType, len(values))
+	for i, value := range values {
+		eventTypes[i] = entity.EventType(value)
+	}
+	return eventTypes
+}
+
+func ToWebhookResponse(webhook *entity.Webhook) *WebhookResponse {
+	eventTypes := make([]string, len(webhook.EventTypes))
+	for i, eventType := range webhook.EventTypes {
+		eventTypes[i] = string(eventType)
+	}
+
+	return &WebhookResponse{
+		ID:         webhook.ID,
+		URL:        webhook.URL,
+		EventTypes: eventTypes,
+		CreatedAt:  webhook.CreatedAt,
+	}
+}
+
+func ToCreatedWebhookResponse(webhook *entity.Webhook) *CreatedWebhookResponse {
+	return &CreatedWebhookResponse{
+		WebhookResponse: *ToWebhookResponse(webhook),
+		Secret:          webhook.Secret,
+	}
+}
+
+func ToWebhookListResponse(webhooks []*entity.Webhook) *WebhookListResponse {
+	responses := make([]*WebhookResponse, len(webhooks))
+	for i, webhook := range webhooks {
+		responses[i] = ToWebhookResponse(webhook)
+	}
+
+	return &WebhookListResponse{
+		Webhooks: responses,
+		Count:    len(responses),
+	}
+}
+
+func ToWebhookDeliveryResponse(delivery *entity.WebhookDelivery) *WebhookDeliveryResponse {
+	return &WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		WebhookID:      delivery.WebhookID,
+		EventID:        delivery.EventID,
+		EventType:      string(delivery.EventType),
+		Status:         string(delivery.Status),
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreatedAt,
+		DeliveredAt:    delivery.DeliveredAt,
+	}
+}
+
+func ToWebhookDeadLetterListResponse(deliveries []*entity.WebhookDelivery, total int, limit int, offset int) *WebhookDeadLetterListResponse {
+	responses := make([]*WebhookDeliveryResponse, len(deliveries))
+	for i, delivery := range deliveries {
+		responses[i] = ToWebhookDeliveryResponse(delivery)
+	}
+
+	return &WebhookDeadLetterListResponse{
+		Deliveries: responses,
+		Count:      len(responses),
+		Total:      total,
+		Limit:      limit,
+		Offset:     offset,
+	}
+}
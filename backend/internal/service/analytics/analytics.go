@@ -0,0 +1,117 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+)
+
+const (
+	// DefaultRange is the window used when no start date is given
+	DefaultRange = 30 * 24 * time.Hour
+	// MaxBuckets bounds the length of a time series
+	MaxBuckets = 1000
+
+	DefaultPackSizeLimit = 20
+	MaxPackSizeLimit     = 100
+)
+
+type AnalyticsService struct {
+	repository entity.AnalyticsRepository
+	now        func() time.Time
+}
+
+func NewAnalyticsService(repository entity.AnalyticsRepository) *AnalyticsService {
+	return &AnalyticsService{
+		repository: repository,
+		now:        time.Now,
+	}
+}
+
+func (s *AnalyticsService) SurplusByBucket(filter entity.AnalyticsFilter) ([]*entity.SurplusPoint, error) {
+	filter, err := s.normalizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	points, err := s.repository.SurplusByBucket(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get surplus series: %w", err)
+	}
+	return points, nil
+}
+
+func (s *AnalyticsService) ExactMatchRates(filter entity.AnalyticsFilter) ([]*entity.ExactMatchRate, error) {
+	filter, err := s.normalizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rates, err := s.repository.ExactMatchRates(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exact match rates: %w", err)
+	}
+	return rates, nil
+}
+
+func (s *AnalyticsService) PackSizeUsage(filter entity.AnalyticsFilter) ([]*entity.PackSizeUsage, error) {
+	filter, err := s.normalizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := s.repository.PackSizeUsage(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pack size usage: %w", err)
+	}
+	return usage, nil
+}
+
+func (s *AnalyticsService) PacksPerOrder(filter entity.AnalyticsFilter) ([]*entity.PacksPerOrderPoint, error) {
+	filter, err := s.normalizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	points, err := s.repository.PacksPerOrder(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get packs per order series: %w", err)
+	}
+	return points, nil
+}
+
+// normalizeFilter fills in the default range, bucket and limit and rejects
+// ranges that would produce an unbounded series
+func (s *AnalyticsService) normalizeFilter(filter entity.AnalyticsFilter) (entity.AnalyticsFilter, error) {
+	if filter.To.IsZero() {
+		filter.To = s.now()
+	}
+	if filter.From.IsZero() {
+		filter.From = filter.To.Add(-DefaultRange)
+	}
+	if !filter.From.Before(filter.To) {
+		return filter, fmt.Errorf("%w: from must be before to", errs.ErrInvalidAnalyticsFilter)
+	}
+
+	bucket, err := entity.ParseTimeBucket(string(filter.Bucket))
+	if err != nil {
+		return filter, fmt.Errorf("%w: %v", errs.ErrInvalidAnalyticsFilter, err)
+	}
+	filter.Bucket = bucket
+
+	if buckets := filter.To.Sub(filter.From) / bucket.Duration(); buckets > MaxBuckets {
+		return filter, fmt.Errorf("%w: range spans %d %s buckets, at most %d allowed",
+			errs.ErrInvalidAnalyticsFilter, buckets, bucket, MaxBuckets)
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultPackSizeLimit
+	}
+	if filter.Limit > MaxPackSizeLimit {
+		filter.Limit = MaxPackSizeLimit
+	}
+
+	return filter, nil
+}
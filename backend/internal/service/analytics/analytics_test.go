@@ -0,0 +1,101 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+)
+
+type fakeAnalyticsRepository struct {
+	filter entity.AnalyticsFilter
+}
+
+func (r *fakeAnalyticsRepository) SurplusByBucket(filter entity.AnalyticsFilter) ([]*entity.SurplusPoint, error) {
+	r.filter = filter
+	return nil, nil
+}
+
+func (r *fakeAnalyticsRepository) ExactMatchRates(filter entity.AnalyticsFilter) ([]*entity.ExactMatchRate, error) {
+	r.filter = filter
+	return nil, nil
+}
+
+func (r *fakeAnalyticsRepository) PackSizeUsage(filter entity.AnalyticsFilter) ([]*entity.PackSizeUsage, error) {
+	r.filter = filter
+	return nil, nil
+}
+
+func (r *fakeAnalyticsRepository) PacksPerOrder(filter entity.AnalyticsFilter) ([]*entity.PacksPerOrderPoint, error) {
+	r.filter = filter
+	return nil, nil
+}
+
+func TestAnalyticsServiceNormalizesFilter(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	from := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		filter      entity.AnalyticsFilter
+		expected    entity.AnalyticsFilter
+		expectError bool
+	}{
+		{
+			name:   "defaults to the last 30 days by day",
+			filter: entity.AnalyticsFilter{},
+			expected: entity.AnalyticsFilter{
+				From:   now.Add(-DefaultRange),
+				To:     now,
+				Bucket: entity.BucketDay,
+				Limit:  DefaultPackSizeLimit,
+			},
+		},
+		{
+			name:   "keeps explicit range and clamps limit",
+			filter: entity.AnalyticsFilter{From: from, To: now, Bucket: entity.BucketWeek, Limit: 1000},
+			expected: entity.AnalyticsFilter{
+				From:   from,
+				To:     now,
+				Bucket: entity.BucketWeek,
+				Limit:  MaxPackSizeLimit,
+			},
+		},
+		{
+			name:        "rejects inverted range",
+			filter:      entity.AnalyticsFilter{From: now, To: from},
+			expectError: true,
+		},
+		{
+			name:        "rejects unknown bucket",
+			filter:      entity.AnalyticsFilter{Bucket: "fortnight"},
+			expectError: true,
+		},
+		{
+			name:        "rejects too many buckets",
+			filter:      entity.AnalyticsFilter{From: now.AddDate(-1, 0, 0), To: now, Bucket: entity.BucketHour},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeAnalyticsRepository{}
+			service := NewAnalyticsService(repo)
+			service.now = func() time.Time { return now }
+
+			_, err := service.SurplusByBucket(tt.filter)
+
+			if tt.expectError {
+				assert.ErrorIs(t, err, errs.ErrInvalidAnalyticsFilter)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, repo.filter)
+		})
+	}
+}
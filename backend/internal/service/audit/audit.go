@@ -0,0 +1,51 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 500
+)
+
+type AuditService struct {
+	repository entity.AuditRepository
+}
+
+func NewAuditService(repository entity.AuditRepository) *AuditService {
+	return &AuditService{
+		repository: repository,
+	}
+}
+
+func (s *AuditService) ListAuditEntries(filter entity.AuditFilter) ([]*entity.AuditEntry, int, error) {
+	filter = NormalizeFilter(filter)
+
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, 0, errs.ErrInvalidTimeRange
+	}
+
+	entries, total, err := s.repository.List(filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	return entries, total, nil
+}
+
+// NormalizeFilter clamps pagination to sane bounds
+func NormalizeFilter(filter entity.AuditFilter) entity.AuditFilter {
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultPageSize
+	}
+	if filter.Limit > MaxPageSize {
+		filter.Limit = MaxPageSize
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	return filter
+}
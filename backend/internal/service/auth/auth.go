@@ -3,6 +3,7 @@ package service
 import (
 	"errors"
 
+	domain "github.com/Schieck/packs-calculator/internal/domain/entity"
 	entity "github.com/Schieck/packs-calculator/internal/domain/entity/auth"
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -23,8 +24,8 @@ func NewAuthService(config entity.AuthConfig, tokenGenerator entity.TokenGenerat
 	}
 }
 
-func NewAuthServiceWithDefaults(jwtSecret, authSecret string) entity.AuthService {
-	config := NewDefaultAuthConfig(authSecret, "packs-calculator")
+func NewAuthServiceWithDefaults(jwtSecret, authSecret string, tenantSecrets map[string]string) entity.AuthService {
+	config := NewDefaultAuthConfig(authSecret, "packs-calculator", tenantSecrets)
 	tokenGenerator := NewJWTTokenGenerator(jwtSecret)
 	tokenValidator := NewJWTTokenValidator(jwtSecret)
 	timeProvider := &DefaultTimeProvider{}
@@ -33,23 +34,48 @@ func NewAuthServiceWithDefaults(jwtSecret, authSecret string) entity.AuthService
 }
 
 func (s authService) Authenticate(req entity.AuthRequest) (*entity.AuthResult, error) {
-	if req.Secret == "" || req.Secret != s.config.GetAuthSecret() {
+	tenant, ok := s.tenantForSecret(req.Secret)
+	if !ok {
 		return nil, errors.New("invalid authentication secret")
 	}
 
-	return s.GenerateToken("authenticated-user")
+	return s.GenerateToken("authenticated-user", tenant)
 }
 
-func (s authService) GenerateToken(subject string) (*entity.AuthResult, error) {
+// tenantForSecret finds the tenant a secret authenticates. AUTH_SECRET
+// belongs to the default tenant.
+func (s authService) tenantForSecret(secret string) (string, bool) {
+	if secret == "" {
+		return "", false
+	}
+	if secret == s.config.GetAuthSecret() {
+		return domain.DefaultTenant, true
+	}
+	for tenant, tenantSecret := range s.config.GetTenantSecrets() {
+		if secret == tenantSecret {
+			return tenant, true
+		}
+	}
+	return "", false
+}
+
+func (s authService) GenerateToken(subject, tenant string) (*entity.AuthResult, error) {
 	if subject == "" {
 		return nil, errors.New("subject cannot be empty")
 	}
+	if tenant == "" {
+		tenant = domain.DefaultTenant
+	}
+	if err := domain.ValidateTenant(tenant); err != nil {
+		return nil, err
+	}
 
 	now := s.timeProvider.Now()
 	expirationTime := now.Add(s.config.GetTokenExpiration())
 
 	claims := &entity.JWTClaims{
 		Subject: subject,
+		Tenant:  tenant,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -65,6 +91,7 @@ func (s authService) GenerateToken(subject string) (*entity.AuthResult, error) {
 
 	return &entity.AuthResult{
 		Token:     tokenString,
+		Tenant:    tenant,
 		ExpiresAt: expirationTime,
 	}, nil
 }
@@ -12,7 +12,7 @@ func TestAuthService_Integration(t *testing.T) {
 	jwtSecret := "test-jwt-secret"
 	authSecret := "test-auth-secret"
 
-	authService := NewAuthServiceWithDefaults(jwtSecret, authSecret)
+	authService := NewAuthServiceWithDefaults(jwtSecret, authSecret, map[string]string{"retail": "retail-secret"})
 
 	t.Run("complete authentication and validation flow", func(t *testing.T) {
 		// Step 1: Authenticate
@@ -30,13 +30,25 @@ func TestAuthService_Integration(t *testing.T) {
 		assert.NoError(t, err)
 		assert.NotNil(t, claims)
 		assert.Equal(t, "authenticated-user", claims.Subject)
+		assert.Equal(t, "default", claims.TenantOrDefault())
 		assert.Equal(t, "packs-calculator", claims.Issuer)
 	})
 
+	t.Run("tenant secret issues a token carrying the tenant claim", func(t *testing.T) {
+		authResp, err := authService.Authenticate(entity.AuthRequest{Secret: "retail-secret"})
+		assert.NoError(t, err)
+		assert.Equal(t, "retail", authResp.Tenant)
+
+		claims, err := authService.ValidateToken(authResp.Token)
+		assert.NoError(t, err)
+		assert.Equal(t, "retail", claims.Tenant)
+		assert.Equal(t, "retail", claims.TenantOrDefault())
+	})
+
 	t.Run("direct token generation and validation", func(t *testing.T) {
 		// Step 1: Generate token directly
 		subject := "direct-user"
-		tokenResp, err := authService.GenerateToken(subject)
+		tokenResp, err := authService.GenerateToken(subject, "")
 
 		assert.NoError(t, err)
 		assert.NotNil(t, tokenResp)
@@ -53,7 +65,7 @@ func TestAuthService_Integration(t *testing.T) {
 
 	t.Run("invalid authentication should not affect valid tokens", func(t *testing.T) {
 		// Generate a valid token first
-		validTokenResp, err := authService.GenerateToken("valid-user")
+		validTokenResp, err := authService.GenerateToken("valid-user", "")
 		assert.NoError(t, err)
 
 		// Try invalid authentication
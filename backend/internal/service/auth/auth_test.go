@@ -43,6 +43,7 @@ func (m *mockTimeProvider) Now() time.Time {
 
 type mockConfig struct {
 	AuthSecret      string
+	TenantSecrets   map[string]string
 	TokenExpiration time.Duration
 	Issuer          string
 }
@@ -51,6 +52,10 @@ func (c *mockConfig) GetAuthSecret() string {
 	return c.AuthSecret
 }
 
+func (c *mockConfig) GetTenantSecrets() map[string]string {
+	return c.TenantSecrets
+}
+
 func (c *mockConfig) GetTokenExpiration() time.Duration {
 	return c.TokenExpiration
 }
@@ -82,6 +87,7 @@ func TestAuthService_Authenticate(t *testing.T) {
 
 		mockTokenGen.On("GenerateToken", mock.MatchedBy(func(claims *entity.JWTClaims) bool {
 			return claims.Subject == "authenticated-user" &&
+				claims.Tenant == "default" &&
 				claims.Issuer == "test-issuer"
 		})).Return(expectedToken, nil).Once()
 
@@ -90,12 +96,38 @@ func TestAuthService_Authenticate(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, result)
 		assert.Equal(t, expectedToken, result.Token)
+		assert.Equal(t, "default", result.Tenant)
 		assert.Equal(t, now.Add(time.Hour), result.ExpiresAt)
 
 		mockTokenGen.AssertExpectations(t)
 		mockTimeProvider.AssertExpectations(t)
 	})
 
+	t.Run("tenant secret authenticates its tenant", func(t *testing.T) {
+		t.Parallel()
+
+		config := &mockConfig{
+			AuthSecret:      "test-auth-secret",
+			TenantSecrets:   map[string]string{"retail": "retail-secret", "wholesale": "wholesale-secret"},
+			TokenExpiration: time.Hour,
+			Issuer:          "test-issuer",
+		}
+		mockTokenGen := new(mockTokenGenerator)
+		mockTimeProvider := new(mockTimeProvider)
+		service := NewAuthService(config, mockTokenGen, nil, mockTimeProvider)
+
+		mockTimeProvider.On("Now").Return(time.Now()).Once()
+		mockTokenGen.On("GenerateToken", mock.MatchedBy(func(claims *entity.JWTClaims) bool {
+			return claims.Tenant == "retail"
+		})).Return("retail-token", nil).Once()
+
+		result, err := service.Authenticate(entity.AuthRequest{Secret: "retail-secret"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "retail", result.Tenant)
+		mockTokenGen.AssertExpectations(t)
+	})
+
 	t.Run("invalid secret should return error", func(t *testing.T) {
 		t.Parallel()
 
@@ -184,10 +216,11 @@ func TestAuthService_GenerateToken(t *testing.T) {
 		mockTimeProvider.On("Now").Return(now).Once()
 		mockTokenGen.On("GenerateToken", mock.MatchedBy(func(claims *entity.JWTClaims) bool {
 			return claims.Subject == subject &&
+				claims.Tenant == "retail" &&
 				claims.Issuer == "test-issuer"
 		})).Return(expectedToken, nil).Once()
 
-		result, err := service.GenerateToken(subject)
+		result, err := service.GenerateToken(subject, "retail")
 
 		require.NoError(t, err)
 		require.NotNil(t, result)
@@ -207,13 +240,29 @@ func TestAuthService_GenerateToken(t *testing.T) {
 		}
 		service := NewAuthService(config, nil, nil, nil)
 
-		result, err := service.GenerateToken("")
+		result, err := service.GenerateToken("", "")
 
 		require.Error(t, err)
 		require.Nil(t, result)
 		assert.Contains(t, err.Error(), "subject cannot be empty")
 	})
 
+	t.Run("invalid tenant should return error", func(t *testing.T) {
+		t.Parallel()
+
+		config := &mockConfig{
+			TokenExpiration: 2 * time.Hour,
+			Issuer:          "test-issuer",
+		}
+		service := NewAuthService(config, nil, nil, nil)
+
+		result, err := service.GenerateToken("test-user", "Not A Tenant")
+
+		require.Error(t, err)
+		require.Nil(t, result)
+		assert.Contains(t, err.Error(), "invalid tenant")
+	})
+
 	t.Run("token generation failure should return error", func(t *testing.T) {
 		t.Parallel()
 
@@ -231,7 +280,7 @@ func TestAuthService_GenerateToken(t *testing.T) {
 		mockTimeProvider.On("Now").Return(now).Once()
 		mockTokenGen.On("GenerateToken", mock.Anything).Return("", assert.AnError).Once()
 
-		result, err := service.GenerateToken(subject)
+		result, err := service.GenerateToken(subject, "")
 
 		require.Error(t, err)
 		require.Nil(t, result)
@@ -1,8 +1,10 @@
 package service
 
 import (
+	"fmt"
 	"time"
 
+	domain "github.com/Schieck/packs-calculator/internal/domain/entity"
 	entity "github.com/Schieck/packs-calculator/internal/domain/entity/auth"
 )
 
@@ -14,13 +16,15 @@ func (p DefaultTimeProvider) Now() time.Time {
 
 type DefaultAuthConfig struct {
 	authSecret      string
+	tenantSecrets   map[string]string
 	tokenExpiration time.Duration
 	issuer          string
 }
 
-func NewDefaultAuthConfig(authSecret string, issuer string) entity.AuthConfig {
+func NewDefaultAuthConfig(authSecret string, issuer string, tenantSecrets map[string]string) entity.AuthConfig {
 	return &DefaultAuthConfig{
 		authSecret:      authSecret,
+		tenantSecrets:   tenantSecrets,
 		tokenExpiration: 24 * time.Hour, // Default set to 24 hours
 		issuer:          issuer,
 	}
@@ -30,6 +34,10 @@ func (c DefaultAuthConfig) GetAuthSecret() string {
 	return c.authSecret
 }
 
+func (c DefaultAuthConfig) GetTenantSecrets() map[string]string {
+	return c.tenantSecrets
+}
+
 func (c DefaultAuthConfig) GetTokenExpiration() time.Duration {
 	return c.tokenExpiration
 }
@@ -37,3 +45,26 @@ func (c DefaultAuthConfig) GetTokenExpiration() time.Duration {
 func (c DefaultAuthConfig) GetIssuer() string {
 	return c.issuer
 }
+
+// ValidateTenantSecrets checks that every tenant name is valid and that no
+// two tenants, including the default one behind authSecret, share a secret,
+// so a secret always identifies exactly one tenant
+func ValidateTenantSecrets(authSecret string, tenantSecrets map[string]string) error {
+	owners := map[string]string{authSecret: domain.DefaultTenant}
+	for tenant, secret := range tenantSecrets {
+		if err := domain.ValidateTenant(tenant); err != nil {
+			return err
+		}
+		if tenant == domain.DefaultTenant {
+			return fmt.Errorf("tenant %q authenticates with AUTH_SECRET", tenant)
+		}
+		if secret == "" {
+			return fmt.Errorf("tenant %q has an empty secret", tenant)
+		}
+		if owner, ok := owners[secret]; ok {
+			return fmt.Errorf("tenants %q and %q share a secret", owner, tenant)
+		}
+		owners[secret] = tenant
+	}
+	return nil
+}
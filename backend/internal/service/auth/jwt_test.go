@@ -207,14 +207,47 @@ func TestJWTTokenValidator_ValidateToken(t *testing.T) {
 func TestDefaultAuthConfig(t *testing.T) {
 	t.Parallel()
 
-	config := NewDefaultAuthConfig("test-secret", "test-issuer")
+	config := NewDefaultAuthConfig("test-secret", "test-issuer", map[string]string{"retail": "retail-secret"})
 
 	assert.NotNil(t, config)
 	assert.NotEmpty(t, config.GetAuthSecret())
+	assert.Equal(t, "retail-secret", config.GetTenantSecrets()["retail"])
 	assert.Positive(t, config.GetTokenExpiration())
 	assert.NotEmpty(t, config.GetIssuer())
 }
 
+func TestValidateTenantSecrets(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		secrets map[string]string
+		wantErr string
+	}{
+		{"no tenants", nil, ""},
+		{"distinct secrets", map[string]string{"retail": "a", "wholesale": "b"}, ""},
+		{"invalid tenant name", map[string]string{"Retail Unit": "a"}, "invalid tenant"},
+		{"default tenant", map[string]string{"default": "a"}, "AUTH_SECRET"},
+		{"empty secret", map[string]string{"retail": ""}, "empty secret"},
+		{"secret shared with default", map[string]string{"retail": "auth-secret"}, "share a secret"},
+		{"secret shared between tenants", map[string]string{"retail": "a", "wholesale": "a"}, "share a secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidateTenantSecrets("auth-secret", tt.secrets)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
 func TestDefaultTimeProvider(t *testing.T) {
 	t.Parallel()
 
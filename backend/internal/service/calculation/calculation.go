@@ -0,0 +1,89 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 500
+)
+
+type CalculationService struct {
+	repository entity.CalculationRepository
+}
+
+func NewCalculationService(repository entity.CalculationRepository) *CalculationService {
+	return &CalculationService{
+		repository: repository,
+	}
+}
+
+func (s *CalculationService) RecordCalculation(record *entity.CalculationRecord) (*entity.CalculationRecord, error) {
+	if err := record.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid calculation record: %w", err)
+	}
+
+	saved, err := s.repository.Create(record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save calculation: %w", err)
+	}
+	return saved, nil
+}
+
+func (s *CalculationService) GetCalculation(tenant string, id int64) (*entity.CalculationRecord, error) {
+	if id <= 0 {
+		return nil, errs.ErrInvalidID.Withf("invalid calculation ID: %d", id)
+	}
+
+	record, err := s.repository.GetByID(tenant, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get calculation: %w", err)
+	}
+	return record, nil
+}
+
+func (s *CalculationService) ListCalculations(filter entity.CalculationFilter) ([]*entity.CalculationRecord, int, error) {
+	filter = NormalizeFilter(filter)
+
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, 0, errs.ErrInvalidTimeRange
+	}
+
+	records, total, err := s.repository.List(filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list calculations: %w", err)
+	}
+	return records, total, nil
+}
+
+// PruneCalculations deletes records older than the retention period
+func (s *CalculationService) PruneCalculations(retention time.Duration) (int64, error) {
+	if retention <= 0 {
+		return 0, fmt.Errorf("retention must be positive, got %s", retention)
+	}
+
+	deleted, err := s.repository.DeleteOlderThan(time.Now().Add(-retention))
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune calculations: %w", err)
+	}
+	return deleted, nil
+}
+
+// NormalizeFilter clamps pagination to sane bounds
+func NormalizeFilter(filter entity.CalculationFilter) entity.CalculationFilter {
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultPageSize
+	}
+	if filter.Limit > MaxPageSize {
+		filter.Limit = MaxPageSize
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	return filter
+}
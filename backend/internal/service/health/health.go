@@ -4,15 +4,21 @@ import (
 	"time"
 
 	"github.com/Schieck/packs-calculator/internal/domain/entity"
-	"github.com/Schieck/packs-calculator/pkg/db"
 )
 
+// Pinger checks that a storage backend is reachable
+type Pinger interface {
+	Ping() error
+}
+
 type HealthService struct {
-	database *db.DB
+	database Pinger
 	version  string
 }
 
-func NewHealthService(database *db.DB, version string) *HealthService {
+// NewHealthService reports on database, which is nil when the server keeps
+// its data in memory and has nothing to ping
+func NewHealthService(database Pinger, version string) *HealthService {
 	return &HealthService{
 		database: database,
 		version:  version,
@@ -20,9 +26,10 @@ func NewHealthService(database *db.DB, version string) *HealthService {
 }
 
 func (s *HealthService) CheckHealth() (*entity.HealthResponse, error) {
-	err := s.database.Ping()
-	if err != nil {
-		return nil, err
+	if s.database != nil {
+		if err := s.database.Ping(); err != nil {
+			return nil, err
+		}
 	}
 
 	return &entity.HealthResponse{
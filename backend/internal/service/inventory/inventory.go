@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+)
+
+// maxReserveAttempts bounds how often a reservation is re-planned when a
+// concurrent reservation consumed the stock between planning and commit
+const maxReserveAttempts = 3
+
+type InventoryService struct {
+	repository    entity.InventoryRepository
+	calculator    entity.StockAwarePackCalculator
+	packProcessor entity.PackSizeProcessor
+}
+
+func NewInventoryService(
+	repository entity.InventoryRepository,
+	calculator entity.StockAwarePackCalculator,
+	packProcessor entity.PackSizeProcessor,
+) *InventoryService {
+	return &InventoryService{
+		repository:    repository,
+		calculator:    calculator,
+		packProcessor: packProcessor,
+	}
+}
+
+func (s *InventoryService) GetStock(ctx context.Context, tenant string, location string) ([]*entity.StockLevel, error) {
+	if location == "" {
+		return nil, errs.ErrInvalidStockLevel.Withf("stock location cannot be empty")
+	}
+
+	levels, err := s.repository.GetStock(ctx, tenant, location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock levels: %w", err)
+	}
+	return levels, nil
+}
+
+func (s *InventoryService) SetStock(ctx context.Context, tenant string, location string, packSize int, onHand int) (*entity.StockLevel, error) {
+	stock, err := entity.NewStockLevel(tenant, location, packSize, onHand)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stock level entity: %w", err)
+	}
+
+	saved, err := s.repository.SetStock(ctx, stock)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save stock level: %w", err)
+	}
+	return saved, nil
+}
+
+// CalculateWithStock plans an order using only packs available at the
+// tenant's location. When packSizes is empty every size stocked there is
+// considered.
+func (s *InventoryService) CalculateWithStock(ctx context.Context, tenant string, location string, packSizes []int, orderQuantity int) (*entity.CalculationResult, error) {
+	levels, err := s.GetStock(ctx, tenant, location)
+	if err != nil {
+		return nil, err
+	}
+
+	stock := entity.AvailableStock(levels)
+
+	if len(packSizes) == 0 {
+		packSizes = make([]int, 0, len(stock))
+		for size := range stock {
+			packSizes = append(packSizes, size)
+		}
+	}
+
+	packSizesEntity, err := s.packProcessor.ProcessPackSizes(packSizes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to process pack sizes: %w", err)
+	}
+
+	orderQuantityEntity, err := entity.NewOrderQuantity(orderQuantity)
+	if err != nil {
+		return nil, err
+	}
+
+	result := s.calculator.CalculateOptimalPacksWithStock(packSizesEntity, stock, orderQuantityEntity)
+	if result.IsUnfulfillable() {
+		return nil, fmt.Errorf("%d items at %s: %w", orderQuantity, location, errs.ErrUnfulfillableOrder)
+	}
+
+	return result, nil
+}
+
+// ReserveOrder plans the order against current stock and holds the planned packs.
+// If another reservation takes the stock first, the order is re-planned.
+func (s *InventoryService) ReserveOrder(ctx context.Context, tenant string, location string, packSizes []int, orderQuantity int) (*entity.Reservation, error) {
+	var lastErr error
+
+	for attempt := 0; attempt < maxReserveAttempts; attempt++ {
+		result, err := s.CalculateWithStock(ctx, tenant, location, packSizes, orderQuantity)
+		if err != nil {
+			return nil, err
+		}
+
+		reservation, err := entity.NewReservation(tenant, location, orderQuantity, result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create reservation entity: %w", err)
+		}
+
+		saved, err := s.repository.Reserve(ctx, reservation)
+		if err == nil {
+			return saved, nil
+		}
+		if !errors.Is(err, errs.ErrInsufficientStock) {
+			return nil, fmt.Errorf("failed to save reservation: %w", err)
+		}
+		lastErr = err
+	}
+
+	return nil, fmt.Errorf("failed to reserve stock after %d attempts: %w", maxReserveAttempts, lastErr)
+}
+
+func (s *InventoryService) GetReservation(ctx context.Context, tenant string, id int) (*entity.Reservation, error) {
+	if id <= 0 {
+		return nil, errs.ErrInvalidID.Withf("invalid reservation ID: %d", id)
+	}
+
+	reservation, err := s.repository.GetReservation(ctx, tenant, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reservation: %w", err)
+	}
+	return reservation, nil
+}
+
+func (s *InventoryService) CancelReservation(ctx context.Context, tenant string, id int) (*entity.Reservation, error) {
+	if id <= 0 {
+		return nil, errs.ErrInvalidID.Withf("invalid reservation ID: %d", id)
+	}
+
+	reservation, err := s.repository.Release(ctx, tenant, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel reservation: %w", err)
+	}
+	return reservation, nil
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+	packCalculatorService "github.com/Schieck/packs-calculator/internal/service/pack_calculator"
+)
+
+type fakeInventoryRepository struct {
+	stock        []*entity.StockLevel
+	reserveErrs  []error
+	reserveCalls int
+	reserved     *entity.Reservation
+}
+
+func (r *fakeInventoryRepository) GetStock(ctx context.Context, tenant string, location string) ([]*entity.StockLevel, error) {
+	var levels []*entity.StockLevel
+	for _, level := range r.stock {
+		if level.Tenant == tenant && level.Location == location {
+			levels = append(levels, level)
+		}
+	}
+	return levels, nil
+}
+
+func (r *fakeInventoryRepository) SetStock(ctx context.Context, stock *entity.StockLevel) (*entity.StockLevel, error) {
+	return stock, nil
+}
+
+func (r *fakeInventoryRepository) Reserve(ctx context.Context, reservation *entity.Reservation) (*entity.Reservation, error) {
+	r.reserveCalls++
+	if len(r.reserveErrs) > 0 {
+		err := r.reserveErrs[0]
+		r.reserveErrs = r.reserveErrs[1:]
+		return nil, err
+	}
+	reservation.ID = 1
+	r.reserved = reservation
+	return reservation, nil
+}
+
+func (r *fakeInventoryRepository) GetReservation(ctx context.Context, tenant string, id int) (*entity.Reservation, error) {
+	return nil, errs.ErrReservationNotFound
+}
+
+func (r *fakeInventoryRepository) Release(ctx context.Context, tenant string, id int) (*entity.Reservation, error) {
+	return nil, errs.ErrReservationNotFound
+}
+
+func newTestInventoryService(repo *fakeInventoryRepository) *InventoryService {
+	return NewInventoryService(
+		repo,
+		packCalculatorService.NewStockAwarePackCalculatorService(),
+		packCalculatorService.NewPackSizeProcessorService(),
+	)
+}
+
+func TestInventoryService_CalculateWithStock(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeInventoryRepository{stock: []*entity.StockLevel{
+		{Tenant: "retail", Location: "a", PackSize: 250, OnHand: 10},
+		{Tenant: "retail", Location: "a", PackSize: 500, OnHand: 3, Reserved: 3},
+		{Tenant: "retail", Location: "a", PackSize: 1000, OnHand: 5},
+		{Tenant: "acme", Location: "a", PackSize: 100, OnHand: 50},
+	}}
+	svc := newTestInventoryService(repo)
+
+	t.Run("skips fully reserved sizes", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := svc.CalculateWithStock(t.Context(), "retail", "a", nil, 251)
+		require.NoError(t, err)
+		assert.Equal(t, map[int]int{250: 2}, result.Allocation.GetAllocation())
+		assert.Equal(t, 249, result.Surplus)
+	})
+
+	t.Run("fails when stock cannot cover the order", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := svc.CalculateWithStock(t.Context(), "retail", "a", []int{250}, 2501)
+		assert.ErrorIs(t, err, errs.ErrUnfulfillableOrder)
+	})
+
+	t.Run("only uses the tenant's stock", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := svc.CalculateWithStock(t.Context(), "acme", "a", nil, 251)
+		require.NoError(t, err)
+		assert.Equal(t, map[int]int{100: 3}, result.Allocation.GetAllocation())
+
+		_, err = svc.CalculateWithStock(t.Context(), "wholesale", "a", []int{250}, 1)
+		assert.ErrorIs(t, err, errs.ErrUnfulfillableOrder)
+	})
+}
+
+func TestInventoryService_ReserveOrder(t *testing.T) {
+	t.Parallel()
+
+	stock := []*entity.StockLevel{{Tenant: "retail", Location: "a", PackSize: 250, OnHand: 10}}
+
+	t.Run("retries when stock is taken concurrently", func(t *testing.T) {
+		t.Parallel()
+
+		repo := &fakeInventoryRepository{stock: stock, reserveErrs: []error{errs.ErrInsufficientStock}}
+		reservation, err := newTestInventoryService(repo).ReserveOrder(t.Context(), "retail", "a", nil, 500)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, repo.reserveCalls)
+		assert.Equal(t, "retail", reservation.Tenant)
+		assert.Equal(t, map[int]int{250: 2}, reservation.Allocation)
+		assert.True(t, reservation.IsActive())
+	})
+
+	t.Run("gives up after repeated conflicts", func(t *testing.T) {
+		t.Parallel()
+
+		repo := &fakeInventoryRepository{stock: stock, reserveErrs: []error{
+			errs.ErrInsufficientStock, errs.ErrInsufficientStock, errs.ErrInsufficientStock,
+		}}
+		_, err := newTestInventoryService(repo).ReserveOrder(t.Context(), "retail", "a", nil, 500)
+
+		assert.ErrorIs(t, err, errs.ErrInsufficientStock)
+		assert.Equal(t, maxReserveAttempts, repo.reserveCalls)
+	})
+}
@@ -0,0 +1,135 @@
+package service
+
+import "sort"
+
+// stockChunk is a group of identical packs that the bounded solver either
+// takes as a whole or not at all. Splitting each stock level into
+// power-of-two chunks (1, 2, 4, ..., remainder) lets a 0/1 knapsack express
+// every count between zero and the available stock.
+type stockChunk struct {
+	size   int
+	count  int
+	source int
+}
+
+func (c stockChunk) items() int { return c.size * c.count }
+
+// splitStock converts per-size stock limits into power-of-two chunks.
+// Sizes without positive stock are skipped.
+func splitStock(packSizes []int, limits map[int]int, source int) []stockChunk {
+	chunks := make([]stockChunk, 0, len(packSizes)*4)
+	for _, size := range packSizes {
+		remaining := limits[size]
+		if size <= 0 || remaining <= 0 {
+			continue
+		}
+		for step := 1; remaining > 0; step <<= 1 {
+			count := step
+			if count > remaining {
+				count = remaining
+			}
+			chunks = append(chunks, stockChunk{size: size, count: count, source: source})
+			remaining -= count
+		}
+	}
+	return chunks
+}
+
+// CalculateBounded solves the same problem as Calculate, but never uses more
+// packs of a size than limits allows. Sizes missing from limits are treated
+// as out of stock.
+//
+// When the available stock cannot cover the order it returns an empty
+// allocation and the order quantity as surplus, the same shape Calculate
+// uses for an infeasible order.
+func CalculateBounded(packSizes []int, limits map[int]int, orderQty int) (map[int]int, int) {
+	if orderQty <= 0 {
+		return map[int]int{}, 0
+	}
+
+	chosen, surplus, ok := solveBounded(splitStock(packSizes, limits, 0), orderQty)
+	if !ok {
+		return map[int]int{}, orderQty
+	}
+
+	alloc := make(map[int]int, len(chosen))
+	for _, chunk := range chosen {
+		alloc[chunk.size] += chunk.count
+	}
+	return alloc, surplus
+}
+
+// solveBounded runs a 0/1 knapsack over the given chunks and applies the
+// same R2/R3 ordering as the unbounded solver: least surplus first, then
+// fewest packs.
+func solveBounded(chunks []stockChunk, orderQty int) ([]stockChunk, int, bool) {
+	if len(chunks) == 0 {
+		return nil, orderQty, false
+	}
+
+	// Stable order keeps reconstruction deterministic between runs
+	sort.SliceStable(chunks, func(i, j int) bool {
+		if chunks[i].size != chunks[j].size {
+			return chunks[i].size < chunks[j].size
+		}
+		return chunks[i].source < chunks[j].source
+	})
+
+	totalItems, maxPack := 0, 0
+	for _, chunk := range chunks {
+		totalItems += chunk.items()
+		if chunk.size > maxPack {
+			maxPack = chunk.size
+		}
+	}
+	if totalItems < orderQty {
+		return nil, orderQty, false
+	}
+
+	// Any plan reaching orderQty+maxPack can drop a pack and still cover the order
+	upper := orderQty + maxPack
+	if upper > totalItems {
+		upper = totalItems
+	}
+
+	dp, last := GetDPArraysFromPool(upper)
+	defer ReturnDPArraysToPool(CreateDPArrays(dp, last))
+
+	taken := make([]bitset, len(chunks))
+	for i, chunk := range chunks {
+		weight := chunk.items()
+		taken[i] = newBitset(upper + 1)
+		for q := upper; q >= weight; q-- {
+			if dp[q-weight] != maxInt && dp[q-weight]+chunk.count < dp[q] {
+				dp[q] = dp[q-weight] + chunk.count
+				taken[i].set(q)
+			}
+		}
+	}
+
+	bestQty := findOptimalQuantity(dp, orderQty, upper)
+	if bestQty == -1 {
+		return nil, orderQty, false
+	}
+
+	// Walking chunks backwards finds the last chunk that improved each quantity
+	chosen := make([]stockChunk, 0, len(chunks))
+	for i, q := len(chunks)-1, bestQty; i >= 0 && q > 0; i-- {
+		if taken[i].has(q) {
+			chosen = append(chosen, chunks[i])
+			q -= chunks[i].items()
+		}
+	}
+
+	return chosen, bestQty - orderQty, true
+}
+
+// bitset keeps the per-chunk decision table at one bit per quantity, which
+// matters for large orders with many chunks.
+type bitset []uint64
+
+func newBitset(n int) bitset { return make(bitset, (n+63)/64) }
+
+func (b bitset) set(i int) { b[i/64] |= 1 << (uint(i) % 64) }
+
+func (b bitset) has(i int) bool { return b[i/64]&(1<<(uint(i)%64)) != 0 }
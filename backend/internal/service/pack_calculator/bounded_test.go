@@ -0,0 +1,107 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculateBounded(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name               string
+		packSizes          []int
+		limits             map[int]int
+		orderQty           int
+		expectedAllocation map[int]int
+		expectedSurplus    int
+	}{
+		{
+			name:               "plenty of stock matches unbounded result",
+			packSizes:          []int{250, 500, 1000, 2000, 5000},
+			limits:             map[int]int{250: 100, 500: 100, 1000: 100, 2000: 100, 5000: 100},
+			orderQty:           12001,
+			expectedAllocation: map[int]int{5000: 2, 2000: 1, 250: 1},
+			expectedSurplus:    249,
+		},
+		{
+			name:               "out of stock size is never used",
+			packSizes:          []int{250, 500, 1000},
+			limits:             map[int]int{250: 10, 1000: 10},
+			orderQty:           251,
+			expectedAllocation: map[int]int{250: 2},
+			expectedSurplus:    249,
+		},
+		{
+			name:               "limited large packs fall back to smaller ones",
+			packSizes:          []int{250, 500, 1000},
+			limits:             map[int]int{250: 10, 500: 10, 1000: 1},
+			orderQty:           2000,
+			expectedAllocation: map[int]int{1000: 1, 500: 2},
+			expectedSurplus:    0,
+		},
+		{
+			name:               "edge case pack sizes with stock caps",
+			packSizes:          []int{23, 31, 53},
+			limits:             map[int]int{23: 2, 31: 7, 53: 7},
+			orderQty:           263,
+			expectedAllocation: map[int]int{23: 2, 31: 7},
+			expectedSurplus:    0,
+		},
+		{
+			name:               "insufficient stock returns empty allocation",
+			packSizes:          []int{250, 500},
+			limits:             map[int]int{250: 1, 500: 1},
+			orderQty:           1000,
+			expectedAllocation: map[int]int{},
+			expectedSurplus:    1000,
+		},
+		{
+			name:               "no stock at all",
+			packSizes:          []int{250, 500},
+			limits:             map[int]int{},
+			orderQty:           10,
+			expectedAllocation: map[int]int{},
+			expectedSurplus:    10,
+		},
+		{
+			name:               "zero order quantity",
+			packSizes:          []int{250},
+			limits:             map[int]int{250: 1},
+			orderQty:           0,
+			expectedAllocation: map[int]int{},
+			expectedSurplus:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			allocation, surplus := CalculateBounded(tt.packSizes, tt.limits, tt.orderQty)
+
+			assert.Equal(t, tt.expectedAllocation, allocation)
+			assert.Equal(t, tt.expectedSurplus, surplus)
+
+			for size, count := range allocation {
+				assert.LessOrEqual(t, count, tt.limits[size], "pack %d exceeds stock", size)
+			}
+		})
+	}
+}
+
+func TestSplitStock(t *testing.T) {
+	t.Parallel()
+
+	chunks := splitStock([]int{10, 20, 30}, map[int]int{10: 6, 20: 0, 30: 1}, 0)
+
+	counts := map[int][]int{}
+	for _, chunk := range chunks {
+		counts[chunk.size] = append(counts[chunk.size], chunk.count)
+	}
+
+	assert.Equal(t, []int{1, 2, 3}, counts[10])
+	assert.Empty(t, counts[20])
+	assert.Equal(t, []int{1}, counts[30])
+}
@@ -1,9 +1,8 @@
 package service
 
 import (
-	"fmt"
-
 	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
 )
 
 type PackSizeProcessorService struct{}
@@ -38,7 +37,7 @@ func (s *PackSizeProcessorService) ProcessPackSizes(rawSizes []int) (*entity.Pac
 
 	for _, size := range rawSizes {
 		if size <= 0 {
-			return nil, fmt.Errorf("pack size must be >0, got %d", size)
+			return nil, errs.ErrInvalidOrder.Withf("pack size must be >0, got %d", size)
 		}
 	}
 
@@ -80,3 +79,29 @@ func (s *PackCalculatorService) CalculateOptimalPacks(
 func CalculateOptimalPacks(packSizes []int, orderQty int) (map[int]int, int) {
 	return Calculate(DedupeAndSort(packSizes), orderQty)
 }
+
+func NewStockAwarePackCalculatorService() entity.StockAwarePackCalculator {
+	return &PackCalculatorService{}
+}
+
+// CalculateOptimalPacksWithStock applies the same business rules as
+// CalculateOptimalPacks while capping each pack size at its available stock.
+// An unfulfillable order yields an empty allocation with the full quantity as surplus.
+func (s *PackCalculatorService) CalculateOptimalPacksWithStock(
+	packSizes *entity.PackSizes,
+	stock map[int]int,
+	orderQuantity *entity.OrderQuantity,
+) *entity.CalculationResult {
+	if orderQuantity.IsZero() || packSizes.IsEmpty() {
+		return entity.NewCalculationResult(entity.NewPackAllocation(), orderQuantity.Quantity)
+	}
+
+	allocationMap, surplus := CalculateBounded(packSizes.Slice(), stock, orderQuantity.Quantity)
+
+	alloc := entity.NewPackAllocation()
+	for sz, qty := range allocationMap {
+		alloc.AddPack(sz, qty)
+	}
+
+	return entity.NewCalculationResult(alloc, surplus)
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+)
+
+// SourcingSolverService extends the bounded solver to several stock sources.
+//
+// Every source is first solved on its own; the best of those is the
+// single-source plan. All sources are then pooled into one bounded knapsack,
+// which yields the best plan when packs may come from anywhere. That pooled
+// plan is only used when it actually spans several sources and still wins
+// after the split penalty is added to its surplus.
+type SourcingSolverService struct{}
+
+func NewSourcingSolverService() entity.SourcingSolver {
+	return &SourcingSolverService{}
+}
+
+func (s *SourcingSolverService) PlanSourcing(
+	candidates []*entity.SourcingCandidate,
+	orderQuantity *entity.OrderQuantity,
+	splitPenalty int,
+) *entity.SourcingPlan {
+	if orderQuantity.IsZero() {
+		return &entity.SourcingPlan{Strategy: entity.SourcingStrategySingle, Allocations: map[int]*entity.PackAllocation{}}
+	}
+
+	qty := orderQuantity.Quantity
+
+	var single *sourcingResult
+	pooled := make([]stockChunk, 0, len(candidates)*8)
+	for i, candidate := range candidates {
+		chunks := splitStock(candidate.PackSizes.Slice(), candidate.Stock, i)
+		pooled = append(pooled, chunks...)
+
+		// solveBounded reorders its input, so solve a copy
+		own := append([]stockChunk(nil), chunks...)
+		if chosen, surplus, ok := solveBounded(own, qty); ok {
+			result := newSourcingResult(chosen, surplus)
+			if single == nil || result.beats(single) {
+				single = result
+			}
+		}
+	}
+
+	var split *sourcingResult
+	if chosen, surplus, ok := solveBounded(pooled, qty); ok {
+		if result := newSourcingResult(chosen, surplus); len(result.allocation) > 1 {
+			split = result
+		}
+	}
+
+	switch {
+	case split != nil && (single == nil || split.beatsWithPenalty(single, splitPenalty)):
+		return split.toPlan(candidates, entity.SourcingStrategySplit)
+	case single != nil:
+		return single.toPlan(candidates, entity.SourcingStrategySingle)
+	default:
+		return &entity.SourcingPlan{Strategy: entity.SourcingStrategySingle, Allocations: map[int]*entity.PackAllocation{}, Surplus: qty}
+	}
+}
+
+type sourcingResult struct {
+	allocation map[int]map[int]int
+	surplus    int
+	packs      int
+}
+
+func newSourcingResult(chosen []stockChunk, surplus int) *sourcingResult {
+	result := &sourcingResult{allocation: make(map[int]map[int]int), surplus: surplus}
+	for _, chunk := range chosen {
+		if result.allocation[chunk.source] == nil {
+			result.allocation[chunk.source] = make(map[int]int)
+		}
+		result.allocation[chunk.source][chunk.size] += chunk.count
+		result.packs += chunk.count
+	}
+	return result
+}
+
+// beats applies R2 then R3: less surplus, then fewer packs
+func (r *sourcingResult) beats(other *sourcingResult) bool {
+	return r.surplus < other.surplus || (r.surplus == other.surplus && r.packs < other.packs)
+}
+
+func (r *sourcingResult) beatsWithPenalty(single *sourcingResult, splitPenalty int) bool {
+	penalised := r.surplus + splitPenalty*(len(r.allocation)-1)
+	return penalised < single.surplus || (penalised == single.surplus && r.packs < single.packs)
+}
+
+func (r *sourcingResult) toPlan(candidates []*entity.SourcingCandidate, strategy entity.SourcingStrategy) *entity.SourcingPlan {
+	allocations := make(map[int]*entity.PackAllocation, len(r.allocation))
+	for index, sizes := range r.allocation {
+		alloc := entity.NewPackAllocation()
+		for size, count := range sizes {
+			alloc.AddPack(size, count)
+		}
+		allocations[candidates[index].SourceID] = alloc
+	}
+
+	return &entity.SourcingPlan{
+		Strategy:    strategy,
+		Allocations: allocations,
+		Surplus:     r.surplus,
+	}
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+)
+
+func sourcingCandidate(id int, stock map[int]int) *entity.SourcingCandidate {
+	sizes := make([]int, 0, len(stock))
+	for size := range stock {
+		sizes = append(sizes, size)
+	}
+	return &entity.SourcingCandidate{
+		SourceID:  id,
+		PackSizes: entity.NewPackSizes(DedupeAndSort(sizes)),
+		Stock:     stock,
+	}
+}
+
+func TestSourcingSolver_PlanSourcing(t *testing.T) {
+	t.Parallel()
+
+	solver := NewSourcingSolverService()
+
+	tests := []struct {
+		name             string
+		candidates       []*entity.SourcingCandidate
+		orderQty         int
+		splitPenalty     int
+		expectedStrategy entity.SourcingStrategy
+		expectedAlloc    map[int]map[int]int
+		expectedSurplus  int
+	}{
+		{
+			name: "single source when one warehouse is optimal",
+			candidates: []*entity.SourcingCandidate{
+				sourcingCandidate(1, map[int]int{250: 10}),
+				sourcingCandidate(2, map[int]int{500: 10}),
+			},
+			orderQty:         500,
+			expectedStrategy: entity.SourcingStrategySingle,
+			expectedAlloc:    map[int]map[int]int{2: {500: 1}},
+			expectedSurplus:  0,
+		},
+		{
+			name: "split when it removes surplus",
+			candidates: []*entity.SourcingCandidate{
+				sourcingCandidate(1, map[int]int{500: 1}),
+				sourcingCandidate(2, map[int]int{250: 1, 1000: 1}),
+			},
+			orderQty:         750,
+			expectedStrategy: entity.SourcingStrategySplit,
+			expectedAlloc:    map[int]map[int]int{1: {500: 1}, 2: {250: 1}},
+			expectedSurplus:  0,
+		},
+		{
+			name: "penalty keeps the order at one warehouse",
+			candidates: []*entity.SourcingCandidate{
+				sourcingCandidate(1, map[int]int{500: 1}),
+				sourcingCandidate(2, map[int]int{250: 1, 1000: 1}),
+			},
+			orderQty:         750,
+			splitPenalty:     250,
+			expectedStrategy: entity.SourcingStrategySingle,
+			expectedAlloc:    map[int]map[int]int{2: {1000: 1}},
+			expectedSurplus:  250,
+		},
+		{
+			name: "split when no warehouse can fulfil alone",
+			candidates: []*entity.SourcingCandidate{
+				sourcingCandidate(1, map[int]int{1000: 1}),
+				sourcingCandidate(2, map[int]int{1000: 1}),
+			},
+			orderQty:         2000,
+			splitPenalty:     10000,
+			expectedStrategy: entity.SourcingStrategySplit,
+			expectedAlloc:    map[int]map[int]int{1: {1000: 1}, 2: {1000: 1}},
+			expectedSurplus:  0,
+		},
+		{
+			name: "unfulfillable across all warehouses",
+			candidates: []*entity.SourcingCandidate{
+				sourcingCandidate(1, map[int]int{250: 1}),
+				sourcingCandidate(2, map[int]int{250: 1}),
+			},
+			orderQty:         1000,
+			expectedStrategy: entity.SourcingStrategySingle,
+			expectedAlloc:    map[int]map[int]int{},
+			expectedSurplus:  1000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			orderQty, err := entity.NewOrderQuantity(tt.orderQty)
+			require.NoError(t, err)
+
+			plan := solver.PlanSourcing(tt.candidates, orderQty, tt.splitPenalty)
+
+			actual := make(map[int]map[int]int, len(plan.Allocations))
+			for source, allocation := range plan.Allocations {
+				actual[source] = allocation.GetAllocation()
+			}
+
+			assert.Equal(t, tt.expectedStrategy, plan.Strategy)
+			assert.Equal(t, tt.expectedAlloc, actual)
+			assert.Equal(t, tt.expectedSurplus, plan.Surplus)
+		})
+	}
+}
@@ -1,56 +1,147 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
 )
 
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 500
+)
+
+// PackConfigurationService makes every write under the actor it is given,
+// which the repository audits in the write's own transaction
 type PackConfigurationService struct {
-	repository entity.PackConfigurationRepository
+	repository         entity.PackConfigurationRepository
+	packTypeRepository entity.PackTypeRepository
+	// allowDuplicatePackSizes lets several active configurations share
+	// the same pack sizes; otherwise writes that would are rejected
+	allowDuplicatePackSizes bool
 }
 
-func NewPackConfigurationService(repository entity.PackConfigurationRepository) *PackConfigurationService {
+func NewPackConfigurationService(repository entity.PackConfigurationRepository, packTypeRepository entity.PackTypeRepository, allowDuplicatePackSizes bool) *PackConfigurationService {
 	return &PackConfigurationService{
-		repository: repository,
+		repository:              repository,
+		packTypeRepository:      packTypeRepository,
+		allowDuplicatePackSizes: allowDuplicatePackSizes,
 	}
 }
 
-func (s *PackConfigurationService) GetAllConfigurations() ([]*entity.PackConfiguration, error) {
-	configurations, err := s.repository.GetAll()
+// ListConfigurations returns one page of configurations plus the total matching the query
+func (s *PackConfigurationService) ListConfigurations(ctx context.Context, query entity.PackConfigurationQuery) ([]*entity.PackConfiguration, int, error) {
+	query = NormalizeQuery(query)
+
+	if query.PackSize != nil && *query.PackSize <= 0 {
+		return nil, 0, fmt.Errorf("pack size must be positive, got %d: %w", *query.PackSize, errs.ErrInvalidConfigurationQuery)
+	}
+	if query.CreatedFrom != nil && query.CreatedTo != nil && !query.CreatedFrom.Before(*query.CreatedTo) {
+		return nil, 0, fmt.Errorf("created_from must be before created_to: %w", errs.ErrInvalidConfigurationQuery)
+	}
+	if _, err := entity.ParsePackConfigurationSort(string(query.Sort)); err != nil {
+		return nil, 0, fmt.Errorf("%v: %w", err, errs.ErrInvalidConfigurationQuery)
+	}
+	if err := query.Labels.Validate(); err != nil {
+		return nil, 0, fmt.Errorf("label selector: %v: %w", err, errs.ErrInvalidConfigurationQuery)
+	}
+
+	configurations, total, err := s.repository.List(ctx, query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get pack configurations: %w", err)
+		return nil, 0, fmt.Errorf("failed to list pack configurations: %w", err)
 	}
-	return configurations, nil
+	if err := s.attachPackTypes(ctx, configurations...); err != nil {
+		return nil, 0, err
+	}
+	return configurations, total, nil
+}
+
+// NormalizeQuery clamps pagination to sane bounds and trims the search term
+func NormalizeQuery(query entity.PackConfigurationQuery) entity.PackConfigurationQuery {
+	query.Search = strings.TrimSpace(query.Search)
+	if query.Limit <= 0 {
+		query.Limit = DefaultPageSize
+	}
+	if query.Limit > MaxPageSize {
+		query.Limit = MaxPageSize
+	}
+	if query.Offset < 0 {
+		query.Offset = 0
+	}
+	return query
 }
 
-func (s *PackConfigurationService) GetConfigurationByID(id int) (*entity.PackConfiguration, error) {
+func (s *PackConfigurationService) GetConfigurationByID(ctx context.Context, tenant string, id int) (*entity.PackConfiguration, error) {
 	if id <= 0 {
-		return nil, fmt.Errorf("invalid pack configuration ID: %d", id)
+		return nil, errs.ErrInvalidID.Withf("invalid pack configuration ID: %d", id)
 	}
 
-	configuration, err := s.repository.GetByID(id)
+	configuration, err := s.repository.GetByID(ctx, tenant, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get pack configuration by ID: %w", err)
 	}
+	if err := s.attachPackTypes(ctx, configuration); err != nil {
+		return nil, err
+	}
 	return configuration, nil
 }
 
-func (s *PackConfigurationService) GetDefaultConfiguration() (*entity.PackConfiguration, error) {
-	configuration, err := s.repository.GetDefault()
+// GetDefaultConfiguration returns the tenant's own default configuration in
+// effect at asOf
+func (s *PackConfigurationService) GetDefaultConfiguration(ctx context.Context, tenant string, asOf time.Time) (*entity.PackConfiguration, error) {
+	configuration, err := s.repository.GetDefault(ctx, tenant, asOf)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get default pack configuration: %w", err)
+		return nil, fmt.Errorf("failed to get default pack configuration as of %s: %w", asOf.Format(time.RFC3339), err)
+	}
+	if err := s.attachPackTypes(ctx, configuration); err != nil {
+		return nil, err
+	}
+	return configuration, nil
+}
+
+// GetEffectiveConfiguration returns the configuration provided it is in
+// effect at asOf
+func (s *PackConfigurationService) GetEffectiveConfiguration(ctx context.Context, tenant string, id int, asOf time.Time) (*entity.PackConfiguration, error) {
+	configuration, err := s.GetConfigurationByID(ctx, tenant, id)
+	if err != nil {
+		return nil, err
+	}
+	if !configuration.EffectiveAt(asOf) {
+		return nil, errs.ErrConfigurationNotEffective.Withf("pack configuration %d is not in effect at %s", id, asOf.Format(time.RFC3339))
 	}
 	return configuration, nil
 }
 
-func (s *PackConfigurationService) CreateConfiguration(name string, packSizes []int) (*entity.PackConfiguration, error) {
-	configuration, err := entity.NewPackConfiguration(name, packSizes)
+// CreateConfiguration creates a configuration offering packSizes plus the
+// item counts of the pack types with the given codes
+func (s *PackConfigurationService) CreateConfiguration(ctx context.Context, actor entity.Actor, name string, packSizes []int, packTypeCodes []string, period entity.EffectivePeriod, details entity.PackConfigurationDetails) (*entity.PackConfiguration, error) {
+	packTypes, err := s.resolvePackTypes(ctx, actor.Tenant, packTypeCodes)
+	if err != nil {
+		return nil, err
+	}
+
+	configuration, err := entity.NewPackConfiguration(actor.Tenant, name, withItemCounts(packSizes, packTypes))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create pack configuration entity: %w", err)
 	}
+	applyPackTypes(configuration, packTypes)
+	configuration.EffectivePeriod = period
+	configuration.PackConfigurationDetails = details
+	if err := configuration.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid pack configuration: %w", err)
+	}
 
-	createdConfig, err := s.repository.Create(configuration)
+	if err := s.checkDuplicatePackSizes(ctx, actor.Tenant, 0, configuration.PackSizes); err != nil {
+		return nil, err
+	}
+
+	createdConfig, err := s.repository.Create(entity.WithActor(ctx, actor), configuration)
 	if err != nil {
 		return nil, fmt.Errorf("failed to save pack configuration: %w", err)
 	}
@@ -58,30 +149,164 @@ func (s *PackConfigurationService) CreateConfiguration(name string, packSizes []
 	return createdConfig, nil
 }
 
-func (s *PackConfigurationService) UpdateConfiguration(id int, name string, packSizes []int, isDefault bool) (*entity.PackConfiguration, error) {
+// UpsertConfiguration creates a configuration, or when an active one already
+// has the name, compared case-insensitively, gives it packSizes, pack types,
+// period, details and name instead. created reports which happened.
+func (s *PackConfigurationService) UpsertConfiguration(ctx context.Context, actor entity.Actor, name string, packSizes []int, packTypeCodes []string, period entity.EffectivePeriod, details entity.PackConfigurationDetails) (configuration *entity.PackConfiguration, created bool, err error) {
+	configuration, err = s.CreateConfiguration(ctx, actor, name, packSizes, packTypeCodes, period, details)
+
+	// The configuration holding the name may also be the one holding the sizes
+	var nameConflict *errs.NameConflictError
+	var duplicate *errs.DuplicatePackSizesError
+	existingID := 0
+	switch {
+	case errors.As(err, &nameConflict):
+		existingID = nameConflict.ExistingID
+	case errors.As(err, &duplicate) && strings.EqualFold(duplicate.ExistingName, name):
+		existingID = duplicate.ExistingID
+	}
+	if existingID == 0 {
+		return configuration, err == nil, err
+	}
+
+	existing, err := s.GetConfigurationByID(ctx, actor.Tenant, existingID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get pack configuration named %q: %w", name, err)
+	}
+	packTypes, err := s.resolvePackTypes(ctx, actor.Tenant, packTypeCodes)
+	if err != nil {
+		return nil, false, err
+	}
+
+	updated := *existing
+	updated.Name = name
+	updated.PackSizes = packSizes
+	applyPackTypes(&updated, packTypes)
+	updated.EffectivePeriod = period
+	updated.PackConfigurationDetails = details
+	updated.IsDefault = false
+	if !existing.ChangesContents(&updated) {
+		return existing, false, nil
+	}
+
+	if err := s.checkChangedPackSizes(ctx, existing, &updated); err != nil {
+		return nil, false, err
+	}
+
+	configuration, err = s.repository.Update(entity.WithActor(ctx, actor), &updated)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to update pack configuration named %q: %w", name, err)
+	}
+
+	return configuration, false, nil
+}
+
+// UpdateConfiguration replaces a configuration's contents. The write only
+// applies while the configuration is still at version; 0 skips that check.
+func (s *PackConfigurationService) UpdateConfiguration(ctx context.Context, actor entity.Actor, id int, version int, name string, packSizes []int, packTypeCodes []string, period entity.EffectivePeriod, details entity.PackConfigurationDetails, isDefault bool) (*entity.PackConfiguration, error) {
 	if id <= 0 {
-		return nil, fmt.Errorf("invalid pack configuration ID: %d", id)
+		return nil, errs.ErrInvalidID.Withf("invalid pack configuration ID: %d", id)
 	}
 
-	existingConfig, err := s.repository.GetByID(id)
+	existingConfig, err := s.repository.GetByID(ctx, actor.Tenant, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get existing configuration: %w", err)
 	}
 
+	expectedVersion, err := expectVersion(existingConfig, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if existingConfig.IsDefault && !isDefault {
+		return nil, errDefaultUnset(id)
+	}
+
+	packTypes, err := s.resolvePackTypes(ctx, actor.Tenant, packTypeCodes)
+	if err != nil {
+		return nil, err
+	}
+
 	updatedConfig := &entity.PackConfiguration{
 		ID:        existingConfig.ID,
+		Tenant:    existingConfig.Tenant,
 		Name:      name,
 		PackSizes: packSizes,
 		IsDefault: isDefault,
 		IsActive:  existingConfig.IsActive,
+		Version:   expectedVersion,
 		CreatedAt: existingConfig.CreatedAt,
 	}
+	applyPackTypes(updatedConfig, packTypes)
+	updatedConfig.EffectivePeriod = period
+	updatedConfig.PackConfigurationDetails = details
 
 	if err := updatedConfig.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid pack configuration: %w", err)
 	}
 
-	savedConfig, err := s.repository.Update(updatedConfig)
+	if err := s.checkChangedPackSizes(ctx, existingConfig, updatedConfig); err != nil {
+		return nil, err
+	}
+
+	savedConfig, err := s.repository.Update(entity.WithActor(ctx, actor), updatedConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update pack configuration: %w", err)
+	}
+
+	return savedConfig, nil
+}
+
+// PatchConfiguration applies a partial update. Content changes write a new
+// version; making the configuration the default goes through the same
+// transactional path as SetDefaultConfiguration. A patch that changes
+// nothing returns the configuration as it is.
+func (s *PackConfigurationService) PatchConfiguration(ctx context.Context, actor entity.Actor, id int, version int, patch entity.PackConfigurationPatch) (*entity.PackConfiguration, error) {
+	if id <= 0 {
+		return nil, errs.ErrInvalidID.Withf("invalid pack configuration ID: %d", id)
+	}
+
+	existingConfig, err := s.repository.GetByID(ctx, actor.Tenant, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get existing configuration: %w", err)
+	}
+
+	expectedVersion, err := expectVersion(existingConfig, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if patch.IsDefault != nil && !*patch.IsDefault && existingConfig.IsDefault {
+		return nil, errDefaultUnset(id)
+	}
+	makeDefault := patch.IsDefault != nil && *patch.IsDefault && !existingConfig.IsDefault
+
+	if err := s.attachPackTypes(ctx, existingConfig); err != nil {
+		return nil, err
+	}
+	patchedConfig := patch.Apply(existingConfig)
+	if err := s.patchPackTypes(ctx, existingConfig, patchedConfig, patch); err != nil {
+		return nil, err
+	}
+	patchedConfig.PackSizes = entity.CanonicalPackSizes(patchedConfig.PackSizes)
+	if !existingConfig.ChangesContents(patchedConfig) {
+		if !makeDefault {
+			return existingConfig, nil
+		}
+		return s.setDefault(ctx, actor, id, expectedVersion)
+	}
+
+	if err := patchedConfig.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid pack configuration: %w", err)
+	}
+
+	if err := s.checkChangedPackSizes(ctx, existingConfig, patchedConfig); err != nil {
+		return nil, err
+	}
+
+	patchedConfig.Version = expectedVersion
+	patchedConfig.IsDefault = makeDefault
+	savedConfig, err := s.repository.Update(entity.WithActor(ctx, actor), patchedConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update pack configuration: %w", err)
 	}
@@ -89,18 +314,27 @@ func (s *PackConfigurationService) UpdateConfiguration(id int, name string, pack
 	return savedConfig, nil
 }
 
-func (s *PackConfigurationService) DeleteConfiguration(id int) error {
+func (s *PackConfigurationService) DeleteConfiguration(ctx context.Context, actor entity.Actor, id int, version int) error {
 	if id <= 0 {
-		return fmt.Errorf("invalid pack configuration ID: %d", id)
+		return errs.ErrInvalidID.Withf("invalid pack configuration ID: %d", id)
+	}
+
+	existingConfig, err := s.repository.GetByID(ctx, actor.Tenant, id)
+	if err != nil {
+		return fmt.Errorf("failed to get existing configuration: %w", err)
+	}
+
+	expectedVersion, err := expectVersion(existingConfig, version)
+	if err != nil {
+		return err
 	}
 
 	// Check if this is the default configuration
-	defaultConfig, err := s.repository.GetDefault()
-	if err == nil && defaultConfig.ID == id {
-		return fmt.Errorf("cannot delete the default pack configuration")
+	if existingConfig.IsDefault {
+		return errs.ErrDefaultConfigurationDelete
 	}
 
-	err = s.repository.Delete(id)
+	err = s.repository.Delete(entity.WithActor(ctx, actor), actor.Tenant, id, expectedVersion)
 	if err != nil {
 		return fmt.Errorf("failed to delete pack configuration: %w", err)
 	}
@@ -108,21 +342,550 @@ func (s *PackConfigurationService) DeleteConfiguration(id int) error {
 	return nil
 }
 
-func (s *PackConfigurationService) SetDefaultConfiguration(id int) error {
+func (s *PackConfigurationService) SetDefaultConfiguration(ctx context.Context, actor entity.Actor, id int, version int) error {
 	if id <= 0 {
-		return fmt.Errorf("invalid pack configuration ID: %d", id)
+		return errs.ErrInvalidID.Withf("invalid pack configuration ID: %d", id)
 	}
 
 	// Verify the configuration exists and is active
-	_, err := s.repository.GetByID(id)
+	existingConfig, err := s.repository.GetByID(ctx, actor.Tenant, id)
 	if err != nil {
 		return fmt.Errorf("configuration not found or inactive: %w", err)
 	}
 
-	err = s.repository.SetDefault(id)
+	expectedVersion, err := expectVersion(existingConfig, version)
 	if err != nil {
-		return fmt.Errorf("failed to set default configuration: %w", err)
+		return err
+	}
+
+	_, err = s.setDefault(ctx, actor, id, expectedVersion)
+	return err
+}
+
+func (s *PackConfigurationService) setDefault(ctx context.Context, actor entity.Actor, id int, version int) (*entity.PackConfiguration, error) {
+	err := s.repository.SetDefault(entity.WithActor(ctx, actor), actor.Tenant, id, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set default configuration: %w", err)
+	}
+
+	updatedConfig, err := s.GetConfigurationByID(ctx, actor.Tenant, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get updated configuration: %w", err)
+	}
+	return updatedConfig, nil
+}
+
+func (s *PackConfigurationService) GetConfigurationVersions(ctx context.Context, tenant string, id int) ([]*entity.PackConfigurationVersion, error) {
+	if id <= 0 {
+		return nil, errs.ErrInvalidID.Withf("invalid pack configuration ID: %d", id)
+	}
+
+	versions, err := s.repository.GetVersions(ctx, tenant, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pack configuration versions: %w", err)
+	}
+	return versions, nil
+}
+
+func (s *PackConfigurationService) GetConfigurationVersion(ctx context.Context, tenant string, id int, version int) (*entity.PackConfigurationVersion, error) {
+	if id <= 0 {
+		return nil, errs.ErrInvalidID.Withf("invalid pack configuration ID: %d", id)
+	}
+	if version <= 0 {
+		return nil, errs.ErrInvalidID.Withf("invalid pack configuration version: %d", version)
+	}
+
+	snapshot, err := s.repository.GetVersion(ctx, tenant, id, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pack configuration version: %w", err)
+	}
+	return snapshot, nil
+}
+
+// RestoreConfigurationVersion rolls a configuration back to an earlier
+// snapshot. History is never rewritten: the restore becomes the newest version.
+func (s *PackConfigurationService) RestoreConfigurationVersion(ctx context.Context, actor entity.Actor, id int, version int) (*entity.PackConfiguration, error) {
+	if id <= 0 {
+		return nil, errs.ErrInvalidID.Withf("invalid pack configuration ID: %d", id)
+	}
+	if version <= 0 {
+		return nil, errs.ErrInvalidID.Withf("invalid pack configuration version: %d", version)
+	}
+
+	configuration, err := s.repository.RestoreVersion(entity.WithActor(ctx, actor), actor.Tenant, id, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore pack configuration version: %w", err)
+	}
+	if err := s.attachPackTypes(ctx, configuration); err != nil {
+		return nil, err
+	}
+	return configuration, nil
+}
+
+// RestoreConfiguration brings a soft-deleted configuration back. An empty
+// name keeps the archived one; either way it must not clash with an active
+// configuration.
+func (s *PackConfigurationService) RestoreConfiguration(ctx context.Context, actor entity.Actor, id int, name string) (*entity.PackConfiguration, error) {
+	if id <= 0 {
+		return nil, errs.ErrInvalidID.Withf("invalid pack configuration ID: %d", id)
+	}
+
+	restoredConfig, err := s.repository.Restore(entity.WithActor(ctx, actor), actor.Tenant, id, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore pack configuration: %w", err)
+	}
+	if err := s.attachPackTypes(ctx, restoredConfig); err != nil {
+		return nil, err
+	}
+
+	return restoredConfig, nil
+}
+
+// PurgeConfiguration permanently removes an archived configuration
+func (s *PackConfigurationService) PurgeConfiguration(ctx context.Context, actor entity.Actor, id int) error {
+	if id <= 0 {
+		return errs.ErrInvalidID.Withf("invalid pack configuration ID: %d", id)
+	}
+
+	if err := s.repository.Purge(entity.WithActor(ctx, actor), actor.Tenant, id); err != nil {
+		return fmt.Errorf("failed to purge pack configuration: %w", err)
 	}
 
 	return nil
 }
+
+// ExportConfigurations returns every active configuration of the tenant ordered by name
+func (s *PackConfigurationService) ExportConfigurations(ctx context.Context, tenant string) ([]*entity.PackConfiguration, error) {
+	configurations, err := s.allConfigurations(ctx, tenant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export pack configurations: %w", err)
+	}
+	return configurations, nil
+}
+
+// ImportConfigurations creates, or with options.Upsert updates by name, one
+// configuration per row. Every row is validated first and reported by its
+// index; if any is invalid nothing is written. Otherwise all writes happen
+// in one transaction, unless options.DryRun only asks for the plan.
+func (s *PackConfigurationService) ImportConfigurations(ctx context.Context, actor entity.Actor, rows []entity.PackConfigurationImportRow, options entity.ImportOptions) ([]entity.PackConfigurationImportResult, error) {
+	existing, err := s.allConfigurations(ctx, actor.Tenant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pack configurations for import: %w", err)
+	}
+	// Active names are unique regardless of case
+	byName := make(map[string]*entity.PackConfiguration, len(existing))
+	for _, config := range existing {
+		byName[strings.ToLower(config.Name)] = config
+	}
+
+	var (
+		imp          = entity.PackConfigurationImport{Tenant: actor.Tenant}
+		results      = make([]entity.PackConfigurationImportResult, len(rows))
+		fieldErrors  []errs.FieldError
+		seen         = make(map[string]int, len(rows))
+		defaultIndex = -1
+		now          = time.Now()
+	)
+	rowError := func(index int, field, format string, args ...interface{}) {
+		fieldErrors = append(fieldErrors, errs.FieldError{
+			Field:   fmt.Sprintf("configurations[%d].%s", index, field),
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	// Written pack sizes may not repeat another configuration's or another row's
+	bySizes := make(map[string]*entity.PackConfiguration, len(existing))
+	for _, config := range existing {
+		if key := fmt.Sprint(config.PackSizes); bySizes[key] == nil {
+			bySizes[key] = config
+		}
+	}
+	claimedSizes := make(map[string]int, len(rows))
+	duplicateSizes := func(index int, packSizes []int, id int) bool {
+		if s.allowDuplicatePackSizes {
+			return false
+		}
+		key := fmt.Sprint(packSizes)
+		if holder := bySizes[key]; holder != nil && holder.ID != id {
+			rowError(index, "pack_sizes", "are already used by pack configuration %d (%q)", holder.ID, holder.Name)
+			return true
+		}
+		if previous, claimed := claimedSizes[key]; claimed {
+			rowError(index, "pack_sizes", "duplicate configurations[%d]", previous)
+			return true
+		}
+		claimedSizes[key] = index
+		return false
+	}
+
+	for i, row := range rows {
+		results[i] = entity.PackConfigurationImportResult{Index: i, Name: row.Name}
+
+		packTypes, err := s.resolvePackTypes(ctx, actor.Tenant, row.PackTypes)
+		if err != nil {
+			if !errors.Is(err, errs.ErrUnknownPackType) && !errors.Is(err, errs.ErrInvalidPackConfiguration) {
+				return nil, err
+			}
+			rowError(i, "pack_types", "%v", err)
+			continue
+		}
+
+		candidate := &entity.PackConfiguration{
+			Tenant:                   actor.Tenant,
+			Name:                     row.Name,
+			PackSizes:                withItemCounts(row.PackSizes, packTypes),
+			IsDefault:                row.IsDefault,
+			IsActive:                 true,
+			EffectivePeriod:          row.EffectivePeriod,
+			PackConfigurationDetails: row.PackConfigurationDetails,
+		}
+		if err := candidate.Validate(); err != nil {
+			field := "pack_sizes"
+			switch {
+			case row.Name == "":
+				field = "name"
+			case row.EffectivePeriod.Validate() != nil:
+				field = "effective_to"
+			case row.PackConfigurationDetails.Validate() != nil:
+				field = detailsField(row.PackConfigurationDetails)
+			}
+			rowError(i, field, "%v", err)
+			continue
+		}
+		applyPackTypes(candidate, packTypes)
+		key := strings.ToLower(row.Name)
+		if previous, duplicate := seen[key]; duplicate {
+			rowError(i, "name", "duplicates configurations[%d]", previous)
+			continue
+		}
+		seen[key] = i
+		if row.IsDefault {
+			if defaultIndex >= 0 {
+				rowError(i, "is_default", "configurations[%d] is already the default", defaultIndex)
+				continue
+			}
+			defaultIndex = i
+		}
+
+		current, exists := byName[key]
+		switch {
+		case !exists:
+			if duplicateSizes(i, candidate.PackSizes, 0) {
+				continue
+			}
+			candidate.Version = 1
+			candidate.CreatedAt = timeOr(row.CreatedAt, now)
+			candidate.UpdatedAt = timeOr(row.UpdatedAt, candidate.CreatedAt)
+			imp.Creates = append(imp.Creates, candidate)
+			results[i].Action = entity.ImportActionCreate
+		case !options.Upsert:
+			rowError(i, "name", "pack configuration %q already exists; import with upsert to update it", row.Name)
+		default:
+			results[i].ID = current.ID
+			if !current.ChangesContents(candidate) {
+				results[i].Action = entity.ImportActionUnchanged
+				if row.IsDefault && !current.IsDefault {
+					imp.DefaultID = current.ID
+				}
+				continue
+			}
+			if !slices.Equal(current.PackSizes, candidate.PackSizes) && duplicateSizes(i, candidate.PackSizes, current.ID) {
+				continue
+			}
+			candidate.ID = current.ID
+			candidate.Version = current.Version
+			candidate.CreatedAt = timeOr(row.CreatedAt, current.CreatedAt)
+			candidate.UpdatedAt = timeOr(row.UpdatedAt, now)
+			imp.Updates = append(imp.Updates, candidate)
+			results[i].Action = entity.ImportActionUpdate
+		}
+	}
+
+	// The default only ever moves; a file that takes the flag off the current
+	// default must give it to another configuration
+	if defaultIndex < 0 {
+		for i, row := range rows {
+			if current, exists := byName[strings.ToLower(row.Name)]; exists && current.IsDefault && !row.IsDefault && options.Upsert {
+				rowError(i, "is_default", "is the current default; mark another configuration as the default instead")
+			}
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return results, &errs.InvalidFieldsError{
+			Err:    errs.ErrInvalidImport.Withf("%d problem(s) in %d configurations; nothing was imported", len(fieldErrors), len(rows)),
+			Fields: fieldErrors,
+		}
+	}
+	if options.DryRun {
+		return results, nil
+	}
+
+	if err := s.repository.Import(entity.WithActor(ctx, actor), imp); err != nil {
+		return nil, fmt.Errorf("failed to import pack configurations: %w", err)
+	}
+
+	for i := range results {
+		if results[i].Action == entity.ImportActionCreate {
+			results[i].ID = imp.Creates[0].ID
+			imp.Creates = imp.Creates[1:]
+		}
+	}
+	return results, nil
+}
+
+// detailsField names the member of invalid details at fault
+func detailsField(details entity.PackConfigurationDetails) string {
+	switch {
+	case details.Labels.Validate() != nil:
+		return "labels"
+	case entity.PackConfigurationDetails{Description: details.Description}.Validate() != nil:
+		return "description"
+	}
+	return "metadata"
+}
+
+// FindEquivalentConfigurations returns the tenant's active configurations
+// with the same pack sizes as packSizes, then those that differ only by
+// redundant sizes, each group ordered by name
+func (s *PackConfigurationService) FindEquivalentConfigurations(ctx context.Context, tenant string, packSizes []int) (*entity.PackSizeEquivalence, error) {
+	query := &entity.PackConfiguration{Name: "query", PackSizes: packSizes}
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	canonical := entity.CanonicalPackSizes(packSizes)
+	minimal, redundant := entity.ReducePackSizes(canonical)
+	equivalence := &entity.PackSizeEquivalence{
+		PackSizes:          canonical,
+		MinimalPackSizes:   minimal,
+		RedundantPackSizes: redundant,
+		Configurations:     []entity.EquivalentConfiguration{},
+	}
+
+	configurations, err := s.allConfigurations(ctx, tenant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pack configurations: %w", err)
+	}
+
+	var equivalent []entity.EquivalentConfiguration
+	for _, config := range configurations {
+		sizes := entity.CanonicalPackSizes(config.PackSizes)
+		if slices.Equal(sizes, canonical) {
+			_, ownRedundant := entity.ReducePackSizes(sizes)
+			equivalence.Configurations = append(equivalence.Configurations, entity.EquivalentConfiguration{
+				Configuration:      config,
+				Match:              entity.PackSizeMatchIdentical,
+				RedundantPackSizes: ownRedundant,
+			})
+			continue
+		}
+
+		// Every set making the same quantities contains the minimal one
+		if !containsAll(sizes, minimal) {
+			continue
+		}
+		ownMinimal, ownRedundant := entity.ReducePackSizes(sizes)
+		if slices.Equal(ownMinimal, minimal) {
+			equivalent = append(equivalent, entity.EquivalentConfiguration{
+				Configuration:      config,
+				Match:              entity.PackSizeMatchEquivalent,
+				RedundantPackSizes: ownRedundant,
+			})
+		}
+	}
+	equivalence.Configurations = append(equivalence.Configurations, equivalent...)
+
+	return equivalence, nil
+}
+
+// checkChangedPackSizes checks for duplicates only when a write changes the
+// pack sizes, so configurations that already share sizes can still be renamed
+func (s *PackConfigurationService) checkChangedPackSizes(ctx context.Context, existing, updated *entity.PackConfiguration) error {
+	if slices.Equal(existing.PackSizes, updated.PackSizes) {
+		return nil
+	}
+	return s.checkDuplicatePackSizes(ctx, existing.Tenant, existing.ID, updated.PackSizes)
+}
+
+// checkDuplicatePackSizes rejects canonical pack sizes that another active
+// configuration of the tenant already has, unless duplicates are allowed
+func (s *PackConfigurationService) checkDuplicatePackSizes(ctx context.Context, tenant string, id int, packSizes []int) error {
+	if s.allowDuplicatePackSizes {
+		return nil
+	}
+
+	matches, err := s.repository.FindByPackSizes(ctx, tenant, packSizes)
+	if err != nil {
+		return fmt.Errorf("failed to find pack configurations with the same pack sizes: %w", err)
+	}
+	for _, match := range matches {
+		if match.ID != id {
+			return &errs.DuplicatePackSizesError{PackSizes: packSizes, ExistingID: match.ID, ExistingName: match.Name}
+		}
+	}
+	return nil
+}
+
+func containsAll(sizes, subset []int) bool {
+	for _, size := range subset {
+		if _, found := slices.BinarySearch(sizes, size); !found {
+			return false
+		}
+	}
+	return true
+}
+
+// allConfigurations pages through every active configuration of the tenant by name
+func (s *PackConfigurationService) allConfigurations(ctx context.Context, tenant string) ([]*entity.PackConfiguration, error) {
+	query := entity.PackConfigurationQuery{Tenant: tenant, Sort: entity.PackConfigurationSortName, Limit: MaxPageSize}
+	var configurations []*entity.PackConfiguration
+	for {
+		page, total, err := s.repository.List(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		configurations = append(configurations, page...)
+		query.Offset += len(page)
+		if len(page) == 0 || query.Offset >= total {
+			return configurations, s.attachPackTypes(ctx, configurations...)
+		}
+	}
+}
+
+// resolvePackTypes looks up the active pack types with the given codes,
+// ordered by ID. Two of them may not hold the same item count, since an
+// allocation of that size could not tell which one to ship.
+func (s *PackConfigurationService) resolvePackTypes(ctx context.Context, tenant string, codes []string) ([]*entity.PackType, error) {
+	if len(codes) == 0 {
+		return nil, nil
+	}
+
+	found, err := s.packTypeRepository.GetByCodes(ctx, tenant, codes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pack types: %w", err)
+	}
+	byCode := make(map[string]*entity.PackType, len(found))
+	for _, packType := range found {
+		byCode[strings.ToLower(packType.Code)] = packType
+	}
+
+	var packTypes []*entity.PackType
+	for _, code := range codes {
+		packType, ok := byCode[strings.ToLower(code)]
+		if !ok {
+			return nil, errs.ErrUnknownPackType.Withf("no active pack type has code %q", code)
+		}
+		if slices.Contains(packTypes, packType) {
+			continue
+		}
+		for _, other := range packTypes {
+			if other.ItemCount == packType.ItemCount {
+				return nil, errs.ErrInvalidPackConfiguration.Withf("pack types %q and %q both hold %d items", other.Code, packType.Code, packType.ItemCount)
+			}
+		}
+		packTypes = append(packTypes, packType)
+	}
+	slices.SortFunc(packTypes, func(a, b *entity.PackType) int { return a.ID - b.ID })
+	return packTypes, nil
+}
+
+// applyPackTypes makes config reference packTypes and offer each one's item
+// count among its canonical pack sizes
+func applyPackTypes(config *entity.PackConfiguration, packTypes []*entity.PackType) {
+	config.PackTypes = packTypes
+	config.PackTypeIDs = nil
+	for _, packType := range packTypes {
+		config.PackTypeIDs = append(config.PackTypeIDs, packType.ID)
+	}
+	config.PackSizes = entity.CanonicalPackSizes(withItemCounts(config.PackSizes, packTypes))
+}
+
+func withItemCounts(packSizes []int, packTypes []*entity.PackType) []int {
+	sizes := slices.Clone(packSizes)
+	for _, packType := range packTypes {
+		sizes = append(sizes, packType.ItemCount)
+	}
+	return sizes
+}
+
+// patchPackTypes applies the patch's pack types to patched. Replacing them
+// also drops the sizes the old ones offered, unless the patch replaces the
+// pack sizes too; otherwise every size a kept pack type offers must remain.
+func (s *PackConfigurationService) patchPackTypes(ctx context.Context, existing, patched *entity.PackConfiguration, patch entity.PackConfigurationPatch) error {
+	if patch.PackTypes == nil {
+		for _, packType := range existing.PackTypes {
+			if !slices.Contains(patched.PackSizes, packType.ItemCount) {
+				return errs.ErrInvalidPackConfiguration.Withf("pack size %d is offered by pack type %q; remove the pack type instead", packType.ItemCount, packType.Code)
+			}
+		}
+		return nil
+	}
+
+	packTypes, err := s.resolvePackTypes(ctx, existing.Tenant, patch.PackTypes)
+	if err != nil {
+		return err
+	}
+	if patch.PackSizes == nil {
+		patched.PackSizes = slices.DeleteFunc(slices.Clone(patched.PackSizes), func(size int) bool {
+			return existing.PackType(size) != nil
+		})
+	}
+	applyPackTypes(patched, packTypes)
+	return nil
+}
+
+// attachPackTypes fills in the pack types the configurations reference. The
+// configurations all belong to the same tenant.
+func (s *PackConfigurationService) attachPackTypes(ctx context.Context, configurations ...*entity.PackConfiguration) error {
+	var ids []int
+	for _, config := range configurations {
+		ids = append(ids, config.PackTypeIDs...)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	packTypes, err := s.packTypeRepository.GetByIDs(ctx, configurations[0].Tenant, ids)
+	if err != nil {
+		return fmt.Errorf("failed to get referenced pack types: %w", err)
+	}
+	byID := make(map[int]*entity.PackType, len(packTypes))
+	for _, packType := range packTypes {
+		byID[packType.ID] = packType
+	}
+	for _, config := range configurations {
+		config.PackTypes = nil
+		for _, id := range config.PackTypeIDs {
+			if packType, ok := byID[id]; ok {
+				config.PackTypes = append(config.PackTypes, packType)
+			}
+		}
+	}
+	return nil
+}
+
+func timeOr(t *time.Time, fallback time.Time) time.Time {
+	if t == nil {
+		return fallback
+	}
+	return *t
+}
+
+// expectVersion resolves the version a write is conditioned on. 0 accepts the
+// version just read, so the write still fails if it changes in between.
+func expectVersion(existing *entity.PackConfiguration, version int) (int, error) {
+	if version == 0 {
+		return existing.Version, nil
+	}
+	if version != existing.Version {
+		return 0, &errs.VersionConflictError{ID: existing.ID, ExpectedVersion: version, CurrentVersion: existing.Version}
+	}
+	return version, nil
+}
+
+// errDefaultUnset rejects clearing the default flag directly, which would
+// leave no default; the flag only moves by making another configuration the default
+func errDefaultUnset(id int) error {
+	return errs.ErrDefaultConfigurationUnset.Withf("pack configuration %d is the default; make another configuration the default instead", id)
+}
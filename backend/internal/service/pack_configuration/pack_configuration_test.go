@@ -0,0 +1,1391 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+)
+
+type fakePackConfigurationRepository struct {
+	configs map[int]*entity.PackConfiguration
+	nextID  int
+	// actors holds the actor each successful write was made under, which the
+	// real repositories audit it for
+	actors []entity.Actor
+}
+
+func (r *fakePackConfigurationRepository) wroteAs(ctx context.Context) {
+	actor, _ := entity.ActorFrom(ctx)
+	r.actors = append(r.actors, actor)
+}
+
+// newFakePackConfigurationRepository seeds configs, placing those without a
+// tenant in the default tenant
+func newFakePackConfigurationRepository(configs ...*entity.PackConfiguration) *fakePackConfigurationRepository {
+	repo := &fakePackConfigurationRepository{configs: map[int]*entity.PackConfiguration{}, nextID: 1}
+	for _, config := range configs {
+		if config.Tenant == "" {
+			config.Tenant = entity.DefaultTenant
+		}
+		repo.configs[config.ID] = config
+		if config.ID >= repo.nextID {
+			repo.nextID = config.ID + 1
+		}
+	}
+	return repo
+}
+
+func (r *fakePackConfigurationRepository) List(ctx context.Context, query entity.PackConfigurationQuery) ([]*entity.PackConfiguration, int, error) {
+	var configs []*entity.PackConfiguration
+	for _, config := range r.configs {
+		if config.Tenant == query.Tenant && config.IsActive != query.Archived && config.Labels.Matches(query.Labels) {
+			configs = append(configs, config)
+		}
+	}
+	slices.SortFunc(configs, func(a, b *entity.PackConfiguration) int { return a.ID - b.ID })
+	return configs, len(configs), nil
+}
+
+func (r *fakePackConfigurationRepository) GetByID(ctx context.Context, tenant string, id int) (*entity.PackConfiguration, error) {
+	config, ok := r.configs[id]
+	if !ok || !config.IsActive || config.Tenant != tenant {
+		return nil, fmt.Errorf("pack configuration with id %d: %w", id, errs.ErrPackConfigurationNotFound)
+	}
+	copied := *config
+	return &copied, nil
+}
+
+func (r *fakePackConfigurationRepository) GetDefault(ctx context.Context, tenant string, asOf time.Time) (*entity.PackConfiguration, error) {
+	for _, config := range r.configs {
+		if config.Tenant == tenant && config.IsDefault && config.IsActive && config.EffectiveAt(asOf) {
+			return r.GetByID(ctx, tenant, config.ID)
+		}
+	}
+	return nil, errs.ErrNoDefaultConfiguration
+}
+
+// nameConflict mirrors the unique index on active names within a tenant
+func (r *fakePackConfigurationRepository) nameConflict(tenant string, name string, id int) error {
+	for _, config := range r.configs {
+		if config.Tenant == tenant && config.IsActive && config.ID != id && strings.EqualFold(config.Name, name) {
+			return &errs.NameConflictError{Name: name, ExistingID: config.ID}
+		}
+	}
+	return nil
+}
+
+// defaultOverlap mirrors the exclusion constraint on overlapping default periods
+func (r *fakePackConfigurationRepository) defaultOverlap(tenant string, id int, period entity.EffectivePeriod) error {
+	for _, config := range r.configs {
+		if config.Tenant == tenant && config.IsDefault && config.ID != id && config.Overlaps(period) {
+			return &errs.DefaultPeriodOverlapError{ID: id, ExistingID: config.ID}
+		}
+	}
+	return nil
+}
+
+// moveDefault makes id the default, displacing the defaults whose periods
+// overlap its own
+func (r *fakePackConfigurationRepository) moveDefault(tenant string, id int) {
+	target := r.configs[id]
+	for _, config := range r.configs {
+		if config.Tenant == tenant && config.ID != id && config.Overlaps(target.EffectivePeriod) {
+			config.IsDefault = false
+		}
+	}
+	target.IsDefault = true
+}
+
+func (r *fakePackConfigurationRepository) FindByPackSizes(ctx context.Context, tenant string, packSizes []int) ([]*entity.PackConfiguration, error) {
+	var configs []*entity.PackConfiguration
+	for _, config := range r.configs {
+		if config.Tenant == tenant && config.IsActive && slices.Equal(config.PackSizes, packSizes) {
+			configs = append(configs, config)
+		}
+	}
+	slices.SortFunc(configs, func(a, b *entity.PackConfiguration) int { return a.ID - b.ID })
+	return configs, nil
+}
+
+func (r *fakePackConfigurationRepository) Create(ctx context.Context, config *entity.PackConfiguration) (*entity.PackConfiguration, error) {
+	if err := r.nameConflict(config.Tenant, config.Name, 0); err != nil {
+		return nil, err
+	}
+	config.ID = r.nextID
+	r.nextID++
+	r.configs[config.ID] = config
+	r.wroteAs(ctx)
+	return r.GetByID(ctx, config.Tenant, config.ID)
+}
+
+// checkVersion mirrors the repository's conditional writes
+func (r *fakePackConfigurationRepository) checkVersion(tenant string, id int, version int) error {
+	current, err := r.GetByID(context.Background(), tenant, id)
+	if err != nil {
+		return err
+	}
+	if current.Version != version {
+		return &errs.VersionConflictError{ID: id, ExpectedVersion: version, CurrentVersion: current.Version}
+	}
+	return nil
+}
+
+func (r *fakePackConfigurationRepository) Update(ctx context.Context, config *entity.PackConfiguration) (*entity.PackConfiguration, error) {
+	if err := r.checkVersion(config.Tenant, config.ID, config.Version); err != nil {
+		return nil, err
+	}
+	if err := r.nameConflict(config.Tenant, config.Name, config.ID); err != nil {
+		return nil, err
+	}
+	makeDefault := config.IsDefault
+	config.Version = r.configs[config.ID].Version + 1
+	config.IsDefault = r.configs[config.ID].IsDefault
+	if config.IsDefault {
+		if err := r.defaultOverlap(config.Tenant, config.ID, config.EffectivePeriod); err != nil {
+			return nil, err
+		}
+	}
+	r.configs[config.ID] = config
+	if makeDefault {
+		r.moveDefault(config.Tenant, config.ID)
+	}
+	r.wroteAs(ctx)
+	return r.GetByID(ctx, config.Tenant, config.ID)
+}
+
+func (r *fakePackConfigurationRepository) Delete(ctx context.Context, tenant string, id int, version int) error {
+	if err := r.checkVersion(tenant, id, version); err != nil {
+		return err
+	}
+	r.configs[id].IsActive = false
+	r.wroteAs(ctx)
+	return nil
+}
+
+func (r *fakePackConfigurationRepository) SetDefault(ctx context.Context, tenant string, id int, version int) error {
+	if err := r.checkVersion(tenant, id, version); err != nil {
+		return err
+	}
+	r.moveDefault(tenant, id)
+	r.wroteAs(ctx)
+	return nil
+}
+
+func (r *fakePackConfigurationRepository) GetVersions(ctx context.Context, tenant string, id int) ([]*entity.PackConfigurationVersion, error) {
+	return nil, nil
+}
+
+func (r *fakePackConfigurationRepository) GetVersion(ctx context.Context, tenant string, id int, version int) (*entity.PackConfigurationVersion, error) {
+	return nil, errs.ErrConfigurationVersionNotFound
+}
+
+func (r *fakePackConfigurationRepository) RestoreVersion(ctx context.Context, tenant string, id int, version int) (*entity.PackConfiguration, error) {
+	return nil, errs.ErrConfigurationVersionNotFound
+}
+
+func (r *fakePackConfigurationRepository) GetArchivedByID(ctx context.Context, tenant string, id int) (*entity.PackConfiguration, error) {
+	config, ok := r.configs[id]
+	if !ok || config.IsActive || config.Tenant != tenant {
+		return nil, fmt.Errorf("archived pack configuration with id %d: %w", id, errs.ErrPackConfigurationNotFound)
+	}
+	copied := *config
+	return &copied, nil
+}
+
+func (r *fakePackConfigurationRepository) Restore(ctx context.Context, tenant string, id int, name string) (*entity.PackConfiguration, error) {
+	if _, err := r.GetArchivedByID(ctx, tenant, id); err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = r.configs[id].Name
+	}
+	if err := r.nameConflict(tenant, name, id); err != nil {
+		return nil, err
+	}
+	r.configs[id].Name = name
+	r.configs[id].IsActive = true
+	r.wroteAs(ctx)
+	return r.GetByID(ctx, tenant, id)
+}
+
+func (r *fakePackConfigurationRepository) Purge(ctx context.Context, tenant string, id int) error {
+	if _, err := r.GetByID(ctx, tenant, id); err == nil {
+		return errs.ErrConfigurationNotArchived.Withf("pack configuration %d must be deleted before it is purged", id)
+	}
+	if _, err := r.GetArchivedByID(ctx, tenant, id); err != nil {
+		return err
+	}
+	delete(r.configs, id)
+	r.wroteAs(ctx)
+	return nil
+}
+
+func (r *fakePackConfigurationRepository) Import(ctx context.Context, imp entity.PackConfigurationImport) error {
+	for _, config := range imp.Updates {
+		if err := r.checkVersion(imp.Tenant, config.ID, config.Version); err != nil {
+			return err
+		}
+	}
+	defaultID := imp.DefaultID
+	for _, config := range imp.Creates {
+		config.ID = r.nextID
+		r.nextID++
+		if config.IsDefault {
+			defaultID = config.ID
+		}
+		config.IsDefault = false
+		r.configs[config.ID] = config
+	}
+	for _, config := range imp.Updates {
+		config.Version++
+		if config.IsDefault {
+			defaultID = config.ID
+		}
+		config.IsDefault = r.configs[config.ID].IsDefault
+		r.configs[config.ID] = config
+	}
+	if defaultID != 0 {
+		r.moveDefault(imp.Tenant, defaultID)
+	}
+	r.wroteAs(ctx)
+	return nil
+}
+
+type fakePackTypeRepository struct {
+	packTypes map[int]*entity.PackType
+}
+
+// newFakePackTypeRepository seeds pack types, placing those without a tenant
+// in the default tenant
+func newFakePackTypeRepository(packTypes ...*entity.PackType) *fakePackTypeRepository {
+	repo := &fakePackTypeRepository{packTypes: map[int]*entity.PackType{}}
+	for _, packType := range packTypes {
+		if packType.Tenant == "" {
+			packType.Tenant = entity.DefaultTenant
+		}
+		repo.packTypes[packType.ID] = packType
+	}
+	return repo
+}
+
+func (r *fakePackTypeRepository) GetAll(ctx context.Context, tenant string, includeInactive bool) ([]*entity.PackType, error) {
+	var packTypes []*entity.PackType
+	for _, packType := range r.packTypes {
+		if packType.Tenant == tenant && (packType.IsActive || includeInactive) {
+			packTypes = append(packTypes, packType)
+		}
+	}
+	return packTypes, nil
+}
+
+func (r *fakePackTypeRepository) GetByID(ctx context.Context, tenant string, id int) (*entity.PackType, error) {
+	packType, ok := r.packTypes[id]
+	if !ok || packType.Tenant != tenant {
+		return nil, fmt.Errorf("pack type with id %d: %w", id, errs.ErrPackTypeNotFound)
+	}
+	return packType, nil
+}
+
+func (r *fakePackTypeRepository) GetByIDs(ctx context.Context, tenant string, ids []int) ([]*entity.PackType, error) {
+	var packTypes []*entity.PackType
+	for _, id := range ids {
+		if packType, err := r.GetByID(ctx, tenant, id); err == nil {
+			packTypes = append(packTypes, packType)
+		}
+	}
+	return packTypes, nil
+}
+
+func (r *fakePackTypeRepository) GetByCodes(ctx context.Context, tenant string, codes []string) ([]*entity.PackType, error) {
+	var packTypes []*entity.PackType
+	for _, packType := range r.packTypes {
+		if packType.Tenant == tenant && packType.IsActive && slices.ContainsFunc(codes, func(code string) bool {
+			return strings.EqualFold(code, packType.Code)
+		}) {
+			packTypes = append(packTypes, packType)
+		}
+	}
+	return packTypes, nil
+}
+
+func (r *fakePackTypeRepository) Create(ctx context.Context, packType *entity.PackType) (*entity.PackType, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (r *fakePackTypeRepository) Update(ctx context.Context, packType *entity.PackType) (*entity.PackType, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (r *fakePackTypeRepository) Delete(ctx context.Context, tenant string, id int) error {
+	return fmt.Errorf("not implemented")
+}
+
+func TestPackConfigurationServiceWritesUnderTheActor(t *testing.T) {
+	actor := entity.Actor{Subject: "alice", Tenant: entity.DefaultTenant, RequestID: "req-1"}
+
+	t.Run("create", func(t *testing.T) {
+		repo := newFakePackConfigurationRepository()
+		service := NewPackConfigurationService(repo, newFakePackTypeRepository(), false)
+
+		_, err := service.CreateConfiguration(t.Context(), actor, "Standard", []int{250, 500}, nil, entity.EffectivePeriod{}, entity.PackConfigurationDetails{})
+		require.NoError(t, err)
+		assert.Equal(t, []entity.Actor{actor}, repo.actors)
+	})
+
+	t.Run("set default", func(t *testing.T) {
+		repo := newFakePackConfigurationRepository(
+			&entity.PackConfiguration{ID: 1, Name: "Old", PackSizes: []int{1}, IsDefault: true, IsActive: true},
+			&entity.PackConfiguration{ID: 2, Name: "New", PackSizes: []int{2}, IsActive: true},
+		)
+		service := NewPackConfigurationService(repo, newFakePackTypeRepository(), false)
+
+		require.NoError(t, service.SetDefaultConfiguration(t.Context(), actor, 2, 0))
+		assert.Equal(t, []entity.Actor{actor}, repo.actors)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		repo := newFakePackConfigurationRepository(
+			&entity.PackConfiguration{ID: 3, Name: "Spare", PackSizes: []int{5}, IsActive: true},
+		)
+		service := NewPackConfigurationService(repo, newFakePackTypeRepository(), false)
+
+		require.NoError(t, service.DeleteConfiguration(t.Context(), actor, 3, 0))
+		assert.Equal(t, []entity.Actor{actor}, repo.actors)
+	})
+
+	t.Run("failed change writes nothing", func(t *testing.T) {
+		repo := newFakePackConfigurationRepository(
+			&entity.PackConfiguration{ID: 1, Name: "Default", PackSizes: []int{1}, IsDefault: true, IsActive: true},
+		)
+		service := NewPackConfigurationService(repo, newFakePackTypeRepository(), false)
+
+		assert.Error(t, service.DeleteConfiguration(t.Context(), actor, 1, 0))
+		assert.ErrorIs(t, service.DeleteConfiguration(t.Context(), actor, 99, 0), errs.ErrPackConfigurationNotFound)
+		assert.Empty(t, repo.actors)
+	})
+}
+
+func TestPackConfigurationServiceRestoreAndPurge(t *testing.T) {
+	actor := entity.Actor{Subject: "admin", Tenant: entity.DefaultTenant}
+
+	newRepo := func() *fakePackConfigurationRepository {
+		return newFakePackConfigurationRepository(
+			&entity.PackConfiguration{ID: 1, Name: "Standard", PackSizes: []int{250}, IsDefault: true, IsActive: true},
+			&entity.PackConfiguration{ID: 2, Name: "Standard", PackSizes: []int{500}, IsActive: false},
+		)
+	}
+
+	t.Run("restore under a clashing name conflicts", func(t *testing.T) {
+		repo := newRepo()
+		service := NewPackConfigurationService(repo, newFakePackTypeRepository(), false)
+
+		_, err := service.RestoreConfiguration(t.Context(), actor, 2, "")
+		assert.ErrorIs(t, err, errs.ErrConfigurationNameConflict)
+		assert.Empty(t, repo.actors)
+	})
+
+	t.Run("restore under a new name is written under the actor", func(t *testing.T) {
+		repo := newRepo()
+		service := NewPackConfigurationService(repo, newFakePackTypeRepository(), false)
+
+		restored, err := service.RestoreConfiguration(t.Context(), actor, 2, "Standard (restored)")
+		require.NoError(t, err)
+		assert.True(t, restored.IsActive)
+		assert.Equal(t, "Standard (restored)", restored.Name)
+		assert.Equal(t, []entity.Actor{actor}, repo.actors)
+	})
+
+	t.Run("restore of an active configuration is not found", func(t *testing.T) {
+		service := NewPackConfigurationService(newRepo(), newFakePackTypeRepository(), false)
+
+		_, err := service.RestoreConfiguration(t.Context(), actor, 1, "")
+		assert.ErrorIs(t, err, errs.ErrPackConfigurationNotFound)
+	})
+
+	t.Run("purge requires the configuration to be archived", func(t *testing.T) {
+		repo := newRepo()
+		service := NewPackConfigurationService(repo, newFakePackTypeRepository(), false)
+
+		assert.ErrorIs(t, service.PurgeConfiguration(t.Context(), actor, 1), errs.ErrConfigurationNotArchived)
+		assert.ErrorIs(t, service.PurgeConfiguration(t.Context(), actor, 99), errs.ErrPackConfigurationNotFound)
+
+		require.NoError(t, service.PurgeConfiguration(t.Context(), actor, 2))
+		assert.NotContains(t, repo.configs, 2)
+
+		require.Len(t, repo.actors, 1)
+	})
+}
+
+func TestPackConfigurationServiceListConfigurations(t *testing.T) {
+	repo := newFakePackConfigurationRepository(
+		&entity.PackConfiguration{ID: 1, Name: "Standard", PackSizes: []int{250}, IsActive: true},
+		&entity.PackConfiguration{ID: 2, Name: "Old", PackSizes: []int{500}, IsActive: false},
+	)
+	service := NewPackConfigurationService(repo, newFakePackTypeRepository(), false)
+
+	configurations, total, err := service.ListConfigurations(t.Context(), entity.PackConfigurationQuery{Tenant: entity.DefaultTenant, Archived: true})
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, configurations, 1)
+	assert.Equal(t, "Old", configurations[0].Name)
+
+	zero := 0
+	from := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	invalid := []entity.PackConfigurationQuery{
+		{PackSize: &zero},
+		{CreatedFrom: &from, CreatedTo: &to},
+		{Sort: "pack_sizes"},
+	}
+	for _, query := range invalid {
+		_, _, err := service.ListConfigurations(t.Context(), query)
+		assert.ErrorIs(t, err, errs.ErrInvalidConfigurationQuery)
+	}
+}
+
+func TestNormalizeQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    entity.PackConfigurationQuery
+		expected entity.PackConfigurationQuery
+	}{
+		{
+			name:     "defaults the page size",
+			query:    entity.PackConfigurationQuery{},
+			expected: entity.PackConfigurationQuery{Limit: DefaultPageSize},
+		},
+		{
+			name:     "caps the page size",
+			query:    entity.PackConfigurationQuery{Limit: MaxPageSize + 1, Offset: -5},
+			expected: entity.PackConfigurationQuery{Limit: MaxPageSize},
+		},
+		{
+			name:     "trims the search term",
+			query:    entity.PackConfigurationQuery{Search: "  Stan ", Limit: 10, Offset: 20},
+			expected: entity.PackConfigurationQuery{Search: "Stan", Limit: 10, Offset: 20},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NormalizeQuery(tt.query))
+		})
+	}
+}
+
+func TestPackConfigurationServiceVersionConflicts(t *testing.T) {
+	actor := entity.Actor{Subject: "alice", Tenant: entity.DefaultTenant}
+
+	newService := func() (*PackConfigurationService, *fakePackConfigurationRepository) {
+		repo := newFakePackConfigurationRepository(
+			&entity.PackConfiguration{ID: 1, Name: "Standard", PackSizes: []int{250}, IsDefault: true, IsActive: true, Version: 3},
+			&entity.PackConfiguration{ID: 2, Name: "Spare", PackSizes: []int{500}, IsActive: true, Version: 1},
+		)
+		return NewPackConfigurationService(repo, newFakePackTypeRepository(), false), repo
+	}
+
+	t.Run("stale update is rejected with the current version", func(t *testing.T) {
+		service, repo := newService()
+
+		_, err := service.UpdateConfiguration(t.Context(), actor, 1, 2, "Standard", []int{250, 500}, nil, entity.EffectivePeriod{}, entity.PackConfigurationDetails{}, true)
+
+		var conflict *errs.VersionConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.ErrorIs(t, err, errs.ErrConfigurationVersionConflict)
+		assert.Equal(t, 2, conflict.ExpectedVersion)
+		assert.Equal(t, 3, conflict.CurrentVersion)
+		assert.Empty(t, repo.actors)
+	})
+
+	t.Run("matching update bumps the version", func(t *testing.T) {
+		service, _ := newService()
+
+		updated, err := service.UpdateConfiguration(t.Context(), actor, 1, 3, "Standard", []int{250, 500}, nil, entity.EffectivePeriod{}, entity.PackConfigurationDetails{}, true)
+		require.NoError(t, err)
+		assert.Equal(t, 4, updated.Version)
+	})
+
+	t.Run("stale delete and set default are rejected", func(t *testing.T) {
+		service, repo := newService()
+
+		assert.ErrorIs(t, service.DeleteConfiguration(t.Context(), actor, 2, 5), errs.ErrConfigurationVersionConflict)
+		assert.ErrorIs(t, service.SetDefaultConfiguration(t.Context(), actor, 2, 5), errs.ErrConfigurationVersionConflict)
+		assert.Empty(t, repo.actors)
+
+		require.NoError(t, service.DeleteConfiguration(t.Context(), actor, 2, 1))
+	})
+}
+
+func TestPackConfigurationServicePatchConfiguration(t *testing.T) {
+	actor := entity.Actor{Subject: "alice", Tenant: entity.DefaultTenant}
+	name := func(value string) *string { return &value }
+	flag := func(value bool) *bool { return &value }
+
+	newService := func() (*PackConfigurationService, *fakePackConfigurationRepository) {
+		repo := newFakePackConfigurationRepository(
+			&entity.PackConfiguration{ID: 1, Name: "Standard", PackSizes: []int{250, 500}, IsDefault: true, IsActive: true, Version: 3},
+			&entity.PackConfiguration{ID: 2, Name: "Spare", PackSizes: []int{500, 1000}, IsActive: true, Version: 1},
+		)
+		return NewPackConfigurationService(repo, newFakePackTypeRepository(), false), repo
+	}
+
+	t.Run("name only keeps pack sizes and default", func(t *testing.T) {
+		service, repo := newService()
+
+		patched, err := service.PatchConfiguration(t.Context(), actor, 1, 3, entity.PackConfigurationPatch{Name: name("Renamed")})
+		require.NoError(t, err)
+		assert.Equal(t, "Renamed", patched.Name)
+		assert.Equal(t, []int{250, 500}, patched.PackSizes)
+		assert.True(t, patched.IsDefault)
+		assert.Equal(t, 4, patched.Version)
+		require.Len(t, repo.actors, 1)
+	})
+
+	t.Run("add and remove pack sizes", func(t *testing.T) {
+		service, _ := newService()
+
+		patched, err := service.PatchConfiguration(t.Context(), actor, 2, 0, entity.PackConfigurationPatch{
+			AddPackSizes:    []int{2000, 500},
+			RemovePackSizes: []int{1000, 42},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []int{500, 2000}, patched.PackSizes)
+	})
+
+	t.Run("removing every pack size is invalid", func(t *testing.T) {
+		service, repo := newService()
+
+		_, err := service.PatchConfiguration(t.Context(), actor, 2, 0, entity.PackConfigurationPatch{RemovePackSizes: []int{500, 1000}})
+		assert.ErrorIs(t, err, errs.ErrValidation)
+		assert.Empty(t, repo.actors)
+	})
+
+	t.Run("default only moves the flag without a new version", func(t *testing.T) {
+		service, repo := newService()
+
+		patched, err := service.PatchConfiguration(t.Context(), actor, 2, 1, entity.PackConfigurationPatch{IsDefault: flag(true)})
+		require.NoError(t, err)
+		assert.True(t, patched.IsDefault)
+		assert.Equal(t, 1, patched.Version)
+		assert.False(t, repo.configs[1].IsDefault)
+		require.Len(t, repo.actors, 1)
+	})
+
+	t.Run("content and default change together", func(t *testing.T) {
+		service, repo := newService()
+
+		patched, err := service.PatchConfiguration(t.Context(), actor, 2, 1, entity.PackConfigurationPatch{
+			PackSizes: []int{23, 31, 53},
+			IsDefault: flag(true),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []int{23, 31, 53}, patched.PackSizes)
+		assert.True(t, patched.IsDefault)
+		assert.Equal(t, 2, patched.Version)
+		assert.False(t, repo.configs[1].IsDefault)
+	})
+
+	t.Run("unsetting the default is rejected", func(t *testing.T) {
+		service, repo := newService()
+
+		_, err := service.PatchConfiguration(t.Context(), actor, 1, 3, entity.PackConfigurationPatch{IsDefault: flag(false)})
+		assert.ErrorIs(t, err, errs.ErrDefaultConfigurationUnset)
+		assert.ErrorIs(t, err, errs.ErrConflict)
+
+		_, err = service.UpdateConfiguration(t.Context(), actor, 1, 3, "Standard", []int{250}, nil, entity.EffectivePeriod{}, entity.PackConfigurationDetails{}, false)
+		assert.ErrorIs(t, err, errs.ErrDefaultConfigurationUnset)
+		assert.Empty(t, repo.actors)
+	})
+
+	t.Run("empty patch changes nothing", func(t *testing.T) {
+		service, repo := newService()
+
+		patched, err := service.PatchConfiguration(t.Context(), actor, 2, 1, entity.PackConfigurationPatch{
+			Name:      name("Spare"),
+			IsDefault: flag(false),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, patched.Version)
+		assert.Empty(t, repo.actors)
+	})
+
+	t.Run("stale patch is rejected", func(t *testing.T) {
+		service, _ := newService()
+
+		_, err := service.PatchConfiguration(t.Context(), actor, 1, 2, entity.PackConfigurationPatch{Name: name("Renamed")})
+		assert.ErrorIs(t, err, errs.ErrConfigurationVersionConflict)
+	})
+}
+
+func TestPackConfigurationServiceImportConfigurations(t *testing.T) {
+	actor := entity.Actor{Subject: "alice", Tenant: entity.DefaultTenant}
+	created := time.Date(2024, 1, 2, 3, 4, 5, 123456000, time.UTC)
+
+	newService := func() (*PackConfigurationService, *fakePackConfigurationRepository) {
+		repo := newFakePackConfigurationRepository(
+			&entity.PackConfiguration{ID: 1, Name: "Standard", PackSizes: []int{250, 500}, IsDefault: true, IsActive: true, Version: 3},
+			&entity.PackConfiguration{ID: 2, Name: "Spare", PackSizes: []int{500, 1000}, IsActive: true, Version: 1},
+		)
+		return NewPackConfigurationService(repo, newFakePackTypeRepository(), false), repo
+	}
+
+	t.Run("creates keep file timestamps", func(t *testing.T) {
+		service, repo := newService()
+
+		results, err := service.ImportConfigurations(t.Context(), actor, []entity.PackConfigurationImportRow{
+			{Name: "Bulk", PackSizes: []int{5000}, CreatedAt: &created, UpdatedAt: &created},
+		}, entity.ImportOptions{})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, entity.ImportActionCreate, results[0].Action)
+		assert.Equal(t, 3, results[0].ID)
+		assert.Equal(t, created, repo.configs[3].CreatedAt)
+		assert.Equal(t, created, repo.configs[3].UpdatedAt)
+		require.Len(t, repo.actors, 1)
+	})
+
+	t.Run("existing names need upsert", func(t *testing.T) {
+		service, repo := newService()
+
+		_, err := service.ImportConfigurations(t.Context(), actor, []entity.PackConfigurationImportRow{
+			{Name: "Bulk", PackSizes: []int{5000}},
+			{Name: "Spare", PackSizes: []int{750}},
+		}, entity.ImportOptions{})
+		assert.ErrorIs(t, err, errs.ErrInvalidImport)
+
+		var invalid *errs.InvalidFieldsError
+		require.ErrorAs(t, err, &invalid)
+		require.Len(t, invalid.Fields, 1)
+		assert.Equal(t, "configurations[1].name", invalid.Fields[0].Field)
+		assert.Empty(t, repo.actors)
+	})
+
+	t.Run("upsert updates, skips unchanged rows and moves the default", func(t *testing.T) {
+		service, repo := newService()
+
+		results, err := service.ImportConfigurations(t.Context(), actor, []entity.PackConfigurationImportRow{
+			{Name: "Standard", PackSizes: []int{250, 500}},
+			{Name: "Spare", PackSizes: []int{750}, IsDefault: true},
+		}, entity.ImportOptions{Upsert: true})
+		require.NoError(t, err)
+		assert.Equal(t, entity.ImportActionUnchanged, results[0].Action)
+		assert.Equal(t, entity.ImportActionUpdate, results[1].Action)
+		assert.Equal(t, []int{750}, repo.configs[2].PackSizes)
+		assert.Equal(t, 2, repo.configs[2].Version)
+		assert.True(t, repo.configs[2].IsDefault)
+		assert.False(t, repo.configs[1].IsDefault)
+		assert.Equal(t, []entity.Actor{actor}, repo.actors)
+	})
+
+	t.Run("default can move onto an unchanged configuration", func(t *testing.T) {
+		service, repo := newService()
+
+		results, err := service.ImportConfigurations(t.Context(), actor, []entity.PackConfigurationImportRow{
+			{Name: "Spare", PackSizes: []int{500, 1000}, IsDefault: true},
+		}, entity.ImportOptions{Upsert: true})
+		require.NoError(t, err)
+		assert.Equal(t, entity.ImportActionUnchanged, results[0].Action)
+		assert.True(t, repo.configs[2].IsDefault)
+		require.Len(t, repo.actors, 1)
+	})
+
+	t.Run("dry run reports without writing", func(t *testing.T) {
+		service, repo := newService()
+
+		results, err := service.ImportConfigurations(t.Context(), actor, []entity.PackConfigurationImportRow{
+			{Name: "Bulk", PackSizes: []int{5000}},
+			{Name: "Spare", PackSizes: []int{750}},
+		}, entity.ImportOptions{Upsert: true, DryRun: true})
+		require.NoError(t, err)
+		assert.Equal(t, entity.ImportActionCreate, results[0].Action)
+		assert.Zero(t, results[0].ID)
+		assert.Equal(t, entity.ImportActionUpdate, results[1].Action)
+		assert.Equal(t, 2, results[1].ID)
+		assert.Len(t, repo.configs, 2)
+		assert.Equal(t, []int{500, 1000}, repo.configs[2].PackSizes)
+		assert.Empty(t, repo.actors)
+	})
+
+	t.Run("every invalid row is reported and nothing is written", func(t *testing.T) {
+		service, repo := newService()
+
+		_, err := service.ImportConfigurations(t.Context(), actor, []entity.PackConfigurationImportRow{
+			{Name: "Bulk", PackSizes: []int{5000}, IsDefault: true},
+			{Name: "", PackSizes: []int{10}},
+			{Name: "Tiny", PackSizes: []int{0}},
+			{Name: "Bulk", PackSizes: []int{10}},
+			{Name: "Huge", PackSizes: []int{9000}, IsDefault: true},
+		}, entity.ImportOptions{})
+
+		var invalid *errs.InvalidFieldsError
+		require.ErrorAs(t, err, &invalid)
+		fields := make([]string, len(invalid.Fields))
+		for i, field := range invalid.Fields {
+			fields[i] = field.Field
+		}
+		assert.Equal(t, []string{
+			"configurations[1].name",
+			"configurations[2].pack_sizes",
+			"configurations[3].name",
+			"configurations[4].is_default",
+		}, fields)
+		assert.Len(t, repo.configs, 2)
+	})
+
+	t.Run("taking the flag off the default is rejected", func(t *testing.T) {
+		service, _ := newService()
+
+		_, err := service.ImportConfigurations(t.Context(), actor, []entity.PackConfigurationImportRow{
+			{Name: "Standard", PackSizes: []int{250, 500}},
+		}, entity.ImportOptions{Upsert: true})
+
+		var invalid *errs.InvalidFieldsError
+		require.ErrorAs(t, err, &invalid)
+		assert.Equal(t, "configurations[0].is_default", invalid.Fields[0].Field)
+	})
+}
+
+func TestPackConfigurationServiceUniqueNames(t *testing.T) {
+	actor := entity.Actor{Subject: "alice", Tenant: entity.DefaultTenant}
+
+	newService := func() (*PackConfigurationService, *fakePackConfigurationRepository) {
+		repo := newFakePackConfigurationRepository(
+			&entity.PackConfiguration{ID: 1, Name: "Standard Packs", PackSizes: []int{250, 500}, IsDefault: true, IsActive: true, Version: 3},
+			&entity.PackConfiguration{ID: 2, Name: "Spare", PackSizes: []int{500, 1000}, IsActive: true, Version: 1},
+		)
+		return NewPackConfigurationService(repo, newFakePackTypeRepository(), false), repo
+	}
+
+	t.Run("create reports the existing configuration", func(t *testing.T) {
+		service, _ := newService()
+
+		_, err := service.CreateConfiguration(t.Context(), actor, "standard packs", []int{250}, nil, entity.EffectivePeriod{}, entity.PackConfigurationDetails{})
+		assert.ErrorIs(t, err, errs.ErrConfigurationNameConflict)
+		assert.ErrorIs(t, err, errs.ErrConflict)
+
+		var conflict *errs.NameConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, 1, conflict.ExistingID)
+	})
+
+	t.Run("rename onto another name conflicts", func(t *testing.T) {
+		service, _ := newService()
+
+		_, err := service.UpdateConfiguration(t.Context(), actor, 2, 1, "STANDARD PACKS", []int{500}, nil, entity.EffectivePeriod{}, entity.PackConfigurationDetails{}, false)
+		assert.ErrorIs(t, err, errs.ErrConfigurationNameConflict)
+	})
+
+	t.Run("upsert creates a new name", func(t *testing.T) {
+		service, _ := newService()
+
+		configuration, created, err := service.UpsertConfiguration(t.Context(), actor, "Bulk", []int{5000}, nil, entity.EffectivePeriod{}, entity.PackConfigurationDetails{})
+		require.NoError(t, err)
+		assert.True(t, created)
+		assert.Equal(t, 3, configuration.ID)
+	})
+
+	t.Run("upsert updates the existing configuration", func(t *testing.T) {
+		service, repo := newService()
+
+		configuration, created, err := service.UpsertConfiguration(t.Context(), actor, "Standard Packs", []int{250, 500, 1000}, nil, entity.EffectivePeriod{}, entity.PackConfigurationDetails{})
+		require.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, 1, configuration.ID)
+		assert.Equal(t, []int{250, 500, 1000}, configuration.PackSizes)
+		assert.Equal(t, 4, configuration.Version)
+		assert.True(t, configuration.IsDefault)
+		require.Len(t, repo.actors, 1)
+	})
+
+	t.Run("upsert with identical contents writes nothing", func(t *testing.T) {
+		service, repo := newService()
+
+		configuration, created, err := service.UpsertConfiguration(t.Context(), actor, "Spare", []int{500, 1000}, nil, entity.EffectivePeriod{}, entity.PackConfigurationDetails{})
+		require.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, 1, configuration.Version)
+		assert.Empty(t, repo.actors)
+	})
+
+	t.Run("import matches names regardless of case", func(t *testing.T) {
+		service, _ := newService()
+
+		results, err := service.ImportConfigurations(t.Context(), actor, []entity.PackConfigurationImportRow{
+			{Name: "SPARE", PackSizes: []int{500, 1000}},
+		}, entity.ImportOptions{Upsert: true})
+		require.NoError(t, err)
+		assert.Equal(t, entity.ImportActionUpdate, results[0].Action)
+		assert.Equal(t, 2, results[0].ID)
+
+		_, err = service.ImportConfigurations(t.Context(), actor, []entity.PackConfigurationImportRow{
+			{Name: "Bulk", PackSizes: []int{5000}},
+			{Name: "bulk", PackSizes: []int{10}},
+		}, entity.ImportOptions{})
+		var invalid *errs.InvalidFieldsError
+		require.ErrorAs(t, err, &invalid)
+		assert.Equal(t, "configurations[1].name", invalid.Fields[0].Field)
+	})
+}
+
+func TestPackConfigurationServiceDuplicatePackSizes(t *testing.T) {
+	actor := entity.Actor{Subject: "alice", Tenant: entity.DefaultTenant}
+
+	newService := func(allowDuplicates bool) (*PackConfigurationService, *fakePackConfigurationRepository) {
+		repo := newFakePackConfigurationRepository(
+			&entity.PackConfiguration{ID: 1, Name: "Standard Packs", PackSizes: []int{250, 500}, IsDefault: true, IsActive: true, Version: 1},
+			&entity.PackConfiguration{ID: 2, Name: "Spare", PackSizes: []int{500, 1000}, IsActive: true, Version: 1},
+		)
+		return NewPackConfigurationService(repo, newFakePackTypeRepository(), allowDuplicates), repo
+	}
+
+	t.Run("create stores canonical sizes", func(t *testing.T) {
+		service, _ := newService(false)
+
+		configuration, err := service.CreateConfiguration(t.Context(), actor, "Bulk", []int{5000, 2000, 5000}, nil, entity.EffectivePeriod{}, entity.PackConfigurationDetails{})
+		require.NoError(t, err)
+		assert.Equal(t, []int{2000, 5000}, configuration.PackSizes)
+	})
+
+	t.Run("create rejects a set another configuration uses", func(t *testing.T) {
+		service, _ := newService(false)
+
+		_, err := service.CreateConfiguration(t.Context(), actor, "Copy", []int{500, 250, 250}, nil, entity.EffectivePeriod{}, entity.PackConfigurationDetails{})
+		assert.ErrorIs(t, err, errs.ErrDuplicatePackSizes)
+		assert.ErrorIs(t, err, errs.ErrConflict)
+
+		var duplicate *errs.DuplicatePackSizesError
+		require.ErrorAs(t, err, &duplicate)
+		assert.Equal(t, 1, duplicate.ExistingID)
+		assert.Equal(t, []int{250, 500}, duplicate.PackSizes)
+	})
+
+	t.Run("allow mode accepts duplicates", func(t *testing.T) {
+		service, _ := newService(true)
+
+		configuration, err := service.CreateConfiguration(t.Context(), actor, "Copy", []int{500, 250}, nil, entity.EffectivePeriod{}, entity.PackConfigurationDetails{})
+		require.NoError(t, err)
+		assert.Equal(t, []int{250, 500}, configuration.PackSizes)
+	})
+
+	t.Run("update onto another set is rejected", func(t *testing.T) {
+		service, _ := newService(false)
+
+		_, err := service.UpdateConfiguration(t.Context(), actor, 2, 1, "Spare", []int{250, 500}, nil, entity.EffectivePeriod{}, entity.PackConfigurationDetails{}, false)
+		assert.ErrorIs(t, err, errs.ErrDuplicatePackSizes)
+
+		_, err = service.PatchConfiguration(t.Context(), actor, 2, 1, entity.PackConfigurationPatch{RemovePackSizes: []int{1000}, AddPackSizes: []int{250}})
+		assert.ErrorIs(t, err, errs.ErrDuplicatePackSizes)
+	})
+
+	t.Run("configurations sharing sizes can still be renamed", func(t *testing.T) {
+		service, repo := newService(false)
+		repo.configs[3] = &entity.PackConfiguration{ID: 3, Tenant: entity.DefaultTenant, Name: "Legacy", PackSizes: []int{250, 500}, IsActive: true, Version: 1}
+
+		configuration, err := service.UpdateConfiguration(t.Context(), actor, 3, 1, "Legacy Packs", []int{500, 250}, nil, entity.EffectivePeriod{}, entity.PackConfigurationDetails{}, false)
+		require.NoError(t, err)
+		assert.Equal(t, "Legacy Packs", configuration.Name)
+	})
+
+	t.Run("upsert reports the configuration holding the sizes", func(t *testing.T) {
+		service, _ := newService(false)
+
+		_, _, err := service.UpsertConfiguration(t.Context(), actor, "Spare", []int{250, 500}, nil, entity.EffectivePeriod{}, entity.PackConfigurationDetails{})
+		var duplicate *errs.DuplicatePackSizesError
+		require.ErrorAs(t, err, &duplicate)
+		assert.Equal(t, 1, duplicate.ExistingID)
+	})
+
+	t.Run("import rejects duplicates of configurations and of other rows", func(t *testing.T) {
+		service, _ := newService(false)
+
+		_, err := service.ImportConfigurations(t.Context(), actor, []entity.PackConfigurationImportRow{
+			{Name: "Copy", PackSizes: []int{500, 250}},
+			{Name: "Bulk", PackSizes: []int{5000}},
+			{Name: "More Bulk", PackSizes: []int{5000, 5000}},
+			{Name: "Spare", PackSizes: []int{500, 1000}},
+		}, entity.ImportOptions{Upsert: true})
+
+		var invalid *errs.InvalidFieldsError
+		require.ErrorAs(t, err, &invalid)
+		require.Len(t, invalid.Fields, 2)
+		assert.Equal(t, "configurations[0].pack_sizes", invalid.Fields[0].Field)
+		assert.Contains(t, invalid.Fields[0].Message, "pack configuration 1")
+		assert.Equal(t, "configurations[2].pack_sizes", invalid.Fields[1].Field)
+		assert.Equal(t, "duplicate configurations[1]", invalid.Fields[1].Message)
+	})
+}
+
+func TestPackConfigurationServiceFindEquivalentConfigurations(t *testing.T) {
+	repo := newFakePackConfigurationRepository(
+		&entity.PackConfiguration{ID: 1, Name: "Standard Packs", PackSizes: []int{250, 500, 1000}, IsDefault: true, IsActive: true, Version: 1},
+		&entity.PackConfiguration{ID: 2, Name: "Quarters", PackSizes: []int{250}, IsActive: true, Version: 1},
+		&entity.PackConfiguration{ID: 3, Name: "Mixed", PackSizes: []int{250, 750}, IsActive: true, Version: 1},
+		&entity.PackConfiguration{ID: 4, Name: "Odd", PackSizes: []int{250, 600}, IsActive: true, Version: 1},
+		&entity.PackConfiguration{ID: 5, Name: "Exact", PackSizes: []int{250, 500, 1000}, IsActive: true, Version: 1},
+	)
+	service := NewPackConfigurationService(repo, newFakePackTypeRepository(), true)
+
+	equivalence, err := service.FindEquivalentConfigurations(t.Context(), entity.DefaultTenant, []int{1000, 500, 250, 500})
+	require.NoError(t, err)
+	assert.Equal(t, []int{250, 500, 1000}, equivalence.PackSizes)
+	assert.Equal(t, []int{250}, equivalence.MinimalPackSizes)
+	assert.Equal(t, []int{500, 1000}, equivalence.RedundantPackSizes)
+
+	var found []string
+	for _, equivalent := range equivalence.Configurations {
+		found = append(found, fmt.Sprintf("%d:%s", equivalent.Configuration.ID, equivalent.Match))
+	}
+	assert.Equal(t, []string{"1:identical", "5:identical", "2:equivalent", "3:equivalent"}, found)
+	assert.Equal(t, []int{750}, equivalence.Configurations[3].RedundantPackSizes)
+
+	_, err = service.FindEquivalentConfigurations(t.Context(), entity.DefaultTenant, []int{250, 0})
+	assert.ErrorIs(t, err, errs.ErrInvalidPackConfiguration)
+}
+
+func TestReducePackSizes(t *testing.T) {
+	tests := []struct {
+		name      string
+		packSizes []int
+		minimal   []int
+		redundant []int
+	}{
+		{name: "single size", packSizes: []int{23}, minimal: []int{23}},
+		{name: "multiples", packSizes: []int{250, 500, 1000, 2000, 5000}, minimal: []int{250}, redundant: []int{500, 1000, 2000, 5000}},
+		{name: "sums of smaller sizes", packSizes: []int{6, 9, 20, 15, 21, 27, 44}, minimal: []int{6, 9, 20}, redundant: []int{15, 21, 27, 44}},
+		{name: "coprime sizes are all needed", packSizes: []int{23, 31, 53}, minimal: []int{23, 31, 53}},
+		{name: "common divisor", packSizes: []int{4, 6, 9, 10, 11}, minimal: []int{4, 6, 9, 11}, redundant: []int{10}},
+		{name: "unsorted with duplicates", packSizes: []int{500, 250, 250, 750}, minimal: []int{250}, redundant: []int{500, 750}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			minimal, redundant := entity.ReducePackSizes(tt.packSizes)
+			assert.Equal(t, tt.minimal, minimal)
+			assert.Equal(t, tt.redundant, redundant)
+		})
+	}
+}
+
+func TestPackConfigurationServiceTenantIsolation(t *testing.T) {
+	retail := entity.Actor{Subject: "alice", Tenant: "retail"}
+	wholesale := entity.Actor{Subject: "bob", Tenant: "wholesale"}
+
+	newService := func() (*PackConfigurationService, *fakePackConfigurationRepository) {
+		repo := newFakePackConfigurationRepository(
+			&entity.PackConfiguration{ID: 1, Tenant: "retail", Name: "Standard", PackSizes: []int{250, 500}, IsDefault: true, IsActive: true, Version: 1},
+			&entity.PackConfiguration{ID: 2, Tenant: "wholesale", Name: "Pallets", PackSizes: []int{1000, 5000}, IsDefault: true, IsActive: true, Version: 1},
+		)
+		return NewPackConfigurationService(repo, newFakePackTypeRepository(), false), repo
+	}
+
+	t.Run("another tenant's configuration is not found", func(t *testing.T) {
+		service, _ := newService()
+
+		_, err := service.GetConfigurationByID(t.Context(), "wholesale", 1)
+		assert.ErrorIs(t, err, errs.ErrNotFound)
+
+		_, err = service.UpdateConfiguration(t.Context(), wholesale, 1, 1, "Taken", []int{10}, nil, entity.EffectivePeriod{}, entity.PackConfigurationDetails{}, false)
+		assert.ErrorIs(t, err, errs.ErrNotFound)
+
+		assert.ErrorIs(t, service.DeleteConfiguration(t.Context(), wholesale, 1, 1), errs.ErrNotFound)
+		assert.ErrorIs(t, service.SetDefaultConfiguration(t.Context(), wholesale, 1, 1), errs.ErrNotFound)
+
+		configuration, err := service.GetConfigurationByID(t.Context(), "retail", 1)
+		require.NoError(t, err)
+		assert.Equal(t, "Standard", configuration.Name)
+		assert.Equal(t, 1, configuration.Version)
+	})
+
+	t.Run("each tenant keeps its own default", func(t *testing.T) {
+		service, _ := newService()
+
+		created, err := service.CreateConfiguration(t.Context(), retail, "Bulk", []int{100}, nil, entity.EffectivePeriod{}, entity.PackConfigurationDetails{})
+		require.NoError(t, err)
+		require.NoError(t, service.SetDefaultConfiguration(t.Context(), retail, created.ID, created.Version))
+
+		retailDefault, err := service.GetDefaultConfiguration(t.Context(), "retail", time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, created.ID, retailDefault.ID)
+
+		wholesaleDefault, err := service.GetDefaultConfiguration(t.Context(), "wholesale", time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, 2, wholesaleDefault.ID)
+
+		_, err = service.GetDefaultConfiguration(t.Context(), "outlet", time.Now())
+		assert.ErrorIs(t, err, errs.ErrNoDefaultConfiguration)
+	})
+
+	t.Run("names and pack sizes only clash within a tenant", func(t *testing.T) {
+		service, _ := newService()
+
+		configuration, err := service.CreateConfiguration(t.Context(), wholesale, "Standard", []int{500, 250}, nil, entity.EffectivePeriod{}, entity.PackConfigurationDetails{})
+		require.NoError(t, err)
+		assert.Equal(t, "wholesale", configuration.Tenant)
+
+		_, err = service.CreateConfiguration(t.Context(), retail, "standard", []int{42}, nil, entity.EffectivePeriod{}, entity.PackConfigurationDetails{})
+		assert.ErrorIs(t, err, errs.ErrConfigurationNameConflict)
+	})
+
+	t.Run("list, export and audit are scoped", func(t *testing.T) {
+		service, repo := newService()
+
+		_, err := service.CreateConfiguration(t.Context(), retail, "Bulk", []int{100}, nil, entity.EffectivePeriod{}, entity.PackConfigurationDetails{})
+		require.NoError(t, err)
+
+		configurations, total, err := service.ListConfigurations(t.Context(), entity.PackConfigurationQuery{Tenant: "wholesale"})
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, "Pallets", configurations[0].Name)
+
+		exported, err := service.ExportConfigurations(t.Context(), "retail")
+		require.NoError(t, err)
+		require.Len(t, exported, 2)
+		for _, configuration := range exported {
+			assert.Equal(t, "retail", configuration.Tenant)
+		}
+
+		require.Len(t, repo.actors, 1)
+		assert.Equal(t, "retail", repo.actors[0].Tenant)
+	})
+}
+
+func TestPackConfigurationServiceEffectivePeriods(t *testing.T) {
+	actor := entity.Actor{Subject: "alice", Tenant: entity.DefaultTenant}
+	march := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	june := time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	newService := func() (*PackConfigurationService, *fakePackConfigurationRepository) {
+		repo := newFakePackConfigurationRepository(
+			&entity.PackConfiguration{ID: 1, Name: "Standard", PackSizes: []int{250, 500}, IsDefault: true, IsActive: true, Version: 1,
+				EffectivePeriod: entity.EffectivePeriod{EffectiveTo: &march}},
+			&entity.PackConfiguration{ID: 2, Name: "Bulk", PackSizes: []int{250, 500, 5000}, IsActive: true, Version: 1,
+				EffectivePeriod: entity.EffectivePeriod{EffectiveFrom: &march}},
+		)
+		return NewPackConfigurationService(repo, newFakePackTypeRepository(), false), repo
+	}
+
+	t.Run("periods are half-open", func(t *testing.T) {
+		period := entity.EffectivePeriod{EffectiveFrom: &march, EffectiveTo: &june}
+
+		assert.False(t, period.EffectiveAt(march.Add(-time.Nanosecond)))
+		assert.True(t, period.EffectiveAt(march))
+		assert.False(t, period.EffectiveAt(june))
+		assert.False(t, period.Overlaps(entity.EffectivePeriod{EffectiveFrom: &june}))
+		assert.True(t, period.Overlaps(entity.EffectivePeriod{EffectiveTo: &june}))
+		assert.True(t, entity.EffectivePeriod{}.EffectiveAt(march))
+	})
+
+	t.Run("rejects a period that ends before it starts", func(t *testing.T) {
+		service, _ := newService()
+
+		_, err := service.CreateConfiguration(t.Context(), actor, "Backwards", []int{10}, nil,
+			entity.EffectivePeriod{EffectiveFrom: &june, EffectiveTo: &march}, entity.PackConfigurationDetails{})
+		assert.ErrorIs(t, err, errs.ErrInvalidPackConfiguration)
+	})
+
+	t.Run("defaults with consecutive periods coexist", func(t *testing.T) {
+		service, _ := newService()
+
+		require.NoError(t, service.SetDefaultConfiguration(t.Context(), actor, 2, 1))
+
+		before, err := service.GetDefaultConfiguration(t.Context(), entity.DefaultTenant, march.Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 1, before.ID)
+
+		after, err := service.GetDefaultConfiguration(t.Context(), entity.DefaultTenant, march)
+		require.NoError(t, err)
+		assert.Equal(t, 2, after.ID)
+	})
+
+	t.Run("a new default displaces only the defaults it overlaps", func(t *testing.T) {
+		service, _ := newService()
+
+		require.NoError(t, service.SetDefaultConfiguration(t.Context(), actor, 2, 1))
+		summer, err := service.CreateConfiguration(t.Context(), actor, "Summer", []int{100}, nil,
+			entity.EffectivePeriod{EffectiveFrom: &june}, entity.PackConfigurationDetails{})
+		require.NoError(t, err)
+		require.NoError(t, service.SetDefaultConfiguration(t.Context(), actor, summer.ID, summer.Version))
+
+		standard, err := service.GetConfigurationByID(t.Context(), entity.DefaultTenant, 1)
+		require.NoError(t, err)
+		assert.True(t, standard.IsDefault)
+
+		bulk, err := service.GetConfigurationByID(t.Context(), entity.DefaultTenant, 2)
+		require.NoError(t, err)
+		assert.False(t, bulk.IsDefault)
+
+		_, err = service.GetDefaultConfiguration(t.Context(), entity.DefaultTenant, march)
+		assert.ErrorIs(t, err, errs.ErrNoDefaultConfiguration)
+	})
+
+	t.Run("extending a default over another default is rejected", func(t *testing.T) {
+		service, _ := newService()
+
+		require.NoError(t, service.SetDefaultConfiguration(t.Context(), actor, 2, 1))
+		_, err := service.UpdateConfiguration(t.Context(), actor, 1, 1, "Standard", []int{250, 500}, nil, entity.EffectivePeriod{EffectiveTo: &june}, entity.PackConfigurationDetails{}, true)
+
+		assert.ErrorIs(t, err, errs.ErrDefaultPeriodOverlap)
+		var overlap *errs.DefaultPeriodOverlapError
+		require.ErrorAs(t, err, &overlap)
+		assert.Equal(t, 2, overlap.ExistingID)
+	})
+
+	t.Run("calculating with a configuration out of effect is rejected", func(t *testing.T) {
+		service, _ := newService()
+
+		_, err := service.GetEffectiveConfiguration(t.Context(), entity.DefaultTenant, 2, march.Add(-time.Hour))
+		assert.ErrorIs(t, err, errs.ErrConfigurationNotEffective)
+		assert.ErrorIs(t, err, errs.ErrValidation)
+
+		configuration, err := service.GetEffectiveConfiguration(t.Context(), entity.DefaultTenant, 2, june)
+		require.NoError(t, err)
+		assert.Equal(t, "Bulk", configuration.Name)
+	})
+
+	t.Run("changing the period bumps the version", func(t *testing.T) {
+		service, _ := newService()
+
+		updated, err := service.PatchConfiguration(t.Context(), actor, 2, 1, entity.PackConfigurationPatch{
+			EffectiveFrom: &entity.TimePatch{},
+		})
+		require.NoError(t, err)
+		assert.Nil(t, updated.EffectiveFrom)
+		assert.Equal(t, 2, updated.Version)
+	})
+}
+
+func TestPackConfigurationServiceDetails(t *testing.T) {
+	actor := entity.Actor{Subject: "alice", Tenant: entity.DefaultTenant}
+	eu := "eu"
+
+	newService := func() *PackConfigurationService {
+		repo := newFakePackConfigurationRepository(
+			&entity.PackConfiguration{ID: 1, Name: "Standard", PackSizes: []int{250, 500}, IsDefault: true, IsActive: true, Version: 1,
+				PackConfigurationDetails: entity.PackConfigurationDetails{
+					Labels:   entity.Labels{"customer": "acme", "region": "us"},
+					Metadata: json.RawMessage(`{"owner": "ops", "limits": {"max": 10, "min": 1}}`),
+				}},
+			&entity.PackConfiguration{ID: 2, Name: "Spare", PackSizes: []int{1000}, IsActive: true, Version: 1,
+				PackConfigurationDetails: entity.PackConfigurationDetails{Labels: entity.Labels{"region": "eu"}}},
+		)
+		return NewPackConfigurationService(repo, newFakePackTypeRepository(), false)
+	}
+
+	t.Run("creates with details", func(t *testing.T) {
+		service := newService()
+
+		configuration, err := service.CreateConfiguration(t.Context(), actor, "Bulk", []int{5000}, nil, entity.EffectivePeriod{}, entity.PackConfigurationDetails{
+			Description: "Pallets for wholesale",
+			Labels:      entity.Labels{"customer": "acme"},
+			Metadata:    json.RawMessage(`{"erp_id": 42}`),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Pallets for wholesale", configuration.Description)
+		assert.Equal(t, entity.Labels{"customer": "acme"}, configuration.Labels)
+		assert.JSONEq(t, `{"erp_id": 42}`, string(configuration.Metadata))
+	})
+
+	t.Run("rejects invalid details", func(t *testing.T) {
+		service := newService()
+
+		for _, details := range []entity.PackConfigurationDetails{
+			{Labels: entity.Labels{"bad key": "x"}},
+			{Labels: entity.Labels{"region": "-eu"}},
+			{Metadata: json.RawMessage(`[1, 2]`)},
+			{Description: strings.Repeat("x", 2001)},
+		} {
+			_, err := service.CreateConfiguration(t.Context(), actor, "Bulk", []int{5000}, nil, entity.EffectivePeriod{}, details)
+			assert.ErrorIs(t, err, errs.ErrInvalidPackConfiguration, "%+v", details)
+		}
+	})
+
+	t.Run("patch merges labels and metadata", func(t *testing.T) {
+		service := newService()
+
+		updated, err := service.PatchConfiguration(t.Context(), actor, 1, 1, entity.PackConfigurationPatch{
+			Labels:   map[string]*string{"region": &eu, "customer": nil},
+			Metadata: json.RawMessage(`{"owner": null, "limits": {"max": 20}}`),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, entity.Labels{"region": "eu"}, updated.Labels)
+		assert.JSONEq(t, `{"limits": {"max": 20, "min": 1}}`, string(updated.Metadata))
+		assert.Equal(t, 2, updated.Version)
+	})
+
+	t.Run("patch removes every label and the metadata with null", func(t *testing.T) {
+		service := newService()
+
+		updated, err := service.PatchConfiguration(t.Context(), actor, 1, 1, entity.PackConfigurationPatch{
+			ClearLabels: true,
+			Metadata:    json.RawMessage(`null`),
+		})
+		require.NoError(t, err)
+		assert.Empty(t, updated.Labels)
+		assert.Nil(t, updated.Metadata)
+	})
+
+	t.Run("metadata differing only in formatting is unchanged", func(t *testing.T) {
+		service := newService()
+
+		updated, err := service.PatchConfiguration(t.Context(), actor, 1, 1, entity.PackConfigurationPatch{
+			Metadata: json.RawMessage(`{"limits":{"min":1,"max":10}}`),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, updated.Version)
+	})
+
+	t.Run("lists by label selector", func(t *testing.T) {
+		service := newService()
+
+		selector, err := entity.ParseLabelSelector([]string{"region=eu"})
+		require.NoError(t, err)
+		configurations, total, err := service.ListConfigurations(t.Context(), entity.PackConfigurationQuery{Tenant: entity.DefaultTenant, Labels: selector})
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, "Spare", configurations[0].Name)
+
+		selector, err = entity.ParseLabelSelector([]string{"region=us,customer=acme"})
+		require.NoError(t, err)
+		configurations, _, err = service.ListConfigurations(t.Context(), entity.PackConfigurationQuery{Tenant: entity.DefaultTenant, Labels: selector})
+		require.NoError(t, err)
+		require.Len(t, configurations, 1)
+		assert.Equal(t, "Standard", configurations[0].Name)
+
+		_, _, err = service.ListConfigurations(t.Context(), entity.PackConfigurationQuery{Tenant: entity.DefaultTenant, Labels: entity.Labels{"no spaces": "x"}})
+		assert.ErrorIs(t, err, errs.ErrInvalidConfigurationQuery)
+	})
+
+	t.Run("label selectors must be key=value", func(t *testing.T) {
+		for _, values := range [][]string{{"region"}, {"=eu"}, {"region=eu", "region=us"}} {
+			_, err := entity.ParseLabelSelector(values)
+			assert.Error(t, err, "%v", values)
+		}
+
+		selector, err := entity.ParseLabelSelector(nil)
+		require.NoError(t, err)
+		assert.Nil(t, selector)
+	})
+
+	t.Run("import reports invalid labels by field", func(t *testing.T) {
+		service := newService()
+
+		_, err := service.ImportConfigurations(t.Context(), actor, []entity.PackConfigurationImportRow{{
+			Name:                     "Bulk",
+			PackSizes:                []int{5000},
+			PackConfigurationDetails: entity.PackConfigurationDetails{Labels: entity.Labels{"bad key": "x"}},
+		}}, entity.ImportOptions{})
+
+		var invalid *errs.InvalidFieldsError
+		require.ErrorAs(t, err, &invalid)
+		require.Len(t, invalid.Fields, 1)
+		assert.Equal(t, "configurations[0].labels", invalid.Fields[0].Field)
+	})
+}
+
+func TestPackConfigurationServicePackTypes(t *testing.T) {
+	actor := entity.Actor{Subject: "alice", Tenant: entity.DefaultTenant}
+
+	newService := func() (*PackConfigurationService, *fakePackConfigurationRepository) {
+		repo := newFakePackConfigurationRepository(
+			&entity.PackConfiguration{ID: 1, Name: "Standard", PackSizes: []int{250, 500, 1000}, PackTypeIDs: []int{1, 2},
+				IsDefault: true, IsActive: true, Version: 1},
+		)
+		packTypes := newFakePackTypeRepository(
+			&entity.PackType{ID: 1, Code: "BOX-250", Label: "Box of 250", ItemCount: 250, IsActive: true},
+			&entity.PackType{ID: 2, Code: "BOX-500", Label: "Box of 500", ItemCount: 500, IsActive: true},
+			&entity.PackType{ID: 3, Code: "PALLET", Label: "Pallet", ItemCount: 5000, IsActive: true},
+			&entity.PackType{ID: 4, Code: "CRATE-500", Label: "Crate of 500", ItemCount: 500, IsActive: true},
+			&entity.PackType{ID: 5, Code: "RETIRED", Label: "Retired box", ItemCount: 42, IsActive: false},
+		)
+		return NewPackConfigurationService(repo, packTypes, false), repo
+	}
+
+	t.Run("reads list the referenced pack types", func(t *testing.T) {
+		service, _ := newService()
+
+		configuration, err := service.GetConfigurationByID(t.Context(), entity.DefaultTenant, 1)
+		require.NoError(t, err)
+		require.Len(t, configuration.PackTypes, 2)
+		assert.Equal(t, "BOX-250", configuration.PackType(250).Code)
+		assert.Nil(t, configuration.PackType(1000))
+	})
+
+	t.Run("pack types add their item counts to the pack sizes", func(t *testing.T) {
+		service, _ := newService()
+
+		configuration, err := service.CreateConfiguration(t.Context(), actor, "Bulk", []int{100}, []string{"pallet"}, entity.EffectivePeriod{}, entity.PackConfigurationDetails{})
+		require.NoError(t, err)
+		assert.Equal(t, []int{100, 5000}, configuration.PackSizes)
+		assert.Equal(t, []int{3}, configuration.PackTypeIDs)
+
+		configuration, err = service.CreateConfiguration(t.Context(), actor, "Pallets", nil, []string{"PALLET", "BOX-250"}, entity.EffectivePeriod{}, entity.PackConfigurationDetails{})
+		require.NoError(t, err)
+		assert.Equal(t, []int{250, 5000}, configuration.PackSizes)
+		assert.Equal(t, []int{1, 3}, configuration.PackTypeIDs)
+	})
+
+	t.Run("rejects unknown and inactive codes", func(t *testing.T) {
+		service, _ := newService()
+
+		for _, code := range []string{"NOPE", "RETIRED"} {
+			_, err := service.CreateConfiguration(t.Context(), actor, "Bulk", []int{100}, []string{code}, entity.EffectivePeriod{}, entity.PackConfigurationDetails{})
+			assert.ErrorIs(t, err, errs.ErrUnknownPackType, code)
+		}
+	})
+
+	t.Run("rejects two pack types with the same item count", func(t *testing.T) {
+		service, _ := newService()
+
+		_, err := service.CreateConfiguration(t.Context(), actor, "Bulk", nil, []string{"BOX-500", "CRATE-500"}, entity.EffectivePeriod{}, entity.PackConfigurationDetails{})
+		assert.ErrorIs(t, err, errs.ErrInvalidPackConfiguration)
+	})
+
+	t.Run("patch cannot remove a size a pack type offers", func(t *testing.T) {
+		service, _ := newService()
+
+		_, err := service.PatchConfiguration(t.Context(), actor, 1, 1, entity.PackConfigurationPatch{RemovePackSizes: []int{500}})
+		assert.ErrorIs(t, err, errs.ErrInvalidPackConfiguration)
+
+		updated, err := service.PatchConfiguration(t.Context(), actor, 1, 1, entity.PackConfigurationPatch{RemovePackSizes: []int{1000}})
+		require.NoError(t, err)
+		assert.Equal(t, []int{250, 500}, updated.PackSizes)
+	})
+
+	t.Run("patch replacing pack types drops the sizes of the old ones", func(t *testing.T) {
+		service, repo := newService()
+
+		updated, err := service.PatchConfiguration(t.Context(), actor, 1, 1, entity.PackConfigurationPatch{PackTypes: []string{"PALLET"}})
+		require.NoError(t, err)
+		assert.Equal(t, []int{1000, 5000}, updated.PackSizes)
+		assert.Equal(t, []int{3}, repo.configs[1].PackTypeIDs)
+
+		updated, err = service.PatchConfiguration(t.Context(), actor, 1, 2, entity.PackConfigurationPatch{PackTypes: []string{}})
+		require.NoError(t, err)
+		assert.Equal(t, []int{1000}, updated.PackSizes)
+		assert.Empty(t, updated.PackTypeIDs)
+	})
+
+	t.Run("import reports unknown pack types by field", func(t *testing.T) {
+		service, _ := newService()
+
+		_, err := service.ImportConfigurations(t.Context(), actor, []entity.PackConfigurationImportRow{
+			{Name: "Bulk", PackTypes: []string{"PALLET"}},
+			{Name: "Other", PackTypes: []string{"NOPE"}},
+		}, entity.ImportOptions{})
+
+		var invalid *errs.InvalidFieldsError
+		require.ErrorAs(t, err, &invalid)
+		require.Len(t, invalid.Fields, 1)
+		assert.Equal(t, "configurations[1].pack_types", invalid.Fields[0].Field)
+	})
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+)
+
+type PackTypeService struct {
+	repository entity.PackTypeRepository
+}
+
+func NewPackTypeService(repository entity.PackTypeRepository) *PackTypeService {
+	return &PackTypeService{
+		repository: repository,
+	}
+}
+
+// ListPackTypes returns the tenant's pack types ordered by code
+func (s *PackTypeService) ListPackTypes(ctx context.Context, tenant string, includeInactive bool) ([]*entity.PackType, error) {
+	packTypes, err := s.repository.GetAll(ctx, tenant, includeInactive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pack types: %w", err)
+	}
+	return packTypes, nil
+}
+
+func (s *PackTypeService) GetPackTypeByID(ctx context.Context, tenant string, id int) (*entity.PackType, error) {
+	if id <= 0 {
+		return nil, errs.ErrInvalidID.Withf("invalid pack type ID: %d", id)
+	}
+
+	packType, err := s.repository.GetByID(ctx, tenant, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pack type by ID: %w", err)
+	}
+	return packType, nil
+}
+
+// CreatePackType stores a new active pack type for the tenant
+func (s *PackTypeService) CreatePackType(ctx context.Context, tenant string, packType entity.PackType) (*entity.PackType, error) {
+	packType.ID = 0
+	packType.Tenant = tenant
+	packType.IsActive = true
+	packType.CreatedAt = time.Now()
+	packType.UpdatedAt = packType.CreatedAt
+	if err := packType.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid pack type: %w", err)
+	}
+
+	created, err := s.repository.Create(ctx, &packType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save pack type: %w", err)
+	}
+	return created, nil
+}
+
+// UpdatePackType replaces a pack type's code, label, item count, dimensions
+// and cost. The item count cannot change while an active configuration
+// references the pack type, since the configuration offers it as a pack size.
+func (s *PackTypeService) UpdatePackType(ctx context.Context, tenant string, id int, packType entity.PackType) (*entity.PackType, error) {
+	existing, err := s.GetPackTypeByID(ctx, tenant, id)
+	if err != nil {
+		return nil, err
+	}
+
+	packType.ID = existing.ID
+	packType.Tenant = existing.Tenant
+	packType.IsActive = existing.IsActive
+	packType.CreatedAt = existing.CreatedAt
+	if err := packType.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid pack type: %w", err)
+	}
+
+	updated, err := s.repository.Update(ctx, &packType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update pack type: %w", err)
+	}
+	return updated, nil
+}
+
+// DeletePackType deactivates a pack type no active configuration references
+func (s *PackTypeService) DeletePackType(ctx context.Context, tenant string, id int) error {
+	if id <= 0 {
+		return errs.ErrInvalidID.Withf("invalid pack type ID: %d", id)
+	}
+
+	if err := s.repository.Delete(ctx, tenant, id); err != nil {
+		return fmt.Errorf("failed to delete pack type: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+)
+
+type fakePackTypeRepository struct {
+	packTypes map[int]*entity.PackType
+	// references counts the active configurations referencing each pack type
+	references map[int]int
+	nextID     int
+}
+
+func newFakePackTypeRepository() *fakePackTypeRepository {
+	return &fakePackTypeRepository{packTypes: map[int]*entity.PackType{}, references: map[int]int{}, nextID: 1}
+}
+
+func (r *fakePackTypeRepository) GetAll(ctx context.Context, tenant string, includeInactive bool) ([]*entity.PackType, error) {
+	var packTypes []*entity.PackType
+	for id := 1; id < r.nextID; id++ {
+		if packType, ok := r.packTypes[id]; ok && packType.Tenant == tenant && (packType.IsActive || includeInactive) {
+			packTypes = append(packTypes, packType)
+		}
+	}
+	return packTypes, nil
+}
+
+func (r *fakePackTypeRepository) GetByID(ctx context.Context, tenant string, id int) (*entity.PackType, error) {
+	packType, ok := r.packTypes[id]
+	if !ok || packType.Tenant != tenant {
+		return nil, fmt.Errorf("pack type with id %d: %w", id, errs.ErrPackTypeNotFound)
+	}
+	copied := *packType
+	return &copied, nil
+}
+
+func (r *fakePackTypeRepository) GetByIDs(ctx context.Context, tenant string, ids []int) ([]*entity.PackType, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (r *fakePackTypeRepository) GetByCodes(ctx context.Context, tenant string, codes []string) ([]*entity.PackType, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (r *fakePackTypeRepository) codeConflict(packType *entity.PackType) error {
+	for _, other := range r.packTypes {
+		if other.ID != packType.ID && other.Tenant == packType.Tenant && strings.EqualFold(other.Code, packType.Code) {
+			return fmt.Errorf("code %q: %w", packType.Code, errs.ErrPackTypeCodeConflict)
+		}
+	}
+	return nil
+}
+
+func (r *fakePackTypeRepository) Create(ctx context.Context, packType *entity.PackType) (*entity.PackType, error) {
+	if err := r.codeConflict(packType); err != nil {
+		return nil, err
+	}
+	packType.ID = r.nextID
+	r.nextID++
+	r.packTypes[packType.ID] = packType
+	return r.GetByID(ctx, packType.Tenant, packType.ID)
+}
+
+func (r *fakePackTypeRepository) Update(ctx context.Context, packType *entity.PackType) (*entity.PackType, error) {
+	stored, err := r.GetByID(ctx, packType.Tenant, packType.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.codeConflict(packType); err != nil {
+		return nil, err
+	}
+	if stored.ItemCount != packType.ItemCount && r.references[packType.ID] > 0 {
+		return nil, errs.ErrPackTypeInUse.Withf("pack type %d is referenced", packType.ID)
+	}
+	r.packTypes[packType.ID] = packType
+	return r.GetByID(ctx, packType.Tenant, packType.ID)
+}
+
+func (r *fakePackTypeRepository) Delete(ctx context.Context, tenant string, id int) error {
+	packType, err := r.GetByID(ctx, tenant, id)
+	if err != nil || !packType.IsActive {
+		return fmt.Errorf("pack type with id %d: %w", id, errs.ErrPackTypeNotFound)
+	}
+	if r.references[id] > 0 {
+		return errs.ErrPackTypeInUse.Withf("pack type %d is referenced", id)
+	}
+	r.packTypes[id].IsActive = false
+	return nil
+}
+
+func TestPackTypeService(t *testing.T) {
+	box := entity.PackType{Code: "BOX-250", Label: "Box of 250", ItemCount: 250, CostCents: 125}
+
+	t.Run("creates active pack types per tenant", func(t *testing.T) {
+		service := NewPackTypeService(newFakePackTypeRepository())
+
+		created, err := service.CreatePackType(t.Context(), entity.DefaultTenant, box)
+		require.NoError(t, err)
+		assert.Equal(t, 1, created.ID)
+		assert.True(t, created.IsActive)
+		assert.Equal(t, entity.DefaultTenant, created.Tenant)
+
+		_, err = service.CreatePackType(t.Context(), entity.DefaultTenant, entity.PackType{Code: "box-250", Label: "Other", ItemCount: 10})
+		assert.ErrorIs(t, err, errs.ErrPackTypeCodeConflict)
+
+		_, err = service.CreatePackType(t.Context(), "retail", entity.PackType{Code: "box-250", Label: "Other", ItemCount: 10})
+		assert.NoError(t, err)
+
+		_, err = service.GetPackTypeByID(t.Context(), "retail", created.ID)
+		assert.ErrorIs(t, err, errs.ErrPackTypeNotFound)
+	})
+
+	t.Run("rejects invalid pack types", func(t *testing.T) {
+		service := NewPackTypeService(newFakePackTypeRepository())
+
+		for _, packType := range []entity.PackType{
+			{Code: "", Label: "Box", ItemCount: 1},
+			{Code: "has space", Label: "Box", ItemCount: 1},
+			{Code: "BOX", Label: "", ItemCount: 1},
+			{Code: "BOX", Label: "Box", ItemCount: 0},
+			{Code: "BOX", Label: "Box", ItemCount: 1, CostCents: -1},
+			{Code: "BOX", Label: "Box", ItemCount: 1, PackDimensions: entity.PackDimensions{HeightMM: -1}},
+		} {
+			_, err := service.CreatePackType(t.Context(), entity.DefaultTenant, packType)
+			assert.ErrorIs(t, err, errs.ErrInvalidPackType, "%+v", packType)
+		}
+	})
+
+	t.Run("referenced pack types keep their item count and cannot be deleted", func(t *testing.T) {
+		repo := newFakePackTypeRepository()
+		service := NewPackTypeService(repo)
+		created, err := service.CreatePackType(t.Context(), entity.DefaultTenant, box)
+		require.NoError(t, err)
+		repo.references[created.ID] = 1
+
+		relabelled := box
+		relabelled.Label = "Carton of 250"
+		updated, err := service.UpdatePackType(t.Context(), entity.DefaultTenant, created.ID, relabelled)
+		require.NoError(t, err)
+		assert.Equal(t, "Carton of 250", updated.Label)
+
+		resized := box
+		resized.ItemCount = 300
+		_, err = service.UpdatePackType(t.Context(), entity.DefaultTenant, created.ID, resized)
+		assert.ErrorIs(t, err, errs.ErrPackTypeInUse)
+
+		err = service.DeletePackType(t.Context(), entity.DefaultTenant, created.ID)
+		assert.ErrorIs(t, err, errs.ErrPackTypeInUse)
+
+		repo.references[created.ID] = 0
+		require.NoError(t, service.DeletePackType(t.Context(), entity.DefaultTenant, created.ID))
+
+		active, err := service.ListPackTypes(t.Context(), entity.DefaultTenant, false)
+		require.NoError(t, err)
+		assert.Empty(t, active)
+		all, err := service.ListPackTypes(t.Context(), entity.DefaultTenant, true)
+		require.NoError(t, err)
+		require.Len(t, all, 1)
+		assert.False(t, all[0].IsActive)
+	})
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+)
+
+type WarehouseService struct {
+	repository          entity.WarehouseRepository
+	configRepository    entity.PackConfigurationRepository
+	inventoryRepository entity.InventoryRepository
+	solver              entity.SourcingSolver
+	packProcessor       entity.PackSizeProcessor
+	splitPenalty        int
+}
+
+func NewWarehouseService(
+	repository entity.WarehouseRepository,
+	configRepository entity.PackConfigurationRepository,
+	inventoryRepository entity.InventoryRepository,
+	solver entity.SourcingSolver,
+	packProcessor entity.PackSizeProcessor,
+	splitPenalty int,
+) *WarehouseService {
+	return &WarehouseService{
+		repository:          repository,
+		configRepository:    configRepository,
+		inventoryRepository: inventoryRepository,
+		solver:              solver,
+		packProcessor:       packProcessor,
+		splitPenalty:        splitPenalty,
+	}
+}
+
+func (s *WarehouseService) GetAllWarehouses(ctx context.Context, tenant string) ([]*entity.Warehouse, error) {
+	warehouses, err := s.repository.GetAll(ctx, tenant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get warehouses: %w", err)
+	}
+	return warehouses, nil
+}
+
+func (s *WarehouseService) GetWarehouseByID(ctx context.Context, tenant string, id int) (*entity.Warehouse, error) {
+	if id <= 0 {
+		return nil, errs.ErrInvalidID.Withf("invalid warehouse ID: %d", id)
+	}
+
+	warehouse, err := s.repository.GetByID(ctx, tenant, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get warehouse by ID: %w", err)
+	}
+	return warehouse, nil
+}
+
+func (s *WarehouseService) CreateWarehouse(ctx context.Context, tenant string, name string, location string, packConfigurationID int) (*entity.Warehouse, error) {
+	warehouse, err := entity.NewWarehouse(tenant, name, location, packConfigurationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create warehouse entity: %w", err)
+	}
+
+	// The warehouse's configuration must be the tenant's and usable for planning
+	if _, err := s.configRepository.GetByID(ctx, tenant, packConfigurationID); err != nil {
+		return nil, fmt.Errorf("pack configuration not found or inactive: %w", err)
+	}
+
+	created, err := s.repository.Create(ctx, warehouse)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save warehouse: %w", err)
+	}
+	return created, nil
+}
+
+func (s *WarehouseService) DeleteWarehouse(ctx context.Context, tenant string, id int) error {
+	if id <= 0 {
+		return errs.ErrInvalidID.Withf("invalid warehouse ID: %d", id)
+	}
+
+	if err := s.repository.Delete(ctx, tenant, id); err != nil {
+		return fmt.Errorf("failed to delete warehouse: %w", err)
+	}
+	return nil
+}
+
+// PlanSourcing decides which warehouses fulfil an order. With no IDs every
+// active warehouse is considered; a nil splitPenalty uses the configured one.
+func (s *WarehouseService) PlanSourcing(ctx context.Context, tenant string, orderQuantity int, warehouseIDs []int, splitPenalty *int) (*entity.SourcingPlan, []*entity.Warehouse, error) {
+	orderQuantityEntity, err := entity.NewOrderQuantity(orderQuantity)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	warehouses, err := s.resolveWarehouses(ctx, tenant, warehouseIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	candidates := make([]*entity.SourcingCandidate, 0, len(warehouses))
+	for _, warehouse := range warehouses {
+		candidate, err := s.buildCandidate(ctx, tenant, warehouse)
+		if err != nil {
+			return nil, nil, err
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	penalty := s.splitPenalty
+	if splitPenalty != nil {
+		penalty = *splitPenalty
+	}
+
+	plan := s.solver.PlanSourcing(candidates, orderQuantityEntity, penalty)
+	if plan.IsUnfulfillable() {
+		return nil, nil, fmt.Errorf("%d items across %d warehouses: %w", orderQuantity, len(warehouses), errs.ErrUnfulfillableOrder)
+	}
+
+	return plan, warehouses, nil
+}
+
+func (s *WarehouseService) resolveWarehouses(ctx context.Context, tenant string, ids []int) ([]*entity.Warehouse, error) {
+	if len(ids) == 0 {
+		return s.GetAllWarehouses(ctx, tenant)
+	}
+
+	warehouses := make([]*entity.Warehouse, 0, len(ids))
+	seen := make(map[int]struct{}, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+
+		warehouse, err := s.GetWarehouseByID(ctx, tenant, id)
+		if err != nil {
+			return nil, err
+		}
+		warehouses = append(warehouses, warehouse)
+	}
+	return warehouses, nil
+}
+
+func (s *WarehouseService) buildCandidate(ctx context.Context, tenant string, warehouse *entity.Warehouse) (*entity.SourcingCandidate, error) {
+	config, err := s.configRepository.GetByID(ctx, tenant, warehouse.PackConfigurationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pack configuration for warehouse %d: %w", warehouse.ID, err)
+	}
+
+	packSizes, err := s.packProcessor.ProcessPackSizes(config.GetRawPackSizes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to process pack sizes for warehouse %d: %w", warehouse.ID, err)
+	}
+
+	levels, err := s.inventoryRepository.GetStock(ctx, tenant, warehouse.Location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock for warehouse %d: %w", warehouse.ID, err)
+	}
+
+	return &entity.SourcingCandidate{
+		SourceID:  warehouse.ID,
+		PackSizes: packSizes,
+		Stock:     entity.AvailableStock(levels),
+	}, nil
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+)
+
+// NewHTTPClient builds the client a Dispatcher sends deliveries with. Unless
+// allowPrivate is set, it refuses to connect to the addresses
+// entity.IsPublicAddress rejects, checked after name resolution so that
+// neither DNS nor redirects can reach them, and ignores proxy settings,
+// which would hide the address it connects to.
+func NewHTTPClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !allowPrivate {
+		dialer := &net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+			Control:   refusePrivateAddress,
+		}
+		transport.DialContext = dialer.DialContext
+		transport.Proxy = nil
+	}
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+func refusePrivateAddress(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("unexpected webhook address %q: %w", address, err)
+	}
+	if !entity.IsPublicAddress(addrPort.Addr()) {
+		return fmt.Errorf("refusing to deliver webhook to private address %s", addrPort.Addr())
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHTTPClient(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer receiver.Close()
+
+	t.Run("refuses private addresses", func(t *testing.T) {
+		response, err := NewHTTPClient(5*time.Second, false).Post(receiver.URL, "application/json", nil)
+		if response != nil {
+			response.Body.Close()
+		}
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "private address 127.0.0.1")
+	})
+
+	t.Run("refuses names resolving to private addresses", func(t *testing.T) {
+		_, port, err := net.SplitHostPort(receiver.Listener.Addr().String())
+		require.NoError(t, err)
+		target := "http://" + net.JoinHostPort("localhost", port)
+		response, err := NewHTTPClient(5*time.Second, false).Post(target, "application/json", nil)
+		if response != nil {
+			response.Body.Close()
+		}
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "private address")
+	})
+
+	t.Run("connects to private addresses when allowed", func(t *testing.T) {
+		response, err := NewHTTPClient(5*time.Second, true).Post(receiver.URL, "application/json", nil)
+		require.NoError(t, err)
+		response.Body.Close()
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+	})
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/pkg/webhook"
+)
+
+// dispatchBatchSize is how many events a dispatcher fans out, and how many
+// deliveries it sends concurrently, at a time
+const dispatchBatchSize = 20
+
+// RetryPolicy decides when a failed delivery is tried again
+type RetryPolicy struct {
+	// MaxAttempts is how many times a delivery is sent before it becomes a
+	// dead letter
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// Backoff is the wait after a delivery's failures-th failed attempt:
+// InitialBackoff doubled for every earlier failure, capped at MaxBackoff.
+// jitter takes off a random part of up to half of it, so that deliveries
+// failing together do not all retry together; it returns a number in [0, n).
+func (p RetryPolicy) Backoff(failures int, jitter func(n int64) int64) time.Duration {
+	backoff := p.InitialBackoff
+	for i := 1; i < failures && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	backoff = min(backoff, p.MaxBackoff)
+	if half := int64(backoff / 2); half > 0 {
+		backoff -= time.Duration(jitter(half))
+	}
+	return backoff
+}
+
+// Dispatcher delivers outbox events to webhooks: POSTs of the event as JSON,
+// signed as the webhook package describes. Delivery is at least once, so
+// receivers should skip event IDs they have already seen, and retries can
+// reorder events.
+type Dispatcher struct {
+	outbox entity.WebhookOutbox
+	client *http.Client
+	policy RetryPolicy
+	now    func() time.Time
+	jitter func(n int64) int64
+}
+
+// NewDispatcher sends deliveries with client, whose Timeout should be set:
+// the lease on claimed deliveries is derived from it
+func NewDispatcher(outbox entity.WebhookOutbox, client *http.Client, policy RetryPolicy) *Dispatcher {
+	return &Dispatcher{
+		outbox: outbox,
+		client: client,
+		policy: policy,
+		now:    time.Now,
+		jitter: rand.Int64N,
+	}
+}
+
+// lease hides claimed deliveries from other dispatchers for long enough to
+// send them all, leaving room to record the outcomes
+func (d *Dispatcher) lease() time.Duration {
+	return max(2*d.client.Timeout, time.Minute)
+}
+
+// Dispatch fans out every undispatched event, then sends the deliveries due
+// now, batch by batch, until none are left
+func (d *Dispatcher) Dispatch(ctx context.Context) (entity.WebhookDispatchResult, error) {
+	var result entity.WebhookDispatchResult
+	for {
+		events, err := d.outbox.FanOut(ctx, dispatchBatchSize)
+		if err != nil {
+			return result, err
+		}
+		result.Events += events
+		if events < dispatchBatchSize {
+			break
+		}
+	}
+
+	for {
+		due, err := d.outbox.ClaimDue(ctx, dispatchBatchSize, d.lease())
+		if err != nil {
+			return result, err
+		}
+
+		attempts := make([]entity.WebhookAttempt, len(due))
+		var wg sync.WaitGroup
+		for i, delivery := range due {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				attempts[i] = d.attempt(ctx, delivery)
+			}()
+		}
+		wg.Wait()
+
+		for _, attempt := range attempts {
+			if err := d.outbox.RecordAttempt(ctx, attempt); err != nil {
+				return result, err
+			}
+			switch {
+			case attempt.Delivered:
+				result.Delivered++
+			case attempt.NextAttemptAt != nil:
+				result.Retried++
+			default:
+				result.DeadLettered++
+			}
+		}
+
+		if len(due) < dispatchBatchSize {
+			return result, nil
+		}
+	}
+}
+
+// attempt sends the delivery once and decides what happens next
+func (d *Dispatcher) attempt(ctx context.Context, delivery *entity.DueWebhookDelivery) entity.WebhookAttempt {
+	attempt := entity.WebhookAttempt{DeliveryID: delivery.ID}
+	attempt.StatusCode, attempt.Delivered, attempt.Error = d.send(ctx, delivery)
+	if attempt.Delivered {
+		return attempt
+	}
+
+	failures := delivery.Attempts + 1
+	if failures < d.policy.MaxAttempts {
+		next := d.now().Add(d.policy.Backoff(failures, d.jitter))
+		attempt.NextAttemptAt = &next
+	}
+	return attempt
+}
+
+// send POSTs the event, reporting the response status, whether it was a 2xx,
+// and otherwise what went wrong
+func (d *Dispatcher) send(ctx context.Context, delivery *entity.DueWebhookDelivery) (int, bool, string) {
+	body, err := json.Marshal(delivery.Event)
+	if err != nil {
+		return 0, false, fmt.Sprintf("failed to encode event: %v", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, false, fmt.Sprintf("invalid request: %v", err)
+	}
+	timestamp := d.now().Unix()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "packs-calculator-webhooks")
+	request.Header.Set(webhook.EventHeader, string(delivery.Event.Type))
+	request.Header.Set(webhook.DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	request.Header.Set(webhook.TimestampHeader, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(webhook.SignatureHeader, webhook.Sign(delivery.Secret, timestamp, body))
+
+	response, err := d.client.Do(request)
+	if err != nil {
+		return 0, false, err.Error()
+	}
+	defer response.Body.Close()
+	// Drain a little of the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, false, fmt.Sprintf("unexpected status %s", response.Status)
+	}
+	return response.StatusCode, true, ""
+}
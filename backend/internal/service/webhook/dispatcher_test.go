@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/pkg/webhook"
+)
+
+// fakeOutbox hands out every pending delivery that is due and records the
+// attempts made on them
+type fakeOutbox struct {
+	mu         sync.Mutex
+	now        time.Time
+	undispatch int
+	deliveries []*fakeDelivery
+	attempts   []entity.WebhookAttempt
+}
+
+type fakeDelivery struct {
+	due     entity.DueWebhookDelivery
+	status  entity.WebhookDeliveryStatus
+	nextAt  time.Time
+	claimed bool
+}
+
+func (o *fakeOutbox) add(url string, secret string, event entity.OutboxEvent) {
+	o.deliveries = append(o.deliveries, &fakeDelivery{
+		due:    entity.DueWebhookDelivery{ID: int64(len(o.deliveries) + 1), URL: url, Secret: secret, Event: event},
+		status: entity.WebhookDeliveryPending,
+		nextAt: o.now,
+	})
+}
+
+func (o *fakeOutbox) FanOut(ctx context.Context, limit int) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	fannedOut := min(o.undispatch, limit)
+	o.undispatch -= fannedOut
+	return fannedOut, nil
+}
+
+func (o *fakeOutbox) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*entity.DueWebhookDelivery, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var due []*entity.DueWebhookDelivery
+	for _, delivery := range o.deliveries {
+		if len(due) == limit {
+			break
+		}
+		if delivery.status == entity.WebhookDeliveryPending && !delivery.claimed && !delivery.nextAt.After(o.now) {
+			delivery.claimed = true
+			claimed := delivery.due
+			due = append(due, &claimed)
+		}
+	}
+	return due, nil
+}
+
+func (o *fakeOutbox) RecordAttempt(ctx context.Context, attempt entity.WebhookAttempt) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.attempts = append(o.attempts, attempt)
+	delivery := o.deliveries[attempt.DeliveryID-1]
+	delivery.claimed = false
+	delivery.due.Attempts++
+	switch {
+	case attempt.Delivered:
+		delivery.status = entity.WebhookDeliveryDelivered
+	case attempt.NextAttemptAt == nil:
+		delivery.status = entity.WebhookDeliveryDead
+	default:
+		delivery.nextAt = *attempt.NextAttemptAt
+	}
+	return nil
+}
+
+func newTestDispatcher(outbox *fakeOutbox, maxAttempts int) *Dispatcher {
+	dispatcher := NewDispatcher(outbox, NewHTTPClient(5*time.Second, true), RetryPolicy{
+		MaxAttempts:    maxAttempts,
+		InitialBackoff: time.Minute,
+		MaxBackoff:     time.Hour,
+	})
+	dispatcher.now = func() time.Time { return outbox.now }
+	dispatcher.jitter = func(int64) int64 { return 0 }
+	return dispatcher
+}
+
+func defaultChangedEvent() entity.OutboxEvent {
+	return entity.OutboxEvent{
+		ID:        42,
+		Tenant:    entity.DefaultTenant,
+		Type:      entity.EventPackConfigurationDefaultChanged,
+		Data:      json.RawMessage(`{"configuration":{"id":7,"is_default":true},"previous_default_ids":[3]}`),
+		CreatedAt: time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, InitialBackoff: 10 * time.Second, MaxBackoff: time.Minute}
+	noJitter := func(int64) int64 { return 0 }
+
+	assert.Equal(t, 10*time.Second, policy.Backoff(1, noJitter))
+	assert.Equal(t, 20*time.Second, policy.Backoff(2, noJitter))
+	assert.Equal(t, 40*time.Second, policy.Backoff(3, noJitter))
+	assert.Equal(t, time.Minute, policy.Backoff(4, noJitter))
+	assert.Equal(t, time.Minute, policy.Backoff(100, noJitter))
+
+	fullJitter := func(n int64) int64 { return n - 1 }
+	assert.Equal(t, 10*time.Second+time.Nanosecond, policy.Backoff(2, fullJitter), "jitter takes off at most half")
+}
+
+func TestDispatcher(t *testing.T) {
+	const secret = "0123456789abcdef0123456789abcdef"
+
+	t.Run("delivers signed events", func(t *testing.T) {
+		var received []*http.Request
+		var bodies [][]byte
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			received = append(received, r)
+			bodies = append(bodies, body)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer receiver.Close()
+
+		outbox := &fakeOutbox{now: time.Now(), undispatch: 3}
+		outbox.add(receiver.URL, secret, defaultChangedEvent())
+
+		result, err := newTestDispatcher(outbox, 3).Dispatch(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, entity.WebhookDispatchResult{Events: 3, Delivered: 1}, result)
+
+		require.Len(t, received, 1)
+		request := received[0]
+		assert.Equal(t, http.MethodPost, request.Method)
+		assert.Equal(t, "pack_configuration.default_changed", request.Header.Get(webhook.EventHeader))
+		assert.Equal(t, "1", request.Header.Get(webhook.DeliveryHeader))
+		require.NoError(t, webhook.Verify(secret, request.Header.Get(webhook.TimestampHeader),
+			request.Header.Get(webhook.SignatureHeader), bodies[0], time.Now(), time.Minute))
+
+		var event map[string]interface{}
+		require.NoError(t, json.Unmarshal(bodies[0], &event))
+		assert.EqualValues(t, 42, event["id"])
+		assert.Equal(t, "pack_configuration.default_changed", event["type"])
+		assert.Equal(t, entity.DefaultTenant, event["tenant"])
+		assert.Equal(t, "2026-05-01T12:00:00Z", event["occurred_at"])
+		assert.Equal(t, []interface{}{float64(3)}, event["data"].(map[string]interface{})["previous_default_ids"])
+	})
+
+	t.Run("retries with backoff until the attempts run out", func(t *testing.T) {
+		calls := 0
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer receiver.Close()
+
+		outbox := &fakeOutbox{now: time.Now()}
+		outbox.add(receiver.URL, secret, defaultChangedEvent())
+		dispatcher := newTestDispatcher(outbox, 3)
+
+		result, err := dispatcher.Dispatch(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, entity.WebhookDispatchResult{Retried: 1}, result)
+		require.NotNil(t, outbox.attempts[0].NextAttemptAt)
+		assert.Equal(t, outbox.now.Add(time.Minute), *outbox.attempts[0].NextAttemptAt)
+		assert.Equal(t, http.StatusServiceUnavailable, outbox.attempts[0].StatusCode)
+		assert.Contains(t, outbox.attempts[0].Error, "503")
+
+		result, err = dispatcher.Dispatch(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, entity.WebhookDispatchResult{}, result, "nothing is due before the backoff passes")
+
+		outbox.now = outbox.now.Add(time.Minute)
+		result, err = dispatcher.Dispatch(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, entity.WebhookDispatchResult{Retried: 1}, result)
+		assert.Equal(t, outbox.now.Add(2*time.Minute), *outbox.attempts[1].NextAttemptAt)
+
+		outbox.now = outbox.now.Add(2 * time.Minute)
+		result, err = dispatcher.Dispatch(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, entity.WebhookDispatchResult{DeadLettered: 1}, result)
+		assert.Nil(t, outbox.attempts[2].NextAttemptAt)
+		assert.Equal(t, entity.WebhookDeliveryDead, outbox.deliveries[0].status)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("retries unreachable receivers", func(t *testing.T) {
+		receiver := httptest.NewServer(http.NotFoundHandler())
+		unreachable := receiver.URL
+		receiver.Close()
+
+		outbox := &fakeOutbox{now: time.Now()}
+		outbox.add(unreachable, secret, defaultChangedEvent())
+
+		result, err := newTestDispatcher(outbox, 3).Dispatch(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, entity.WebhookDispatchResult{Retried: 1}, result)
+		assert.Zero(t, outbox.attempts[0].StatusCode)
+		assert.NotEmpty(t, outbox.attempts[0].Error)
+	})
+
+	t.Run("sends more deliveries than fit in a batch", func(t *testing.T) {
+		var mu sync.Mutex
+		calls := 0
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			calls++
+			mu.Unlock()
+		}))
+		defer receiver.Close()
+
+		outbox := &fakeOutbox{now: time.Now(), undispatch: 2*dispatchBatchSize + 1}
+		for range 2*dispatchBatchSize + 1 {
+			outbox.add(receiver.URL, secret, defaultChangedEvent())
+		}
+
+		result, err := newTestDispatcher(outbox, 3).Dispatch(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, entity.WebhookDispatchResult{Events: 2*dispatchBatchSize + 1, Delivered: 2*dispatchBatchSize + 1}, result)
+		assert.Equal(t, 2*dispatchBatchSize+1, calls)
+	})
+}
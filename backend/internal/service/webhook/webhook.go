@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 500
+)
+
+type WebhookService struct {
+	repository   entity.WebhookRepository
+	allowPrivate bool
+}
+
+// NewWebhookService refuses webhooks aimed at private addresses unless
+// allowPrivate is set, which only local receivers and tests should need
+func NewWebhookService(repository entity.WebhookRepository, allowPrivate bool) *WebhookService {
+	return &WebhookService{
+		repository:   repository,
+		allowPrivate: allowPrivate,
+	}
+}
+
+func (s *WebhookService) ListWebhooks(ctx context.Context, tenant string) ([]*entity.Webhook, error) {
+	webhooks, err := s.repository.List(ctx, tenant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+// CreateWebhook registers url for the tenant's events of eventTypes, or all
+// of them when none are given. Without a secret one is generated; either way
+// the returned webhook carries it, and it is never shown again.
+func (s *WebhookService) CreateWebhook(ctx context.Context, tenant string, url string, secret string, eventTypes []entity.EventType) (*entity.Webhook, error) {
+	if secret == "" {
+		generated, err := generateSecret()
+		if err != nil {
+			return nil, err
+		}
+		secret = generated
+	}
+
+	webhook := &entity.Webhook{
+		Tenant:     tenant,
+		URL:        url,
+		Secret:     secret,
+		EventTypes: eventTypes,
+	}
+	if err := webhook.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid webhook: %w", err)
+	}
+	if !s.allowPrivate {
+		if err := webhook.CheckTarget(); err != nil {
+			return nil, fmt.Errorf("invalid webhook: %w", err)
+		}
+	}
+
+	created, err := s.repository.Create(ctx, webhook)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save webhook: %w", err)
+	}
+	return created, nil
+}
+
+func generateSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+func (s *WebhookService) DeleteWebhook(ctx context.Context, tenant string, id int) error {
+	if id <= 0 {
+		return errs.ErrInvalidID.Withf("invalid webhook ID: %d", id)
+	}
+	if err := s.repository.Delete(ctx, tenant, id); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	return nil
+}
+
+// ListDeadLetters returns the deliveries that ran out of attempts, newest
+// first, of one webhook when webhookID is set
+func (s *WebhookService) ListDeadLetters(ctx context.Context, tenant string, webhookID int, limit int, offset int) ([]*entity.WebhookDelivery, int, error) {
+	limit, offset = NormalizePage(limit, offset)
+	deliveries, total, err := s.repository.ListDeliveries(ctx, entity.WebhookDeliveryFilter{
+		Tenant:    tenant,
+		WebhookID: webhookID,
+		Status:    entity.WebhookDeliveryDead,
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+	return deliveries, total, nil
+}
+
+// NormalizePage applies the default and maximum page size and clamps the offset
+func NormalizePage(limit int, offset int) (int, int) {
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	return min(limit, MaxPageSize), max(offset, 0)
+}
+
+// Redeliver queues a dead letter for delivery again, with a fresh set of attempts
+func (s *WebhookService) Redeliver(ctx context.Context, tenant string, deliveryID int64) (*entity.WebhookDelivery, error) {
+	if deliveryID <= 0 {
+		return nil, errs.ErrInvalidID.Withf("invalid webhook delivery ID: %d", deliveryID)
+	}
+	delivery, err := s.repository.Redeliver(ctx, tenant, deliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to redeliver webhook delivery: %w", err)
+	}
+	return delivery, nil
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+)
+
+type fakeWebhookRepository struct {
+	entity.WebhookRepository
+	created []*entity.Webhook
+	filter  entity.WebhookDeliveryFilter
+}
+
+func (r *fakeWebhookRepository) Create(ctx context.Context, webhook *entity.Webhook) (*entity.Webhook, error) {
+	webhook.ID = len(r.created) + 1
+	r.created = append(r.created, webhook)
+	return webhook, nil
+}
+
+func (r *fakeWebhookRepository) ListDeliveries(ctx context.Context, filter entity.WebhookDeliveryFilter) ([]*entity.WebhookDelivery, int, error) {
+	r.filter = filter
+	return nil, 0, nil
+}
+
+func TestWebhookService(t *testing.T) {
+	t.Run("generates a secret when none is given", func(t *testing.T) {
+		service := NewWebhookService(&fakeWebhookRepository{}, false)
+
+		first, err := service.CreateWebhook(t.Context(), "retail", "https://wms.example.com/hooks", "", nil)
+		require.NoError(t, err)
+		second, err := service.CreateWebhook(t.Context(), "retail", "https://wms.example.com/hooks", "", nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, "retail", first.Tenant)
+		assert.Len(t, first.Secret, 64)
+		assert.NotEqual(t, first.Secret, second.Secret)
+	})
+
+	t.Run("keeps a given secret", func(t *testing.T) {
+		service := NewWebhookService(&fakeWebhookRepository{}, false)
+		created, err := service.CreateWebhook(t.Context(), "retail", "https://wms.example.com/hooks", "shared-secret-of-the-wms", []entity.EventType{entity.EventPackConfigurationDefaultChanged})
+		require.NoError(t, err)
+		assert.Equal(t, "shared-secret-of-the-wms", created.Secret)
+	})
+
+	t.Run("rejects invalid webhooks", func(t *testing.T) {
+		service := NewWebhookService(&fakeWebhookRepository{}, false)
+		tests := []struct {
+			name       string
+			url        string
+			secret     string
+			eventTypes []entity.EventType
+		}{
+			{"relative url", "/hooks", "", nil},
+			{"unsupported scheme", "ftp://wms.example.com/hooks", "", nil},
+			{"short secret", "https://wms.example.com/hooks", "short", nil},
+			{"long secret", "https://wms.example.com/hooks", strings.Repeat("s", entity.MaxWebhookSecretLength+1), nil},
+			{"unknown event", "https://wms.example.com/hooks", "", []entity.EventType{"pack_configuration.exploded"}},
+			{"localhost", "http://localhost:9090/", "", nil},
+			{"loopback", "http://127.0.0.1/", "", nil},
+			{"ipv6 loopback", "http://[::1]/", "", nil},
+			{"metadata service", "http://169.254.169.254/latest/meta-data/", "", nil},
+			{"private network", "https://10.0.0.1/hooks", "", nil},
+			{"unspecified", "http://0.0.0.0/", "", nil},
+			{"multicast", "http://224.0.0.1/", "", nil},
+			{"mapped loopback", "http://[::ffff:127.0.0.1]/", "", nil},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := service.CreateWebhook(t.Context(), "retail", tt.url, tt.secret, tt.eventTypes)
+				assert.ErrorIs(t, err, errs.ErrInvalidWebhook)
+			})
+		}
+	})
+
+	t.Run("accepts private addresses when allowed", func(t *testing.T) {
+		service := NewWebhookService(&fakeWebhookRepository{}, true)
+		_, err := service.CreateWebhook(t.Context(), "retail", "http://localhost:9090/", "", nil)
+		require.NoError(t, err)
+	})
+
+	t.Run("lists dead letters a page at a time", func(t *testing.T) {
+		repository := &fakeWebhookRepository{}
+		service := NewWebhookService(repository, false)
+
+		_, _, err := service.ListDeadLetters(t.Context(), "retail", 3, 0, -5)
+		require.NoError(t, err)
+		assert.Equal(t, entity.WebhookDeliveryFilter{
+			Tenant: "retail", WebhookID: 3, Status: entity.WebhookDeliveryDead, Limit: DefaultPageSize,
+		}, repository.filter)
+
+		_, _, err = service.ListDeadLetters(t.Context(), "retail", 0, 10_000, 20)
+		require.NoError(t, err)
+		assert.Equal(t, MaxPageSize, repository.filter.Limit)
+		assert.Equal(t, 20, repository.filter.Offset)
+	})
+}
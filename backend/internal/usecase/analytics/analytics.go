@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"log/slog"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+)
+
+type AnalyticsService interface {
+	SurplusByBucket(filter entity.AnalyticsFilter) ([]*entity.SurplusPoint, error)
+	ExactMatchRates(filter entity.AnalyticsFilter) ([]*entity.ExactMatchRate, error)
+	PackSizeUsage(filter entity.AnalyticsFilter) ([]*entity.PackSizeUsage, error)
+	PacksPerOrder(filter entity.AnalyticsFilter) ([]*entity.PacksPerOrderPoint, error)
+}
+
+type GetSurplusTrendUseCase struct {
+	service AnalyticsService
+	logger  *slog.Logger
+}
+
+func NewGetSurplusTrendUseCase(service AnalyticsService, logger *slog.Logger) *GetSurplusTrendUseCase {
+	return &GetSurplusTrendUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *GetSurplusTrendUseCase) Execute(filter entity.AnalyticsFilter) ([]*entity.SurplusPoint, error) {
+	uc.logger.Info("Executing get surplus trend use case", "bucket", filter.Bucket)
+
+	points, err := uc.service.SurplusByBucket(filter)
+	if err != nil {
+		uc.logger.Error("Failed to get surplus trend", "error", err)
+		return nil, err
+	}
+
+	uc.logger.Info("Successfully retrieved surplus trend", "points", len(points))
+	return points, nil
+}
+
+type GetExactMatchRatesUseCase struct {
+	service AnalyticsService
+	logger  *slog.Logger
+}
+
+func NewGetExactMatchRatesUseCase(service AnalyticsService, logger *slog.Logger) *GetExactMatchRatesUseCase {
+	return &GetExactMatchRatesUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *GetExactMatchRatesUseCase) Execute(filter entity.AnalyticsFilter) ([]*entity.ExactMatchRate, error) {
+	uc.logger.Info("Executing get exact match rates use case", "bucket", filter.Bucket)
+
+	rates, err := uc.service.ExactMatchRates(filter)
+	if err != nil {
+		uc.logger.Error("Failed to get exact match rates", "error", err)
+		return nil, err
+	}
+
+	uc.logger.Info("Successfully retrieved exact match rates", "rows", len(rates))
+	return rates, nil
+}
+
+type GetPackSizeUsageUseCase struct {
+	service AnalyticsService
+	logger  *slog.Logger
+}
+
+func NewGetPackSizeUsageUseCase(service AnalyticsService, logger *slog.Logger) *GetPackSizeUsageUseCase {
+	return &GetPackSizeUsageUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *GetPackSizeUsageUseCase) Execute(filter entity.AnalyticsFilter) ([]*entity.PackSizeUsage, error) {
+	uc.logger.Info("Executing get pack size usage use case", "limit", filter.Limit)
+
+	usage, err := uc.service.PackSizeUsage(filter)
+	if err != nil {
+		uc.logger.Error("Failed to get pack size usage", "error", err)
+		return nil, err
+	}
+
+	uc.logger.Info("Successfully retrieved pack size usage", "rows", len(usage))
+	return usage, nil
+}
+
+type GetPacksPerOrderUseCase struct {
+	service AnalyticsService
+	logger  *slog.Logger
+}
+
+func NewGetPacksPerOrderUseCase(service AnalyticsService, logger *slog.Logger) *GetPacksPerOrderUseCase {
+	return &GetPacksPerOrderUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *GetPacksPerOrderUseCase) Execute(filter entity.AnalyticsFilter) ([]*entity.PacksPerOrderPoint, error) {
+	uc.logger.Info("Executing get packs per order use case", "bucket", filter.Bucket)
+
+	points, err := uc.service.PacksPerOrder(filter)
+	if err != nil {
+		uc.logger.Error("Failed to get packs per order", "error", err)
+		return nil, err
+	}
+
+	uc.logger.Info("Successfully retrieved packs per order", "points", len(points))
+	return points, nil
+}
@@ -0,0 +1,40 @@
+package usecase
+
+import (
+	"log/slog"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+)
+
+type AuditService interface {
+	ListAuditEntries(filter entity.AuditFilter) ([]*entity.AuditEntry, int, error)
+}
+
+type ListAuditEntriesUseCase struct {
+	service AuditService
+	logger  *slog.Logger
+}
+
+func NewListAuditEntriesUseCase(service AuditService, logger *slog.Logger) *ListAuditEntriesUseCase {
+	return &ListAuditEntriesUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *ListAuditEntriesUseCase) Execute(filter entity.AuditFilter) ([]*entity.AuditEntry, int, error) {
+	uc.logger.Info("Executing list audit entries use case",
+		"entity_type", filter.EntityType,
+		"actor", filter.Actor,
+		"limit", filter.Limit,
+		"offset", filter.Offset)
+
+	entries, total, err := uc.service.ListAuditEntries(filter)
+	if err != nil {
+		uc.logger.Error("Failed to list audit entries", "error", err)
+		return nil, 0, err
+	}
+
+	uc.logger.Info("Successfully listed audit entries", "count", len(entries), "total", total)
+	return entries, total, nil
+}
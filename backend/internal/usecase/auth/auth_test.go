@@ -33,8 +33,8 @@ func (m *mockAuthService) ValidateToken(token string) (*entity.JWTClaims, error)
 	return args.Get(0).(*entity.JWTClaims), args.Error(1)
 }
 
-func (m *mockAuthService) GenerateToken(subject string) (*entity.AuthResult, error) {
-	args := m.Called(subject)
+func (m *mockAuthService) GenerateToken(subject, tenant string) (*entity.AuthResult, error) {
+	args := m.Called(subject, tenant)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
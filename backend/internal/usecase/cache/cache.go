@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"log/slog"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+)
+
+type CacheStatsProvider interface {
+	Stats() entity.CacheStats
+}
+
+type GetCacheStatsUseCase struct {
+	cache  CacheStatsProvider
+	logger *slog.Logger
+}
+
+func NewGetCacheStatsUseCase(cache CacheStatsProvider, logger *slog.Logger) *GetCacheStatsUseCase {
+	return &GetCacheStatsUseCase{
+		cache:  cache,
+		logger: logger,
+	}
+}
+
+func (uc *GetCacheStatsUseCase) Execute() entity.CacheStats {
+	uc.logger.Debug("Executing get cache stats use case")
+	return uc.cache.Stats()
+}
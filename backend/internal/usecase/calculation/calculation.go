@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+)
+
+type CalculationService interface {
+	RecordCalculation(record *entity.CalculationRecord) (*entity.CalculationRecord, error)
+	GetCalculation(tenant string, id int64) (*entity.CalculationRecord, error)
+	ListCalculations(filter entity.CalculationFilter) ([]*entity.CalculationRecord, int, error)
+	PruneCalculations(retention time.Duration) (int64, error)
+}
+
+type RecordCalculationUseCase struct {
+	service CalculationService
+	logger  *slog.Logger
+}
+
+func NewRecordCalculationUseCase(service CalculationService, logger *slog.Logger) *RecordCalculationUseCase {
+	return &RecordCalculationUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *RecordCalculationUseCase) Execute(record *entity.CalculationRecord) (*entity.CalculationRecord, error) {
+	uc.logger.Info("Executing record calculation use case", "subject", record.Subject, "items", record.Items)
+
+	saved, err := uc.service.RecordCalculation(record)
+	if err != nil {
+		uc.logger.Error("Failed to record calculation", "error", err)
+		return nil, err
+	}
+
+	uc.logger.Info("Successfully recorded calculation", "id", saved.ID)
+	return saved, nil
+}
+
+type GetCalculationUseCase struct {
+	service CalculationService
+	logger  *slog.Logger
+}
+
+func NewGetCalculationUseCase(service CalculationService, logger *slog.Logger) *GetCalculationUseCase {
+	return &GetCalculationUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *GetCalculationUseCase) Execute(tenant string, id int64) (*entity.CalculationRecord, error) {
+	uc.logger.Info("Executing get calculation use case", "id", id)
+
+	if id <= 0 {
+		uc.logger.Warn("Invalid calculation ID", "id", id)
+		return nil, errs.ErrInvalidID.Withf("invalid calculation ID: %d", id)
+	}
+
+	record, err := uc.service.GetCalculation(tenant, id)
+	if err != nil {
+		uc.logger.Error("Failed to get calculation", "id", id, "error", err)
+		return nil, err
+	}
+
+	uc.logger.Info("Successfully retrieved calculation", "id", id)
+	return record, nil
+}
+
+type ListCalculationsUseCase struct {
+	service CalculationService
+	logger  *slog.Logger
+}
+
+func NewListCalculationsUseCase(service CalculationService, logger *slog.Logger) *ListCalculationsUseCase {
+	return &ListCalculationsUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *ListCalculationsUseCase) Execute(filter entity.CalculationFilter) ([]*entity.CalculationRecord, int, error) {
+	uc.logger.Info("Executing list calculations use case",
+		"subject", filter.Subject,
+		"limit", filter.Limit,
+		"offset", filter.Offset)
+
+	records, total, err := uc.service.ListCalculations(filter)
+	if err != nil {
+		uc.logger.Error("Failed to list calculations", "error", err)
+		return nil, 0, err
+	}
+
+	uc.logger.Info("Successfully listed calculations", "count", len(records), "total", total)
+	return records, total, nil
+}
+
+type PruneCalculationsUseCase struct {
+	service CalculationService
+	logger  *slog.Logger
+}
+
+func NewPruneCalculationsUseCase(service CalculationService, logger *slog.Logger) *PruneCalculationsUseCase {
+	return &PruneCalculationsUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *PruneCalculationsUseCase) Execute(retention time.Duration) (int64, error) {
+	uc.logger.Debug("Executing prune calculations use case", "retention", retention)
+
+	deleted, err := uc.service.PruneCalculations(retention)
+	if err != nil {
+		uc.logger.Error("Failed to prune calculations", "error", err)
+		return 0, err
+	}
+
+	uc.logger.Info("Pruned calculation history", "deleted", deleted, "retention", retention)
+	return deleted, nil
+}
@@ -0,0 +1,245 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+)
+
+type InventoryService interface {
+	GetStock(ctx context.Context, tenant string, location string) ([]*entity.StockLevel, error)
+	SetStock(ctx context.Context, tenant string, location string, packSize int, onHand int) (*entity.StockLevel, error)
+	CalculateWithStock(ctx context.Context, tenant string, location string, packSizes []int, orderQuantity int) (*entity.CalculationResult, error)
+	ReserveOrder(ctx context.Context, tenant string, location string, packSizes []int, orderQuantity int) (*entity.Reservation, error)
+	GetReservation(ctx context.Context, tenant string, id int) (*entity.Reservation, error)
+	CancelReservation(ctx context.Context, tenant string, id int) (*entity.Reservation, error)
+}
+
+type GetStockUseCase struct {
+	service InventoryService
+	logger  *slog.Logger
+}
+
+func NewGetStockUseCase(service InventoryService, logger *slog.Logger) *GetStockUseCase {
+	return &GetStockUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *GetStockUseCase) Execute(ctx context.Context, tenant string, location string) ([]*entity.StockLevel, error) {
+	uc.logger.Info("Executing get stock use case", "tenant", tenant, "location", location)
+
+	if location == "" {
+		uc.logger.Warn("Empty stock location")
+		return nil, errs.ErrInvalidStockLevel.Withf("stock location cannot be empty")
+	}
+
+	levels, err := uc.service.GetStock(ctx, tenant, location)
+	if err != nil {
+		uc.logger.Error("Failed to get stock levels", "location", location, "error", err)
+		return nil, err
+	}
+
+	uc.logger.Info("Successfully retrieved stock levels", "location", location, "count", len(levels))
+	return levels, nil
+}
+
+type SetStockUseCase struct {
+	service InventoryService
+	logger  *slog.Logger
+}
+
+func NewSetStockUseCase(service InventoryService, logger *slog.Logger) *SetStockUseCase {
+	return &SetStockUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *SetStockUseCase) Execute(ctx context.Context, tenant string, location string, packSize int, onHand int) (*entity.StockLevel, error) {
+	uc.logger.Info("Executing set stock use case", "tenant", tenant, "location", location, "pack_size", packSize, "on_hand", onHand)
+
+	if err := uc.validateInput(location, packSize, onHand); err != nil {
+		uc.logger.Warn("Set stock input validation failed", "error", err)
+		return nil, err
+	}
+
+	level, err := uc.service.SetStock(ctx, tenant, location, packSize, onHand)
+	if err != nil {
+		uc.logger.Error("Failed to set stock level", "location", location, "pack_size", packSize, "error", err)
+		return nil, err
+	}
+
+	uc.logger.Info("Successfully set stock level", "location", location, "pack_size", packSize, "on_hand", level.OnHand)
+	return level, nil
+}
+
+func (uc *SetStockUseCase) validateInput(location string, packSize int, onHand int) error {
+	if location == "" {
+		return errs.ErrInvalidStockLevel.Withf("stock location cannot be empty")
+	}
+
+	if packSize <= 0 {
+		return errs.ErrInvalidStockLevel.Withf("pack size must be positive, got %d", packSize)
+	}
+
+	if onHand < 0 {
+		return errs.ErrInvalidStockLevel.Withf("on hand quantity cannot be negative, got %d", onHand)
+	}
+
+	return nil
+}
+
+type CalculateWithStockUseCase struct {
+	service InventoryService
+	logger  *slog.Logger
+}
+
+func NewCalculateWithStockUseCase(service InventoryService, logger *slog.Logger) *CalculateWithStockUseCase {
+	return &CalculateWithStockUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *CalculateWithStockUseCase) Execute(ctx context.Context, tenant string, location string, packSizes []int, orderQuantity int) (*entity.CalculationResult, error) {
+	uc.logger.Info("Executing stock-aware pack calculation use case",
+		"tenant", tenant,
+		"location", location,
+		"order_quantity", orderQuantity,
+		"pack_sizes", packSizes)
+
+	if err := validateOrderInput(location, packSizes, orderQuantity); err != nil {
+		uc.logger.Warn("Stock-aware calculation input validation failed", "error", err)
+		return nil, err
+	}
+
+	result, err := uc.service.CalculateWithStock(ctx, tenant, location, packSizes, orderQuantity)
+	if err != nil {
+		uc.logger.Error("Stock-aware pack calculation failed", "location", location, "error", err)
+		return nil, err
+	}
+
+	uc.logger.Info("Stock-aware pack calculation completed successfully",
+		"location", location,
+		"total_packs", result.Allocation.TotalPacks(),
+		"total_items", result.Allocation.TotalItems(),
+		"surplus", result.Surplus)
+
+	return result, nil
+}
+
+type ReserveOrderUseCase struct {
+	service InventoryService
+	logger  *slog.Logger
+}
+
+func NewReserveOrderUseCase(service InventoryService, logger *slog.Logger) *ReserveOrderUseCase {
+	return &ReserveOrderUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *ReserveOrderUseCase) Execute(ctx context.Context, tenant string, location string, packSizes []int, orderQuantity int) (*entity.Reservation, error) {
+	uc.logger.Info("Executing reserve order use case",
+		"tenant", tenant,
+		"location", location,
+		"order_quantity", orderQuantity,
+		"pack_sizes", packSizes)
+
+	if err := validateOrderInput(location, packSizes, orderQuantity); err != nil {
+		uc.logger.Warn("Reserve order input validation failed", "error", err)
+		return nil, err
+	}
+
+	reservation, err := uc.service.ReserveOrder(ctx, tenant, location, packSizes, orderQuantity)
+	if err != nil {
+		uc.logger.Error("Failed to reserve order", "location", location, "error", err)
+		return nil, err
+	}
+
+	uc.logger.Info("Successfully reserved order", "id", reservation.ID, "location", location, "allocation", reservation.Allocation)
+	return reservation, nil
+}
+
+type GetReservationUseCase struct {
+	service InventoryService
+	logger  *slog.Logger
+}
+
+func NewGetReservationUseCase(service InventoryService, logger *slog.Logger) *GetReservationUseCase {
+	return &GetReservationUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *GetReservationUseCase) Execute(ctx context.Context, tenant string, id int) (*entity.Reservation, error) {
+	uc.logger.Info("Executing get reservation use case", "tenant", tenant, "id", id)
+
+	if id <= 0 {
+		uc.logger.Warn("Invalid reservation ID", "id", id)
+		return nil, errs.ErrInvalidID.Withf("invalid reservation ID: %d", id)
+	}
+
+	reservation, err := uc.service.GetReservation(ctx, tenant, id)
+	if err != nil {
+		uc.logger.Error("Failed to get reservation", "id", id, "error", err)
+		return nil, err
+	}
+
+	uc.logger.Info("Successfully retrieved reservation", "id", id, "status", reservation.Status)
+	return reservation, nil
+}
+
+type CancelReservationUseCase struct {
+	service InventoryService
+	logger  *slog.Logger
+}
+
+func NewCancelReservationUseCase(service InventoryService, logger *slog.Logger) *CancelReservationUseCase {
+	return &CancelReservationUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *CancelReservationUseCase) Execute(ctx context.Context, tenant string, id int) (*entity.Reservation, error) {
+	uc.logger.Info("Executing cancel reservation use case", "tenant", tenant, "id", id)
+
+	if id <= 0 {
+		uc.logger.Warn("Invalid reservation ID", "id", id)
+		return nil, errs.ErrInvalidID.Withf("invalid reservation ID: %d", id)
+	}
+
+	reservation, err := uc.service.CancelReservation(ctx, tenant, id)
+	if err != nil {
+		uc.logger.Error("Failed to cancel reservation", "id", id, "error", err)
+		return nil, err
+	}
+
+	uc.logger.Info("Successfully cancelled reservation", "id", id)
+	return reservation, nil
+}
+
+func validateOrderInput(location string, packSizes []int, orderQuantity int) error {
+	if location == "" {
+		return errs.ErrInvalidStockLevel.Withf("stock location cannot be empty")
+	}
+
+	if orderQuantity < 0 {
+		return errs.ErrInvalidOrder.Withf("order quantity cannot be negative")
+	}
+
+	for _, size := range packSizes {
+		if size <= 0 {
+			return errs.ErrInvalidOrder.Withf("pack sizes must be positive")
+		}
+	}
+
+	return nil
+}
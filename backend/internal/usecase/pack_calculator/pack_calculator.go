@@ -5,6 +5,7 @@ import (
 	"log/slog"
 
 	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
 )
 
 type CalculatePacksUseCase struct {
@@ -59,12 +60,12 @@ func (uc *CalculatePacksUseCase) Execute(packSizes []int, orderQuantity int) (*e
 
 func (uc *CalculatePacksUseCase) validateInput(packSizes []int, orderQuantity int) error {
 	if orderQuantity < 0 {
-		return fmt.Errorf("order quantity cannot be negative")
+		return errs.ErrInvalidOrder.Withf("order quantity cannot be negative")
 	}
 
 	for _, size := range packSizes {
 		if size <= 0 {
-			return fmt.Errorf("pack sizes must be positive")
+			return errs.ErrInvalidOrder.Withf("pack sizes must be positive")
 		}
 	}
 
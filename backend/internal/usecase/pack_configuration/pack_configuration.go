@@ -1,45 +1,63 @@
 package usecase
 
 import (
-	"fmt"
+	"context"
 	"log/slog"
+	"time"
 
 	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
 )
 
 type PackConfigurationService interface {
-	GetAllConfigurations() ([]*entity.PackConfiguration, error)
-	GetConfigurationByID(id int) (*entity.PackConfiguration, error)
-	GetDefaultConfiguration() (*entity.PackConfiguration, error)
-	CreateConfiguration(name string, packSizes []int) (*entity.PackConfiguration, error)
-	UpdateConfiguration(id int, name string, packSizes []int, isDefault bool) (*entity.PackConfiguration, error)
-	DeleteConfiguration(id int) error
-	SetDefaultConfiguration(id int) error
+	ListConfigurations(ctx context.Context, query entity.PackConfigurationQuery) ([]*entity.PackConfiguration, int, error)
+	GetConfigurationByID(ctx context.Context, tenant string, id int) (*entity.PackConfiguration, error)
+	GetDefaultConfiguration(ctx context.Context, tenant string, asOf time.Time) (*entity.PackConfiguration, error)
+	GetEffectiveConfiguration(ctx context.Context, tenant string, id int, asOf time.Time) (*entity.PackConfiguration, error)
+	CreateConfiguration(ctx context.Context, actor entity.Actor, name string, packSizes []int, packTypeCodes []string, period entity.EffectivePeriod, details entity.PackConfigurationDetails) (*entity.PackConfiguration, error)
+	UpsertConfiguration(ctx context.Context, actor entity.Actor, name string, packSizes []int, packTypeCodes []string, period entity.EffectivePeriod, details entity.PackConfigurationDetails) (*entity.PackConfiguration, bool, error)
+	UpdateConfiguration(ctx context.Context, actor entity.Actor, id int, version int, name string, packSizes []int, packTypeCodes []string, period entity.EffectivePeriod, details entity.PackConfigurationDetails, isDefault bool) (*entity.PackConfiguration, error)
+	PatchConfiguration(ctx context.Context, actor entity.Actor, id int, version int, patch entity.PackConfigurationPatch) (*entity.PackConfiguration, error)
+	DeleteConfiguration(ctx context.Context, actor entity.Actor, id int, version int) error
+	SetDefaultConfiguration(ctx context.Context, actor entity.Actor, id int, version int) error
+	GetConfigurationVersions(ctx context.Context, tenant string, id int) ([]*entity.PackConfigurationVersion, error)
+	GetConfigurationVersion(ctx context.Context, tenant string, id int, version int) (*entity.PackConfigurationVersion, error)
+	RestoreConfigurationVersion(ctx context.Context, actor entity.Actor, id int, version int) (*entity.PackConfiguration, error)
+	RestoreConfiguration(ctx context.Context, actor entity.Actor, id int, name string) (*entity.PackConfiguration, error)
+	PurgeConfiguration(ctx context.Context, actor entity.Actor, id int) error
+	ExportConfigurations(ctx context.Context, tenant string) ([]*entity.PackConfiguration, error)
+	ImportConfigurations(ctx context.Context, actor entity.Actor, rows []entity.PackConfigurationImportRow, options entity.ImportOptions) ([]entity.PackConfigurationImportResult, error)
+	FindEquivalentConfigurations(ctx context.Context, tenant string, packSizes []int) (*entity.PackSizeEquivalence, error)
 }
 
-type GetAllConfigurationsUseCase struct {
+type ListConfigurationsUseCase struct {
 	service PackConfigurationService
 	logger  *slog.Logger
 }
 
-func NewGetAllConfigurationsUseCase(service PackConfigurationService, logger *slog.Logger) *GetAllConfigurationsUseCase {
-	return &GetAllConfigurationsUseCase{
+func NewListConfigurationsUseCase(service PackConfigurationService, logger *slog.Logger) *ListConfigurationsUseCase {
+	return &ListConfigurationsUseCase{
 		service: service,
 		logger:  logger,
 	}
 }
 
-func (uc *GetAllConfigurationsUseCase) Execute() ([]*entity.PackConfiguration, error) {
-	uc.logger.Info("Executing get all pack configurations use case")
+func (uc *ListConfigurationsUseCase) Execute(ctx context.Context, query entity.PackConfigurationQuery) ([]*entity.PackConfiguration, int, error) {
+	uc.logger.Info("Executing list pack configurations use case",
+		"tenant", query.Tenant,
+		"archived", query.Archived,
+		"search", query.Search,
+		"limit", query.Limit,
+		"offset", query.Offset)
 
-	configurations, err := uc.service.GetAllConfigurations()
+	configurations, total, err := uc.service.ListConfigurations(ctx, query)
 	if err != nil {
-		uc.logger.Error("Failed to get all pack configurations", "error", err)
-		return nil, err
+		uc.logger.Error("Failed to list pack configurations", "error", err)
+		return nil, 0, err
 	}
 
-	uc.logger.Info("Successfully retrieved pack configurations", "count", len(configurations))
-	return configurations, nil
+	uc.logger.Info("Successfully listed pack configurations", "count", len(configurations), "total", total)
+	return configurations, total, nil
 }
 
 type GetConfigurationByIDUseCase struct {
@@ -54,15 +72,15 @@ func NewGetConfigurationByIDUseCase(service PackConfigurationService, logger *sl
 	}
 }
 
-func (uc *GetConfigurationByIDUseCase) Execute(id int) (*entity.PackConfiguration, error) {
-	uc.logger.Info("Executing get pack configuration by ID use case", "id", id)
+func (uc *GetConfigurationByIDUseCase) Execute(ctx context.Context, tenant string, id int) (*entity.PackConfiguration, error) {
+	uc.logger.Info("Executing get pack configuration by ID use case", "tenant", tenant, "id", id)
 
 	if id <= 0 {
 		uc.logger.Warn("Invalid pack configuration ID", "id", id)
-		return nil, fmt.Errorf("invalid pack configuration ID: %d", id)
+		return nil, errs.ErrInvalidID.Withf("invalid pack configuration ID: %d", id)
 	}
 
-	configuration, err := uc.service.GetConfigurationByID(id)
+	configuration, err := uc.service.GetConfigurationByID(ctx, tenant, id)
 	if err != nil {
 		uc.logger.Error("Failed to get pack configuration by ID", "id", id, "error", err)
 		return nil, err
@@ -84,10 +102,10 @@ func NewGetDefaultConfigurationUseCase(service PackConfigurationService, logger
 	}
 }
 
-func (uc *GetDefaultConfigurationUseCase) Execute() (*entity.PackConfiguration, error) {
-	uc.logger.Info("Executing get default pack configuration use case")
+func (uc *GetDefaultConfigurationUseCase) Execute(ctx context.Context, tenant string, asOf time.Time) (*entity.PackConfiguration, error) {
+	uc.logger.Info("Executing get default pack configuration use case", "tenant", tenant, "as_of", asOf)
 
-	configuration, err := uc.service.GetDefaultConfiguration()
+	configuration, err := uc.service.GetDefaultConfiguration(ctx, tenant, asOf)
 	if err != nil {
 		uc.logger.Error("Failed to get default pack configuration", "error", err)
 		return nil, err
@@ -97,6 +115,36 @@ func (uc *GetDefaultConfigurationUseCase) Execute() (*entity.PackConfiguration,
 	return configuration, nil
 }
 
+type GetEffectiveConfigurationUseCase struct {
+	service PackConfigurationService
+	logger  *slog.Logger
+}
+
+func NewGetEffectiveConfigurationUseCase(service PackConfigurationService, logger *slog.Logger) *GetEffectiveConfigurationUseCase {
+	return &GetEffectiveConfigurationUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *GetEffectiveConfigurationUseCase) Execute(ctx context.Context, tenant string, id int, asOf time.Time) (*entity.PackConfiguration, error) {
+	uc.logger.Info("Executing get effective pack configuration use case", "tenant", tenant, "id", id, "as_of", asOf)
+
+	if id <= 0 {
+		uc.logger.Warn("Invalid pack configuration ID", "id", id)
+		return nil, errs.ErrInvalidID.Withf("invalid pack configuration ID: %d", id)
+	}
+
+	configuration, err := uc.service.GetEffectiveConfiguration(ctx, tenant, id, asOf)
+	if err != nil {
+		uc.logger.Warn("Failed to get effective pack configuration", "id", id, "error", err)
+		return nil, err
+	}
+
+	uc.logger.Info("Successfully retrieved effective pack configuration", "id", id, "version", configuration.Version)
+	return configuration, nil
+}
+
 type CreateConfigurationUseCase struct {
 	service PackConfigurationService
 	logger  *slog.Logger
@@ -109,15 +157,15 @@ func NewCreateConfigurationUseCase(service PackConfigurationService, logger *slo
 	}
 }
 
-func (uc *CreateConfigurationUseCase) Execute(name string, packSizes []int) (*entity.PackConfiguration, error) {
-	uc.logger.Info("Executing create pack configuration use case", "name", name, "pack_sizes", packSizes)
+func (uc *CreateConfigurationUseCase) Execute(ctx context.Context, actor entity.Actor, name string, packSizes []int, packTypeCodes []string, period entity.EffectivePeriod, details entity.PackConfigurationDetails) (*entity.PackConfiguration, error) {
+	uc.logger.Info("Executing create pack configuration use case", "name", name, "pack_sizes", packSizes, "pack_types", packTypeCodes, "actor", actor.Subject)
 
-	if err := uc.validateInput(name, packSizes); err != nil {
+	if err := validateCreateInput(name, packSizes, packTypeCodes); err != nil {
 		uc.logger.Warn("Create pack configuration input validation failed", "error", err)
 		return nil, err
 	}
 
-	configuration, err := uc.service.CreateConfiguration(name, packSizes)
+	configuration, err := uc.service.CreateConfiguration(ctx, actor, name, packSizes, packTypeCodes, period, details)
 	if err != nil {
 		uc.logger.Error("Failed to create pack configuration", "name", name, "error", err)
 		return nil, err
@@ -127,24 +175,69 @@ func (uc *CreateConfigurationUseCase) Execute(name string, packSizes []int) (*en
 	return configuration, nil
 }
 
-func (uc *CreateConfigurationUseCase) validateInput(name string, packSizes []int) error {
+func validateCreateInput(name string, packSizes []int, packTypeCodes []string) error {
 	if name == "" {
-		return fmt.Errorf("pack configuration name cannot be empty")
+		return errs.ErrInvalidPackConfiguration.Withf("pack configuration name cannot be empty")
 	}
 
-	if len(packSizes) == 0 {
-		return fmt.Errorf("pack configuration must have at least one pack size")
+	return validatePackSizes(packSizes, packTypeCodes)
+}
+
+// validatePackSizes requires at least one pack size or pack type, since a
+// pack type offers its item count as a pack size
+func validatePackSizes(packSizes []int, packTypeCodes []string) error {
+	if len(packSizes) == 0 && len(packTypeCodes) == 0 {
+		return errs.ErrInvalidPackConfiguration.Withf("pack configuration must have at least one pack size or pack type")
 	}
 
 	for _, size := range packSizes {
 		if size <= 0 {
-			return fmt.Errorf("pack sizes must be positive, got %d", size)
+			return errs.ErrInvalidPackConfiguration.Withf("pack sizes must be positive, got %d", size)
+		}
+	}
+
+	for _, code := range packTypeCodes {
+		if code == "" {
+			return errs.ErrInvalidPackConfiguration.Withf("pack type codes cannot be empty")
 		}
 	}
 
 	return nil
 }
 
+type UpsertConfigurationUseCase struct {
+	service PackConfigurationService
+	logger  *slog.Logger
+}
+
+func NewUpsertConfigurationUseCase(service PackConfigurationService, logger *slog.Logger) *UpsertConfigurationUseCase {
+	return &UpsertConfigurationUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *UpsertConfigurationUseCase) Execute(ctx context.Context, actor entity.Actor, name string, packSizes []int, packTypeCodes []string, period entity.EffectivePeriod, details entity.PackConfigurationDetails) (*entity.PackConfiguration, bool, error) {
+	uc.logger.Info("Executing upsert pack configuration use case", "name", name, "pack_sizes", packSizes, "pack_types", packTypeCodes, "actor", actor.Subject)
+
+	if err := validateCreateInput(name, packSizes, packTypeCodes); err != nil {
+		uc.logger.Warn("Upsert pack configuration input validation failed", "error", err)
+		return nil, false, err
+	}
+
+	configuration, created, err := uc.service.UpsertConfiguration(ctx, actor, name, packSizes, packTypeCodes, period, details)
+	if err != nil {
+		uc.logger.Error("Failed to upsert pack configuration", "name", name, "error", err)
+		return nil, false, err
+	}
+
+	uc.logger.Info("Successfully upserted pack configuration",
+		"id", configuration.ID,
+		"name", configuration.Name,
+		"created", created)
+	return configuration, created, nil
+}
+
 type UpdateConfigurationUseCase struct {
 	service PackConfigurationService
 	logger  *slog.Logger
@@ -157,15 +250,15 @@ func NewUpdateConfigurationUseCase(service PackConfigurationService, logger *slo
 	}
 }
 
-func (uc *UpdateConfigurationUseCase) Execute(id int, name string, packSizes []int, isDefault bool) (*entity.PackConfiguration, error) {
-	uc.logger.Info("Executing update pack configuration use case", "id", id, "name", name, "pack_sizes", packSizes, "is_default", isDefault)
+func (uc *UpdateConfigurationUseCase) Execute(ctx context.Context, actor entity.Actor, id int, version int, name string, packSizes []int, packTypeCodes []string, period entity.EffectivePeriod, details entity.PackConfigurationDetails, isDefault bool) (*entity.PackConfiguration, error) {
+	uc.logger.Info("Executing update pack configuration use case", "id", id, "version", version, "name", name, "pack_sizes", packSizes, "pack_types", packTypeCodes, "is_default", isDefault, "actor", actor.Subject)
 
-	if err := uc.validateInput(id, name, packSizes); err != nil {
+	if err := uc.validateInput(id, name, packSizes, packTypeCodes); err != nil {
 		uc.logger.Warn("Update pack configuration input validation failed", "error", err)
 		return nil, err
 	}
 
-	configuration, err := uc.service.UpdateConfiguration(id, name, packSizes, isDefault)
+	configuration, err := uc.service.UpdateConfiguration(ctx, actor, id, version, name, packSizes, packTypeCodes, period, details, isDefault)
 	if err != nil {
 		uc.logger.Error("Failed to update pack configuration", "id", id, "error", err)
 		return nil, err
@@ -175,26 +268,53 @@ func (uc *UpdateConfigurationUseCase) Execute(id int, name string, packSizes []i
 	return configuration, nil
 }
 
-func (uc *UpdateConfigurationUseCase) validateInput(id int, name string, packSizes []int) error {
+func (uc *UpdateConfigurationUseCase) validateInput(id int, name string, packSizes []int, packTypeCodes []string) error {
 	if id <= 0 {
-		return fmt.Errorf("invalid pack configuration ID: %d", id)
+		return errs.ErrInvalidID.Withf("invalid pack configuration ID: %d", id)
 	}
 
 	if name == "" {
-		return fmt.Errorf("pack configuration name cannot be empty")
+		return errs.ErrInvalidPackConfiguration.Withf("pack configuration name cannot be empty")
 	}
 
-	if len(packSizes) == 0 {
-		return fmt.Errorf("pack configuration must have at least one pack size")
+	return validatePackSizes(packSizes, packTypeCodes)
+}
+
+type PatchConfigurationUseCase struct {
+	service PackConfigurationService
+	logger  *slog.Logger
+}
+
+func NewPatchConfigurationUseCase(service PackConfigurationService, logger *slog.Logger) *PatchConfigurationUseCase {
+	return &PatchConfigurationUseCase{
+		service: service,
+		logger:  logger,
 	}
+}
 
-	for _, size := range packSizes {
-		if size <= 0 {
-			return fmt.Errorf("pack sizes must be positive, got %d", size)
-		}
+func (uc *PatchConfigurationUseCase) Execute(ctx context.Context, actor entity.Actor, id int, version int, patch entity.PackConfigurationPatch) (*entity.PackConfiguration, error) {
+	uc.logger.Info("Executing patch pack configuration use case",
+		"id", id,
+		"version", version,
+		"pack_sizes", patch.PackSizes,
+		"add_pack_sizes", patch.AddPackSizes,
+		"remove_pack_sizes", patch.RemovePackSizes,
+		"pack_types", patch.PackTypes,
+		"actor", actor.Subject)
+
+	if id <= 0 {
+		uc.logger.Warn("Invalid pack configuration ID", "id", id)
+		return nil, errs.ErrInvalidID.Withf("invalid pack configuration ID: %d", id)
 	}
 
-	return nil
+	configuration, err := uc.service.PatchConfiguration(ctx, actor, id, version, patch)
+	if err != nil {
+		uc.logger.Error("Failed to patch pack configuration", "id", id, "error", err)
+		return nil, err
+	}
+
+	uc.logger.Info("Successfully patched pack configuration", "id", configuration.ID, "version", configuration.Version)
+	return configuration, nil
 }
 
 type DeleteConfigurationUseCase struct {
@@ -209,15 +329,15 @@ func NewDeleteConfigurationUseCase(service PackConfigurationService, logger *slo
 	}
 }
 
-func (uc *DeleteConfigurationUseCase) Execute(id int) error {
-	uc.logger.Info("Executing delete pack configuration use case", "id", id)
+func (uc *DeleteConfigurationUseCase) Execute(ctx context.Context, actor entity.Actor, id int, version int) error {
+	uc.logger.Info("Executing delete pack configuration use case", "id", id, "version", version, "actor", actor.Subject)
 
 	if id <= 0 {
 		uc.logger.Warn("Invalid pack configuration ID", "id", id)
-		return fmt.Errorf("invalid pack configuration ID: %d", id)
+		return errs.ErrInvalidID.Withf("invalid pack configuration ID: %d", id)
 	}
 
-	err := uc.service.DeleteConfiguration(id)
+	err := uc.service.DeleteConfiguration(ctx, actor, id, version)
 	if err != nil {
 		uc.logger.Error("Failed to delete pack configuration", "id", id, "error", err)
 		return err
@@ -239,15 +359,15 @@ func NewSetDefaultConfigurationUseCase(service PackConfigurationService, logger
 	}
 }
 
-func (uc *SetDefaultConfigurationUseCase) Execute(id int) error {
-	uc.logger.Info("Executing set default pack configuration use case", "id", id)
+func (uc *SetDefaultConfigurationUseCase) Execute(ctx context.Context, actor entity.Actor, id int, version int) error {
+	uc.logger.Info("Executing set default pack configuration use case", "id", id, "version", version, "actor", actor.Subject)
 
 	if id <= 0 {
 		uc.logger.Warn("Invalid pack configuration ID", "id", id)
-		return fmt.Errorf("invalid pack configuration ID: %d", id)
+		return errs.ErrInvalidID.Withf("invalid pack configuration ID: %d", id)
 	}
 
-	err := uc.service.SetDefaultConfiguration(id)
+	err := uc.service.SetDefaultConfiguration(ctx, actor, id, version)
 	if err != nil {
 		uc.logger.Error("Failed to set default pack configuration", "id", id, "error", err)
 		return err
@@ -256,3 +376,250 @@ func (uc *SetDefaultConfigurationUseCase) Execute(id int) error {
 	uc.logger.Info("Successfully set default pack configuration", "id", id)
 	return nil
 }
+
+type GetConfigurationVersionsUseCase struct {
+	service PackConfigurationService
+	logger  *slog.Logger
+}
+
+func NewGetConfigurationVersionsUseCase(service PackConfigurationService, logger *slog.Logger) *GetConfigurationVersionsUseCase {
+	return &GetConfigurationVersionsUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *GetConfigurationVersionsUseCase) Execute(ctx context.Context, tenant string, id int) ([]*entity.PackConfigurationVersion, error) {
+	uc.logger.Info("Executing get pack configuration versions use case", "tenant", tenant, "id", id)
+
+	if id <= 0 {
+		uc.logger.Warn("Invalid pack configuration ID", "id", id)
+		return nil, errs.ErrInvalidID.Withf("invalid pack configuration ID: %d", id)
+	}
+
+	versions, err := uc.service.GetConfigurationVersions(ctx, tenant, id)
+	if err != nil {
+		uc.logger.Error("Failed to get pack configuration versions", "id", id, "error", err)
+		return nil, err
+	}
+
+	uc.logger.Info("Successfully retrieved pack configuration versions", "id", id, "count", len(versions))
+	return versions, nil
+}
+
+type GetConfigurationVersionUseCase struct {
+	service PackConfigurationService
+	logger  *slog.Logger
+}
+
+func NewGetConfigurationVersionUseCase(service PackConfigurationService, logger *slog.Logger) *GetConfigurationVersionUseCase {
+	return &GetConfigurationVersionUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *GetConfigurationVersionUseCase) Execute(ctx context.Context, tenant string, id int, version int) (*entity.PackConfigurationVersion, error) {
+	uc.logger.Info("Executing get pack configuration version use case", "tenant", tenant, "id", id, "version", version)
+
+	if id <= 0 || version <= 0 {
+		uc.logger.Warn("Invalid pack configuration version", "id", id, "version", version)
+		return nil, errs.ErrInvalidID.Withf("invalid pack configuration %d version %d", id, version)
+	}
+
+	snapshot, err := uc.service.GetConfigurationVersion(ctx, tenant, id, version)
+	if err != nil {
+		uc.logger.Error("Failed to get pack configuration version", "id", id, "version", version, "error", err)
+		return nil, err
+	}
+
+	uc.logger.Info("Successfully retrieved pack configuration version", "id", id, "version", version)
+	return snapshot, nil
+}
+
+type RestoreConfigurationVersionUseCase struct {
+	service PackConfigurationService
+	logger  *slog.Logger
+}
+
+func NewRestoreConfigurationVersionUseCase(service PackConfigurationService, logger *slog.Logger) *RestoreConfigurationVersionUseCase {
+	return &RestoreConfigurationVersionUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *RestoreConfigurationVersionUseCase) Execute(ctx context.Context, actor entity.Actor, id int, version int) (*entity.PackConfiguration, error) {
+	uc.logger.Info("Executing restore pack configuration version use case", "id", id, "version", version, "actor", actor.Subject)
+
+	if id <= 0 || version <= 0 {
+		uc.logger.Warn("Invalid pack configuration version", "id", id, "version", version)
+		return nil, errs.ErrInvalidID.Withf("invalid pack configuration %d version %d", id, version)
+	}
+
+	configuration, err := uc.service.RestoreConfigurationVersion(ctx, actor, id, version)
+	if err != nil {
+		uc.logger.Error("Failed to restore pack configuration version", "id", id, "version", version, "error", err)
+		return nil, err
+	}
+
+	uc.logger.Info("Successfully restored pack configuration version",
+		"id", id,
+		"restored_version", version,
+		"new_version", configuration.Version)
+	return configuration, nil
+}
+
+type RestoreConfigurationUseCase struct {
+	service PackConfigurationService
+	logger  *slog.Logger
+}
+
+func NewRestoreConfigurationUseCase(service PackConfigurationService, logger *slog.Logger) *RestoreConfigurationUseCase {
+	return &RestoreConfigurationUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *RestoreConfigurationUseCase) Execute(ctx context.Context, actor entity.Actor, id int, name string) (*entity.PackConfiguration, error) {
+	uc.logger.Info("Executing restore pack configuration use case", "id", id, "name", name, "actor", actor.Subject)
+
+	if id <= 0 {
+		uc.logger.Warn("Invalid pack configuration ID", "id", id)
+		return nil, errs.ErrInvalidID.Withf("invalid pack configuration ID: %d", id)
+	}
+
+	configuration, err := uc.service.RestoreConfiguration(ctx, actor, id, name)
+	if err != nil {
+		uc.logger.Error("Failed to restore pack configuration", "id", id, "error", err)
+		return nil, err
+	}
+
+	uc.logger.Info("Successfully restored pack configuration",
+		"id", id,
+		"name", configuration.Name,
+		"is_default", configuration.IsDefault)
+	return configuration, nil
+}
+
+type PurgeConfigurationUseCase struct {
+	service PackConfigurationService
+	logger  *slog.Logger
+}
+
+func NewPurgeConfigurationUseCase(service PackConfigurationService, logger *slog.Logger) *PurgeConfigurationUseCase {
+	return &PurgeConfigurationUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *PurgeConfigurationUseCase) Execute(ctx context.Context, actor entity.Actor, id int) error {
+	uc.logger.Info("Executing purge pack configuration use case", "id", id, "actor", actor.Subject)
+
+	if id <= 0 {
+		uc.logger.Warn("Invalid pack configuration ID", "id", id)
+		return errs.ErrInvalidID.Withf("invalid pack configuration ID: %d", id)
+	}
+
+	if err := uc.service.PurgeConfiguration(ctx, actor, id); err != nil {
+		uc.logger.Error("Failed to purge pack configuration", "id", id, "error", err)
+		return err
+	}
+
+	uc.logger.Warn("Pack configuration purged", "id", id, "actor", actor.Subject)
+	return nil
+}
+
+type ExportConfigurationsUseCase struct {
+	service PackConfigurationService
+	logger  *slog.Logger
+}
+
+func NewExportConfigurationsUseCase(service PackConfigurationService, logger *slog.Logger) *ExportConfigurationsUseCase {
+	return &ExportConfigurationsUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *ExportConfigurationsUseCase) Execute(ctx context.Context, tenant string) ([]*entity.PackConfiguration, error) {
+	uc.logger.Info("Executing export pack configurations use case", "tenant", tenant)
+
+	configurations, err := uc.service.ExportConfigurations(ctx, tenant)
+	if err != nil {
+		uc.logger.Error("Failed to export pack configurations", "error", err)
+		return nil, err
+	}
+
+	uc.logger.Info("Successfully exported pack configurations", "count", len(configurations))
+	return configurations, nil
+}
+
+type ImportConfigurationsUseCase struct {
+	service PackConfigurationService
+	logger  *slog.Logger
+}
+
+func NewImportConfigurationsUseCase(service PackConfigurationService, logger *slog.Logger) *ImportConfigurationsUseCase {
+	return &ImportConfigurationsUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *ImportConfigurationsUseCase) Execute(ctx context.Context, actor entity.Actor, rows []entity.PackConfigurationImportRow, options entity.ImportOptions) ([]entity.PackConfigurationImportResult, error) {
+	uc.logger.Info("Executing import pack configurations use case",
+		"rows", len(rows),
+		"upsert", options.Upsert,
+		"dry_run", options.DryRun,
+		"actor", actor.Subject)
+
+	if len(rows) == 0 {
+		uc.logger.Warn("Import has no configurations")
+		return nil, errs.ErrInvalidImport.Withf("import has no configurations")
+	}
+
+	results, err := uc.service.ImportConfigurations(ctx, actor, rows, options)
+	if err != nil {
+		uc.logger.Error("Failed to import pack configurations", "rows", len(rows), "error", err)
+		return results, err
+	}
+
+	uc.logger.Info("Successfully imported pack configurations", "rows", len(rows), "dry_run", options.DryRun)
+	return results, nil
+}
+
+type FindEquivalentConfigurationsUseCase struct {
+	service PackConfigurationService
+	logger  *slog.Logger
+}
+
+func NewFindEquivalentConfigurationsUseCase(service PackConfigurationService, logger *slog.Logger) *FindEquivalentConfigurationsUseCase {
+	return &FindEquivalentConfigurationsUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *FindEquivalentConfigurationsUseCase) Execute(ctx context.Context, tenant string, packSizes []int) (*entity.PackSizeEquivalence, error) {
+	uc.logger.Info("Executing find equivalent pack configurations use case", "tenant", tenant, "pack_sizes", packSizes)
+
+	if len(packSizes) == 0 {
+		uc.logger.Warn("Find equivalent pack configurations input validation failed")
+		return nil, errs.ErrInvalidPackConfiguration.Withf("at least one pack size is required")
+	}
+
+	equivalence, err := uc.service.FindEquivalentConfigurations(ctx, tenant, packSizes)
+	if err != nil {
+		uc.logger.Error("Failed to find equivalent pack configurations", "pack_sizes", packSizes, "error", err)
+		return nil, err
+	}
+
+	uc.logger.Info("Successfully found equivalent pack configurations",
+		"pack_sizes", equivalence.PackSizes,
+		"minimal_pack_sizes", equivalence.MinimalPackSizes,
+		"count", len(equivalence.Configurations))
+	return equivalence, nil
+}
@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+)
+
+type PackTypeService interface {
+	ListPackTypes(ctx context.Context, tenant string, includeInactive bool) ([]*entity.PackType, error)
+	GetPackTypeByID(ctx context.Context, tenant string, id int) (*entity.PackType, error)
+	CreatePackType(ctx context.Context, tenant string, packType entity.PackType) (*entity.PackType, error)
+	UpdatePackType(ctx context.Context, tenant string, id int, packType entity.PackType) (*entity.PackType, error)
+	DeletePackType(ctx context.Context, tenant string, id int) error
+}
+
+type ListPackTypesUseCase struct {
+	service PackTypeService
+	logger  *slog.Logger
+}
+
+func NewListPackTypesUseCase(service PackTypeService, logger *slog.Logger) *ListPackTypesUseCase {
+	return &ListPackTypesUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *ListPackTypesUseCase) Execute(ctx context.Context, tenant string, includeInactive bool) ([]*entity.PackType, error) {
+	uc.logger.Info("Executing list pack types use case", "tenant", tenant, "include_inactive", includeInactive)
+
+	packTypes, err := uc.service.ListPackTypes(ctx, tenant, includeInactive)
+	if err != nil {
+		uc.logger.Error("Failed to list pack types", "error", err)
+		return nil, err
+	}
+
+	uc.logger.Info("Successfully listed pack types", "count", len(packTypes))
+	return packTypes, nil
+}
+
+type GetPackTypeByIDUseCase struct {
+	service PackTypeService
+	logger  *slog.Logger
+}
+
+func NewGetPackTypeByIDUseCase(service PackTypeService, logger *slog.Logger) *GetPackTypeByIDUseCase {
+	return &GetPackTypeByIDUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *GetPackTypeByIDUseCase) Execute(ctx context.Context, tenant string, id int) (*entity.PackType, error) {
+	uc.logger.Info("Executing get pack type by ID use case", "tenant", tenant, "id", id)
+
+	if id <= 0 {
+		uc.logger.Warn("Invalid pack type ID", "id", id)
+		return nil, errs.ErrInvalidID.Withf("invalid pack type ID: %d", id)
+	}
+
+	packType, err := uc.service.GetPackTypeByID(ctx, tenant, id)
+	if err != nil {
+		uc.logger.Error("Failed to get pack type by ID", "id", id, "error", err)
+		return nil, err
+	}
+
+	uc.logger.Info("Successfully retrieved pack type", "id", id, "code", packType.Code)
+	return packType, nil
+}
+
+type CreatePackTypeUseCase struct {
+	service PackTypeService
+	logger  *slog.Logger
+}
+
+func NewCreatePackTypeUseCase(service PackTypeService, logger *slog.Logger) *CreatePackTypeUseCase {
+	return &CreatePackTypeUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *CreatePackTypeUseCase) Execute(ctx context.Context, tenant string, packType entity.PackType) (*entity.PackType, error) {
+	uc.logger.Info("Executing create pack type use case", "tenant", tenant, "code", packType.Code, "item_count", packType.ItemCount)
+
+	if err := packType.Validate(); err != nil {
+		uc.logger.Warn("Create pack type input validation failed", "error", err)
+		return nil, err
+	}
+
+	created, err := uc.service.CreatePackType(ctx, tenant, packType)
+	if err != nil {
+		uc.logger.Error("Failed to create pack type", "code", packType.Code, "error", err)
+		return nil, err
+	}
+
+	uc.logger.Info("Successfully created pack type", "id", created.ID, "code", created.Code)
+	return created, nil
+}
+
+type UpdatePackTypeUseCase struct {
+	service PackTypeService
+	logger  *slog.Logger
+}
+
+func NewUpdatePackTypeUseCase(service PackTypeService, logger *slog.Logger) *UpdatePackTypeUseCase {
+	return &UpdatePackTypeUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *UpdatePackTypeUseCase) Execute(ctx context.Context, tenant string, id int, packType entity.PackType) (*entity.PackType, error) {
+	uc.logger.Info("Executing update pack type use case", "tenant", tenant, "id", id, "code", packType.Code, "item_count", packType.ItemCount)
+
+	if id <= 0 {
+		uc.logger.Warn("Invalid pack type ID", "id", id)
+		return nil, errs.ErrInvalidID.Withf("invalid pack type ID: %d", id)
+	}
+
+	if err := packType.Validate(); err != nil {
+		uc.logger.Warn("Update pack type input validation failed", "error", err)
+		return nil, err
+	}
+
+	updated, err := uc.service.UpdatePackType(ctx, tenant, id, packType)
+	if err != nil {
+		uc.logger.Error("Failed to update pack type", "id", id, "error", err)
+		return nil, err
+	}
+
+	uc.logger.Info("Successfully updated pack type", "id", updated.ID, "code", updated.Code)
+	return updated, nil
+}
+
+type DeletePackTypeUseCase struct {
+	service PackTypeService
+	logger  *slog.Logger
+}
+
+func NewDeletePackTypeUseCase(service PackTypeService, logger *slog.Logger) *DeletePackTypeUseCase {
+	return &DeletePackTypeUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *DeletePackTypeUseCase) Execute(ctx context.Context, tenant string, id int) error {
+	uc.logger.Info("Executing delete pack type use case", "tenant", tenant, "id", id)
+
+	if id <= 0 {
+		uc.logger.Warn("Invalid pack type ID", "id", id)
+		return errs.ErrInvalidID.Withf("invalid pack type ID: %d", id)
+	}
+
+	if err := uc.service.DeletePackType(ctx, tenant, id); err != nil {
+		uc.logger.Error("Failed to delete pack type", "id", id, "error", err)
+		return err
+	}
+
+	uc.logger.Info("Successfully deleted pack type", "id", id)
+	return nil
+}
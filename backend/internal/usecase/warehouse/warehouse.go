@@ -0,0 +1,203 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+)
+
+type WarehouseService interface {
+	GetAllWarehouses(ctx context.Context, tenant string) ([]*entity.Warehouse, error)
+	GetWarehouseByID(ctx context.Context, tenant string, id int) (*entity.Warehouse, error)
+	CreateWarehouse(ctx context.Context, tenant string, name string, location string, packConfigurationID int) (*entity.Warehouse, error)
+	DeleteWarehouse(ctx context.Context, tenant string, id int) error
+	PlanSourcing(ctx context.Context, tenant string, orderQuantity int, warehouseIDs []int, splitPenalty *int) (*entity.SourcingPlan, []*entity.Warehouse, error)
+}
+
+type GetAllWarehousesUseCase struct {
+	service WarehouseService
+	logger  *slog.Logger
+}
+
+func NewGetAllWarehousesUseCase(service WarehouseService, logger *slog.Logger) *GetAllWarehousesUseCase {
+	return &GetAllWarehousesUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *GetAllWarehousesUseCase) Execute(ctx context.Context, tenant string) ([]*entity.Warehouse, error) {
+	uc.logger.Info("Executing get all warehouses use case", "tenant", tenant)
+
+	warehouses, err := uc.service.GetAllWarehouses(ctx, tenant)
+	if err != nil {
+		uc.logger.Error("Failed to get all warehouses", "error", err)
+		return nil, err
+	}
+
+	uc.logger.Info("Successfully retrieved warehouses", "count", len(warehouses))
+	return warehouses, nil
+}
+
+type GetWarehouseByIDUseCase struct {
+	service WarehouseService
+	logger  *slog.Logger
+}
+
+func NewGetWarehouseByIDUseCase(service WarehouseService, logger *slog.Logger) *GetWarehouseByIDUseCase {
+	return &GetWarehouseByIDUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *GetWarehouseByIDUseCase) Execute(ctx context.Context, tenant string, id int) (*entity.Warehouse, error) {
+	uc.logger.Info("Executing get warehouse by ID use case", "tenant", tenant, "id", id)
+
+	if id <= 0 {
+		uc.logger.Warn("Invalid warehouse ID", "id", id)
+		return nil, errs.ErrInvalidID.Withf("invalid warehouse ID: %d", id)
+	}
+
+	warehouse, err := uc.service.GetWarehouseByID(ctx, tenant, id)
+	if err != nil {
+		uc.logger.Error("Failed to get warehouse by ID", "id", id, "error", err)
+		return nil, err
+	}
+
+	uc.logger.Info("Successfully retrieved warehouse", "id", id, "name", warehouse.Name)
+	return warehouse, nil
+}
+
+type CreateWarehouseUseCase struct {
+	service WarehouseService
+	logger  *slog.Logger
+}
+
+func NewCreateWarehouseUseCase(service WarehouseService, logger *slog.Logger) *CreateWarehouseUseCase {
+	return &CreateWarehouseUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *CreateWarehouseUseCase) Execute(ctx context.Context, tenant string, name string, location string, packConfigurationID int) (*entity.Warehouse, error) {
+	uc.logger.Info("Executing create warehouse use case", "tenant", tenant, "name", name, "location", location, "pack_configuration_id", packConfigurationID)
+
+	if err := uc.validateInput(name, location, packConfigurationID); err != nil {
+		uc.logger.Warn("Create warehouse input validation failed", "error", err)
+		return nil, err
+	}
+
+	warehouse, err := uc.service.CreateWarehouse(ctx, tenant, name, location, packConfigurationID)
+	if err != nil {
+		uc.logger.Error("Failed to create warehouse", "name", name, "error", err)
+		return nil, err
+	}
+
+	uc.logger.Info("Successfully created warehouse", "id", warehouse.ID, "name", warehouse.Name)
+	return warehouse, nil
+}
+
+func (uc *CreateWarehouseUseCase) validateInput(name string, location string, packConfigurationID int) error {
+	if name == "" {
+		return errs.ErrInvalidWarehouse.Withf("warehouse name cannot be empty")
+	}
+
+	if location == "" {
+		return errs.ErrInvalidWarehouse.Withf("warehouse location cannot be empty")
+	}
+
+	if packConfigurationID <= 0 {
+		return errs.ErrInvalidWarehouse.Withf("invalid pack configuration ID: %d", packConfigurationID)
+	}
+
+	return nil
+}
+
+type DeleteWarehouseUseCase struct {
+	service WarehouseService
+	logger  *slog.Logger
+}
+
+func NewDeleteWarehouseUseCase(service WarehouseService, logger *slog.Logger) *DeleteWarehouseUseCase {
+	return &DeleteWarehouseUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *DeleteWarehouseUseCase) Execute(ctx context.Context, tenant string, id int) error {
+	uc.logger.Info("Executing delete warehouse use case", "tenant", tenant, "id", id)
+
+	if id <= 0 {
+		uc.logger.Warn("Invalid warehouse ID", "id", id)
+		return errs.ErrInvalidID.Withf("invalid warehouse ID: %d", id)
+	}
+
+	if err := uc.service.DeleteWarehouse(ctx, tenant, id); err != nil {
+		uc.logger.Error("Failed to delete warehouse", "id", id, "error", err)
+		return err
+	}
+
+	uc.logger.Info("Successfully deleted warehouse", "id", id)
+	return nil
+}
+
+type PlanSourcingUseCase struct {
+	service WarehouseService
+	logger  *slog.Logger
+}
+
+func NewPlanSourcingUseCase(service WarehouseService, logger *slog.Logger) *PlanSourcingUseCase {
+	return &PlanSourcingUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *PlanSourcingUseCase) Execute(ctx context.Context, tenant string, orderQuantity int, warehouseIDs []int, splitPenalty *int) (*entity.SourcingPlan, []*entity.Warehouse, error) {
+	uc.logger.Info("Executing plan sourcing use case",
+		"tenant", tenant,
+		"order_quantity", orderQuantity,
+		"warehouse_ids", warehouseIDs)
+
+	if err := uc.validateInput(orderQuantity, warehouseIDs, splitPenalty); err != nil {
+		uc.logger.Warn("Plan sourcing input validation failed", "error", err)
+		return nil, nil, err
+	}
+
+	plan, warehouses, err := uc.service.PlanSourcing(ctx, tenant, orderQuantity, warehouseIDs, splitPenalty)
+	if err != nil {
+		uc.logger.Error("Failed to plan sourcing", "error", err)
+		return nil, nil, err
+	}
+
+	uc.logger.Info("Sourcing plan completed successfully",
+		"strategy", plan.Strategy,
+		"warehouses", plan.SourceCount(),
+		"total_packs", plan.TotalPacks(),
+		"surplus", plan.Surplus)
+
+	return plan, warehouses, nil
+}
+
+func (uc *PlanSourcingUseCase) validateInput(orderQuantity int, warehouseIDs []int, splitPenalty *int) error {
+	if orderQuantity < 0 {
+		return errs.ErrInvalidOrder.Withf("order quantity cannot be negative")
+	}
+
+	for _, id := range warehouseIDs {
+		if id <= 0 {
+			return errs.ErrInvalidID.Withf("invalid warehouse ID: %d", id)
+		}
+	}
+
+	if splitPenalty != nil && *splitPenalty < 0 {
+		return errs.ErrInvalidOrder.Withf("split penalty cannot be negative")
+	}
+
+	return nil
+}
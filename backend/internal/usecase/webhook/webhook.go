@@ -0,0 +1,182 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Schieck/packs-calculator/internal/domain/entity"
+)
+
+type WebhookService interface {
+	ListWebhooks(ctx context.Context, tenant string) ([]*entity.Webhook, error)
+	CreateWebhook(ctx context.Context, tenant string, url string, secret string, eventTypes []entity.EventType) (*entity.Webhook, error)
+	DeleteWebhook(ctx context.Context, tenant string, id int) error
+	ListDeadLetters(ctx context.Context, tenant string, webhookID int, limit int, offset int) ([]*entity.WebhookDelivery, int, error)
+	Redeliver(ctx context.Context, tenant string, deliveryID int64) (*entity.WebhookDelivery, error)
+}
+
+type WebhookDispatcher interface {
+	Dispatch(ctx context.Context) (entity.WebhookDispatchResult, error)
+}
+
+type ListWebhooksUseCase struct {
+	service WebhookService
+	logger  *slog.Logger
+}
+
+func NewListWebhooksUseCase(service WebhookService, logger *slog.Logger) *ListWebhooksUseCase {
+	return &ListWebhooksUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *ListWebhooksUseCase) Execute(ctx context.Context, tenant string) ([]*entity.Webhook, error) {
+	uc.logger.Info("Executing list webhooks use case", "tenant", tenant)
+
+	webhooks, err := uc.service.ListWebhooks(ctx, tenant)
+	if err != nil {
+		uc.logger.Error("Failed to list webhooks", "tenant", tenant, "error", err)
+		return nil, err
+	}
+
+	uc.logger.Info("Successfully listed webhooks", "tenant", tenant, "count", len(webhooks))
+	return webhooks, nil
+}
+
+type CreateWebhookUseCase struct {
+	service WebhookService
+	logger  *slog.Logger
+}
+
+func NewCreateWebhookUseCase(service WebhookService, logger *slog.Logger) *CreateWebhookUseCase {
+	return &CreateWebhookUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *CreateWebhookUseCase) Execute(ctx context.Context, tenant string, url string, secret string, eventTypes []entity.EventType) (*entity.Webhook, error) {
+	uc.logger.Info("Executing create webhook use case", "tenant", tenant, "url", url, "event_types", eventTypes)
+
+	webhook, err := uc.service.CreateWebhook(ctx, tenant, url, secret, eventTypes)
+	if err != nil {
+		uc.logger.Error("Failed to create webhook", "tenant", tenant, "url", url, "error", err)
+		return nil, err
+	}
+
+	uc.logger.Info("Successfully created webhook", "tenant", tenant, "id", webhook.ID)
+	return webhook, nil
+}
+
+type DeleteWebhookUseCase struct {
+	service WebhookService
+	logger  *slog.Logger
+}
+
+func NewDeleteWebhookUseCase(service WebhookService, logger *slog.Logger) *DeleteWebhookUseCase {
+	return &DeleteWebhookUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *DeleteWebhookUseCase) Execute(ctx context.Context, tenant string, id int) error {
+	uc.logger.Info("Executing delete webhook use case", "tenant", tenant, "id", id)
+
+	if err := uc.service.DeleteWebhook(ctx, tenant, id); err != nil {
+		uc.logger.Error("Failed to delete webhook", "tenant", tenant, "id", id, "error", err)
+		return err
+	}
+
+	uc.logger.Info("Successfully deleted webhook", "tenant", tenant, "id", id)
+	return nil
+}
+
+type ListDeadLettersUseCase struct {
+	service WebhookService
+	logger  *slog.Logger
+}
+
+func NewListDeadLettersUseCase(service WebhookService, logger *slog.Logger) *ListDeadLettersUseCase {
+	return &ListDeadLettersUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *ListDeadLettersUseCase) Execute(ctx context.Context, tenant string, webhookID int, limit int, offset int) ([]*entity.WebhookDelivery, int, error) {
+	uc.logger.Info("Executing list dead letters use case",
+		"tenant", tenant,
+		"webhook_id", webhookID,
+		"limit", limit,
+		"offset", offset)
+
+	deliveries, total, err := uc.service.ListDeadLetters(ctx, tenant, webhookID, limit, offset)
+	if err != nil {
+		uc.logger.Error("Failed to list dead letters", "tenant", tenant, "error", err)
+		return nil, 0, err
+	}
+
+	uc.logger.Info("Successfully listed dead letters", "tenant", tenant, "count", len(deliveries), "total", total)
+	return deliveries, total, nil
+}
+
+type RedeliverUseCase struct {
+	service WebhookService
+	logger  *slog.Logger
+}
+
+func NewRedeliverUseCase(service WebhookService, logger *slog.Logger) *RedeliverUseCase {
+	return &RedeliverUseCase{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (uc *RedeliverUseCase) Execute(ctx context.Context, tenant string, deliveryID int64) (*entity.WebhookDelivery, error) {
+	uc.logger.Info("Executing redeliver use case", "tenant", tenant, "delivery_id", deliveryID)
+
+	delivery, err := uc.service.Redeliver(ctx, tenant, deliveryID)
+	if err != nil {
+		uc.logger.Error("Failed to redeliver webhook delivery", "tenant", tenant, "delivery_id", deliveryID, "error", err)
+		return nil, err
+	}
+
+	uc.logger.Info("Successfully queued webhook delivery again", "tenant", tenant, "delivery_id", deliveryID)
+	return delivery, nil
+}
+
+type DispatchWebhooksUseCase struct {
+	dispatcher WebhookDispatcher
+	logger     *slog.Logger
+}
+
+func NewDispatchWebhooksUseCase(dispatcher WebhookDispatcher, logger *slog.Logger) *DispatchWebhooksUseCase {
+	return &DispatchWebhooksUseCase{
+		dispatcher: dispatcher,
+		logger:     logger,
+	}
+}
+
+func (uc *DispatchWebhooksUseCase) Execute(ctx context.Context) (entity.WebhookDispatchResult, error) {
+	uc.logger.Debug("Executing dispatch webhooks use case")
+
+	result, err := uc.dispatcher.Dispatch(ctx)
+	if err != nil {
+		uc.logger.Error("Failed to dispatch webhooks", "error", err)
+		return result, err
+	}
+
+	if result != (entity.WebhookDispatchResult{}) {
+		uc.logger.Info("Dispatched webhooks",
+			"events", result.Events,
+			"delivered", result.Delivered,
+			"retried", result.Retried,
+			"dead_lettered", result.DeadLettered)
+	}
+	if result.DeadLettered > 0 {
+		uc.logger.Warn("Webhook deliveries ran out of attempts", "dead_lettered", result.DeadLettered)
+	}
+	return result, nil
+}
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_reservations_location_status;
+DROP TABLE IF EXISTS reservation_items;
+DROP TABLE IF EXISTS reservations;
+DROP TABLE IF EXISTS stock_levels;
@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS stock_levels (
+    id SERIAL PRIMARY KEY,
+    location VARCHAR(255) NOT NULL,
+    pack_size INTEGER NOT NULL CHECK (pack_size > 0),
+    on_hand INTEGER NOT NULL DEFAULT 0 CHECK (on_hand >= 0),
+    reserved INTEGER NOT NULL DEFAULT 0 CHECK (reserved >= 0),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT stock_levels_reserved_within_on_hand CHECK (reserved <= on_hand),
+    CONSTRAINT stock_levels_location_pack_size_key UNIQUE (location, pack_size)
+);
+
+CREATE TABLE IF NOT EXISTS reservations (
+    id SERIAL PRIMARY KEY,
+    location VARCHAR(255) NOT NULL,
+    order_quantity INTEGER NOT NULL CHECK (order_quantity >= 0),
+    surplus INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(32) NOT NULL DEFAULT 'active',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS reservation_items (
+    reservation_id INTEGER NOT NULL REFERENCES reservations (id) ON DELETE CASCADE,
+    pack_size INTEGER NOT NULL CHECK (pack_size > 0),
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (reservation_id, pack_size)
+);
+
+CREATE INDEX idx_reservations_location_status ON reservations (location, status);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_warehouses_location_active;
+DROP TABLE IF EXISTS warehouses;
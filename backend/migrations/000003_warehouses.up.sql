@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS warehouses (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    location VARCHAR(255) NOT NULL,
+    pack_configuration_id INTEGER NOT NULL REFERENCES pack_configurations (id),
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- A location holds one warehouse's stock, so only one active warehouse may claim it
+CREATE UNIQUE INDEX idx_warehouses_location_active ON warehouses (location) WHERE is_active = true;
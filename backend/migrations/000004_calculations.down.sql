@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_calculations_configuration_id;
+DROP INDEX IF EXISTS idx_calculations_subject_created_at;
+DROP INDEX IF EXISTS idx_calculations_created_at;
+DROP TABLE IF EXISTS calculations;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS calculations (
+    id BIGSERIAL PRIMARY KEY,
+    subject VARCHAR(255) NOT NULL,
+    items INTEGER NOT NULL CHECK (items >= 0),
+    pack_sizes INTEGER[] NOT NULL,
+    configuration_id INTEGER REFERENCES pack_configurations (id),
+    configuration_version INTEGER,
+    location VARCHAR(255),
+    allocation JSONB NOT NULL DEFAULT '{}'::jsonb,
+    total_packs INTEGER NOT NULL DEFAULT 0,
+    total_items INTEGER NOT NULL DEFAULT 0,
+    surplus INTEGER NOT NULL DEFAULT 0,
+    solver VARCHAR(64) NOT NULL,
+    duration_us BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Listing is newest first and the retention job prunes by age
+CREATE INDEX idx_calculations_created_at ON calculations (created_at DESC);
+CREATE INDEX idx_calculations_subject_created_at ON calculations (subject, created_at DESC);
+CREATE INDEX idx_calculations_configuration_id ON calculations (configuration_id) WHERE configuration_id IS NOT NULL;
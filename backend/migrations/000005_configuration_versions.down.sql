@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS pack_configuration_versions;
+ALTER TABLE pack_configurations DROP COLUMN IF EXISTS version;
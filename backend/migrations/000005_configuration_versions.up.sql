@@ -0,0 +1,17 @@
+ALTER TABLE pack_configurations ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+-- Every change to a configuration's contents is kept as an immutable snapshot
+CREATE TABLE IF NOT EXISTS pack_configuration_versions (
+    id SERIAL PRIMARY KEY,
+    configuration_id INTEGER NOT NULL REFERENCES pack_configurations (id),
+    version INTEGER NOT NULL CHECK (version > 0),
+    name VARCHAR(255) NOT NULL,
+    pack_sizes INTEGER[] NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (configuration_id, version)
+);
+
+-- Existing configurations start their history at version 1
+INSERT INTO pack_configuration_versions (configuration_id, version, name, pack_sizes, created_at)
+SELECT id, version, name, pack_sizes, updated_at FROM pack_configurations
+ON CONFLICT (configuration_id, version) DO NOTHING;
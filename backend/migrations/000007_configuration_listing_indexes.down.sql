@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_pack_configurations_pack_sizes;
+DROP INDEX IF EXISTS idx_pack_configurations_lower_name;
+DROP INDEX IF EXISTS idx_pack_configurations_active_updated_at;
+DROP INDEX IF EXISTS idx_pack_configurations_active_created_at;
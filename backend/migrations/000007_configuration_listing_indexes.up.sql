@@ -0,0 +1,6 @@
+-- Listing filters by status and created range, searches by name prefix
+-- and by contained pack size
+CREATE INDEX IF NOT EXISTS idx_pack_configurations_active_created_at ON pack_configurations (is_active, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_pack_configurations_active_updated_at ON pack_configurations (is_active, updated_at DESC);
+CREATE INDEX IF NOT EXISTS idx_pack_configurations_lower_name ON pack_configurations (lower(name) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_pack_configurations_pack_sizes ON pack_configurations USING GIN (pack_sizes);
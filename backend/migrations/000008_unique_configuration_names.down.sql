@@ -0,0 +1,2 @@
+-- Configurations renamed by the up migration keep their new names
+DROP INDEX IF EXISTS idx_pack_configurations_active_lower_name;
@@ -0,0 +1,21 @@
+-- Active configuration names are unique regardless of case. Existing
+-- duplicates keep the oldest configuration's name; the others get their id
+-- appended, as a new version
+WITH renamed AS (
+    UPDATE pack_configurations pc
+    SET name = left(pc.name, 240) || ' (' || pc.id || ')',
+        version = pc.version + 1,
+        updated_at = CURRENT_TIMESTAMP
+    FROM (
+        SELECT id, row_number() OVER (PARTITION BY lower(name) ORDER BY id) AS position
+        FROM pack_configurations
+        WHERE is_active = true
+    ) ranked
+    WHERE pc.id = ranked.id AND ranked.position > 1
+    RETURNING pc.id, pc.version, pc.name, pc.pack_sizes
+)
+INSERT INTO pack_configuration_versions (configuration_id, version, name, pack_sizes)
+SELECT id, version, name, pack_sizes FROM renamed;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pack_configurations_active_lower_name
+    ON pack_configurations (lower(name)) WHERE is_active = true;
@@ -0,0 +1,2 @@
+-- Rewritten pack sizes stay canonical
+DROP INDEX IF EXISTS idx_pack_configurations_active_pack_sizes;
@@ -0,0 +1,20 @@
+-- Pack sizes are stored sorted ascending without duplicates. Configurations
+-- saved otherwise are rewritten in that form, as a new version
+WITH canonical AS (
+    SELECT id, ARRAY(SELECT DISTINCT size FROM unnest(pack_sizes) AS size ORDER BY size) AS pack_sizes
+    FROM pack_configurations
+), rewritten AS (
+    UPDATE pack_configurations pc
+    SET pack_sizes = canonical.pack_sizes,
+        version = pc.version + 1,
+        updated_at = CURRENT_TIMESTAMP
+    FROM canonical
+    WHERE pc.id = canonical.id AND pc.pack_sizes <> canonical.pack_sizes
+    RETURNING pc.id, pc.version, pc.name, pc.pack_sizes
+)
+INSERT INTO pack_configuration_versions (configuration_id, version, name, pack_sizes)
+SELECT id, version, name, pack_sizes FROM rewritten;
+
+-- Finds configurations with exactly the same pack sizes
+CREATE INDEX IF NOT EXISTS idx_pack_configurations_active_pack_sizes
+    ON pack_configurations (pack_sizes) WHERE is_active = true;
@@ -0,0 +1,23 @@
+-- Every tenant's rows are kept; restoring the global name index fails if
+-- two tenants use the same active name
+DROP INDEX IF EXISTS idx_audit_log_tenant_created_at;
+DROP INDEX IF EXISTS idx_calculations_tenant_created_at;
+
+DROP INDEX IF EXISTS idx_pack_configurations_tenant_active_pack_sizes;
+DROP INDEX IF EXISTS idx_pack_configurations_tenant_active_updated_at;
+DROP INDEX IF EXISTS idx_pack_configurations_tenant_active_created_at;
+CREATE INDEX IF NOT EXISTS idx_pack_configurations_active_pack_sizes
+    ON pack_configurations (pack_sizes) WHERE is_active = true;
+CREATE INDEX IF NOT EXISTS idx_pack_configurations_active_updated_at ON pack_configurations (is_active, updated_at DESC);
+CREATE INDEX IF NOT EXISTS idx_pack_configurations_active_created_at ON pack_configurations (is_active, created_at DESC);
+
+DROP INDEX IF EXISTS idx_pack_configurations_tenant_default;
+CREATE INDEX IF NOT EXISTS idx_pack_configurations_is_default ON pack_configurations (is_default) WHERE is_default = true;
+
+DROP INDEX IF EXISTS idx_pack_configurations_active_tenant_lower_name;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pack_configurations_active_lower_name
+    ON pack_configurations (lower(name)) WHERE is_active = true;
+
+ALTER TABLE audit_log DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE calculations DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE pack_configurations DROP COLUMN IF EXISTS tenant_id;
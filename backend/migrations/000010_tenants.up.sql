@@ -0,0 +1,39 @@
+-- Configurations, calculations and audit entries belong to a tenant. Rows
+-- written before tenants existed belong to the default tenant.
+ALTER TABLE pack_configurations ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE calculations ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+
+-- Names are unique within a tenant
+DROP INDEX IF EXISTS idx_pack_configurations_active_lower_name;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pack_configurations_active_tenant_lower_name
+    ON pack_configurations (tenant_id, lower(name)) WHERE is_active = true;
+
+-- Each tenant has at most one default; should several rows hold the flag,
+-- the most recently updated keeps it
+UPDATE pack_configurations pc
+SET is_default = false
+FROM (
+    SELECT id, row_number() OVER (PARTITION BY tenant_id ORDER BY is_active DESC, updated_at DESC, id DESC) AS position
+    FROM pack_configurations
+    WHERE is_default = true
+) ranked
+WHERE pc.id = ranked.id AND ranked.position > 1;
+
+DROP INDEX IF EXISTS idx_pack_configurations_is_default;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pack_configurations_tenant_default
+    ON pack_configurations (tenant_id) WHERE is_default = true;
+
+-- Listings and duplicate checks always filter by tenant first
+DROP INDEX IF EXISTS idx_pack_configurations_active_created_at;
+DROP INDEX IF EXISTS idx_pack_configurations_active_updated_at;
+DROP INDEX IF EXISTS idx_pack_configurations_active_pack_sizes;
+CREATE INDEX IF NOT EXISTS idx_pack_configurations_tenant_active_created_at
+    ON pack_configurations (tenant_id, is_active, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_pack_configurations_tenant_active_updated_at
+    ON pack_configurations (tenant_id, is_active, updated_at DESC);
+CREATE INDEX IF NOT EXISTS idx_pack_configurations_tenant_active_pack_sizes
+    ON pack_configurations (tenant_id, pack_sizes) WHERE is_active = true;
+
+CREATE INDEX IF NOT EXISTS idx_calculations_tenant_created_at ON calculations (tenant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_tenant_created_at ON audit_log (tenant_id, created_at DESC);
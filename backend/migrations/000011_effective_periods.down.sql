@@ -0,0 +1,21 @@
+ALTER TABLE pack_configurations DROP CONSTRAINT IF EXISTS excl_pack_configurations_tenant_default_period;
+
+-- Only the default in effect now keeps the flag
+UPDATE pack_configurations pc
+SET is_default = false
+FROM (
+    SELECT id, row_number() OVER (
+        PARTITION BY tenant_id
+        ORDER BY tstzrange(effective_from, effective_to) @> CURRENT_TIMESTAMP DESC, updated_at DESC, id DESC
+    ) AS position
+    FROM pack_configurations
+    WHERE is_default = true
+) ranked
+WHERE pc.id = ranked.id AND ranked.position > 1;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pack_configurations_tenant_default
+    ON pack_configurations (tenant_id) WHERE is_default = true;
+
+ALTER TABLE pack_configurations DROP CONSTRAINT IF EXISTS chk_pack_configurations_effective_period;
+ALTER TABLE pack_configurations DROP COLUMN IF EXISTS effective_to;
+ALTER TABLE pack_configurations DROP COLUMN IF EXISTS effective_from;
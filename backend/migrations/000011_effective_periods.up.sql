@@ -0,0 +1,14 @@
+-- A configuration may be limited to the period from effective_from,
+-- inclusive, until effective_to, exclusive. NULL leaves that side open.
+ALTER TABLE pack_configurations ADD COLUMN IF NOT EXISTS effective_from TIMESTAMPTZ;
+ALTER TABLE pack_configurations ADD COLUMN IF NOT EXISTS effective_to TIMESTAMPTZ;
+ALTER TABLE pack_configurations ADD CONSTRAINT chk_pack_configurations_effective_period
+    CHECK (effective_from IS NULL OR effective_to IS NULL OR effective_from < effective_to);
+
+-- A tenant may hold several defaults, but never two in effect at the same
+-- moment. btree_gist lets the exclusion compare tenant_id with =.
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+DROP INDEX IF EXISTS idx_pack_configurations_tenant_default;
+ALTER TABLE pack_configurations ADD CONSTRAINT excl_pack_configurations_tenant_default_period
+    EXCLUDE USING gist (tenant_id WITH =, tstzrange(effective_from, effective_to) WITH &&)
+    WHERE (is_default = true);
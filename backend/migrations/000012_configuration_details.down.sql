@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_pack_configurations_labels;
+ALTER TABLE pack_configurations DROP CONSTRAINT IF EXISTS chk_pack_configurations_metadata_object;
+ALTER TABLE pack_configurations DROP CONSTRAINT IF EXISTS chk_pack_configurations_labels_object;
+ALTER TABLE pack_configurations DROP COLUMN IF EXISTS metadata;
+ALTER TABLE pack_configurations DROP COLUMN IF EXISTS labels;
+ALTER TABLE pack_configurations DROP COLUMN IF EXISTS description;
@@ -0,0 +1,12 @@
+-- Descriptive details that never affect a calculation: free text, key/value
+-- labels for selecting configurations, and arbitrary JSON metadata
+ALTER TABLE pack_configurations ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '';
+ALTER TABLE pack_configurations ADD COLUMN IF NOT EXISTS labels JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE pack_configurations ADD COLUMN IF NOT EXISTS metadata JSONB;
+ALTER TABLE pack_configurations ADD CONSTRAINT chk_pack_configurations_labels_object
+    CHECK (jsonb_typeof(labels) = 'object');
+ALTER TABLE pack_configurations ADD CONSTRAINT chk_pack_configurations_metadata_object
+    CHECK (metadata IS NULL OR jsonb_typeof(metadata) = 'object');
+
+-- Label selectors match with labels @> '{"region": "eu"}'
+CREATE INDEX IF NOT EXISTS idx_pack_configurations_labels ON pack_configurations USING GIN (labels jsonb_path_ops);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_pack_configurations_pack_type_ids;
+ALTER TABLE pack_configurations DROP COLUMN IF EXISTS pack_type_ids;
+DROP INDEX IF EXISTS idx_pack_types_tenant_code;
+DROP TABLE IF EXISTS pack_types;
@@ -0,0 +1,22 @@
+-- A pack type is a pack SKU. Configurations reference pack types by ID and
+-- still store every item count in pack_sizes, so calculations read one column.
+CREATE TABLE IF NOT EXISTS pack_types (
+    id SERIAL PRIMARY KEY,
+    tenant_id VARCHAR(64) NOT NULL DEFAULT 'default',
+    code VARCHAR(64) NOT NULL,
+    label VARCHAR(255) NOT NULL,
+    item_count INTEGER NOT NULL CHECK (item_count > 0),
+    length_mm INTEGER NOT NULL DEFAULT 0 CHECK (length_mm >= 0),
+    width_mm INTEGER NOT NULL DEFAULT 0 CHECK (width_mm >= 0),
+    height_mm INTEGER NOT NULL DEFAULT 0 CHECK (height_mm >= 0),
+    cost_cents BIGINT NOT NULL DEFAULT 0 CHECK (cost_cents >= 0),
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Deactivated pack types keep their code, so references stay unambiguous
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pack_types_tenant_code ON pack_types (tenant_id, lower(code));
+
+ALTER TABLE pack_configurations ADD COLUMN IF NOT EXISTS pack_type_ids INTEGER[] NOT NULL DEFAULT '{}';
+CREATE INDEX IF NOT EXISTS idx_pack_configurations_pack_type_ids ON pack_configurations USING GIN (pack_type_ids);
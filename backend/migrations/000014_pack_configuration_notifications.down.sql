@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS pack_configurations_notify_change ON pack_configurations;
+DROP FUNCTION IF EXISTS notify_pack_configuration_change();
@@ -0,0 +1,18 @@
+-- Announce every change to a pack configuration on the
+-- pack_configuration_changes channel, with the tenant as payload, so that
+-- replicas caching configurations drop that tenant's entries. Notifications
+-- are delivered on commit and repeated ones within a transaction are folded.
+CREATE OR REPLACE FUNCTION notify_pack_configuration_change() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM pg_notify('pack_configuration_changes', OLD.tenant_id);
+    ELSE
+        PERFORM pg_notify('pack_configuration_changes', NEW.tenant_id);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER pack_configurations_notify_change
+    AFTER INSERT OR UPDATE OR DELETE ON pack_configurations
+    FOR EACH ROW EXECUTE FUNCTION notify_pack_configuration_change();
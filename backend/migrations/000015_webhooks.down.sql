@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_webhook_deliveries_dead;
+DROP INDEX IF EXISTS idx_webhook_deliveries_due;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP INDEX IF EXISTS idx_webhooks_tenant;
+DROP TABLE IF EXISTS webhooks;
+DROP INDEX IF EXISTS idx_outbox_events_undispatched;
+DROP TABLE IF EXISTS outbox_events;
@@ -0,0 +1,6 @@
+ALTER TABLE pack_configuration_versions DROP COLUMN IF EXISTS metadata;
+ALTER TABLE pack_configuration_versions DROP COLUMN IF EXISTS labels;
+ALTER TABLE pack_configuration_versions DROP COLUMN IF EXISTS description;
+ALTER TABLE pack_configuration_versions DROP COLUMN IF EXISTS effective_to;
+ALTER TABLE pack_configuration_versions DROP COLUMN IF EXISTS effective_from;
+ALTER TABLE pack_configuration_versions DROP COLUMN IF EXISTS pack_type_ids;
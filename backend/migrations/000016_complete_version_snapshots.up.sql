@@ -0,0 +1,20 @@
+-- Versions snapshot every field a configuration's contents consist of, so
+-- restoring one brings back its pack types, effective period and details too
+ALTER TABLE pack_configuration_versions ADD COLUMN IF NOT EXISTS pack_type_ids INTEGER[] NOT NULL DEFAULT '{}';
+ALTER TABLE pack_configuration_versions ADD COLUMN IF NOT EXISTS effective_from TIMESTAMPTZ;
+ALTER TABLE pack_configuration_versions ADD COLUMN IF NOT EXISTS effective_to TIMESTAMPTZ;
+ALTER TABLE pack_configuration_versions ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '';
+ALTER TABLE pack_configuration_versions ADD COLUMN IF NOT EXISTS labels JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE pack_configuration_versions ADD COLUMN IF NOT EXISTS metadata JSONB;
+
+-- Earlier snapshots never recorded these fields, so they take the
+-- configuration's current ones: restoring them leaves those fields as they are
+UPDATE pack_configuration_versions v
+SET pack_type_ids = pc.pack_type_ids,
+    effective_from = pc.effective_from,
+    effective_to = pc.effective_to,
+    description = pc.description,
+    labels = pc.labels,
+    metadata = pc.metadata
+FROM pack_configurations pc
+WHERE pc.id = v.configuration_id;
@@ -0,0 +1,14 @@
+-- Every tenant's rows are kept; restoring the global location keys fails if
+-- two tenants use the same location
+DROP INDEX IF EXISTS idx_reservations_tenant_location_status;
+CREATE INDEX IF NOT EXISTS idx_reservations_location_status ON reservations (location, status);
+
+ALTER TABLE stock_levels DROP CONSTRAINT IF EXISTS stock_levels_tenant_location_pack_size_key;
+ALTER TABLE stock_levels ADD CONSTRAINT stock_levels_location_pack_size_key UNIQUE (location, pack_size);
+
+DROP INDEX IF EXISTS idx_warehouses_tenant_location_active;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_warehouses_location_active ON warehouses (location) WHERE is_active = true;
+
+ALTER TABLE reservations DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE stock_levels DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE warehouses DROP COLUMN IF EXISTS tenant_id;
@@ -0,0 +1,35 @@
+-- Warehouses, stock levels and reservations belong to a tenant
+ALTER TABLE warehouses ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE stock_levels ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE reservations ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+
+-- A warehouse belongs to the tenant of the configuration it plans with, and
+-- stock and reservations to the tenant of the warehouse at their location.
+-- Locations no warehouse claims stay with the default tenant.
+UPDATE warehouses w
+SET tenant_id = pc.tenant_id
+FROM pack_configurations pc
+WHERE pc.id = w.pack_configuration_id;
+
+UPDATE stock_levels s
+SET tenant_id = w.tenant_id
+FROM warehouses w
+WHERE w.location = s.location AND w.is_active = true;
+
+UPDATE reservations r
+SET tenant_id = w.tenant_id
+FROM warehouses w
+WHERE w.location = r.location AND w.is_active = true;
+
+-- Locations are unique within a tenant
+DROP INDEX IF EXISTS idx_warehouses_location_active;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_warehouses_tenant_location_active
+    ON warehouses (tenant_id, location) WHERE is_active = true;
+
+ALTER TABLE stock_levels DROP CONSTRAINT IF EXISTS stock_levels_location_pack_size_key;
+ALTER TABLE stock_levels ADD CONSTRAINT stock_levels_tenant_location_pack_size_key
+    UNIQUE (tenant_id, location, pack_size);
+
+DROP INDEX IF EXISTS idx_reservations_location_status;
+CREATE INDEX IF NOT EXISTS idx_reservations_tenant_location_status
+    ON reservations (tenant_id, location, status);
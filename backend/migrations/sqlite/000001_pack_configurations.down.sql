@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS pack_configuration_versions;
+DROP TABLE IF EXISTS pack_configurations;
@@ -0,0 +1,43 @@
+-- SQLite has no arrays, so pack sizes and pack type IDs are JSON arrays of
+-- integers, pack sizes in canonical order. Timestamps are written by the
+-- application in UTC, so comparing them as text orders them in time.
+CREATE TABLE IF NOT EXISTS pack_configurations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant_id TEXT NOT NULL DEFAULT 'default',
+    name TEXT NOT NULL,
+    pack_sizes TEXT NOT NULL CHECK (json_valid(pack_sizes) AND json_type(pack_sizes) = 'array'),
+    pack_type_ids TEXT NOT NULL DEFAULT '[]' CHECK (json_valid(pack_type_ids) AND json_type(pack_type_ids) = 'array'),
+    is_default BOOLEAN NOT NULL DEFAULT false,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    version INTEGER NOT NULL DEFAULT 1,
+    effective_from TIMESTAMP,
+    effective_to TIMESTAMP,
+    description TEXT NOT NULL DEFAULT '',
+    labels TEXT NOT NULL DEFAULT '{}' CHECK (json_valid(labels)),
+    metadata TEXT CHECK (metadata IS NULL OR json_valid(metadata)),
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    CHECK (effective_from IS NULL OR effective_to IS NULL OR effective_from < effective_to)
+);
+
+-- Names are unique among a tenant's active configurations. lower() only folds
+-- ASCII letters in SQLite.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pack_configurations_active_tenant_lower_name
+    ON pack_configurations (tenant_id, lower(name)) WHERE is_active = true;
+CREATE INDEX IF NOT EXISTS idx_pack_configurations_tenant_active_created_at
+    ON pack_configurations (tenant_id, is_active, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_pack_configurations_tenant_active_pack_sizes
+    ON pack_configurations (tenant_id, pack_sizes) WHERE is_active = true;
+-- SQLite cannot exclude overlapping periods, so the repository checks them
+-- under the write lock; this index finds a tenant's defaults
+CREATE INDEX IF NOT EXISTS idx_pack_configurations_tenant_default
+    ON pack_configurations (tenant_id) WHERE is_default = true;
+
+CREATE TABLE IF NOT EXISTS pack_configuration_versions (
+    configuration_id INTEGER NOT NULL REFERENCES pack_configurations (id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    pack_sizes TEXT NOT NULL CHECK (json_valid(pack_sizes)),
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (configuration_id, version)
+);
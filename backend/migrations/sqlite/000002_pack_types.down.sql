@@ -0,0 +1 @@
+DROP TABLE IF EXISTS pack_types;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS pack_types (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant_id TEXT NOT NULL DEFAULT 'default',
+    code TEXT NOT NULL,
+    label TEXT NOT NULL,
+    item_count INTEGER NOT NULL CHECK (item_count > 0),
+    length_mm INTEGER NOT NULL DEFAULT 0 CHECK (length_mm >= 0),
+    width_mm INTEGER NOT NULL DEFAULT 0 CHECK (width_mm >= 0),
+    height_mm INTEGER NOT NULL DEFAULT 0 CHECK (height_mm >= 0),
+    cost_cents INTEGER NOT NULL DEFAULT 0 CHECK (cost_cents >= 0),
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- Deactivated pack types keep their code, so references stay unambiguous
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pack_types_tenant_code ON pack_types (tenant_id, lower(code));
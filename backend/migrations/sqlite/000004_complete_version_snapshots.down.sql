@@ -0,0 +1,6 @@
+ALTER TABLE pack_configuration_versions DROP COLUMN metadata;
+ALTER TABLE pack_configuration_versions DROP COLUMN labels;
+ALTER TABLE pack_configuration_versions DROP COLUMN description;
+ALTER TABLE pack_configuration_versions DROP COLUMN effective_to;
+ALTER TABLE pack_configuration_versions DROP COLUMN effective_from;
+ALTER TABLE pack_configuration_versions DROP COLUMN pack_type_ids;
@@ -0,0 +1,18 @@
+-- Versions snapshot every field a configuration's contents consist of, so
+-- restoring one brings back its pack types, effective period and details too
+ALTER TABLE pack_configuration_versions ADD COLUMN pack_type_ids TEXT NOT NULL DEFAULT '[]' CHECK (json_valid(pack_type_ids));
+ALTER TABLE pack_configuration_versions ADD COLUMN effective_from TIMESTAMP;
+ALTER TABLE pack_configuration_versions ADD COLUMN effective_to TIMESTAMP;
+ALTER TABLE pack_configuration_versions ADD COLUMN description TEXT NOT NULL DEFAULT '';
+ALTER TABLE pack_configuration_versions ADD COLUMN labels TEXT NOT NULL DEFAULT '{}' CHECK (json_valid(labels));
+ALTER TABLE pack_configuration_versions ADD COLUMN metadata TEXT CHECK (metadata IS NULL OR json_valid(metadata));
+
+-- Earlier snapshots never recorded these fields, so they take the
+-- configuration's current ones: restoring them leaves those fields as they are
+UPDATE pack_configuration_versions
+SET pack_type_ids = (SELECT pc.pack_type_ids FROM pack_configurations pc WHERE pc.id = configuration_id),
+    effective_from = (SELECT pc.effective_from FROM pack_configurations pc WHERE pc.id = configuration_id),
+    effective_to = (SELECT pc.effective_to FROM pack_configurations pc WHERE pc.id = configuration_id),
+    description = (SELECT pc.description FROM pack_configurations pc WHERE pc.id = configuration_id),
+    labels = (SELECT pc.labels FROM pack_configurations pc WHERE pc.id = configuration_id),
+    metadata = (SELECT pc.metadata FROM pack_configurations pc WHERE pc.id = configuration_id);
@@ -4,20 +4,77 @@ import (
 	"database/sql"
 	"fmt"
 	"log/slog"
+	"net/url"
+	"path"
+	"strings"
 	"time"
 
 	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
 )
 
+// Supported database drivers, picked by the scheme of the DSN
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite3"
+)
+
+// Each driver has its own migration set: Postgres the migrations directory
+// itself, SQLite its sqlite subdirectory
+var migrationSubdirectories = map[string]string{
+	DriverPostgres: "",
+	DriverSQLite:   "sqlite",
+}
+
 type DB struct {
 	*sql.DB
+	// Driver is the database/sql driver the connection uses
+	Driver string
 }
 
+// DriverFor picks the driver for a DSN: postgres:// and postgresql:// use
+// Postgres, sqlite:// and sqlite3:// a SQLite file. A DSN without a scheme
+// is a Postgres key=value connection string.
+func DriverFor(dsn string) (string, error) {
+	scheme, _, found := strings.Cut(dsn, "://")
+	if !found {
+		return DriverPostgres, nil
+	}
+	switch strings.ToLower(scheme) {
+	case "postgres", "postgresql":
+		return DriverPostgres, nil
+	case "sqlite", "sqlite3":
+		return DriverSQLite, nil
+	}
+	return "", fmt.Errorf("unsupported database scheme %q: use postgres:// or sqlite://", scheme)
+}
+
+// NewConnection opens the database the DSN names, with the driver its
+// scheme selects, and brings its schema up to date from ./migrations
 func NewConnection(dsn string) (*DB, error) {
-	db, err := sql.Open("postgres", dsn)
+	return Open(dsn, "./migrations")
+}
+
+// Open is NewConnection with the migrations read from migrationsDir
+func Open(dsn string, migrationsDir string) (*DB, error) {
+	driver, err := DriverFor(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	dataSource := dsn
+	if driver == DriverSQLite {
+		if dataSource, err = sqliteDataSource(dsn); err != nil {
+			return nil, err
+		}
+	}
+
+	db, err := sql.Open(driver, dataSource)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -30,12 +87,12 @@ func NewConnection(dsn string) (*DB, error) {
 	db.SetMaxIdleConns(25)
 	db.SetConnMaxLifetime(5 * time.Minute)
 
-	dbWrapper := &DB{db}
+	dbWrapper := &DB{DB: db, Driver: driver}
 
-	if err := dbWrapper.runMigrations(dsn); err != nil {
+	if err := dbWrapper.runMigrations(dataSource, path.Join(migrationsDir, migrationSubdirectories[driver])); err != nil {
 		return nil, fmt.Errorf("failed to run migrations: %w", err)
 	}
-	slog.Info("Database migrations completed successfully")
+	slog.Info("Database migrations completed successfully", "driver", driver)
 
 	if err := dbWrapper.Ping(); err != nil {
 		return nil, fmt.Errorf("database connection failed after migrations: %w", err)
@@ -44,21 +101,57 @@ func NewConnection(dsn string) (*DB, error) {
 	return dbWrapper, nil
 }
 
-func (db DB) runMigrations(dsn string) error {
-	migrationDB, err := sql.Open("postgres", dsn)
+// sqliteDataSource turns sqlite://path/to/file.db?options into a go-sqlite3
+// data source. Writers take the lock when their transaction begins and wait
+// for each other instead of failing, and the write-ahead log lets readers
+// carry on meanwhile; options in the DSN override these defaults.
+func sqliteDataSource(dsn string) (string, error) {
+	_, rest, _ := strings.Cut(dsn, "://")
+	file, rawQuery, _ := strings.Cut(rest, "?")
+	if file == "" || strings.Contains(file, ":memory:") {
+		return "", fmt.Errorf("sqlite DSN must name a database file, e.g. sqlite://packs.db")
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", fmt.Errorf("invalid sqlite DSN options: %w", err)
+	}
+	defaults := map[string]string{
+		"_busy_timeout": "5000",
+		"_txlock":       "immediate",
+		"_journal_mode": "WAL",
+		"_foreign_keys": "on",
+	}
+	for key, value := range defaults {
+		if !query.Has(key) {
+			query.Set(key, value)
+		}
+	}
+
+	return "file:" + file + "?" + query.Encode(), nil
+}
+
+func (db DB) runMigrations(dataSource string, migrationsDir string) error {
+	migrationDB, err := sql.Open(db.Driver, dataSource)
 	if err != nil {
 		return fmt.Errorf("failed to open migration database connection: %w", err)
 	}
 	defer migrationDB.Close()
 
-	driver, err := postgres.WithInstance(migrationDB, &postgres.Config{})
+	var driver database.Driver
+	switch db.Driver {
+	case DriverSQLite:
+		driver, err = sqlite3.WithInstance(migrationDB, &sqlite3.Config{})
+	default:
+		driver, err = postgres.WithInstance(migrationDB, &postgres.Config{})
+	}
 	if err != nil {
-		return fmt.Errorf("failed to create postgres driver: %w", err)
+		return fmt.Errorf("failed to create %s driver: %w", db.Driver, err)
 	}
 
 	m, err := migrate.NewWithDatabaseInstance(
-		"file://./migrations",
-		"postgres",
+		"file://"+migrationsDir,
+		db.Driver,
 		driver,
 	)
 	if err != nil {
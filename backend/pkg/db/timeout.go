@@ -0,0 +1,35 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+)
+
+// WithQueryTimeout bounds one repository operation, a single query or a
+// whole transaction, by timeout on top of ctx's own deadline; a timeout of
+// zero adds none. Deferring the returned finish with the operation's error
+// cancels the context and, when a deadline cut the operation short,
+// replaces the error with a QueryTimeoutError. Drivers word cancelled
+// queries their own way, so it is the context that tells a timeout apart.
+func WithQueryTimeout(ctx context.Context, timeout time.Duration, operation string) (context.Context, func(*error)) {
+	// The error names the timeout only when it expires before the caller's deadline
+	limit := timeout
+	if deadline, ok := ctx.Deadline(); ok && (timeout <= 0 || time.Until(deadline) < timeout) {
+		limit = 0
+	}
+
+	cancel := context.CancelFunc(func() {})
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+
+	return ctx, func(err *error) {
+		defer cancel()
+		if *err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			*err = &errs.QueryTimeoutError{Operation: operation, Timeout: limit}
+		}
+	}
+}
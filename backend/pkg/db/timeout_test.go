@@ -0,0 +1,59 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Schieck/packs-calculator/internal/domain/errs"
+)
+
+func TestWithQueryTimeout(t *testing.T) {
+	t.Run("reports the timeout that expired", func(t *testing.T) {
+		ctx, finish := WithQueryTimeout(t.Context(), time.Millisecond, "GetByID")
+		<-ctx.Done()
+		err := fmt.Errorf("failed to get pack configuration: %w", ctx.Err())
+		finish(&err)
+
+		var timeoutErr *errs.QueryTimeoutError
+		require.ErrorAs(t, err, &timeoutErr)
+		assert.Equal(t, &errs.QueryTimeoutError{Operation: "GetByID", Timeout: time.Millisecond}, timeoutErr)
+		assert.ErrorIs(t, err, errs.ErrQueryTimeout)
+	})
+
+	t.Run("leaves the timeout out when the caller's deadline comes first", func(t *testing.T) {
+		parent, cancel := context.WithTimeout(t.Context(), time.Millisecond)
+		defer cancel()
+		ctx, finish := WithQueryTimeout(parent, time.Hour, "List")
+		<-ctx.Done()
+		err := ctx.Err()
+		finish(&err)
+
+		assert.Equal(t, &errs.QueryTimeoutError{Operation: "List"}, err)
+	})
+
+	t.Run("keeps other errors", func(t *testing.T) {
+		parent, cancel := context.WithCancel(t.Context())
+		ctx, finish := WithQueryTimeout(parent, time.Hour, "Update")
+		cancel()
+		<-ctx.Done()
+		err := ctx.Err()
+		finish(&err)
+		assert.ErrorIs(t, err, context.Canceled)
+
+		_, finish = WithQueryTimeout(t.Context(), time.Hour, "Update")
+		err = errors.New("boom")
+		finish(&err)
+		assert.EqualError(t, err, "boom")
+
+		_, finish = WithQueryTimeout(t.Context(), 0, "Update")
+		err = nil
+		finish(&err)
+		assert.NoError(t, err)
+	})
+}
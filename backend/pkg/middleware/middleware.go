@@ -1,8 +1,12 @@
 package middleware
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -36,6 +40,7 @@ func Logger(logger *slog.Logger) gin.HandlerFunc {
 			slog.String("ip", clientIP),
 			slog.Duration("latency", latency),
 			slog.String("user_agent", c.Request.UserAgent()),
+			slog.String("request_id", GetRequestID(c)),
 		)
 	}
 }
@@ -50,22 +55,63 @@ func Recovery(logger *slog.Logger) gin.HandlerFunc {
 					slog.String("method", c.Request.Method),
 				)
 
-				c.JSON(http.StatusInternalServerError, errs.ErrorResponse{
-					Error: "Internal server error",
-				})
-				c.Abort()
+				AbortWithProblem(c, NewProblem(c, http.StatusInternalServerError, "internal_error", "Internal server error", ""))
 			}
 		}()
 		c.Next()
 	}
 }
 
+// RequestIDHeader carries the request ID in both directions
+const RequestIDHeader = "X-Request-ID"
+
+const maxRequestIDLength = 128
+
+// RequestID tags every request with an ID, reusing the caller's X-Request-ID
+// when it is reasonable, and echoes it in the response
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+
+		c.Set("request_id", requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Next()
+	}
+}
+
+func GetRequestID(c *gin.Context) string {
+	return c.GetString("request_id")
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
+
 func CORS() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
-		c.Header("Access-Control-Expose-Headers", "Content-Length")
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-Request-ID, If-Match")
+		c.Header("Access-Control-Expose-Headers", "Content-Length, X-Request-ID, ETag")
 		c.Header("Access-Control-Allow-Credentials", "true")
 
 		if c.Request.Method == "OPTIONS" {
@@ -81,10 +127,7 @@ func JWT(validateTokenUseCase entity.ValidateTokenUseCase) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, errs.ErrorResponse{
-				Error: "Authorization header required",
-			})
-			c.Abort()
+			AbortWithProblem(c, NewProblem(c, http.StatusUnauthorized, "unauthorized", "Authorization header required", ""))
 			return
 		}
 
@@ -92,28 +135,141 @@ func JWT(validateTokenUseCase entity.ValidateTokenUseCase) gin.HandlerFunc {
 		if strings.HasPrefix(authHeader, "Bearer ") {
 			tokenString = authHeader[7:]
 		} else {
-			c.JSON(http.StatusUnauthorized, errs.ErrorResponse{
-				Error: "Invalid authorization header format. Use 'Bearer <token>'",
-			})
-			c.Abort()
+			AbortWithProblem(c, NewProblem(c, http.StatusUnauthorized, "unauthorized",
+				"Invalid authorization header", "Use 'Bearer <token>'"))
 			return
 		}
 
 		claims, err := validateTokenUseCase.Execute(tokenString)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, errs.ErrorResponse{
-				Error: "Invalid or expired token",
-			})
-			c.Abort()
+			AbortWithProblem(c, NewProblem(c, http.StatusUnauthorized, "unauthorized", "Invalid or expired token", ""))
 			return
 		}
 
 		c.Set("authenticated", true)
 		c.Set("subject", claims.Subject)
+		c.Set("tenant", claims.TenantOrDefault())
+		c.Next()
+	}
+}
+
+// RequireSubject only lets through requests whose JWT subject is in the
+// allowed list. It must run after JWT. An empty list forbids everyone.
+func RequireSubject(allowed []string) gin.HandlerFunc {
+	subjects := make(map[string]bool, len(allowed))
+	for _, subject := range allowed {
+		subjects[subject] = true
+	}
+
+	return func(c *gin.Context) {
+		subject, ok := GetSubject(c)
+		if !ok || !subjects[subject] {
+			AbortWithError(c, errs.ErrInsufficientPermissions)
+			return
+		}
+		c.Next()
+	}
+}
+
+// ProblemContentType is the media type of every error response
+const ProblemContentType = "application/problem+json"
+
+// problemTypeBase prefixes the code to form a problem's type URI
+const problemTypeBase = "/problems/"
+
+// NewProblem describes a failure of the current request. The code names
+// the problem type; title is its fixed summary and detail explains this
+// occurrence.
+func NewProblem(c *gin.Context, status int, code, title, detail string) *errs.Problem {
+	return &errs.Problem{
+		Type:      problemTypeBase + code,
+		Title:     title,
+		Status:    status,
+		Detail:    detail,
+		Instance:  c.Request.URL.Path,
+		Code:      code,
+		RequestID: GetRequestID(c),
+	}
+}
+
+// AbortWithProblem writes problem as application/problem+json and stops the chain
+func AbortWithProblem(c *gin.Context, problem *errs.Problem) {
+	c.Header("Content-Type", ProblemContentType)
+	c.AbortWithStatusJSON(problem.Status, problem)
+}
+
+// errorStatuses maps each domain error kind to its HTTP status
+var errorStatuses = []struct {
+	kind   error
+	status int
+}{
+	{errs.ErrNotFound, http.StatusNotFound},
+	{errs.ErrConflict, http.StatusConflict},
+	{errs.ErrValidation, http.StatusUnprocessableEntity},
+	{errs.ErrForbidden, http.StatusForbidden},
+	{errs.ErrInfeasible, http.StatusUnprocessableEntity},
+	{errs.ErrUnsupported, http.StatusNotImplemented},
+	{errs.ErrTimeout, http.StatusGatewayTimeout},
+}
+
+// ErrorProblem picks the problem for an error. Domain errors keep their
+// code, summary, detail and any field errors; anything else is a 500 whose title is
+// fallback, so internal details never reach the client.
+func ErrorProblem(c *gin.Context, err error, fallback string) *errs.Problem {
+	var domainErr *errs.Error
+	if errors.As(err, &domainErr) {
+		for _, mapping := range errorStatuses {
+			if errors.Is(domainErr, mapping.kind) {
+				problem := NewProblem(c, mapping.status, domainErr.Code, sentence(domainErr.Message), domainErr.Detail)
+				var fieldsErr *errs.InvalidFieldsError
+				if errors.As(err, &fieldsErr) {
+					problem.Errors = fieldsErr.Fields
+				}
+				var existingErr errs.ExistingRecordError
+				if errors.As(err, &existingErr) {
+					problem.ExistingID = existingErr.ExistingRecordID()
+				}
+				return problem
+			}
+		}
+	}
+
+	if fallback == "" {
+		fallback = "Internal server error"
+	}
+	return NewProblem(c, http.StatusInternalServerError, "internal_error", fallback, "")
+}
+
+// AbortWithError writes err as a mapped problem and stops the chain
+func AbortWithError(c *gin.Context, err error) {
+	AbortWithProblem(c, ErrorProblem(c, err, ""))
+}
+
+// ErrorHandler renders the last error a handler attached with c.Error,
+// unless the handler already wrote a response. A string Meta on the error
+// is the title used when it turns out to be unexpected.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
 		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		last := c.Errors.Last()
+		fallback, _ := last.Meta.(string)
+		AbortWithProblem(c, ErrorProblem(c, last.Err, fallback))
 	}
 }
 
+// sentence capitalises the first letter of a lower-case error message
+func sentence(message string) string {
+	if message == "" {
+		return message
+	}
+	return strings.ToUpper(message[:1]) + message[1:]
+}
+
 func IsAuthenticated(c *gin.Context) bool {
 	authenticated, exists := c.Get("authenticated")
 	if !exists {
@@ -131,3 +287,13 @@ func GetSubject(c *gin.Context) (string, bool) {
 	sub, ok := subject.(string)
 	return sub, ok
 }
+
+// GetTenant returns the tenant of the authenticated token
+func GetTenant(c *gin.Context) (string, bool) {
+	tenant, exists := c.Get("tenant")
+	if !exists {
+		return "", false
+	}
+	t, ok := tenant.(string)
+	return t, ok
+}
@@ -3,6 +3,8 @@ package middleware
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
@@ -116,6 +118,158 @@ func (suite *MiddlewareTestSuite) TestLoggerMiddleware() {
 	assert.Contains(suite.T(), logContent, "test-agent")
 }
 
+func (suite *MiddlewareTestSuite) TestRequestIDMiddleware() {
+	suite.router.Use(RequestID())
+	suite.router.GET("/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"request_id": GetRequestID(c)})
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	generated := w.Header().Get(RequestIDHeader)
+	assert.Len(suite.T(), generated, 32)
+	assert.Contains(suite.T(), w.Body.String(), generated)
+
+	req = httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set(RequestIDHeader, "client-supplied-id")
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), "client-supplied-id", w.Header().Get(RequestIDHeader))
+
+	req = httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set(RequestIDHeader, "has spaces")
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.NotEqual(suite.T(), "has spaces", w.Header().Get(RequestIDHeader))
+	assert.Len(suite.T(), w.Header().Get(RequestIDHeader), 32)
+}
+
+func (suite *MiddlewareTestSuite) TestRequireSubjectMiddleware() {
+	setSubject := func(subject string) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			if subject != "" {
+				c.Set("subject", subject)
+			}
+			c.Next()
+		}
+	}
+
+	tests := []struct {
+		name     string
+		allowed  []string
+		subject  string
+		expected int
+	}{
+		{"allowed subject", []string{"admin"}, "admin", http.StatusOK},
+		{"other subject", []string{"admin"}, "authenticated-user", http.StatusForbidden},
+		{"no subject", []string{"admin"}, "", http.StatusForbidden},
+		{"empty allow list", nil, "admin", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			router := gin.New()
+			router.GET("/test", setSubject(tt.subject), RequireSubject(tt.allowed), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+
+			assert.Equal(suite.T(), tt.expected, w.Code)
+		})
+	}
+}
+
+func (suite *MiddlewareTestSuite) TestErrorHandlerMiddleware() {
+	tests := []struct {
+		name   string
+		err    error
+		meta   string
+		status int
+		code   string
+		title  string
+		detail string
+	}{
+		{"not found", errs.ErrPackConfigurationNotFound, "", http.StatusNotFound, "pack_configuration_not_found", "Pack configuration not found", ""},
+		{"conflict", errs.ErrConfigurationInUse, "", http.StatusConflict, "configuration_in_use", "Pack configuration is referenced by a warehouse", ""},
+		{"validation with detail", errs.ErrInvalidPackConfiguration.Withf("pack size 0 must be positive"), "", http.StatusUnprocessableEntity, "invalid_pack_configuration", "Invalid pack configuration", "pack size 0 must be positive"},
+		{"wrapped", fmt.Errorf("loading: %w", errs.ErrWarehouseNotFound), "", http.StatusNotFound, "warehouse_not_found", "Warehouse not found", ""},
+		{"forbidden", errs.ErrInsufficientPermissions, "", http.StatusForbidden, "insufficient_permissions", "Insufficient permissions", ""},
+		{"infeasible", errs.ErrUnfulfillableOrder, "", http.StatusUnprocessableEntity, "unfulfillable_order", "Order cannot be fulfilled with available stock", ""},
+		{"unsupported", errs.ErrStorageUnsupported.Withf("stock-aware calculations need Postgres storage"), "", http.StatusNotImplemented, "storage_unsupported", "The configured storage does not support this feature", "stock-aware calculations need Postgres storage"},
+		{"query timeout", &errs.QueryTimeoutError{Operation: "GetByID", Timeout: 5 * time.Second}, "", http.StatusGatewayTimeout, "query_timeout", "The database did not answer in time", "GetByID did not finish within 5s"},
+		{"unexpected with fallback", errors.New("connection refused"), "Failed to load", http.StatusInternalServerError, "internal_error", "Failed to load", ""},
+		{"unexpected", errors.New("connection refused"), "", http.StatusInternalServerError, "internal_error", "Internal server error", ""},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			router := gin.New()
+			router.Use(RequestID(), ErrorHandler())
+			router.GET("/test", func(c *gin.Context) {
+				_ = c.Error(tt.err).SetMeta(tt.meta)
+			})
+
+			req := httptest.NewRequest("GET", "/test", nil)
+			req.Header.Set(RequestIDHeader, "req-123")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(suite.T(), tt.status, w.Code)
+			assert.Equal(suite.T(), ProblemContentType, w.Header().Get("Content-Type"))
+
+			var problem errs.Problem
+			assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(suite.T(), "/problems/"+tt.code, problem.Type)
+			assert.Equal(suite.T(), tt.code, problem.Code)
+			assert.Equal(suite.T(), tt.status, problem.Status)
+			assert.Equal(suite.T(), tt.title, problem.Title)
+			assert.Equal(suite.T(), tt.detail, problem.Detail)
+			assert.Equal(suite.T(), "/test", problem.Instance)
+			assert.Equal(suite.T(), "req-123", problem.RequestID)
+			assert.NotContains(suite.T(), w.Body.String(), "connection refused")
+		})
+	}
+}
+
+func (suite *MiddlewareTestSuite) TestErrorHandlerNameConflict() {
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.POST("/test", func(c *gin.Context) {
+		_ = c.Error(fmt.Errorf("saving: %w", &errs.NameConflictError{Name: "Standard Packs", ExistingID: 4}))
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/test", nil))
+
+	var problem errs.Problem
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(suite.T(), http.StatusConflict, w.Code)
+	assert.Equal(suite.T(), "configuration_name_conflict", problem.Code)
+	assert.Equal(suite.T(), `name "Standard Packs" is used by active pack configuration 4`, problem.Detail)
+	assert.Equal(suite.T(), 4, problem.ExistingID)
+}
+
+func (suite *MiddlewareTestSuite) TestErrorHandlerKeepsWrittenResponse() {
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.GET("/test", func(c *gin.Context) {
+		_ = c.Error(errs.ErrPackConfigurationNotFound)
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.JSONEq(suite.T(), `{"ok":true}`, w.Body.String())
+}
+
 func (suite *MiddlewareTestSuite) TestRecoveryMiddleware() {
 	var logOutput bytes.Buffer
 	testLogger := slog.New(slog.NewJSONHandler(&logOutput, &slog.HandlerOptions{
@@ -133,10 +287,13 @@ func (suite *MiddlewareTestSuite) TestRecoveryMiddleware() {
 
 	assert.Equal(suite.T(), http.StatusInternalServerError, w.Code)
 
-	var errorResponse errs.ErrorResponse
-	err := json.Unmarshal(w.Body.Bytes(), &errorResponse)
+	assert.Equal(suite.T(), ProblemContentType, w.Header().Get("Content-Type"))
+
+	var problem errs.Problem
+	err := json.Unmarshal(w.Body.Bytes(), &problem)
 	assert.NoError(suite.T(), err)
-	assert.Equal(suite.T(), "Internal server error", errorResponse.Error)
+	assert.Equal(suite.T(), "Internal server error", problem.Title)
+	assert.Equal(suite.T(), "internal_error", problem.Code)
 }
 
 func (suite *MiddlewareTestSuite) TestJWTMiddleware() {
@@ -155,10 +312,11 @@ func (suite *MiddlewareTestSuite) TestJWTMiddleware() {
 	suite.router.ServeHTTP(w, req)
 
 	assert.Equal(suite.T(), http.StatusUnauthorized, w.Code)
-	var errorResponse errs.ErrorResponse
-	err := json.Unmarshal(w.Body.Bytes(), &errorResponse)
+	var problem errs.Problem
+	err := json.Unmarshal(w.Body.Bytes(), &problem)
 	assert.NoError(suite.T(), err)
-	assert.Equal(suite.T(), "Authorization header required", errorResponse.Error)
+	assert.Equal(suite.T(), "Authorization header required", problem.Title)
+	assert.Equal(suite.T(), http.StatusUnauthorized, problem.Status)
 
 	req = httptest.NewRequest("GET", "/protected", nil)
 	req.Header.Set("Authorization", "InvalidToken")
@@ -166,9 +324,11 @@ func (suite *MiddlewareTestSuite) TestJWTMiddleware() {
 	suite.router.ServeHTTP(w, req)
 
 	assert.Equal(suite.T(), http.StatusUnauthorized, w.Code)
-	err = json.Unmarshal(w.Body.Bytes(), &errorResponse)
+	problem = errs.Problem{}
+	err = json.Unmarshal(w.Body.Bytes(), &problem)
 	assert.NoError(suite.T(), err)
-	assert.Contains(suite.T(), errorResponse.Error, "Invalid authorization header format")
+	assert.Equal(suite.T(), "Invalid authorization header", problem.Title)
+	assert.Contains(suite.T(), problem.Detail, "Bearer <token>")
 
 	req = httptest.NewRequest("GET", "/protected", nil)
 	req.Header.Set("Authorization", "Bearer invalid-token")
@@ -204,10 +364,10 @@ func (suite *MiddlewareTestSuite) TestJWTMiddlewareExpiredToken() {
 	suite.router.ServeHTTP(w, req)
 
 	assert.Equal(suite.T(), http.StatusUnauthorized, w.Code)
-	var errorResponse errs.ErrorResponse
-	err := json.Unmarshal(w.Body.Bytes(), &errorResponse)
+	var problem errs.Problem
+	err := json.Unmarshal(w.Body.Bytes(), &problem)
 	assert.NoError(suite.T(), err)
-	assert.Equal(suite.T(), "Invalid or expired token", errorResponse.Error)
+	assert.Equal(suite.T(), "Invalid or expired token", problem.Title)
 }
 
 func (suite *MiddlewareTestSuite) TestIsAuthenticated() {
@@ -254,6 +414,45 @@ func (suite *MiddlewareTestSuite) TestGetSubject() {
 	assert.Equal(suite.T(), "test-subject", successResponse["subject"])
 }
 
+func (suite *MiddlewareTestSuite) TestGetTenant() {
+	suite.router.Use(JWT(suite.validateTokenUseCase))
+	suite.router.GET("/test", func(c *gin.Context) {
+		tenant, _ := GetTenant(c)
+		c.JSON(http.StatusOK, gin.H{"tenant": tenant})
+	})
+
+	tenantClaims := &entity.JWTClaims{
+		Subject: "test-subject",
+		Tenant:  "retail",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+	tenantToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, tenantClaims).SignedString([]byte(suite.jwtKey))
+	suite.Require().NoError(err)
+
+	tests := []struct {
+		name   string
+		token  string
+		tenant string
+	}{
+		{"token with tenant claim", tenantToken, "retail"},
+		{"token without tenant claim", suite.createValidJWT(), "default"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("Authorization", "Bearer "+tt.token)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+
+		assert.Equal(suite.T(), http.StatusOK, w.Code, tt.name)
+		var response map[string]interface{}
+		assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(suite.T(), tt.tenant, response["tenant"], tt.name)
+	}
+}
+
 func (suite *MiddlewareTestSuite) createValidJWT() string {
 	claims := &entity.JWTClaims{
 		Subject: "test-subject",
@@ -299,10 +498,10 @@ func (suite *MiddlewareTestSuite) TestJWTMiddlewareInvalidSigningMethod() {
 	suite.router.ServeHTTP(w, req)
 
 	assert.Equal(suite.T(), http.StatusUnauthorized, w.Code)
-	var errorResponse errs.ErrorResponse
-	err := json.Unmarshal(w.Body.Bytes(), &errorResponse)
+	var problem errs.Problem
+	err := json.Unmarshal(w.Body.Bytes(), &problem)
 	assert.NoError(suite.T(), err)
-	assert.Equal(suite.T(), "Invalid or expired token", errorResponse.Error)
+	assert.Equal(suite.T(), "Invalid or expired token", problem.Title)
 }
 
 func (suite *MiddlewareTestSuite) TestMiddlewareChaining() {
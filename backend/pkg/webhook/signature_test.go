@@ -0,0 +1,49 @@
+package webhook
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignature(t *testing.T) {
+	const secret = "0123456789abcdef"
+	now := time.Unix(1_800_000_000, 0)
+	body := []byte(`{"type":"pack_configuration.default_changed"}`)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	signature := Sign(secret, now.Unix(), body)
+
+	t.Run("accepts its own signature", func(t *testing.T) {
+		require.NoError(t, Verify(secret, timestamp, signature, body, now.Add(time.Minute), 5*time.Minute))
+	})
+
+	t.Run("is an HMAC of the timestamp and body", func(t *testing.T) {
+		assert.Equal(t, "sha256=", signature[:7])
+		assert.Len(t, signature, 7+64)
+		assert.NotEqual(t, signature, Sign(secret, now.Unix()+1, body))
+		assert.NotEqual(t, signature, Sign("another-secret-value", now.Unix(), body))
+	})
+
+	t.Run("rejects a tampered body", func(t *testing.T) {
+		err := Verify(secret, timestamp, signature, []byte(`{"type":"pack_configuration.deleted"}`), now, 5*time.Minute)
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("rejects another secret", func(t *testing.T) {
+		err := Verify("fedcba9876543210", timestamp, signature, body, now, 5*time.Minute)
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("rejects a stale timestamp", func(t *testing.T) {
+		err := Verify(secret, timestamp, signature, body, now.Add(time.Hour), 5*time.Minute)
+		assert.ErrorIs(t, err, ErrStaleTimestamp)
+		require.NoError(t, Verify(secret, timestamp, signature, body, now.Add(time.Hour), 0))
+	})
+
+	t.Run("rejects a malformed timestamp", func(t *testing.T) {
+		assert.Error(t, Verify(secret, "yesterday", signature, body, now, 5*time.Minute))
+	})
+}
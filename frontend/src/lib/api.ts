@@ -20,6 +20,20 @@ export interface AuthResponse {
     token: string;
 }
 
+// etagOf is the ETag the API returns for a configuration version
+const etagOf = (version: number): string => `"${version}"`;
+
+// Raised when an active configuration already uses the name, compared case-insensitively
+export class NameConflictError extends Error {
+    readonly existingId?: number;
+
+    constructor(configurationName: string, existingId?: number) {
+        super(`A pack configuration named "${configurationName}" already exists`);
+        this.name = 'NameConflictError';
+        this.existingId = existingId;
+    }
+}
+
 // TODO: Add a proper API service, separated concerns and with error handling and caching
 
 class ApiService {
@@ -178,7 +192,8 @@ class ApiService {
     async getAllPackConfigurations(): Promise<PackConfiguration[]> {
         try {
             const token = await this.getAuthToken();
-            const response = await fetch(`${this.baseUrl}/pack-configurations`, {
+            // The list is paginated; the selector shows up to the largest page
+            const response = await fetch(`${this.baseUrl}/pack-configurations?limit=500`, {
                 method: 'GET',
                 headers: {
                     'Authorization': `Bearer ${token}`
@@ -233,6 +248,10 @@ class ApiService {
                 body: JSON.stringify(request),
             });
 
+            if (response.status === 409) {
+                const problem = await response.json().catch(() => ({}));
+                throw new NameConflictError(request.name, problem.existing_id);
+            }
             if (!response.ok) {
                 throw new Error(`Failed to create pack configuration: ${response.status}`);
             }
@@ -244,14 +263,27 @@ class ApiService {
         }
     }
 
-    async updatePackConfiguration(id: number, request: UpdatePackConfigurationRequest): Promise<PackConfiguration> {
+    // ifMatch conditions a write on the configuration version the client last read.
+    // Without one the write is refused here rather than sent against whatever version
+    // is current, so a write never overwrites a version the client has not seen.
+    private ifMatch(id: number, version: number | undefined): string {
+        if (!version) {
+            throw new Error(`Pack configuration ${id} has not been read; reload it before changing it`);
+        }
+        return etagOf(version);
+    }
+
+    // Writes to a configuration are conditioned on the version the client last read;
+    // a 412 means someone else changed it in between.
+    async updatePackConfiguration(id: number, version: number | undefined, request: UpdatePackConfigurationRequest): Promise<PackConfiguration> {
         try {
             const token = await this.getAuthToken();
             const response = await fetch(`${this.baseUrl}/pack-configurations/${id}`, {
                 method: 'PUT',
                 headers: {
                     'Content-Type': 'application/json',
-                    'Authorization': `Bearer ${token}`
+                    'Authorization': `Bearer ${token}`,
+                    'If-Match': this.ifMatch(id, version)
                 },
                 body: JSON.stringify(request),
             });
@@ -267,13 +299,14 @@ class ApiService {
         }
     }
 
-    async deletePackConfiguration(id: number): Promise<void> {
+    async deletePackConfiguration(id: number, version: number | undefined): Promise<void> {
         try {
             const token = await this.getAuthToken();
             const response = await fetch(`${this.baseUrl}/pack-configurations/${id}`, {
                 method: 'DELETE',
                 headers: {
-                    'Authorization': `Bearer ${token}`
+                    'Authorization': `Bearer ${token}`,
+                    'If-Match': this.ifMatch(id, version)
                 },
             });
 
@@ -286,13 +319,14 @@ class ApiService {
         }
     }
 
-    async setDefaultPackConfiguration(id: number): Promise<PackConfiguration> {
+    async setDefaultPackConfiguration(id: number, version: number | undefined): Promise<PackConfiguration> {
         try {
             const token = await this.getAuthToken();
             const response = await fetch(`${this.baseUrl}/pack-configurations/${id}/default`, {
                 method: 'PATCH',
                 headers: {
-                    'Authorization': `Bearer ${token}`
+                    'Authorization': `Bearer ${token}`,
+                    'If-Match': this.ifMatch(id, version)
                 },
             });
 
@@ -1,6 +1,6 @@
 import { create } from 'zustand';
 import { persist } from 'zustand/middleware';
-import { apiService } from './api';
+import { apiService, NameConflictError } from './api';
 import type { PackConfiguration, CreatePackConfigurationRequest } from './types';
 
 interface PackSizesStore {
@@ -99,6 +99,9 @@ interface PackConfigurationStore {
     setLoading: (loading: boolean) => void;
 }
 
+const versionOf = (configurations: PackConfiguration[], id: number): number | undefined =>
+    configurations.find(c => c.id === id)?.version;
+
 export const usePackConfigurationStore = create<PackConfigurationStore>()((set, get) => ({
     // Initial state
     configurations: [],
@@ -158,7 +161,7 @@ export const usePackConfigurationStore = create<PackConfigurationStore>()((set,
         } catch (error) {
             console.error('Failed to create configuration:', error);
             set({
-                error: 'Failed to create pack configuration',
+                error: error instanceof NameConflictError ? error.message : 'Failed to create pack configuration',
                 isLoading: false
             });
             throw error;
@@ -168,8 +171,8 @@ export const usePackConfigurationStore = create<PackConfigurationStore>()((set,
     deleteConfiguration: async (id: number) => {
         try {
             set({ isLoading: true, error: null });
-            await apiService.deletePackConfiguration(id);
             const { configurations, selectedConfiguration } = get();
+            await apiService.deletePackConfiguration(id, versionOf(configurations, id));
             const updatedConfigurations = configurations.filter(c => c.id !== id);
             const updatedSelected = selectedConfiguration?.id === id ? null : selectedConfiguration;
             set({
@@ -190,13 +193,12 @@ export const usePackConfigurationStore = create<PackConfigurationStore>()((set,
     setAsDefault: async (id: number) => {
         try {
             set({ isLoading: true, error: null });
-            const updatedConfig = await apiService.setDefaultPackConfiguration(id);
             const { configurations } = get();
+            const updatedConfig = await apiService.setDefaultPackConfiguration(id, versionOf(configurations, id));
 
-            const updatedConfigurations = configurations.map(config => ({
-                ...config,
-                is_default: config.id === id
-            }));
+            // Moving the default gives the configurations losing it a new version too,
+            // so the list is read again for writes to them to match
+            const updatedConfigurations = await apiService.getAllPackConfigurations();
 
             set({
                 configurations: updatedConfigurations,
@@ -30,6 +30,8 @@ export interface PackConfiguration {
     name: string;
     pack_sizes: number[];
     is_default: boolean;
+    is_active: boolean;
+    version: number;
     created_at: string;
     updated_at: string;
 }